@@ -4,7 +4,7 @@ grammar Argo;
 // Rules
 
 sourceFile
-    : packageClause eos ( importClause eos )* ( functionDecl eos )* 
+    : packageClause eos ( importClause eos )* ( ( functionDecl | typeDecl | constDecl ) eos )* 
     ;
 
 packageClause
@@ -16,7 +16,21 @@ importClause
     ;
 
 functionDecl
-    : 'func' IDENTIFIER signature block
+    : 'func' receiver? IDENTIFIER signature block
+    ;
+
+// the receiver of a method, e.g. func (p RouterPkt) route() 
+receiver
+    : parameters
+    ;
+
+// only single type specs for now, e.g. type RouterPkt struct { ... } 
+typeDecl
+    : 'type' typeSpec
+    ;
+
+typeSpec
+    : IDENTIFIER r_type
     ;
 
 block
@@ -59,6 +73,19 @@ simpleStmt
 
 declaration
     : varDecl
+    | constDecl
+    ;
+
+// constant declarations, e.g. const ROUTER_LOG uint32 = 2. The values must be
+// constant expressions, which are folded into the expressions using them.
+// A constSpec of a group may omit its values to repeat those of the one before,
+// e.g. an enum of iota 
+constDecl
+    : 'const' ( constSpec | '(' ( constSpec eos )* ')' )
+    ;
+
+constSpec
+    : identifierList ( r_type? '=' expressionList )?
     ;
 
 varDecl 
@@ -242,6 +269,13 @@ typeLit
     | channelType
     | structType
     | pointerType 
+    | functionType
+    ;
+
+// a function type, e.g. func(int) int. A variable of a function type holds one
+// known function 
+functionType
+    : 'func' signature
     ;
 
 arrayType
@@ -342,6 +376,7 @@ operand
 
 literal
     : basicLit
+    | compositeLit
     | functionLit
     ;
 
@@ -351,6 +386,7 @@ basicLit
     | STRING_LIT
     | HEX_LIT
     | OCTAL_LIT
+    | BINARY_LIT
     ;
 
 operandName
@@ -361,6 +397,40 @@ functionLit
     : 'func' function
     ;
 
+// composite literals, e.g. RouterPkt{dest_port: 1, seq: 2} or struct{a,b uint8}{1,2} 
+compositeLit
+    : literalType literalValue
+    ;
+
+literalType
+    : structType
+    | arrayType
+    | typeName
+    ;
+
+literalValue
+    : '{' ( elementList ','? )? '}'
+    ;
+
+elementList
+    : keyedElement ( ',' keyedElement )*
+    ;
+
+keyedElement
+    : ( key ':' )? element
+    ;
+
+key
+    : IDENTIFIER
+    | expression
+    | literalValue
+    ;
+
+element
+    : expression
+    | literalValue
+    ;
+
 
 
 function
@@ -422,25 +492,32 @@ STRING_LIT
 
 // Integer literals
 
-//int_lit     = decimal_lit | octal_lit | hex_lit .
+//int_lit     = decimal_lit | binary_lit | octal_lit | hex_lit .
 INT_LIT
     : DECIMAL_LIT
+    | BINARY_LIT
     | OCTAL_LIT
     | HEX_LIT
     ;
-//decimal_lit = ( "1" … "9" ) { DECIMAL_DIGIT } .
+//decimal_lit = ( "1" … "9" ) [ [ "_" ] decimal_digits ] .
 DECIMAL_LIT
-    : [1-9] DECIMAL_DIGIT*
+    : [1-9] ( '_'? DECIMAL_DIGIT )*
     ;
 
-//octal_lit   = "0" { octal_digit } .
+//binary_lit  = "0" ( "b" | "B" ) [ "_" ] binary_digits .
+BINARY_LIT
+    : '0' ( 'b' | 'B' ) ( '_'? BINARY_DIGIT )+
+    ;
+
+//octal_lit   = "0" [ "o" | "O" ] [ "_" ] octal_digits .
 OCTAL_LIT
-    : '0' OCTAL_DIGIT*
+    : '0' ( '_'? OCTAL_DIGIT )*
+    | '0' ( 'o' | 'O' ) ( '_'? OCTAL_DIGIT )+
     ;
 
-//hex_lit     = "0" ( "x" | "X" ) hex_digit { hex_digit } .
+//hex_lit     = "0" ( "x" | "X" ) [ "_" ] hex_digits .
 HEX_LIT
-    : '0' ( 'x' | 'X' ) HEX_DIGIT+
+    : '0' ( 'x' | 'X' ) ( '_'? HEX_DIGIT )+
     ;
 
 // Floating-point literals
@@ -475,6 +552,11 @@ DECIMAL_DIGIT
     : [0-9]
     ;
 
+//binary_digit  = "0" | "1" .
+BINARY_DIGIT
+    : [01]
+    ;
+
 //octal_digit   = "0" … "7" .
 OCTAL_DIGIT
     : [0-7]
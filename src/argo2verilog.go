@@ -48,8 +48,11 @@ import (
 	"bufio"
 	"errors"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"log"
+	"encoding/json"
+	"time"
 	// "bytes"
 	"./parser"
 	"github.com/antlr/antlr4/runtime/Go/antlr"
@@ -61,6 +64,13 @@ import (
 
 const NOTSPECIFIED = -1   // not specified, e.g. channel or map size 
 const PARAMETER = -2      // variable is a parameter 
+const BLANKIDENT = "_"    // the blank identifier, which is never a variable 
+
+// the version of the compiler. The git commit and ANTLR runtime version are set
+// when building, e.g. go build -ldflags "-X main.gitCommit=`git rev-parse --short HEAD`" 
+const VERSION = "0.2"
+var gitCommit = "unknown"
+var antlrVersion = ""
 
 // force some control flow in some statements 
 func Pass() {
@@ -108,23 +118,44 @@ func _file_line_() string {
     return s
 }
 
+// get the file name and line number of the caller of the function calling this,
+// e.g. the pass which reported a diagnostic 
+func _caller_file_line_() string {
+	_, fileName, fileLine, ok := runtime.Caller(2)
+	if (!ok) {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", fileName, fileLine)
+}
+
 /* ***************  graph nodes structures definition section   ********************** */
 
+// a problem in the program found by a pass: an error, which fails the translation,
+// or a warning. The passes collect the diagnostics on the listener, so one run
+// reports all of them, and main prints them at the end 
+type Diagnostic struct {
+	severity string   // error or warning 
+	where string      // the file and line of the pass reporting it 
+	row int           // row in the source code 
+	col int           // column in the source code 
+	message string    // what is wrong 
+}
+
 // This is the representation of the parse tree nodes
 type ParseNode struct {
 	id int                // integer ID 
 	ruleType string       // the type of the rule from the Argo.g4 definition
 	isTerminal bool       // is a terminal node 
 	parentID int          // parent integer ID
-	childIDs []int        // list of child interger IDs 
 	parent *ParseNode       // pointer to the parent 
 	children []*ParseNode   // list of pointers to child nodes 
-	sourceCode string     // the source code as a string
+	sourceCode string     // the source code as a string. Empty for interior nodes with -lowmem 
 	sourceLineStart int     // start line in the source code
 	sourceColStart  int     // start column in the source code
 	sourceLineEnd   int     // ending line in the source code
 	sourceColEnd   int     // ending column in the source code
 	visited        bool    // flag for if this node is visited
+	programLines   []string  // the lines of the program, only on the root node with -lowmem 
 }
 
 // this is for the list of functions
@@ -140,6 +171,10 @@ type FunctionNode struct {
 	retVarsIDs    []int         // list of return variables IDs 
 	callers []*StatementNode  // list of statements calling this function
 	goCalls []*StatementNode  // list of statements calling this function
+	deferStmts []*StatementNode  // defer statements in this function, in source order 
+	receiver *VariableNode       // the receiver of a method, nil for a function 
+	verilogName string           // name of the module, if different from the function name 
+	inlineCall *StatementNode    // with -inline, the statement the function is inlined into, else nil 
 }
 	
 // this is the object that holds a variable state 
@@ -150,20 +185,30 @@ type VariableNode struct {
 	astClass    string    // originating class
 	isParameter bool      // is the a parameter to a function
 	isResult bool      // is this a generated return value for the function
-	goLangType  string    // numberic, channel, array or map 
+	isReceiver bool    // is this the receiver of a method. It is not in the parameters 
+	isPointer bool     // a pointer, which names the statically allocated value it points to 
+	goLangType  string    // numberic, channel, array, map or function 
 	sourceName string     // name in the source code
 	sourceRow  int        // row in the source code
 	sourceCol  int        // column in the source code 
 	funcName  string      // which function is this variable defined in
 	primType string        // primitive type, e.g. int, float, uint.
 	numBits     int           // number of bits in this variable
+	goBits      int           // number of bits of the Go type if numBits is narrowed, else 0 
+	sharedWith  *VariableNode // the variable whose register this one shares with -sharemem, else nil 
 	canName string        // cannonical name for Verilog: name_func_row_col
 	depth    int          // depth of a channel (number of element in the queue)               
+	chanDir  string       // direction of a channel: both, send (chan<-) or recv (<-chan)
+	initExpr *ParseNode   // the initializer expression in the declaration, if any
+	initValue string      // reset value from a constant initializer, empty if none 
+	structType *StructType // struct type of a struct variable or channel element, else nil 
 	numDim   int          // number of dimension if an array
 	dimensions []int      // the size of the dimensions 
 	mapKeyType string     // type of the map key
 	mapValType string     // type of the map value
 	cfgNodes  []*CfgNode  // control flow nodes for data-flow 
+	verilogName string    // name of the register, if different from the source name 
+	funcTarget string     // the function a function variable holds 
 	visited        bool    // flag for if this node is visited 
 }
 
@@ -176,6 +221,41 @@ type VarScope struct {
 	visited        bool    // flag if visited 	
 }
 
+// a field of a struct type. The fields are packed into one bit-vector with
+// the first field in the most significant bits 
+type StructField struct {
+	name string            // name of the field 
+	primType string        // primitive type, or the name of a nested struct type
+	numBits int            // number of bits in the field
+	offset int             // bit position of the least significant bit of the field
+	structType *StructType // type of a nested struct field, else nil 
+}
+
+// a struct type from a type declaration, or an anonymous struct type 
+type StructType struct {
+	id int                 // every struct type gets a unique ID
+	typeName string        // name of the type, empty for an anonymous struct 
+	parseDef *ParseNode    // the structType AST node 
+	fields []*StructField  // the fields in source order
+	numBits int            // the width of the packed struct 
+}
+
+
+// a named integer constant from a const declaration. A constant has no register;
+// its value is folded into the expressions which use it 
+type ConstantNode struct {
+	id int                 // every constant gets a unique ID
+	name string            // name in the source code
+	funcName string        // the function declaring the constant, empty for the package 
+	typeName string        // the integer type, e.g. uint32, or empty if untyped 
+	numBits int            // bits of the type, 0 if untyped 
+	value int64            // the value 
+	sourceRow  int         // row in the source code
+	sourceCol  int         // column in the source code 
+	parseDef *ParseNode    // the constSpec AST node 
+	verilogName string     // the localparam of a typed constant, empty if it is a literal 
+	enum bool              // the value is from iota, so even an untyped constant has a localparam 
+}
 
 // holds the nodes for the statement control flow graph
 // The statement graph is modeled on a control flow graph. However, we model blocks as
@@ -200,6 +280,7 @@ type StatementNode struct {
 	vScope  *VarScope         // list of variables in the scope of this statement 
 	readVars       []*VariableNode  // variables read in this statement
 	writeVars      []*VariableNode  // variables written to in this statement
+	writeIndexes   map[*VariableNode][]*ParseNode // the index expressions of a written array or map element, e.g. i for a[i] = x 
 	predecessors   []*StatementNode // list of predicessors
 	predIDs        []int       // IDs of the predicessors
 	successors     []*StatementNode // list of successors
@@ -213,6 +294,7 @@ type StatementNode struct {
 	ifTaken  *StatementNode     // The enclosed block of sub-statements for the taken xpart of an if
 	ifElse   *StatementNode     // The enclosed block of sub-statements for the else clause
 	ifRoot   *StatementNode    // root if stmt if this a simple, test, taken or else node 
+	ifExit   *StatementNode    // the eos shared by every branch of an if/else-if chain 
 	forInit *StatementNode        // the for pre-statement 
 	forCond   *StatementNode     // the for test expression
 	forPost   *StatementNode      // the for post-statement
@@ -220,6 +302,7 @@ type StatementNode struct {
 	forTail    *StatementNode     //  end of the for block
 	forRoot   *StatementNode       // root for stmt if this is an init, cond post or block 
 	caseList   [][]*StatementNode  // list of statements for a switch or select statement
+	label      string              // the label of a labeled statement, e.g. L in L: for { ... } 
 	callTargets []*StatementNode     // regular caller target statement (funcDecl)
 	callers []*StatementNode         // which statements call into this node
 	goTargets   []*StatementNode     // target of go statemetn (funcDecl)
@@ -253,9 +336,27 @@ type CfgNode struct {
         readVars [] *VariableNode        // variables read by the node 
         writeVars [] *VariableNode       // vartiable written by the node 
 	verilog   []* string              // the verilog to output 
+	block     *BasicBlock             // the basic block this node is in 
+	caseComms []*ParseNode            // for a select, the send or receive of each comm clause, for a switch the expression list of each case 
+	caseTargets []*CfgNode            // for a select or switch, the head of each clause
+	defaultTarget *CfgNode            // for a select, the successor when no channel is ready, for a switch when no case matches 
+	caseTag *ParseNode                // for a switch, the tag expression, or nil if there is none 
+	coalesced []*StatementNode        // with -coalesce, the chain of assignments evaluated in this node, in order 
+	coalescedVars []*VariableNode     // the variable each assignment of the chain writes 
         visited bool                     // for graph traversal, if visited or not
 }
 
+// a basic block is a maximal straight-line sequence of control flow nodes.
+// Control only enters at the first node and only leaves from the last node 
+type BasicBlock struct {
+	id int                           // the integer ID of this block
+	funcName string                  // which function this block is in 
+	cfgNodes []*CfgNode              // the control flow nodes in the block, in order
+	successors []*BasicBlock         // blocks that can follow this one
+	predecessors []*BasicBlock       // blocks that could come before this one
+	visited bool                     // for graph traversal, if visited or not
+}
+
 // Functions to add links in the statement graph
 func (node *StatementNode) addStmtSuccessor(succ *StatementNode) {
 	if (succ == nil ) {
@@ -440,8 +541,44 @@ type argoListener struct {
 	funcNameMap map[string]*FunctionNode  //  maps the names of the functions to the function node 
 	statementGraph   []*StatementNode   // list of statement nodes.
 	controlFlowGraph []*CfgNode         // list of control flow nodes
+	basicBlocks      []*BasicBlock      // list of basic blocks 
+	typeSpecMap map[string]*ParseNode   // maps declared type names to their typeSpec AST node
+	structTypeList []*StructType        // list of struct types, named and anonymous 
+	structTypeMap map[string]*StructType // maps the names of struct types to the type 
+	constNodeList []*ConstantNode       // list of named constants, package and function 
+	constIota int64                     // the value of iota in the constSpec being evaluated, -1 outside one 
+	nextBlockID int                     // IDs for the basic blocks 
 	debugFlags     uint64               // flags for debugging. 1 = verilog control 
+	genCombo       bool                 // generate combinational modules for small leaf functions 
+	genFSM         bool                 // generate an encoded state machine instead of one-hot control bits 
+	condWires      bool                 // hoist the conditions of if and for nodes into named wires 
+	condWireNames  map[string]string    // the wire of each condition of the module being output 
+	genPerf        bool                 // generate a cycle counter in every module 
+	traceWrites    bool                 // display the new value of every variable write 
+	assertOneHot   bool                 // stop the simulation if more control bits are set than the control can reach 
+	initArrays     bool                 // clear the memory of every array on reset 
+	mulStyle       string               // comb for a one cycle multiply, seq for a pipelined multiplier 
+	memStyle       string               // the ram_style of array memories, block, distributed or auto, empty for none 
+	ioInterface    string               // start for the start and done bits, readyvalid for ready/valid handshakes 
+	resetSync      bool                 // synchronize the reset of the top module with a two flop synchronizer 
+	mulProducts    map[*ParseNode]string // the product wire of each pipelined multiply 
+	hoistedCalls   []*HoistedCall       // the calls in if and for conditions, made by control nodes before the test 
+	inlineFuncs    bool                 // inline functions called from one statement into the caller 
+	keywordPrefix  string               // prefix of identifiers renamed from Verilog keywords, empty to escape them 
+	diagnostics    []*Diagnostic        // the errors and warnings of the passes, in the order they are found 
+	goNames        map[string]string    // the Go name of each renamed Verilog identifier 
+	intBits        int                  // width of int, uint and untyped integer constants 
+	dataWidth      int                  // with -datawidth, the width of every integer with no explicit size, else 0 
+	maxMem         int                  // largest number of elements of an array or channel memory, 0 for no limit 
+	timescale      string               // the `timescale of the output, e.g. 1ns/1ps, or "" for none 
+	strictNets     bool                 // declare every net, with `default_nettype none 
+	coalesce       bool                 // evaluate short chains of assignments in one control node 
+	skipEmpty      bool                 // remove the control nodes of empty statements and of the eos after simple statements 
+	substitutes    map[*VariableNode]string // the Verilog read for a variable assigned earlier in a coalesced chain 
+	ignoredCalls   map[string]string    // library calls with no hardware meaning, lowered to a noop or a yield 
+	lowMem         bool                 // only keep the source code of terminal parse nodes 
 	moduleName    string                // name of the module for Verilog/VHDL
+	fileName      string                // name of the input source file 
 	outputFile       *os.File           // output file writer
 	debugFile        *os.File           // file for debugging output 
 	
@@ -494,6 +631,25 @@ func (node *ParseNode) walkUpToRule(ruleType string) *ParseNode {
 }
 
 
+// get the name of a function from its functionDecl node. A method is named by
+// the type of its receiver and the method name, e.g. RouterPkt_route, so methods
+// of different types do not collide 
+func (funcDecl *ParseNode) getFuncDeclName() string {
+	var typeNameNode *ParseNode
+
+	if (len(funcDecl.children) < 2) {
+		return ""
+	}
+	if (funcDecl.children[1].ruleType != "receiver") {
+		return funcDecl.children[1].ruleType
+	}
+	typeNameNode = funcDecl.children[1].walkDownToRule("typeName")
+	if (len(funcDecl.children) < 3) || (typeNameNode == nil) || (len(typeNameNode.children) == 0) {
+		return ""
+	}
+	return typeNameNode.children[0].ruleType + "_" + funcDecl.children[2].ruleType
+}
+
 // Walk down the AST until we find a matching rule. Use BFS order
 // Returns the first matching node 
 func (node *ParseNode) walkDownToRule(ruleType string) *ParseNode {
@@ -567,23 +723,24 @@ func (node *ParseNode) walkDownToAllRules(ruleType string) []*ParseNode {
 // add to the list of variables
 
 // given and AST node of type r_type find the primitive type of the node
-// also returns the number of bits of the type 
-func (n *ParseNode) getPrimitiveType() (string,int) {
+// also returns the number of bits of the type. Types without a width in
+// the name, e.g. int, get defaultBits. 
+// Returns an error, with a negative number of bits, if the type can not be found 
+func (n *ParseNode) getPrimitiveType(defaultBits int) (string,int,error) {
 	var identifierType, identifierR_type *ParseNode
 	var name,numB,nameB string  // number of bit as a string, name with no number 
 	var numBits int   // number of bits for this type 
+	var err error 
 
 	// this should not happen
 	if (n == nil) {
-		fmt.Printf("Error at %s in getPrimitive type AST node line \n",_file_line_())
-		return "",-1
+		return "",-1,errors.New("no type AST node")
 	}
 
-	numBits = 32 // default is 32 bits for variables 
+	numBits = defaultBits // default for variables with no width in the type 
 
 	if (len(n.children) == 0){
-		fmt.Printf("Error at %s no children in getPrimitive type node %d\n",_file_line_(),n.id)
-		return "", -2
+		return "",-2,errors.New("type node " + strconv.Itoa(n.id) + " has no children")
 	}
 
 	
@@ -594,16 +751,31 @@ func (n *ParseNode) getPrimitiveType() (string,int) {
 	// the child r_type, then recurse down the tree
 	// to find the primitive type 
 	if (identifierType.ruleType == "typeLit")  {
+		// a function value has no register 
+		if (len(identifierType.children) > 0) && (identifierType.children[0].ruleType == "functionType") {
+			return "func",0,nil
+		}
 		identifierR_type = identifierType.walkDownToRule("r_type")
-		name, numBits = identifierR_type.getPrimitiveType()
-		return name, numBits 
+		if (identifierR_type == nil) {
+			return "",-1,errors.New("unsupported type literal " + strings.TrimSpace(identifierType.getSourceCode()))
+		}
+		name, numBits, err = identifierR_type.getPrimitiveType(defaultBits)
+		return name, numBits, err 
 	}
 	
 	// if the child is a typename, go one level down
 	if (identifierType.ruleType == "typeName")  {
+		if (len(identifierType.children) == 0) {
+			return "",-2,errors.New("type name node " + strconv.Itoa(identifierType.id) + " has no children")
+		}
 		identifierType = identifierType.children[0]
 	}
 
+	// the type must be a single identifier, e.g. not a qualified pkg.Type name 
+	if (len(identifierType.children) != 0) {
+		return "",-1,errors.New("unsupported type " + strings.TrimSpace(identifierType.getSourceCode()))
+	}
+
 	// get the name and number of bits 
 	name = identifierType.ruleType
 
@@ -620,51 +792,242 @@ func (n *ParseNode) getPrimitiveType() (string,int) {
 	}
 
 	//fmt.Printf("get prim type returning %s %d\n",nameB,numBits)		
-	return nameB,numBits
+	return nameB,numBits,nil
 }
 
 // return the dimension sizes of the array
-// assumes we are at the arrayType Node in the AST graph
-func (node *ParseNode) getArrayDimensions() ([] int) {
-	var arrayLenNode, basicLitNode *ParseNode
+// assumes we are at the arrayType Node in the AST graph. A length may be a
+// constant expression of literals and named constants 
+func (l *argoListener) getArrayDimensions(node *ParseNode,funcName string) ([] int, error) {
+	var arrayLenNode *ParseNode
 	var dimensions []int
 	var dimSize int
+	var value int64
+	var ok bool
 	
 	dimensions = make([] int, 0)
 	
 	for _, child := range node.children {
 		arrayLenNode = child.walkDownToRule("arrayLength")
 		if arrayLenNode != nil {
-			basicLitNode = arrayLenNode.walkDownToRule("basicLit")
-			if (basicLitNode != nil) {
-				dimSize, _  = strconv.Atoi(basicLitNode.children[0].ruleType)
-				dimensions = append(dimensions,dimSize)
-			} else {
-				fmt.Printf("Error: at %s getting array dimensions AST node %d \n",_file_line_(),node.id)
+			value, ok = l.evalConstExpr(arrayLenNode.children[0],funcName)
+			if (!ok) {
+				return nil, errors.New("array length is not a constant: " + strings.TrimSpace(arrayLenNode.getSourceCode()))
+			}
+			dimSize = int(value)
+			if (dimSize <= 0) {
+				return nil, errors.New("bad array length " + strings.TrimSpace(arrayLenNode.getSourceCode()))
+			}
+			dimensions = append(dimensions,dimSize)
+		}
+	}
+	if (len(dimensions) == 0) {
+		return nil, errors.New("no array dimensions found in AST node " + strconv.Itoa(node.id))
+	}
+	return dimensions, nil 
+}
+
+// get the capacity of a channel from the make of a declaration or expression, e.g.
+// 4 for make(chan int,4) or a named constant. An unbuffered channel, make(chan int),
+// has capacity 0. Returns -1 (NOTSPECIFIED) if there is no make 
+func (l *argoListener) getChannelDepth(node *ParseNode,funcName string) (int, error) {
+	var capNode *ParseNode
+	var exprs []*ParseNode
+	var hasType bool
+
+	for _, argNode := range node.walkDownToAllRules("arguments") {
+		if (argNode.parent == nil) || (argNode.parent.children[0].getPlainOperandName() != "make") {
+			continue
+		}
+		exprs = nil
+		hasType = false
+		for _, child := range argNode.children {
+			switch child.ruleType {
+			case "r_type":
+				hasType = true
+			case "expressionList":
+				exprs = child.getExpressionList()
 			}
 		}
+		// the type may parse as an expression, e.g. make(T,4) of a named channel type 
+		capNode = nil
+		if (hasType) && (len(exprs) > 0) {
+			capNode = exprs[0]
+		} else if (!hasType) && (len(exprs) > 1) {
+			capNode = exprs[1]
+		}
+		if (capNode == nil) {
+			return 0, nil
+		}
+		value, ok := l.evalConstExpr(capNode,funcName)
+		if (!ok) || (value < 0) {
+			return NOTSPECIFIED, errors.New("the channel capacity " + strings.TrimSpace(capNode.getSourceCode()) + " is not a constant")
+		}
+		return int(value), nil
 	}
-	if (dimensions == nil) {
-		fmt.Printf("Error: at %s no array dimensions found AST node %d \n",_file_line_(),node.id)
+	return NOTSPECIFIED, nil
+}
+
+// get the direction of a channel from a channelType AST node.
+// "chan<- T" is send-only, "<-chan T" is receive-only, "chan T" is both 
+func (node *ParseNode) getChannelDirection() string {
+	if (len(node.children) >= 2) {
+		if (node.children[0].ruleType == "<-") {
+			return "recv"
+		}
+		if (node.children[1].ruleType == "<-") {
+			return "send"
+		}
 	}
-	return dimensions 
+	return "both"
 }
 
-// get the number of elements in the channel
-// or -1 if no size is found 
-func (node *ParseNode) getChannelDepth() (int) {
-	var queueSize int
-	var basicLitNode *ParseNode
+// report an error finding the type of a declaration at its source position 
+func (l *argoListener) declError(node *ParseNode, err error) {
+	l.addDiagnostic("error",_caller_file_line_(),node.sourceLineStart,node.sourceColStart,
+		fmt.Sprintf("declaration %s: %s",strings.TrimSpace(node.getSourceCode()),err))
+}
 
-	queueSize = NOTSPECIFIED
-	basicLitNode = node.walkDownToRule("basicLit")
-	if (basicLitNode != nil) {
-		queueSize, _  = strconv.Atoi(basicLitNode.children[0].ruleType)
-	} else {
-		//fmt.Printf("error: getting channel size AST node %d \n",node.id)
+// add a diagnostic at a source position. A pass run more than once, e.g. for each
+// module, finds the same problem again, so a diagnostic is only added once 
+func (l *argoListener) addDiagnostic(severity string,where string,row int,col int,message string) {
+	for _, diag := range l.diagnostics {
+		if (diag.severity == severity) && (diag.row == row) && (diag.col == col) && (diag.message == message) {
+			return
+		}
 	}
-	
-	return queueSize
+	l.diagnostics = append(l.diagnostics,&Diagnostic{severity: severity, where: where, row: row, col: col, message: message})
+}
+
+// report an error in the program at a source position 
+func (l *argoListener) reportError(row int,col int,format string,args ...interface{}) {
+	l.addDiagnostic("error",_caller_file_line_(),row,col,fmt.Sprintf(format,args...))
+}
+
+// report a possible problem in the program at a source position, which does not
+// fail the translation 
+func (l *argoListener) reportWarning(row int,col int,format string,args ...interface{}) {
+	l.addDiagnostic("warning",_caller_file_line_(),row,col,fmt.Sprintf(format,args...))
+}
+
+// print the diagnostics collected so far, sorted by source position, and clear
+// them. Returns the number of errors 
+func (l *argoListener) printDiagnostics() int {
+	var numErrors int
+	var label string
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		if (l.diagnostics[i].row != l.diagnostics[j].row) {
+			return l.diagnostics[i].row < l.diagnostics[j].row
+		}
+		return l.diagnostics[i].col < l.diagnostics[j].col
+	})
+	numErrors = 0
+	for _, diag := range l.diagnostics {
+		label = "Warning"
+		if (diag.severity == "error") {
+			label = "Error"
+			numErrors++
+		}
+		fmt.Printf("%s at %s: %s:%d:%d: %s \n",label,diag.where,l.fileName,diag.row,diag.col,diag.message)
+	}
+	l.diagnostics = nil
+	return numErrors
+}
+
+// get the k-th initializer expression of a short variable declaration, or nil if
+// there is none, e.g. a call returning several values 
+func (node *ParseNode) getDeclInitializer(k int) *ParseNode {
+	var identifierList *ParseNode
+	var exprList []*ParseNode
+	var numNames int
+
+	if (node.ruleType != "shortVarDecl") || (len(node.children) < 3) {
+		return nil
+	}
+	identifierList = node.children[0]
+	numNames = 0
+	for _, child := range identifierList.children {
+		if (child.ruleType != ",") {
+			numNames++
+		}
+	}
+	exprList = node.children[2].getExpressionList()
+	if (len(exprList) != numNames) || (k >= len(exprList)) {
+		return nil
+	}
+	return exprList[k]
+}
+
+// parse a Go integer literal in any base, e.g. 42, 0x2a, 0o52, 052, 0b101010 or 1_000,
+// and return its value and the bits of a variable it initializes. A hex, octal or
+// binary literal is as wide as its digits, and a decimal literal is defaultBits wide,
+// or wider if the value needs it 
+func parseIntLiteral(lit string,defaultBits int) (int64, int, error) {
+	var value int64
+	var digits string
+	var numBits, bitsPerDigit int
+
+	value, err := strconv.ParseInt(lit,0,64)
+	if (err != nil) {
+		// hex literals may set the sign bit of a 64 bit value 
+		uValue, uErr := strconv.ParseUint(lit,0,64)
+		if (uErr != nil) {
+			return 0, -1, err
+		}
+		value = int64(uValue)
+	}
+
+	digits = strings.Replace(lit,"_","",-1)
+	bitsPerDigit = 0
+	if (len(digits) >= 2) && (digits[0] == '0') {
+		switch digits[1] {
+		case 'x', 'X':
+			bitsPerDigit = 4 ; digits = digits[2:]
+		case 'o', 'O':
+			bitsPerDigit = 3 ; digits = digits[2:]
+		case 'b', 'B':
+			bitsPerDigit = 1 ; digits = digits[2:]
+		default:
+			bitsPerDigit = 3 ; digits = digits[1:]  // legacy octal, e.g. 0755 
+		}
+	}
+	if (bitsPerDigit > 0) {
+		return value, bitsPerDigit*len(digits), nil
+	}
+
+	numBits = defaultBits
+	for (numBits < 64) && (uint64(value) >= (uint64(1) << uint(numBits))) {
+		numBits++
+	}
+	return value, numBits, nil
+}
+
+// if an initializer is a constant, return its value as a Verilog decimal constant.
+// Integer literals, negated integer literals, true and false are constants 
+func (node *ParseNode) getConstantInit() (string, bool) {
+	var inner *ParseNode
+
+	if (node == nil) {
+		return "", false
+	}
+	inner = node.stripParens()
+	if (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && (inner.children[0].ruleType == "-") {
+		if value, ok := inner.children[1].getIntLiteral() ; ok {
+			return strconv.FormatInt(-value,10), true
+		}
+		return "", false
+	}
+	switch strings.TrimSpace(inner.getSourceCode()) {
+	case "true":
+		return "1", true
+	case "false":
+		return "0", true
+	}
+	if value, ok := node.getIntLiteral() ; ok {
+		return strconv.FormatInt(value,10), true
+	}
+	return "", false
 }
 
 // return a variable node by the package, function and variable name 
@@ -686,6 +1049,240 @@ func (l *argoListener) getVarNodeByNames(packageName,funcName,varName string) *V
 	return nil
 }
 
+// get the parse node of the scope of a variable declaration: the block the
+// declaration is in, or the for, if or switch statement or case clause for a
+// declaration in its header. Parameters are in the scope of the function 
+func (vNode *VariableNode) getScopeNode() *ParseNode {
+	if (vNode.parseDef == nil) {
+		return nil
+	}
+	for parent := vNode.parseDef.parent ; parent != nil ; parent = parent.parent {
+		switch parent.ruleType {
+		case "block", "forStmt", "ifStmt", "exprSwitchStmt", "typeSwitchStmt", "exprCaseClause", "typeCaseClause", "commClause", "functionDecl":
+			return parent
+		}
+	}
+	return nil
+}
+
+// return true if a parse node is the same as or a descendant of another 
+func (node *ParseNode) isWithin(ancestor *ParseNode) bool {
+	for n := node ; n != nil ; n = n.parent {
+		if (n == ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// return true if a declared variable can be seen at a use in the parse tree. A
+// variable is visible after its declaration to the end of its scope. The names
+// declared are visible in the declaration itself, but the initializers are not,
+// so in i := i + 1 the right hand side reads the enclosing scope's i 
+func (vNode *VariableNode) isVisibleAt(useNode *ParseNode) bool {
+	var scope, decl *ParseNode
+
+	decl = vNode.parseDef
+	scope = vNode.getScopeNode()
+	if (decl == nil) || (scope == nil) || (vNode.isParameter) || (vNode.isResult) {
+		return true
+	}
+	if (!useNode.isWithin(scope)) {
+		return false
+	}
+	if (useNode.isWithin(decl)) {
+		for n := useNode ; n != decl ; n = n.parent {
+			if (n.ruleType == "identifierList") {
+				return true
+			}
+		}
+		return (useNode == decl)
+	}
+	return (useNode.sourceLineStart > decl.sourceLineEnd) ||
+		((useNode.sourceLineStart == decl.sourceLineEnd) && (useNode.sourceColStart >= decl.sourceColEnd))
+}
+
+// return the variable a name refers to at a use in the parse tree. A variable
+// declared in a nested scope shadows the variables of the same name in the
+// enclosing scopes, so the innermost visible declaration is returned 
+func (l *argoListener) getVarNodeInScope(funcName string,varName string,useNode *ParseNode) *VariableNode {
+	var found *VariableNode
+	var foundDepth, depth int
+
+	if (useNode == nil) {
+		return l.getVarNodeByNames("",funcName,varName)
+	}
+	// an inlined function shares the module of its caller, so a use is in the
+	// scope of the function which encloses it 
+	if (l.inlineFuncs) {
+		if enclosing := useNode.getEnclosingFuncName() ; (enclosing != "") && (l.moduleFuncName(enclosing) == l.moduleFuncName(funcName)) {
+			funcName = enclosing
+		}
+	}
+	foundDepth = -1
+	for _, varNode := range l.varNodeList {
+		if (varNode.funcName != funcName) || (varNode.sourceName != varName) || (!varNode.isVisibleAt(useNode)) {
+			continue
+		}
+		depth = 0
+		for scope := varNode.getScopeNode() ; scope != nil ; scope = scope.parent {
+			depth++
+		}
+		// a later declaration in the same scope re-declares the name 
+		if (depth >= foundDepth) {
+			found = varNode
+			foundDepth = depth
+		}
+	}
+	return found
+}
+
+// return the variable a name of a short var decl re-declares, or nil if it declares
+// a new variable. Go assigns a variable declared earlier in the same scope, e.g. a
+// in a, b := f(), rather than declaring it again. The parameters and results are in
+// the scope of the body of the function 
+func (l *argoListener) getRedeclaredVar(newVar *VariableNode) *VariableNode {
+	var newScope, scope *ParseNode
+
+	newScope = newVar.getScopeNode()
+	if (newVar.parseDef == nil) || (newScope == nil) {
+		return nil
+	}
+	for _, varNode := range l.varNodeList {
+		if (varNode.funcName != newVar.funcName) || (varNode.sourceName != newVar.sourceName) ||
+			(varNode.parseDef == newVar.parseDef) || (!varNode.isVisibleAt(newVar.parseDef)) {
+			continue
+		}
+		scope = varNode.getScopeNode()
+		if (scope == newScope) {
+			return varNode
+		}
+		if (scope != nil) && (scope.ruleType == "functionDecl") && (newScope.parent != nil) &&
+			(newScope.parent.ruleType == "function") && (newScope.parent.parent == scope) {
+			return varNode
+		}
+	}
+	return nil
+}
+
+// give each variable which shadows a variable of the same name in its function a
+// distinct register name, made from its source position 
+func (l *argoListener) nameShadowedVariables() {
+	var declared map[string]bool
+	var key string
+
+	declared = make(map[string]bool)
+	for _, varNode := range l.varNodeList {
+		key = varNode.funcName + "." + varNode.sourceName
+		if (declared[key]) {
+			varNode.verilogName = varNode.sourceName + "_" + strconv.Itoa(varNode.sourceRow) + "_" + strconv.Itoa(varNode.sourceCol)
+		}
+		declared[key] = true
+	}
+}
+
+// the reserved words of Verilog-2005, and the names the compiler declares in every
+// module. A Go identifier can be any of them, e.g. a function named input 
+var verilogKeywords = map[string]bool{
+	"always": true, "and": true, "assign": true, "automatic": true, "begin": true, "buf": true, "bufif0": true,
+	"bufif1": true, "case": true, "casex": true, "casez": true, "cell": true, "cmos": true, "config": true,
+	"deassign": true, "default": true, "defparam": true, "design": true, "disable": true, "edge": true, "else": true,
+	"end": true, "endcase": true, "endconfig": true, "endfunction": true, "endgenerate": true, "endmodule": true,
+	"endprimitive": true, "endspecify": true, "endtable": true, "endtask": true, "event": true, "for": true,
+	"force": true, "forever": true, "fork": true, "function": true, "generate": true, "genvar": true,
+	"highz0": true, "highz1": true, "if": true, "ifnone": true, "incdir": true, "include": true, "initial": true,
+	"inout": true, "input": true, "instance": true, "integer": true, "join": true, "large": true, "liblist": true,
+	"library": true, "localparam": true, "macromodule": true, "medium": true, "module": true, "nand": true,
+	"negedge": true, "nmos": true, "nor": true, "noshowcancelled": true, "not": true, "notif0": true, "notif1": true,
+	"or": true, "output": true, "parameter": true, "pmos": true, "posedge": true, "primitive": true, "pull0": true,
+	"pull1": true, "pulldown": true, "pullup": true, "pulsestyle_onevent": true, "pulsestyle_ondetect": true,
+	"rcmos": true, "real": true, "realtime": true, "reg": true, "release": true, "repeat": true, "rnmos": true,
+	"rpmos": true, "rtran": true, "rtranif0": true, "rtranif1": true, "scalared": true, "showcancelled": true,
+	"signed": true, "small": true, "specify": true, "specparam": true, "strong0": true, "strong1": true,
+	"supply0": true, "supply1": true, "table": true, "task": true, "time": true, "tran": true, "tranif0": true,
+	"tranif1": true, "tri": true, "tri0": true, "tri1": true, "triand": true, "trior": true, "trireg": true,
+	"unsigned": true, "use": true, "uwire": true, "vectored": true, "wait": true, "wand": true, "weak0": true,
+	"weak1": true, "while": true, "wire": true, "wor": true, "xnor": true, "xor": true,
+	"clock": true, "rst": true, "start": true, "done": true, "ce": true, "state": true, "start_held": true,
+	"cycle_count": true,
+}
+
+// make a Go identifier a legal Verilog identifier. Characters Verilog does not allow
+// are removed, and a name which is a keyword gets the keyword prefix. With an empty
+// prefix the name is an escaped identifier, e.g. \reg followed by a space 
+func (l *argoListener) sanitizeIdentifier(name string) string {
+	var clean string
+
+	clean = regexp.MustCompile("[^A-Za-z0-9_$]").ReplaceAllString(name,"")
+	if (clean != "") && (!verilogKeywords[clean]) && (clean[0] != '$') && ( (clean[0] < '0') || (clean[0] > '9') ) {
+		return clean
+	}
+	if (l.keywordPrefix == "") {
+		return "\\" + name + " "
+	}
+	return l.keywordPrefix + clean
+}
+
+// rename the registers and modules whose names are not legal Verilog identifiers.
+// The Go name of each renamed identifier is kept for the messages 
+func (l *argoListener) sanitizeIdentifiers() {
+	var name, clean string
+
+	l.goNames = make(map[string]string)
+	for _, varNode := range l.varNodeList {
+		name = varNode.sourceName
+		if (varNode.verilogName != "") {
+			name = varNode.verilogName
+		}
+		if clean = l.sanitizeIdentifier(name) ; clean != name {
+			varNode.verilogName = clean
+			l.goNames[clean] = varNode.sourceName
+		}
+	}
+	for _, funcNode := range l.funcNodeList {
+		if clean = l.sanitizeIdentifier(funcNode.funcName) ; clean != funcNode.funcName {
+			funcNode.verilogName = clean
+			l.goNames[clean] = funcNode.funcName
+		}
+	}
+	l.nameTypedConstants()
+}
+
+// name the localparams of the typed constants and of the iota enums. A constant of a
+// function is suffixed with the function name, as an inlined function shares the
+// module of its caller. A constant whose name is taken by a register or port stays a literal 
+func (l *argoListener) nameTypedConstants() {
+	var taken map[string]bool
+	var name string
+
+	taken = map[string]bool{"clock": true, "rst": true, "start": true, "done": true}
+	for _, varNode := range l.varNodeList {
+		taken[verilogVarName(varNode)] = true
+	}
+	for _, cNode := range l.constNodeList {
+		cNode.verilogName = ""
+		if (cNode.numBits == 0) && (!cNode.enum) {
+			continue
+		}
+		name = cNode.name
+		if (cNode.funcName != "") {
+			name = cNode.name + "_" + cNode.funcName
+		}
+		if name = l.sanitizeIdentifier(name) ; !taken[name] {
+			cNode.verilogName = name
+			l.goNames[name] = cNode.name
+		}
+	}
+}
+
+// the Go name of a Verilog identifier, which is the identifier unless it was renamed 
+func (l *argoListener) goName(name string) string {
+	if goName, ok := l.goNames[name] ; ok {
+		return goName
+	}
+	return name
+}
+
 // get a function node by string name 
 func (l *argoListener) getFuncNodeByNames(packageName,funcName string) *FunctionNode {
 
@@ -712,21 +1309,22 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 	var returnVarList []*VariableNode // list of vars to return 
 	var funcDecl *ParseNode
 	var identifierList,identifierR_type *ParseNode
-	var funcName *ParseNode  // AST node of the function and function name
+	var funcStr string       // name of the function, or the type and name of a method 
 	var identChild *ParseNode // AST node for an identifier for the inferred type 
 	// the three type of declarations are: varDecl (var keyword), parameterDecls (in a function signature), and shortVarDecls (:=)
 
 	var varNameList []string
 	var varNode     *VariableNode 
 	var varTypeStr string  // the type pf the var 
-	var arrayTypeNode,channelTypeNode,mapTypeNode *ParseNode // if the variables are this class
+	var arrayTypeNode,channelTypeNode,mapTypeNode,funcTypeNode *ParseNode // if the variables are this class
 	var numBits int        // number of bits in the type
 	var depth int          // channel depth (size of the buffer) 
 	var dimensions [] int  // slice which holds array dimensions 
+	var err error          // error finding the type of a declaration 
 	
 	
 	funcDecl = nil
-	funcName = nil
+	funcStr = ""
 	identifierList = nil
 	varTypeStr = ""
 	numBits = NOTSPECIFIED
@@ -744,9 +1342,9 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 		
 		funcDecl = node.walkUpToRule("functionDecl")
 		if (len(funcDecl.children) < 2) {  // need assertions here 
-			fmt.Printf("Error at %s: no function name",_file_line_())
+			l.reportError(node.sourceLineStart,node.sourceColStart,"no function name for the declaration")
 		}
-		funcName = funcDecl.children[1]
+		funcStr = funcDecl.getFuncDeclName()
 		// now get the name and type of the actual declaration.
 		// getting both the name and type depends on the kind of declaration it is 
 		if ( (node.ruleType == "varDecl") || (node.ruleType== "parameterDecl") || (node.ruleType == "shortVarDecl"))  {
@@ -770,7 +1368,7 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 					//continue ParseNodeLoop ;
 					return returnVarList 
 				}
-				fmt.Printf("Error at %s: no identifier list",_file_line_())
+				l.reportError(node.sourceLineStart,node.sourceColStart,"no identifier list in the declaration")
 				return returnVarList
 			}
 
@@ -782,25 +1380,22 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 			// if we assign a constant to a variable, we need to infer the
 			// type of the constant which becomes the type of the variable 
 			// TODO: need a better function to infer the type here
-			if identifierR_type == nil {
+			if (identifierR_type == nil) && (l.typedConstExpr(node.getDeclInitializer(0),funcStr) != "") {
+				// a typed constant gives its type, e.g. k := ROUTER_LOG for
+				// const ROUTER_LOG uint32 = 2 is a uint32 
+				typeName := l.typedConstExpr(node.getDeclInitializer(0),funcStr)
+				numBits, _, _ = intTypeBits(typeName,l.intBits)
+				varTypeStr = regexp.MustCompile("[a-z]+").FindString(typeName)
+			} else if identifierR_type == nil {
 				identifierR_type = node.walkDownToRule("basicLit")
 				if identifierR_type != nil {
 					identChild  =  identifierR_type.children[0]
 					numStr := identChild.ruleType
 						
-					_, err := strconv.ParseInt(numStr,0,64)
+					_, litBits, err := parseIntLiteral(numStr,l.intBits)
 					if err == nil {
 						varTypeStr = "int"
-						if (len(numStr) >= 2) {
-							if ( (numStr[0] == byte("0"[0])) &&
-								((numStr[1] == byte("x"[0])) || (numStr[1] == byte("X"[0])))) {
-								numBits = 4*( len(numStr)-2) // make size = to number of digits 
-							} else { 
-								numBits = 32  // default size is 32 bit ints 
-							}
-						} else {
-							numBits = 32  // default size is 32 bit ints 
-						}
+						numBits = litBits
 					} else {
 						_, err := strconv.ParseFloat(identChild.ruleType,32)
 						if err == nil {
@@ -815,14 +1410,41 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 				}
 				
 			} else { 
-				varTypeStr,numBits = identifierR_type.getPrimitiveType()
+				varTypeStr,numBits,err = identifierR_type.getPrimitiveType(l.intBits)
+				if (err != nil) {
+					l.declError(node,err)
+					return returnVarList
+				}
 			}
 
+			// a function type may have arrays or channels in its signature 
+			funcTypeNode = nil
+			if (identifierR_type != nil) && (identifierR_type.ruleType == "r_type") {
+				funcTypeNode = identifierR_type.walkDownToRule("functionType")
+			}
 			arrayTypeNode = node.walkDownToRule("arrayType")
 			
 			// check if these are arrays or channels 
-			if ( arrayTypeNode != nil) {
-				dimensions = arrayTypeNode.getArrayDimensions()
+			if (funcTypeNode != nil) {
+				arrayTypeNode = nil
+				varTypeStr = "func"
+				numBits = 0
+			} else if ( arrayTypeNode != nil) {
+				dimensions, err = l.getArrayDimensions(arrayTypeNode,funcStr)
+				if (err != nil) {
+					l.declError(node,err)
+					return returnVarList
+				}
+				// an array of channels is a bank of FIFOs, one for each element.
+				// The depth is set from the makes of its elements 
+				channelTypeNode = arrayTypeNode.walkDownToRule("channelType")
+				if (channelTypeNode != nil) {
+					if (node.ruleType == "parameterDecl") {
+						l.declError(node,errors.New("an array of channels can not be a parameter"))
+						return returnVarList
+					}
+					depth = 1
+				}
 			} else {
 				channelTypeNode = node.walkDownToRule("channelType")
 
@@ -833,10 +1455,15 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 					// parameter 
 					depth = -2
 					if ((node.ruleType == "varDecl") || (node.ruleType == "shortVarDecl")) {
-						// any literal as a child is used as the depth. This might not always work. 
-						depth = node.getChannelDepth()
-						// default to 1 if no depth is found 
-						if (depth == NOTSPECIFIED) {
+						// the FIFO holds the capacity of the make 
+						depth, err = l.getChannelDepth(node,funcStr)
+						if (err != nil) {
+							l.declError(node,err)
+							return returnVarList
+						}
+						// an unbuffered channel, or one with no make, is a FIFO of one
+						// element, as the FIFO must hold the value until it is received 
+						if (depth == NOTSPECIFIED) || (depth == 0) {
 							depth = 1
 						}
 					}else {
@@ -859,18 +1486,22 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 				
 			}
 
-			for _, varName := range varNameList {
-				// fmt.Printf("found variable in func %s name: %s type: %s:%d",funcName.sourceCode,varName,varTypeStr,numBits)
+			for k, varName := range varNameList {
+				// fmt.Printf("found variable in func %s name: %s type: %s:%d",funcStr,varName,varTypeStr,numBits)
+				// the blank identifier keeps its position, so k still indexes the initializers 
+				if (varName == BLANKIDENT) {
+					continue
+				}
 				varNode = new(VariableNode)
 				varNode.id = l.nextVarID ; l.nextVarID++
 				varNode.parseDef = node
 				varNode.parseDefNum = node.id
 				varNode.astClass = node.ruleType
-				varNode.funcName = funcName.sourceCode
+				varNode.funcName = funcStr
 				varNode.sourceName  = varName
 				varNode.sourceRow = node.sourceLineStart
 				varNode.sourceCol = node.sourceColStart
-				varNode.canName = varName + "_" + funcName.sourceCode + "_" + strconv.Itoa(node.sourceLineStart) + "_" + strconv.Itoa(node.sourceColStart)
+				varNode.canName = varName + "_" + funcStr + "_" + strconv.Itoa(node.sourceLineStart) + "_" + strconv.Itoa(node.sourceColStart)
 				varNode.primType = varTypeStr
 				varNode.numBits = numBits
 				varNode.visited = false
@@ -886,14 +1517,34 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 				if (channelTypeNode != nil) {
 					varNode.goLangType = "channel"
 					varNode.depth = depth 
+					varNode.chanDir = channelTypeNode.getChannelDirection()
 				}
 				if (mapTypeNode != nil) {
 					varNode.goLangType = "map"
 
 				}
+				if (funcTypeNode != nil) {
+					varNode.goLangType = "function"
+				}
 				
 				if (node.ruleType== "parameterDecl") {
 					varNode.isParameter = true 
+					varNode.isReceiver = (node.parent != nil) && (node.parent.parent != nil) && (node.parent.parent.parent != nil) &&
+						(node.parent.parent.parent.ruleType == "receiver")
+				}
+				varNode.initExpr = node.getDeclInitializer(k)
+				varNode.initValue, _ = varNode.initExpr.getConstantInit()
+
+				// a pointer names the value it points to. A short var decl of new(T)
+				// is a pointer to a T 
+				varNode.isPointer = identifierR_type.isPointerType()
+				if newType := l.getNewType(varNode.initExpr) ; newType != nil {
+					varNode.isPointer = true
+					varNode.primType, varNode.numBits, _, err = l.getFieldType(newType,make(map[string]bool))
+					if (err != nil) {
+						l.declError(node,err)
+						return returnVarList
+					}
 				}
 					
 				// add this to a list of the variable nodes
@@ -919,2572 +1570,5703 @@ func (l *argoListener) getParseVariables( node *ParseNode) []*VariableNode {
 }
 
 
+// get all the variables of the program. Each declaration is made into
+// variables by getParseVariables, and each range clause by getRangeVariables.
+// Returns the number of variables found 
 func (l *argoListener) getAllVariables() int {
-	var funcDecl *ParseNode
-	var identifierList,identifierR_type *ParseNode
-	var funcName *ParseNode  // AST node of the function and function name
-	var identChild *ParseNode // AST node for an identifier for the inferred type 
-	// the three type of declarations are: varDecl (var keyword), parameterDecls (in a function signature), and shortVarDecls (:=)
-
-	var varNameList []string
-	var varNode     *VariableNode 
-	var varTypeStr string  // the type pf the var 
-	var arrayTypeNode,channelTypeNode,mapTypeNode *ParseNode // if the variables are this class
-	var numBits int        // number of bits in the type
-	var depth int          // channel depth (size of the buffer) 
-	var dimensions [] int  // slice which holds array dimensions 
-	
-	
-	funcDecl = nil
-	funcName = nil
-	identifierList = nil
-	varTypeStr = ""
-	numBits = NOTSPECIFIED
-	dimensions = nil
-	depth = 1
-	
-	varNameList = make([] string, 1)     // list of names of the variables 
-	arrayTypeNode = nil
-	channelTypeNode = nil
+	var numVars int
 
-	// for every AST node, see if it is a declaration
-	// if so, name the variable the _function_name_name
-	// for multiple instances of go functions, add the instance number
-	ParseNodeLoop: 
+	numVars = 0
 	for _, node := range l.ParseNodeList {
-		// find the enclosing function name
-		if (node.ruleType == "varDecl") || (node.ruleType == "parameterDecl") || (node.ruleType == "shortVarDecl") {
-
-
-			funcDecl = node.walkUpToRule("functionDecl")
-			if (len(funcDecl.children) < 2) {  // need assertions here 
-				fmt.Printf("Error at %s: no function name",_file_line_())
+		switch node.ruleType {
+		case "rangeClause":
+			for _, rangeVar := range l.getRangeVariables(node) {
+				l.addVarNode(rangeVar)
+				numVars++
 			}
-			funcName = funcDecl.children[1]
-			// now get the name and type of the actual declaration.
-			// getting both the name and type depends on the kind of declaration it is 
-			if ( (node.ruleType == "varDecl") || (node.ruleType== "parameterDecl") || (node.ruleType == "shortVarDecl"))  {
-
-				// we dont know what the types are yet for this declaraion
-				varNameList = nil
-				arrayTypeNode = nil
-				channelTypeNode = nil
-				
-				// find the list of identifiers as strings for these rules
-				identifierList = node.walkDownToRule("identifierList")
-
-
-				// if the identifierList is nil and the rule is a parameterdecl
-				// these are the functions return parameters
-				// We create special hidden vars for the return values in
-				// the function parsing as the return variables 
-				// are not named variables with AST nodes 
-				if (identifierList == nil) {
-					if (node.ruleType == "parameterDecl") {
-						continue ParseNodeLoop ;
-					}
-					fmt.Printf("Error at %s: no identifier list",_file_line_())
-					return 0
+		case "varDecl", "parameterDecl":
+			for _, varNode := range l.getParseVariables(node) {
+				l.addVarNode(varNode)
+				numVars++
+			}
+		case "shortVarDecl":
+			// the names declared before in the same scope are assigned 
+			numNew := 0
+			newVars := l.getParseVariables(node)
+			for _, varNode := range newVars {
+				if (l.getRedeclaredVar(varNode) != nil) {
+					continue
 				}
+				l.addVarNode(varNode)
+				numVars++
+				numNew++
+			}
+			if (len(newVars) > 0) && (numNew == 0) {
+				l.reportError(node.sourceLineStart,node.sourceColStart,"no new variables on left side of :=")
+			}
+		}
+	}
+	return numVars
+}
 
-				// get the type for this Decl rule
-				identifierR_type = node.walkDownToRule("r_type")
-				
-				varTypeStr = ""; numBits = -1
-
-				// if we assign a constant to a variable, we need to infer the
-				// type of the constant which becomes the type of the variable 
-				// TODO: need a better function to infer the type here
-				if identifierR_type == nil {
-					identifierR_type = node.walkDownToRule("basicLit")
-					if identifierR_type != nil {
-						identChild  =  identifierR_type.children[0]
-						numStr := identChild.ruleType
-						
-						_, err := strconv.ParseInt(numStr,0,64)
-						if err == nil {
-							varTypeStr = "int"
-							if (len(numStr) >= 2) {
-								if ( (numStr[0] == byte("0"[0])) &&
-									((numStr[1] == byte("x"[0])) || (numStr[1] == byte("X"[0])))) {
-									numBits = 4*( len(numStr)-2) // make size = to number of digits 
-								} else { 
-									numBits = 32  // default size is 32 bit ints 
-								}
-							} else {
-								numBits = 32  // default size is 32 bit ints 
-							}
-						} else {
-							_, err := strconv.ParseFloat(identChild.ruleType,32)
-							if err == nil {
-								varTypeStr = "float" 
-							} else {
-								fmt.Printf("primitive type failed for node %d\n",node.id )
-							}
-						}
- 
-					} else {  // if there is no name, this probably a return parameterDecl. 
-                                                  // these dont have a name, so we need to make one up 
-					}
-					
-				} else { 
-					varTypeStr,numBits = identifierR_type.getPrimitiveType()
-				}
+// set the depth of the FIFOs of each array of channels. An element is made by an
+// assignment, e.g. cs[i] = make(chan int,4), so the bank is as deep as the deepest
+// make of its elements. A close of an element is not supported.
+// Returns the number of arrays of channels 
+func (l *argoListener) setChannelArrayDepths() int {
+	var lhsList, rhsList []*ParseNode
+	var vNode *VariableNode
+	var numArrays int
 
-				arrayTypeNode = node.walkDownToRule("arrayType")
-				
-				// check if these are arrays or channels 
-				if ( arrayTypeNode != nil) {
-					dimensions = arrayTypeNode.getArrayDimensions()
-				} else {
-					channelTypeNode = node.walkDownToRule("channelType")
-
-
-					if ( channelTypeNode!= nil) {
-						// channels in parameters do not have a depth
-						// set to -2 as a flag for a channel in a
-						// parameter 
-						depth = -2
-						if ((node.ruleType == "varDecl") || (node.ruleType == "shortVarDecl")) {
-							// any literal as a child is used as the depth. This might not always work. 
-							depth = node.getChannelDepth()
-							// default to 1 if no depth is found 
-							if (depth == NOTSPECIFIED) {
-								depth = 1
-							}
-						}else {
-							depth = PARAMETER
-						}
-					} else {
-						mapTypeNode = node.walkDownToRule("mapType")
-						if ( mapTypeNode!= nil) {
-							// a map 
-						}
-					}
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType == "arguments") {
+			closeArg := l.getCloseArg(node)
+			if (closeArg == nil) {
+				continue
+			}
+			if name, indexes := getArrayElement(closeArg.stripParens()) ; len(indexes) > 0 {
+				vNode = l.getVarNodeInScope(node.getEnclosingFuncName(),name,closeArg)
+				if (vNode != nil) && (vNode.goLangType == "channel") {
+					l.reportError(node.sourceLineStart,node.sourceColStart,"close of an element of the array of channels %s is not supported",
+						name)
 				}
-				
-				// create list of variable for all the children of this Decl rule 
-				for _, child := range identifierList.children {
-					if (child.ruleType != ","){
-						varNameList = append(varNameList,child.ruleType)
+			}
+			continue
+		}
+		if (node.ruleType != "assignment") || (len(node.children) < 3) {
+			continue
+		}
+		lhsList = node.children[0].getExpressionList()
+		rhsList = node.children[2].getExpressionList()
+		if (len(lhsList) != len(rhsList)) {
+			continue
+		}
+		for k, lhs := range lhsList {
+			name, indexes := getArrayElement(lhs.stripParens())
+			if (len(indexes) == 0) || (rhsList[k].walkDownToRule("channelType") == nil) {
+				continue
+			}
+			vNode = l.getVarNodeInScope(node.getEnclosingFuncName(),name,lhs)
+			if (vNode == nil) || (vNode.goLangType != "channel") {
+				continue
+			}
+			if depth, err := l.getChannelDepth(rhsList[k],node.getEnclosingFuncName()) ; (err == nil) && (depth > vNode.depth) {
+				vNode.depth = depth
+			}
+		}
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.goLangType == "channel") && (vNode.numDim > 0) {
+			numArrays++
+		}
+	}
+	return numArrays
+}
 
-					}
+// get all the struct types declared in the source file.
+// Every typeSpec is recorded by name first, so a field may name a struct type
+// declared later in the file 
+func (l *argoListener) getAllStructTypes() int {
+	var err error
 
-				}
+	l.typeSpecMap = make(map[string]*ParseNode)
+	l.structTypeMap = make(map[string]*StructType)
 
-				for _, varName := range varNameList {
-					// fmt.Printf("found variable in func %s name: %s type: %s:%d",funcName.sourceCode,varName,varTypeStr,numBits)
-					varNode = new(VariableNode)
-					varNode.id = l.nextVarID ; l.nextVarID++
-					varNode.parseDef = node
-					varNode.parseDefNum = node.id
-					varNode.astClass = node.ruleType
-					varNode.funcName = funcName.sourceCode
-					varNode.sourceName  = varName
-					varNode.sourceRow = node.sourceLineStart
-					varNode.sourceCol = node.sourceColStart
-					varNode.canName = varName + "_" + funcName.sourceCode + "_" + strconv.Itoa(node.sourceLineStart) + "_" + strconv.Itoa(node.sourceColStart)
-					varNode.primType = varTypeStr
-					varNode.numBits = numBits
-					varNode.visited = false
-					varNode.isParameter = false
-					varNode.isResult = false 
-					varNode.goLangType = "numeric"  // default 
-					if (arrayTypeNode != nil) {
-						varNode.dimensions = dimensions
-						varNode.numDim = len(dimensions) 
-						varNode.goLangType = "array"
-						
-					} 
-					if (channelTypeNode != nil) {
-						varNode.goLangType = "channel"
-						varNode.depth = depth 
-					}
-					if (mapTypeNode != nil) {
-						varNode.goLangType = "map"
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType == "typeSpec") && (len(node.children) == 2) {
+			l.typeSpecMap[node.children[0].ruleType] = node
+		}
+	}
 
-					}
-					
-					if (node.ruleType== "parameterDecl") {
-						varNode.isParameter = true 
-					}
-					
-					// add this to a list of the variable nodes
-					// for this program 
-					l.addVarNode(varNode)
-					
-				}
-				
-				// Given the function name, type and variable names in the list
-				// create a new variable node 
-				
-			} else if (node.ruleType == "shorVarDecl") {
-				// short variable declaration 
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType == "typeSpec") && (len(node.children) == 2) {
+			_, _, _, err = l.getNamedType(node.children[0].ruleType,make(map[string]bool))
+			if (err != nil) {
+				l.declError(node,err)
+			}
+		}
+	}
+	return len(l.structTypeList)
+}
+
+// get all the named constants of const declarations. A constant may use the
+// constants before it, and package constants may be declared in any order, so the
+// constants are evaluated until no more can be. Returns the number of constants 
+func (l *argoListener) getAllConstants() int {
+	var pending []*ParseNode
+	var progress bool
+
+	l.constNodeList = nil
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType == "constSpec") {
+			pending = append(pending,node)
+		}
+	}
+
+	progress = true
+	for (len(pending) > 0) && (progress) {
+		var unresolved []*ParseNode
+
+		progress = false
+		for _, node := range pending {
+			if (l.addConstants(node,false)) {
+				progress = true
 			} else {
-				fmt.Printf("Major Error\n ")
+				unresolved = append(unresolved,node)
 			}
 		}
+		pending = unresolved
+	}
+	// report the constants which are not constant expressions 
+	for _, node := range pending {
+		l.addConstants(node,true)
+	}
+	return len(l.constNodeList)
+}
 
+// add the constants of a constSpec if all the values can be evaluated. Returns
+// false if a value uses a constant which is not known yet 
+func (l *argoListener) addConstants(node *ParseNode,report bool) bool {
+	var identifierList, exprListNode, typeNode *ParseNode
+	var names []string
+	var exprList []*ParseNode
+	var values []int64
+	var typeNames []string
+	var funcName, typeName string
+	var ok bool
+
+	identifierList = node.walkDownToRule("identifierList")
+	iota, valueSpec := node.constSpecIota()
+	if (valueSpec == nil) {
+		if (report) {
+			l.declError(node,errors.New("missing constant values"))
+		}
+		return false
 	}
-	if (funcName == nil) {
-		return 0
+	exprListNode = valueSpec.walkDownToRule("expressionList")
+	if (identifierList == nil) || (exprListNode == nil) {
+		return false
 	}
-	if (identifierList == nil) {
-		return 0 
+	for _, child := range identifierList.children {
+		if (child.ruleType != ",") {
+			names = append(names,child.ruleType)
+		}
+	}
+	exprList = exprListNode.getExpressionList()
+	if (len(names) != len(exprList)) {
+		if (report) {
+			l.declError(node,errors.New(strconv.Itoa(len(names)) + " names but " + strconv.Itoa(len(exprList)) + " values"))
+		}
+		return false
 	}
+	funcName = node.getEnclosingFuncName()
 
-	return 1
-	
+	typeName = ""
+	for _, child := range valueSpec.children {
+		if (child.ruleType == "r_type") {
+			typeNode = child
+		}
+	}
+	if (typeNode != nil) {
+		typeName = strings.TrimSpace(typeNode.getSourceCode())
+		if _, _, ok = intTypeBits(typeName,l.intBits) ; !ok {
+			if (report) {
+				l.declError(node,errors.New("constants must have an integer type, not " + typeName))
+			}
+			return false
+		}
+	}
+
+	l.constIota = iota
+	defer func() { l.constIota = -1 }()
+	for _, expr := range exprList {
+		value, ok := l.evalConstExpr(expr,funcName)
+		if (!ok) {
+			if (report) {
+				l.declError(node,errors.New("not a constant integer expression: " + strings.TrimSpace(expr.getSourceCode())))
+			}
+			return false
+		}
+		// with no type, an expression of a typed constant has its type, e.g.
+		// DEPTH = LOG + 1 is a uint32 for const LOG uint32 = 2 
+		exprType := typeName
+		if (typeNode == nil) {
+			exprType = l.typedConstExpr(expr,funcName)
+		}
+		if (exprType != "") {
+			value = truncateConst(value,exprType,l.intBits)
+		}
+		values = append(values,value)
+		typeNames = append(typeNames,exprType)
+	}
+
+	for k, name := range names {
+		cNode := new(ConstantNode)
+		cNode.id = len(l.constNodeList)
+		cNode.name = name
+		cNode.funcName = funcName
+		cNode.typeName = typeNames[k]
+		cNode.numBits, _, _ = intTypeBits(typeNames[k],l.intBits)
+		cNode.value = values[k]
+		cNode.sourceRow = node.sourceLineStart
+		cNode.sourceCol = node.sourceColStart
+		cNode.parseDef = node
+		cNode.enum = (exprList[k].walkDownToRule("iota") != nil)
+		l.constNodeList = append(l.constNodeList,cNode)
+	}
+	return true
 }
 
-// Rules for edge dangles:
-// Returns always jump to the exit node
-// If statements ends jump to the sucessor of the If statement
-// for statements return to the top of the for statement 
-// continue return to the outer for statement
-// break jumps to the successor of the enclosing for statement
+// get the value of iota in a constSpec, which is its index in the group of its
+// constDecl, and the constSpec holding its values: itself, or the last one before
+// it with values. Returns nil if no constSpec of the group has values 
+func (node *ParseNode) constSpecIota() (int64, *ParseNode) {
+	var iota int64
+	var valueSpec *ParseNode
 
-// link the 
-func (l *argoListener) linkDangles(parentHead,parentTail *StatementNode) int {
-	var nextChild *StatementNode
-	var childTail *StatementNode 
-	// var forPost *StatementNode
-	// get the successor of the parent.
+	if (node.parent == nil) {
+		return 0, node
+	}
+	iota = 0
+	for _, sibling := range node.parent.children {
+		if (sibling.ruleType != "constSpec") {
+			continue
+		}
+		if (sibling.walkDownToRule("expressionList") != nil) {
+			valueSpec = sibling
+		}
+		if (sibling == node) {
+			return iota, valueSpec
+		}
+		iota++
+	}
+	return 0, node
+}
 
-	var count int
+// the type name of a constant expression of a typed constant, e.g. uint32 for
+// ROUTER_LOG + 1, or "" if the expression is not constant or is untyped 
+func (l *argoListener) typedConstExpr(pNode *ParseNode,funcName string) string {
+	var inner *ParseNode
 
-	
-	if (parentHead == nil) {
-		fmt.Printf("Error! linkDangles called with nil parent %s\n", _file_line_())		
-		return 0 
+	if (pNode == nil) {
+		return ""
 	}
-
-	
-	if (parentHead.child == nil) {
-		return 0 
+	if _, ok := l.evalConstExpr(pNode,funcName) ; !ok {
+		return ""
+	}
+	inner = pNode.stripParens()
+	switch inner.ruleType {
+	case "operand", "operandName":
+		if cNode := l.getConstant(funcName,inner.getPlainOperandName()) ; cNode != nil {
+			return cNode.typeName
+		}
+	case "expression":
+		if (len(inner.children) != 3) {
+			return ""
+		}
+		// a shift has the type of its left operand 
+		if (inner.children[1].ruleType == "<<") || (inner.children[1].ruleType == ">>") {
+			return l.typedConstExpr(inner.children[0],funcName)
+		}
+		if typeName := l.typedConstExpr(inner.children[0],funcName) ; typeName != "" {
+			return typeName
+		}
+		return l.typedConstExpr(inner.children[2],funcName)
+	case "unaryExpr":
+		if (len(inner.children) == 2) {
+			return l.typedConstExpr(inner.children[1],funcName)
+		}
+	}
+	if typeName, operand := l.getIntConversion(inner,funcName) ; operand != nil {
+		return typeName
 	}
+	return ""
+}
 
-	if (parentHead.visited == true) {
-		return 0 
+// get a named constant visible in a function. A constant of the function hides a
+// package constant of the same name 
+func (l *argoListener) getConstant(funcName string,name string) *ConstantNode {
+	var found *ConstantNode
+
+	for _, cNode := range l.constNodeList {
+		if (cNode.name != name) {
+			continue
+		}
+		if (cNode.funcName == funcName) && (funcName != "") {
+			return cNode
+		}
+		if (cNode.funcName == "") {
+			found = cNode
+		}
 	}
+	return found
+}
 
-	count = 0
-	nextChild = parentHead.child
-	childTail = nil
-	parentHead.visited = true
+// truncate a constant value to an integer type, sign extending a signed type 
+func truncateConst(value int64,typeName string,intBits int) int64 {
+	bits, signed, ok := intTypeBits(typeName,intBits)
+	if (!ok) || (bits >= 64) {
+		return value
+	}
+	value = value & int64((uint64(1) << uint(bits)) - 1)
+	if (signed) && (((value >> uint(bits-1)) & 1) == 1) {
+		value = value - int64(uint64(1) << uint(bits))
+	}
+	return value
+}
 
-	// find the tail of the 
-	for {
-		switch nextChild.stmtType { 
-		case "declaration": 
-		case "labeledStmt":
-		case "goStmt":
-		case "returnStmt":
-		case "breakStmt":
-		case "continueStmt":
-		case "gotoStmt":
-		case "fallthroughStmt":
-		case "ifStmt": // go down each branch to set the links to the successor if this if statement 
-			if (nextChild.ifTaken != nil) {
-				count += l.linkDangles(nextChild.ifTaken,nextChild.successors[0])
-			}
-			if (nextChild.ifElse != nil) {
-				count += l.linkDangles(nextChild.ifElse,nextChild.successors[0])
-			}						
-		case "switchStmt":
-		case "selectStmt":
-		case "forStmt":
-			if (nextChild.child != nil) {
-				count += l.linkDangles(nextChild,nextChild.successors[0])
-			}									
-		case "sendStmt":
-		case "expressionStmt":
-		case "incDecStmt":
-		case "assignment":
-		case "shortVarDecl":
-		case "emptyStmt":
+// evaluate a constant integer expression of literals and named constants, e.g.
+// (1<<ROUTER_LOG) or uint32(ROUTER_DEPTH+1). Returns false if the expression is
+// not constant 
+func (l *argoListener) evalConstExpr(node *ParseNode,funcName string) (int64, bool) {
+	var inner *ParseNode
+	var lhs, rhs int64
+	var ok bool
 
-		default:
-			Pass()
+	if (node == nil) {
+		return 0, false
+	}
+	inner = node.stripParens()
+	if value, ok := inner.getIntLiteral() ; ok {
+		return value, true
+	}
+	if name := inner.getPlainOperandName() ; name != "" {
+		// a variable of the same name hides a constant 
+		if (funcName != "") && (l.getVarNodeByNames("",funcName,name) != nil) {
+			return 0, false
 		}
-		
-
-		if (len(nextChild.successors) > 0) {  
-			nextChild = nextChild.successors[0] // walk to the next successor 
-		} else { // no succesor, link to the target
-			childTail = nextChild
-			if (parentHead.stmtType == "forStmt") {
-				childTail.addStmtSuccessor(parentHead) // loop back to top of the for loop
-			} else { 
-				parentTail.addStmtPredecessor(childTail)  // fall through to the outer statement 
-				childTail.addStmtSuccessor(parentTail)   
-			}
-			count ++
-			break 
+		if cNode := l.getConstant(funcName,name) ; cNode != nil {
+			return cNode.value, true
 		}
-	} // end for 
-
-	return count 
+		if (name == "iota") && (l.constIota >= 0) {
+			return l.constIota, true
+		}
+		return 0, false
+	}
+	if typeName, operand := l.getIntConversion(inner,funcName) ; operand != nil {
+		if lhs, ok = l.evalConstExpr(operand,funcName) ; !ok {
+			return 0, false
+		}
+		return truncateConst(lhs,typeName,l.intBits), true
+	}
+	if (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) {
+		if lhs, ok = l.evalConstExpr(inner.children[1],funcName) ; !ok {
+			return 0, false
+		}
+		switch inner.children[0].ruleType {
+		case "-":
+			return -lhs, true
+		case "+":
+			return lhs, true
+		case "^":
+			return ^lhs, true
+		}
+		return 0, false
+	}
+	if (inner.ruleType != "expression") || (len(inner.children) != 3) {
+		return 0, false
+	}
+	if lhs, ok = l.evalConstExpr(inner.children[0],funcName) ; !ok {
+		return 0, false
+	}
+	if rhs, ok = l.evalConstExpr(inner.children[2],funcName) ; !ok {
+		return 0, false
+	}
+	switch inner.children[1].ruleType {
+	case "+":
+		return lhs + rhs, true
+	case "-":
+		return lhs - rhs, true
+	case "*":
+		return lhs * rhs, true
+	case "/":
+		if (rhs != 0) {
+			return lhs / rhs, true
+		}
+	case "%":
+		if (rhs != 0) {
+			return lhs % rhs, true
+		}
+	case "<<":
+		if (rhs >= 0) && (rhs < 64) {
+			return lhs << uint(rhs), true
+		}
+	case ">>":
+		if (rhs >= 0) && (rhs < 64) {
+			return lhs >> uint(rhs), true
+		}
+	case "&":
+		return lhs & rhs, true
+	case "|":
+		return lhs | rhs, true
+	case "^":
+		return lhs ^ rhs, true
+	case "&^":
+		return lhs &^ rhs, true
+	}
+	return 0, false
 }
 
-// parse an ifStmt AST node into a statement graph nodes
-// return a list of lists of any sub-statements from the blocks 
-// The structure is to create new statement nodes for all the childern in a main loop looking for
-// the types of the children nodes. Then the function creates the predecessors and successor edges
-func (l *argoListener) parseIfStmt(ifNode *ParseNode,funcDecl *ParseNode,ifStmt *StatementNode,eosStmt *StatementNode) []*StatementNode {
-	var funcName *ParseNode               // name of the function
-	var funcStr  string                 // name of the function as a string 
-	var subNode        *ParseNode         // sub-simple statement type
-	var ifSubStmtNode *ParseNode          // if we have an ifSubstatement, put a pointer to it here 
-	
-	var childStmt, simpleStmt, testStmt, takenStmt, elseStmt,subIfStmt *StatementNode
-	var takenHead,takenTail, elseHead,elseTail, headStmt, tailStmt *StatementNode
-	
-	var seenBlockCount int;             // Counts blocks in the children. First It is the taken branch, second the else 
-	var statements []*StatementNode       // list of statmements 
-	var blocklist []*StatementNode          // list of statements for the block 
-	childStmt = nil; simpleStmt = nil;  testStmt = nil; takenStmt = nil; elseStmt = nil; subIfStmt = nil 
-	
-	seenBlockCount = 0
-	statements = nil
+// get the type of a declared type name. Struct types are built on first use, other
+// declared types are aliases of their underlying type.
+// The resolving set holds the names being built, to catch recursive types 
+func (l *argoListener) getNamedType(typeName string,resolving map[string]bool) (string,int,*StructType,error) {
+	var typeSpec, structNode *ParseNode
+	var sType *StructType
+	var err error
 
-	// get the name of the function the ifStmt is in  
-	funcName = funcDecl.children[1]
-	funcStr = funcName.sourceCode
-	ifSubStmtNode = nil 
-	// loop for each child and create the appropriate sub-statement node
-	// after looping through all the children, we fix up the successors and predecessors edges
-	ifNode.visited = true 
-	for _, childNode := range ifNode.children {
+	if sType, ok := l.structTypeMap[typeName] ; ok {
+		return sType.typeName, sType.numBits, sType, nil
+	}
+	typeSpec, ok := l.typeSpecMap[typeName]
+	if (!ok) {
+		return "",-1,nil,errors.New("unknown type " + typeName)
+	}
+	if (resolving[typeName]) {
+		return "",-1,nil,errors.New("recursive type " + typeName)
+	}
+	resolving[typeName] = true
+	defer delete(resolving,typeName)
 
-		childStmt = nil
-		// note the child statement can be nil around this loop if the type is not one of
+	structNode = typeSpec.children[1].getStructTypeNode()
+	if (structNode == nil) {
+		return l.getFieldType(typeSpec.children[1],resolving)
+	}
+	sType, err = l.makeStructType(typeName,structNode,resolving)
+	if (err != nil) {
+		return "",-1,nil,err
+	}
+	l.structTypeMap[typeName] = sType
+	return sType.typeName, sType.numBits, sType, nil
+}
 
-		// simpleStmt, expression, block of ifStmt
-		if (childNode.visited == false) {
+// if an r_type AST node is a struct type literal, return the structType node, else nil 
+func (node *ParseNode) getStructTypeNode() *ParseNode {
+	if (node == nil) || (len(node.children) == 0) || (node.children[0].ruleType != "typeLit") {
+		return nil
+	}
+	if (len(node.children[0].children) == 0) || (node.children[0].children[0].ruleType != "structType") {
+		return nil
+	}
+	return node.children[0].children[0]
+}
 
-			childNode.visited = true 
+// get the primitive type and number of bits of a field from its r_type AST node.
+// Named and anonymous struct fields return their struct type 
+func (l *argoListener) getFieldType(rType *ParseNode,resolving map[string]bool) (string,int,*StructType,error) {
+	var structNode *ParseNode
+	var sType *StructType
+	var primType string
+	var numBits int
+	var err error
 
-			// create a new node and populate it, set the type later 
-			if ( (childNode.ruleType == "simpleStmt") || (childNode.ruleType == "expression") ||
-				(childNode.ruleType == "ifStmt")) {
-				childStmt = new(StatementNode)
-				childStmt.id = l.nextStatementID; l.nextStatementID++ 
-				childStmt.parseDef = childNode
-				childStmt.parseDefID =  childNode.id 
-				childStmt.funcName = funcStr
-				childStmt.sourceRow =  childNode.sourceLineStart 
-				childStmt.sourceCol =  childNode.sourceColStart
-				childStmt.parent = ifStmt
-				childStmt.parentID = ifStmt.id
-				
-				// set the variable scope to be the same as the if statement's scope 
-				childStmt.vScope = ifStmt.vScope 
-				childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
-				
-				// add this to the global list of statements 
-				l.statementGraph = append(l.statementGraph,childStmt)
+	if (rType == nil) || (len(rType.children) == 0) {
+		return "",-1,nil,errors.New("no type AST node")
+	}
+	if (rType.children[0].ruleType == "typeName") && (len(rType.children[0].children) == 1) {
+		if _, ok := l.typeSpecMap[rType.children[0].children[0].ruleType] ; ok {
+			return l.getNamedType(rType.children[0].children[0].ruleType,resolving)
+		}
+	}
+	structNode = rType.getStructTypeNode()
+	if (structNode != nil) {
+		sType, err = l.getAnonStructType(structNode,resolving)
+		if (err != nil) {
+			return "",-1,nil,err
+		}
+		return "struct", sType.numBits, sType, nil
+	}
+	if (rType.children[0].ruleType == "typeLit") {
+		return "",-1,nil,errors.New("unsupported field type " + strings.TrimSpace(rType.getSourceCode()))
+	}
+	primType, numBits, err = rType.getPrimitiveType(l.intBits)
+	return primType, numBits, nil, err
+}
 
-			}
+// get the struct type of an anonymous structType AST node, building it on first use 
+func (l *argoListener) getAnonStructType(structNode *ParseNode,resolving map[string]bool) (*StructType,error) {
+	for _, sType := range l.structTypeList {
+		if (sType.parseDef == structNode) {
+			return sType, nil
+		}
+	}
+	return l.makeStructType("",structNode,resolving)
+}
 
-			// set the node type 
-			if (childNode.ruleType == "simpleStmt") {
-				simpleStmt = childStmt
+// build a struct type from a structType AST node and add it to the list of struct types.
+// Offsets are assigned from the last field, so the first field is the most significant 
+func (l *argoListener) makeStructType(typeName string,structNode *ParseNode,resolving map[string]bool) (*StructType,error) {
+	var sType *StructType
+	var field *StructField
+	var primType string
+	var numBits, offset int
+	var fieldStruct *StructType
+	var err error
 
-				subNode =  childNode.children[0]
-				simpleStmt.stmtType = subNode.ruleType
-				simpleStmt.parseSubDef = subNode 
-				simpleStmt.parseSubDefID =  subNode.id
+	sType = new(StructType)
+	sType.typeName = typeName
+	sType.parseDef = structNode
 
+	for _, fieldDecl := range structNode.children {
+		if (fieldDecl.ruleType != "fieldDecl") || (len(fieldDecl.children) < 2) {
+			continue
+		}
+		primType, numBits, fieldStruct, err = l.getFieldType(fieldDecl.children[1],resolving)
+		if (err != nil) {
+			return nil, err
+		}
+		for _, child := range fieldDecl.children[0].children {
+			if (child.ruleType != ",") {
+				field = new(StructField)
+				field.name = child.ruleType
+				field.primType = primType
+				field.numBits = numBits
+				field.structType = fieldStruct
+				sType.fields = append(sType.fields,field)
 			}
+		}
+	}
 
-			if (childNode.ruleType == "expression") {
-				testStmt = childStmt
-				subNode =  childNode.children[0]
-				testStmt.stmtType = subNode.ruleType
-				testStmt.parseSubDef = subNode 
-				testStmt.parseSubDefID =  subNode.id
-			}
+	offset = 0
+	for i := len(sType.fields) - 1 ; i >= 0 ; i-- {
+		sType.fields[i].offset = offset
+		offset = offset + sType.fields[i].numBits
+	}
+	sType.numBits = offset
+	if (sType.numBits == 0) {
+		return nil, errors.New("struct type " + strings.TrimSpace(structNode.getSourceCode()) + " has no fields")
+	}
 
-			// if we have  block, recurse down the block to get the resulting statement list
-			// also fix up the child to go to the statement, not the statementlist 
-			if  (childNode.ruleType == "block") { 
-				statementListNode := childNode.children[1] // get the list of statements in the block 
-				blocklist = l.getListOfStatements(statementListNode,ifStmt,funcDecl)
-				
-				// get the list of statements from the statementlist 
-				if (len(blocklist) >0)  {
-					// statements = append(statements,slist...) dont make global list 
-					// connect the taken of else clause back to this node 
-					headStmt = blocklist[0]
-					tailStmt = blocklist[len(blocklist)-1] 
-				}
-			}
+	sType.id = len(l.structTypeList)
+	l.structTypeList = append(l.structTypeList,sType)
+	return sType, nil
+}
 
-			// this is the else block, unless the else is another if statement 
-			if (seenBlockCount == 1)  && (childNode.ruleType == "block") {
-				elseStmt = headStmt
-				seenBlockCount = 2
-				elseHead = headStmt
-				elseTail = tailStmt 
-			}
+// get the field of a struct type by name, or nil if there is no such field 
+func (sType *StructType) getField(fieldName string) *StructField {
+	for _, field := range sType.fields {
+		if (field.name == fieldName) {
+			return field
+		}
+	}
+	return nil
+}
 
-			// this is the taken branch block
-			// this has to come after the previous statement to make this work 
-			if (seenBlockCount == 0) && (childNode.ruleType == "block") {
-				takenStmt = headStmt
-				seenBlockCount = 1 
-				takenHead = headStmt
-				takenTail = tailStmt
-			}
+// if an expression is only a composite literal, return the compositeLit AST node, else nil 
+func (node *ParseNode) getCompositeLit() *ParseNode {
+	var inner *ParseNode
 
-			// this is the if () {block } else if {} construct when the else is an if statement 
-			if (childNode.ruleType == "ifStmt") {
-				childStmt.stmtType = "ifStmt"
-				ifSubStmtNode = childNode
-				subIfStmt = childStmt
-				blocklist = l.parseIfStmt(ifSubStmtNode,funcDecl,subIfStmt,eosStmt)
-				if (len(blocklist) >0) {
-					elseHead = blocklist[0]
-					elseTail = blocklist[len(blocklist)-1]
-				}
-			}
-		} // end if not visited 
-		
-	} // end for children of isStmt 
+	if (node == nil) {
+		return nil
+	}
+	inner = node.stripParens()
+	for ((inner.ruleType == "operand") || (inner.ruleType == "literal")) && (len(inner.children) == 1) {
+		inner = inner.children[0]
+	}
+	if (inner.ruleType == "compositeLit") && (len(inner.children) == 2) {
+		return inner
+	}
+	return nil
+}
 
-	
-	// add links to the main if statement for the control flow graph 
-	ifStmt.ifSimple = simpleStmt
-	ifStmt.ifTest = testStmt
-	ifStmt.ifTaken = takenStmt
+// get the struct type of a composite literal from its literal type, or nil if
+// the literal is not a struct 
+func (l *argoListener) getLiteralStructType(compLit *ParseNode) *StructType {
+	var litType *ParseNode
 
-	// Assertions that must hold for every if statements 
-	if (testStmt == nil) {
-		fmt.Printf("Error! at %s no test with if statement at AST node id %d\n", _file_line_(),ifNode.id)
-		return nil 
-	}  else {
-		testStmt.ifRoot = ifStmt
+	litType = compLit.children[0]
+	if (len(litType.children) == 0) {
+		return nil
 	}
-
-	if (takenStmt == nil) {
-		fmt.Printf("Error! at %s no taken block with if statement at AST node id %d\n", _file_line_(),ifNode.id)
-		return nil 
-	} else {
-		// takenStmt.ifRoot = ifStmt 
+	switch litType.children[0].ruleType {
+	case "typeName":
+		if (len(litType.children[0].children) == 1) {
+			return l.structTypeMap[litType.children[0].children[0].ruleType]
+		}
+	case "structType":
+		sType, _ := l.getAnonStructType(litType.children[0],make(map[string]bool))
+		return sType
 	}
+	return nil
+}
 
+// get the struct type of a variable, or of the elements of a channel or array.
+// A short var decl of a composite literal has the type of the literal 
+func (l *argoListener) getVarStructType(vNode *VariableNode) *StructType {
+	var rType, structNode *ParseNode
 
-	// santiy check, both the else an sub if statement can not be set 
-	if (elseStmt != nil) && (subIfStmt != nil) {
-		fmt.Printf("Error! at %s both else and if sub-statement set AST node id %d\n", _file_line_(),ifNode.id)
-		fmt.Printf("Error! at %s statement len %d %s\n",_file_line_(),len(statements),statements)
-		//elseStmt.ifRoot = ifStmt 		
+	if (vNode.initExpr != nil) {
+		if compLit := vNode.initExpr.getCompositeLit() ; compLit != nil {
+			return l.getLiteralStructType(compLit)
+		}
+		if newType := l.getNewType(vNode.initExpr) ; newType != nil {
+			_, _, sType, _ := l.getFieldType(newType,make(map[string]bool))
+			return sType
+		}
+	}
+	if (vNode.parseDef == nil) || (vNode.astClass == "rangeClause") {
+		return nil
+	}
+	rType = vNode.parseDef.walkDownToRule("r_type")
+	for (rType != nil) && (len(rType.children) > 0) {
+		switch rType.children[0].ruleType {
+		case "typeName":
+			if (len(rType.children[0].children) == 1) {
+				return l.structTypeMap[rType.children[0].children[0].ruleType]
+			}
+			return nil
+		case "typeLit":
+			structNode = rType.getStructTypeNode()
+			if (structNode != nil) {
+				sType, _ := l.getAnonStructType(structNode,make(map[string]bool))
+				return sType
+			}
+			// a pointer has the struct type it points to 
+			if (rType.isPointerType()) {
+				rType = rType.children[0].children[0].walkDownToRule("r_type")
+				continue
+			}
+			// the element type of a channel, array or slice 
+			rType = rType.children[0].walkDownToRule("elementType").walkDownToRule("r_type")
+		default:
+			return nil
+		}
 	}
+	return nil
+}
 
-	// if we passed the above sanity check/assertion, we can set the sub-if statement 
-	if (subIfStmt != nil) {
-		elseStmt = elseHead 
-		ifStmt.ifElse = subIfStmt
-		testStmt.addStmtSuccessor(subIfStmt)
-		subIfStmt.addStmtSuccessor(eosStmt)
-		subIfStmt.addStmtPredecessor(ifStmt)		
-		eosStmt.addStmtPredecessor(subIfStmt)
-		//subIfStmt.ifRoot = ifStmt 
-	} else if (elseStmt != nil) {
-		ifStmt.ifElse = elseStmt
+// set the types of the variables declared by a receive, e.g. v := <-ch, or by a
+// comma-ok receive, e.g. v, ok := <-ch. The value has the element type of the
+// channel and ok is a bool. 
+// Must be called after the struct types of the channels are resolved 
+func (l *argoListener) resolveRecvVariables() {
+	var recvNode *ParseNode
+	var chanVar *VariableNode
+	var names []string
 
+	for _, vNode := range l.varNodeList {
+		if (vNode.astClass != "shortVarDecl") || (vNode.parseDef == nil) {
+			continue
+		}
+		recvNode = vNode.parseDef.getCommaOkRecv()
+		if (recvNode == nil) && (vNode.initExpr != nil) && (vNode.goLangType == "numeric") {
+			// the initializer of the variable is a receive 
+			recvNode = vNode.initExpr.stripParens()
+			if (recvNode.ruleType != "unaryExpr") || (len(recvNode.children) != 2) || (recvNode.children[0].ruleType != "<-") {
+				recvNode = nil
+			}
+		}
+		if (recvNode == nil) {
+			continue
+		}
+		names = nil
+		for _, child := range vNode.parseDef.children[0].children {
+			if (child.ruleType != ",") {
+				names = append(names,child.ruleType)
+			}
+		}
+		if (len(names) == 2) && (vNode.parseDef.getCommaOkRecv() != nil) && (names[1] == vNode.sourceName) {
+			vNode.primType = "bool"
+			vNode.numBits = 1
+			vNode.structType = nil
+			continue
+		}
+		chanVar, _ = l.getChannelOperand(recvNode.children[1],vNode.funcName)
+		if (chanVar == nil) {
+			l.reportError(recvNode.sourceLineStart,recvNode.sourceColStart,"receive of %s is not from a channel",
+				strings.TrimSpace(recvNode.getSourceCode()))
+			continue
+		}
+		vNode.primType = chanVar.primType
+		vNode.numBits = chanVar.numBits
+		vNode.structType = chanVar.structType
 	}
+}
 
-	// This sets the pred and successor links for the simple statement 
-	if (simpleStmt != nil) {
-				
-		statements = append(statements,simpleStmt)
+// set the type and width of variables, channels and arrays of struct types.
+// Must be called after the variables and functions are found 
+func (l *argoListener) resolveStructVariables() {
+	var sType *StructType
 
-		simpleStmt.addStmtSuccessor(testStmt)
-		testStmt.addStmtPredecessor(simpleStmt)
-		simpleStmt.ifRoot = ifStmt 
+	for _, vNode := range l.varNodeList {
+		sType = l.getVarStructType(vNode)
+		if (sType != nil) {
+			vNode.structType = sType
+			vNode.primType = sType.typeName
+			if (sType.typeName == "") {
+				vNode.primType = "struct"
+			}
+			vNode.numBits = sType.numBits
+		}
 	}
+}
 
-	// there must always be a test and taken statement
-	statements = append(statements,testStmt)
-	testStmt.addStmtSuccessor(takenStmt)
-	takenStmt.addStmtPredecessor(testStmt)
-	//takenStmt.addStmtSuccessor(eosStmt)
-	//takenStmt.ifRoot = ifStmt 
-
-	statements = append(statements,takenStmt)
+// get the variables declared by a range clause with a short variable declaration,
+// e.g. for i, v := range a. The index and value are ints. The blank identifier
+// declares no variable 
+func (l *argoListener) getRangeVariables(node *ParseNode) []*VariableNode {
+	var rangeVars []*VariableNode
+	var varNode *VariableNode
+	var funcDecl *ParseNode
+	var funcStr string
+	var names []string
 
-	if (takenTail != nil) {
-		takenTail.addStmtSuccessor(eosStmt)		
+	funcDecl = node.walkUpToRule("functionDecl")
+	if (funcDecl == nil) || (len(funcDecl.children) < 2) {
+		l.reportError(node.sourceLineStart,node.sourceColStart,"no function name for the range clause")
+		return nil
 	}
+	funcStr = funcDecl.getFuncDeclName()
 
-	// the else and subIf are interchangable as the 2nd clause 
-	if (elseStmt != nil) && (subIfStmt == nil) {
-		testStmt.addStmtSuccessor(elseStmt)
-		elseStmt.addStmtPredecessor(testStmt)
-		elseTail.addStmtSuccessor(eosStmt)
-	} 
-
-	if (takenHead != nil) || (takenTail != nil) || (elseHead != nil) || (elseTail !=nil) {
-		//fmt.Printf("IF statement at %s statement len %d \n",_file_line_(),len(statements))
-		Pass()
+	if (len(node.children) >= 2) && (node.children[0].ruleType == "identifierList") && (node.children[1].ruleType == ":=") {
+		for _, child := range node.children[0].children {
+			if (child.ruleType == ",") || (child.ruleType == BLANKIDENT) {
+				continue
+			}
+			names = append(names,child.ruleType)
+		}
 	}
-	
-	return statements 
+	// a range over an integer with no named counter, e.g. for range n, still
+	// needs a counter register to count the loops 
+	if (len(names) == 0) && (node.children[0].ruleType != "expressionList") && (l.isRangeOverInt(node,funcStr)) {
+		names = append(names,"range_" + strconv.Itoa(node.sourceLineStart) + "_" + strconv.Itoa(node.sourceColStart))
+	}
+
+	for _, name := range names {
+		varNode = new(VariableNode)
+		varNode.id = l.nextVarID ; l.nextVarID++
+		varNode.parseDef = node
+		varNode.parseDefNum = node.id
+		varNode.astClass = node.ruleType
+		varNode.funcName = funcStr
+		varNode.sourceName = name
+		varNode.sourceRow = node.sourceLineStart
+		varNode.sourceCol = node.sourceColStart
+		varNode.canName = name + "_" + funcStr + "_" + strconv.Itoa(node.sourceLineStart) + "_" + strconv.Itoa(node.sourceColStart)
+		varNode.primType = "int"
+		varNode.numBits = l.intBits
+		varNode.goLangType = "numeric"
+		rangeVars = append(rangeVars,varNode)
+	}
+	return rangeVars
 }
 
-// This parses a for statement.
-// It tried to get the block and forClause first. Then it walks the children of the for clause and creates new
-// statement nodes as it walks the forClause. The end of the function creates the edges between the statement nodes 
-func (l *argoListener) parseForStmt(forNode *ParseNode,funcDecl *ParseNode,forStmt,eosStmt *StatementNode) []*StatementNode {
-	var funcName *ParseNode               // name of the function
-	var funcStr  string                 // name of the function
-	var forClauseNode  *ParseNode              //  if this statement has a for clause
-	var forBlockNode   *ParseNode             // the block of statements for the for
-	var subNode        *ParseNode         // sub-simple statement type
-	var statements []*StatementNode       // list of statmements 
-	var blocklist []*StatementNode          // list of statements for the block
+// return true if a range clause ranges over an integer, e.g. for i := range n,
+// which loops n times. The range expression is a constant or a numeric variable,
+// not an array or channel 
+func (l *argoListener) isRangeOverInt(node *ParseNode,funcName string) bool {
+	var rangeExpr *ParseNode
+	var name string
 
-	var childStmt *StatementNode       
-	var blockStmt, initStmt, conditionStmt, postStmt  *StatementNode // statement nodes for the for statement
-	var blockHead, blockTail *StatementNode   // the head and tail of the block statement 
-	var seenSimple int
-	
-	statements = nil
-	blocklist = nil
-	
-	// get the name of the function the ifStmt is in  
-	funcName = funcDecl.children[1]
-	funcStr = funcName.sourceCode // ToDO: change this to use the ruleType instead of the source code 
+	if (node == nil) || (node.ruleType != "rangeClause") || (len(node.children) == 0) {
+		return false
+	}
+	rangeExpr = node.children[len(node.children)-1]
+	if _, ok := rangeExpr.getIntLiteral() ; ok {
+		return true
+	}
+	name = rangeExpr.getPlainOperandName()
+	if (name == "") {
+		return false
+	}
+	vNode := l.getVarNodeInScope(funcName,name,node)
+	return (vNode != nil) && (vNode.goLangType == "numeric") && (vNode.structType == nil)
+}
 
-	// loop for each child and create the appropriate sub-statement node
-	// after looping through all the children, we fix up the successors and predecessors edges
-	forStmt.visited = true
-	forClauseNode = nil
-	forBlockNode = nil
-	blockStmt = nil
-	seenSimple = 0  // how many simple statements have we seen?
+// return the counter variable of a range over an integer. This is the variable
+// assigned by the range clause, or the hidden counter if it names none 
+func (l *argoListener) getRangeCounter(node *ParseNode,funcName string) *VariableNode {
+	if (node.children[0].ruleType == "expressionList") {
+		exprList := node.children[0].getExpressionList()
+		if (len(exprList) != 1) {
+			return nil
+		}
+		return l.getVarNodeInScope(funcName,exprList[0].getPlainOperandName(),node)
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.parseDef == node) {
+			return vNode
+		}
+	}
+	return nil
+}
 
-	// walk the for statement clauses
-	// create a new statement node, except for the block statement 
-	for _, childNode := range forNode.children {
+// return the number of bits needed to hold a non-negative value 
+func bitsForValue(value int64) int {
+	var bits int
 
-		// create a new statement node 
-		if (childNode.ruleType == "expression") {
-			childStmt = new(StatementNode)
-			childStmt.id = l.nextStatementID; l.nextStatementID++ // create new ID
-			childStmt.parseDef = childNode
-			childStmt.parseDefID =  childNode.id 
-			childStmt.funcName = funcStr
-			childStmt.sourceRow =  childNode.sourceLineStart
-			childStmt.sourceCol =  childNode.sourceColStart
-			childStmt.parent = forStmt 
-			childStmt.parentID = forStmt.id
+	bits = 1
+	for (value >> uint(bits)) != 0 {
+		bits++
+	}
+	return bits
+}
 
-			// set the variable scope to be the same as the for statement's scope 
-			childStmt.vScope = forStmt.vScope 
-			childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
+// if a write is the post statement of a for clause which counts a variable up by
+// one, e.g. i++, i += 1 or i = i + 1, and the loop condition is i < C or i <= C for
+// a constant C, return the largest value the variable reaches in the loop 
+func (node *ParseNode) loopCountBound(varName string) (int64, bool) {
+	var simpleStmt, forClause, condNode *ParseNode
+	var numSemis int
+	var isPost bool
+	var limit int64
+	var ok bool
+
+	// the write must count up by one 
+	switch node.ruleType {
+	case "incDecStmt":
+		if (len(node.children) != 2) || (node.children[1].ruleType != "++") || (node.children[0].getPlainOperandName() != varName) {
+			return 0, false
+		}
+	case "assignment":
+		if (len(node.children) != 3) || (node.children[0].getPlainOperandName() != varName) {
+			return 0, false
+		}
+		rhs := node.children[2].stripParens()
+		switch strings.TrimSpace(node.children[1].getSourceCode()) {
+		case "+=":
+			if one, ok := rhs.getIntLiteral() ; (!ok) || (one != 1) {
+				return 0, false
+			}
+		case "=":
+			if (rhs.ruleType != "expression") || (len(rhs.children) != 3) || (rhs.children[1].ruleType != "+") {
+				return 0, false
+			}
+			one, ok := rhs.children[2].getIntLiteral()
+			if (rhs.children[0].getPlainOperandName() != varName) || (!ok) || (one != 1) {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	// it must be the post statement of a for clause, after the second ; 
+	simpleStmt = node.parent
+	if (simpleStmt == nil) || (simpleStmt.ruleType != "simpleStmt") || (simpleStmt.parent == nil) || (simpleStmt.parent.ruleType != "forClause") {
+		return 0, false
+	}
+	forClause = simpleStmt.parent
+	numSemis = 0
+	isPost = false
+	for _, child := range forClause.children {
+		if (child.ruleType == ";") {
+			numSemis++
+		} else if (child.ruleType == "expression") {
+			condNode = child
+		} else if (child == simpleStmt) && (numSemis == 2) {
+			isPost = true
+		}
+	}
+	if (!isPost) || (condNode == nil) {
+		return 0, false
+	}
 
-			l.statementGraph = append(l.statementGraph,childStmt)
+	// the condition must bound the variable by a constant 
+	condNode = condNode.stripParens()
+	if (condNode.ruleType != "expression") || (len(condNode.children) != 3) || (condNode.children[0].getPlainOperandName() != varName) {
+		return 0, false
+	}
+	limit, ok = condNode.children[2].getIntLiteral()
+	if (!ok) || (limit < 0) {
+		return 0, false
+	}
+	switch condNode.children[1].ruleType {
+	case "<":
+		return limit, true
+	case "<=":
+		return limit + 1, true
+	}
+	return 0, false
+}
 
-			conditionStmt = childStmt
-			subNode =  childNode.children[0]
-			conditionStmt.stmtType = subNode.ruleType
-			conditionStmt.parseSubDef = subNode 
-			conditionStmt.parseSubDefID =  subNode.id
-			
+// narrow integer variables to the number of bits their values need. A variable is
+// narrowed only when every write is a non-negative constant, or a count by one in
+// the post statement of a for loop bounded by a constant, so the largest value it can
+// hold is known. Any other write leaves the declared width. Signed variables keep a
+// sign bit. Returns the number of variables narrowed 
+func (l *argoListener) inferVariableWidths() int {
+	var maxValue map[*VariableNode]int64
+	var unbounded map[*VariableNode]bool
+	var names []string
+	var rhsList []*ParseNode
+	var vNode *VariableNode
+	var funcName string
+	var numNarrowed, bits int
+
+	maxValue = make(map[*VariableNode]int64)
+	unbounded = make(map[*VariableNode]bool)
+
+	// the candidates are the local integer variables. The initial value is the
+	// constant initializer, or zero for a var declaration 
+	for _, vNode = range l.varNodeList {
+		if (vNode.goLangType != "numeric") || (vNode.isParameter) || (vNode.isResult) || (vNode.structType != nil) ||
+			((vNode.primType != "int") && (vNode.primType != "uint")) || (vNode.numBits <= 0) {
+			continue
 		}
-		if (childNode.ruleType == "block") {
-			forBlockNode = childNode
-			
+		// the counter of a range over a constant reaches the constant when the loop exits 
+		if (vNode.astClass == "rangeClause") {
+			count, ok := vNode.parseDef.children[len(vNode.parseDef.children)-1].getIntLiteral()
+			if (ok) && (count >= 0) && (l.isRangeOverInt(vNode.parseDef,vNode.funcName)) {
+				maxValue[vNode] = count
+			}
+			continue
+		}
+		maxValue[vNode] = 0
+		if (vNode.astClass == "shortVarDecl") {
+			initValue, err := strconv.ParseInt(vNode.initValue,10,64)
+			if (err != nil) || (initValue < 0) {
+				unbounded[vNode] = true
+			}
+			maxValue[vNode] = initValue
 		}
+	}
 
-		if (childNode.ruleType == "forClause") {
-			forClauseNode = childNode 
+	for _, node := range l.ParseNodeList {
+		names = nil
+		rhsList = nil
+		switch node.ruleType {
+		case "assignment":
+			if (len(node.children) < 3) {
+				continue
+			}
+			for _, lhs := range node.children[0].getExpressionList() {
+				names = append(names,lhs.getPlainOperandName())
+			}
+			if (strings.TrimSpace(node.children[1].getSourceCode()) == "=") {
+				rhsList = node.children[2].getExpressionList()
+			}
+		case "incDecStmt":
+			if (len(node.children) > 0) {
+				names = append(names,node.children[0].getPlainOperandName())
+			}
+		case "recvStmt":
+			if (len(node.children) > 0) && (node.children[0].ruleType == "expressionList") {
+				for _, lhs := range node.children[0].getExpressionList() {
+					names = append(names,lhs.getPlainOperandName())
+				}
+			}
+		case "rangeClause":
+			// for i = range n counts i up to n 
+			if (len(node.children) > 0) && (node.children[0].ruleType == "expressionList") {
+				for _, lhs := range node.children[0].getExpressionList() {
+					names = append(names,lhs.getPlainOperandName())
+				}
+				if (l.isRangeOverInt(node,node.getEnclosingFuncName())) {
+					rhsList = node.children[len(node.children)-1:]
+				}
+			}
+		default:
+			continue
 		}
 
-	} 
+		funcName = node.getEnclosingFuncName()
+		for k, name := range names {
+			vNode = l.getVarNodeInScope(funcName,name,node)
+			if _, ok := maxValue[vNode] ; (vNode == nil) || (!ok) {
+				continue
+			}
+			value, ok := int64(0), false
+			if (len(rhsList) == len(names)) {
+				value, ok = rhsList[k].getIntLiteral()
+			}
+			if (!ok) {
+				value, ok = node.loopCountBound(name)
+			}
+			if (!ok) || (value < 0) {
+				unbounded[vNode] = true
+			} else if (value > maxValue[vNode]) {
+				maxValue[vNode] = value
+			}
+		}
+	}
 
-	// if we have a forClause, walk these children 
-	if (forClauseNode != nil) { 
-		for _, childNode := range forClauseNode.children {
-			
-			// note the child statement can be nil around this loop if the type is not one of
-			// simpleStmt, expression, block of ifStmt
-			if (childNode.visited == false) {
-
-				if (childNode.ruleType == "block") {
-					forBlockNode = childNode
-					
-				}
-				
-				// create the new node 
-				if (childNode.ruleType == "expression") || (childNode.ruleType == "simpleStmt")  {
-					childStmt = new(StatementNode)
-					childStmt.id = l.nextStatementID; l.nextStatementID++ // create new ID
-					childStmt.parseDef = childNode
-					childStmt.parseDefID =  childNode.id 
-					childStmt.funcName = funcStr
-					childStmt.sourceRow =  childNode.sourceLineStart
-					childStmt.sourceCol =  childNode.sourceColStart
-					childStmt.parent = forStmt 
-					childStmt.parentID = forStmt.id
-
-					// set the variable scope to be the same as the if statement's scope 
-					childStmt.vScope = forStmt.vScope 
-					childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
-					
-					// add this to the global list of statements 
-					l.statementGraph = append(l.statementGraph,childStmt)
-					
-				}
-				
-				if (childNode.ruleType == "expression") {
-					conditionStmt = childStmt
-					subNode =  childNode.children[0]
-					conditionStmt.stmtType = subNode.ruleType
-					conditionStmt.parseSubDef = subNode 
-					conditionStmt.parseSubDefID =  subNode.id
-				}
+	numNarrowed = 0
+	for _, vNode = range l.varNodeList {
+		if _, ok := maxValue[vNode] ; (!ok) || (unbounded[vNode]) {
+			continue
+		}
+		bits = bitsForValue(maxValue[vNode])
+		if (verilogSigned(vNode) != "") {
+			bits++
+		}
+		if (bits < vNode.numBits) {
+			vNode.goBits = vNode.numBits
+			vNode.numBits = bits
+			numNarrowed++
+		}
+	}
+	return numNarrowed
+}
 
-				// the second simple statement is the post-condition 
-				if (seenSimple == 1) && (childNode.ruleType == "simpleStmt")  {
-					postStmt = childStmt
+// return true if the integer type of a variable, or of the elements of an array or
+// channel, has no explicit size: it is declared int or uint, or takes its type from
+// an untyped initializer, e.g. x := 0xF 
+func (l *argoListener) hasDefaultWidth(vNode *VariableNode) bool {
+	var rType *ParseNode
+	var typeNames []*ParseNode
+
+	if ((vNode.goLangType != "numeric") && (vNode.goLangType != "array") && (vNode.goLangType != "channel")) ||
+		(vNode.structType != nil) || ((vNode.primType != "int") && (vNode.primType != "uint")) || (vNode.parseDef == nil) {
+		return false
+	}
+	rType = vNode.parseDef.walkDownToRule("r_type")
+	if (rType != nil) {
+		typeNames = rType.walkDownToAllRules("typeName")
+		if (len(typeNames) == 0) {
+			return false
+		}
+		typeName := strings.TrimSpace(typeNames[len(typeNames)-1].getSourceCode())
+		return (typeName == "int") || (typeName == "uint")
+	}
+	// a variable declared by a receive has the element type of its channel 
+	if recvNode := vNode.initExpr.stripParens() ; (vNode.initExpr != nil) && (recvNode.ruleType == "unaryExpr") &&
+		(len(recvNode.children) == 2) && (recvNode.children[0].ruleType == "<-") {
+		chanVar, _ := l.getChannelOperand(recvNode.children[1],vNode.funcName)
+		return (chanVar != nil) && (chanVar != vNode) && (l.hasDefaultWidth(chanVar))
+	}
+	return l.typedConstExpr(vNode.initExpr,vNode.funcName) == ""
+}
 
-					subNode =  childNode.children[0]
-					postStmt.stmtType = subNode.ruleType
-					postStmt.parseSubDef = subNode 
-					postStmt.parseSubDefID =  subNode.id
+// with -datawidth, set the width of every variable, array element and channel
+// element of an integer type with no explicit size to the data width, e.g. every
+// int of the design is 16 bits. Sized types, e.g. uint8, and struct fields keep
+// their width. The width overrides the widths inferred from literals and by
+// -narrow. Returns the number of variables set 
+func (l *argoListener) applyDataWidth() int {
+	var numSet int
 
-					seenSimple++
+	numSet = 0
+	if (l.dataWidth <= 0) {
+		return 0
+	}
+	for _, vNode := range l.varNodeList {
+		if (!l.hasDefaultWidth(vNode)) {
+			continue
+		}
+		vNode.numBits = l.dataWidth
+		vNode.goBits = 0
+		numSet++
+	}
+	return numSet
+}
+// the variables a control node reads and the variables it writes all of. A write of
+// an element or a field, e.g. a[i] = v or p.f = v, also reads the variable and its
+// index. The writes of other statements, e.g. the count of a range loop, are reads
+// as well, as they may be read-modify-writes 
+func (l *argoListener) cfgUseDef(cNode *CfgNode) ([]*VariableNode, []*VariableNode) {
+	var uses, defs []*VariableNode
+	var sNode *StatementNode
+	var vNode *VariableNode
+
+	// a coalesced chain reads and writes the variables of all its assignments 
+	if (len(cNode.coalesced) > 0) {
+		return cNode.readVars, cNode.writeVars
+	}
+	uses = append(uses,cNode.readVars...)
+	sNode = cNode.statement
+	if (cNode.subStmt != nil) {
+		sNode = cNode.subStmt
+	}
+	switch sNode.stmtType {
+	case "shortVarDecl":
+		defs = append(defs,cNode.writeVars...)
+	case "assignment":
+		for _, lhs := range sNode.parseSubDef.children[0].getExpressionList() {
+			if name := lhs.stripDeref().getPlainOperandName() ; name != "" {
+				vNode = l.getVarNodeInScope(sNode.funcName,name,lhs)
+				if (vNode != nil) {
+					defs = append(defs,vNode)
 				}
-
-				// the first simple statement is the initialization statement
-				// add check here if there is a short var decl; create a new
-				// var context for this statement 
-				if (seenSimple == 0 ) && (childNode.ruleType == "simpleStmt")  {
-					initStmt = childStmt
-
-					subNode =  childNode.children[0]
-					initStmt.stmtType = subNode.ruleType
-					initStmt.parseSubDef = subNode 
-					initStmt.parseSubDefID =  subNode.id
-
-					seenSimple++ 
+				continue
+			}
+			for _, opNode := range lhs.walkDownToAllRules("operandName") {
+				vNode = l.getVarNodeInScope(sNode.funcName,opNode.children[0].ruleType,opNode)
+				if (vNode != nil) {
+					uses = append(uses,vNode)
 				}
-				
-
-			} // end if not visited 
-		} // end of forcause children loop
-	} // end if for clause != nil 
-
-	if (forBlockNode != nil) {
-		statementListNode := forBlockNode.children[1] // get the list of statements in the block 
-		blocklist = l.getListOfStatements(statementListNode,forStmt,funcDecl)
-
-		// get the list of statements from the statementlist 
-		if (len(blocklist) >0)  {
-
-			// connect the taken of else clause back to this node
-			blockHead = blocklist[0]
-			blockTail = blocklist[len(blocklist)-1] 
-			blockStmt = blockHead 
-		} else {
-			// should not happen, zero length block
-			fmt.Printf("error, for statement with zero statement block \n")
+			}
 		}
-		
+	default:
+		uses = append(uses,cNode.writeVars...)
+		defs = append(defs,cNode.writeVars...)
 	}
+	return uses, defs
+}
 
-	// add links to the main if statement for the control flow graph 
-	forStmt.forInit = initStmt
-	forStmt.forCond = conditionStmt
-	forStmt.forPost = postStmt 
-	forStmt.forBlock = blockStmt
-	forStmt.forTail = blockTail
-	
-	// This sets the pred and successor links for the initialization statement
-	// note this assume the block is defined, which is might not be
-	
-	if (initStmt != nil) {
-		statements = append(statements,initStmt)
-		if ( conditionStmt != nil) {
-			initStmt.addStmtSuccessor(conditionStmt)
-			conditionStmt.addStmtPredecessor(initStmt)
+// compute the variables live on entry to and on exit from each control node of a
+// module. A variable is live if it may be read before it is next written. The
+// successors of a node are the nodes its control bits enter.
+// Returns false if the module has parallel control, where more than one node can
+// be active, as the liveness of a sequence of nodes does not then hold 
+func (l *argoListener) getLiveness(funcName string) (map[*CfgNode]map[*VariableNode]bool, map[*CfgNode]map[*VariableNode]bool, bool) {
+	var liveIn, liveOut map[*CfgNode]map[*VariableNode]bool
+	var nextNode map[string]*CfgNode
+	var nodes []*CfgNode
+	var changed bool
+
+	nextNode, ok := fsmTransitions(l,funcName)
+	if (!ok) {
+		return nil, nil, false
+	}
+	nodes = moduleCfgNodes(l,funcName)
+	liveIn = make(map[*CfgNode]map[*VariableNode]bool)
+	liveOut = make(map[*CfgNode]map[*VariableNode]bool)
+	for _, cNode := range nodes {
+		liveIn[cNode] = make(map[*VariableNode]bool)
+		liveOut[cNode] = make(map[*VariableNode]bool)
+	}
+
+	// iterate backwards to a fixed point: out is the union of the successors' in,
+	// and in is the uses and what is live out and not written 
+	changed = true
+	for (changed) {
+		changed = false
+		for i := len(nodes) - 1 ; i >= 0 ; i-- {
+			cNode := nodes[i]
+			for _, bit := range nodeExitBits(cNode) {
+				succ, ok := nextNode[bit]
+				if (!ok) {
+					continue
+				}
+				for vNode := range liveIn[succ] {
+					if (!liveOut[cNode][vNode]) {
+						liveOut[cNode][vNode] = true
+						changed = true
+					}
+				}
+			}
+			uses, defs := l.cfgUseDef(cNode)
+			killed := make(map[*VariableNode]bool)
+			for _, vNode := range defs {
+				killed[vNode] = true
+			}
+			for vNode := range liveOut[cNode] {
+				if (!killed[vNode]) && (!liveIn[cNode][vNode]) {
+					liveIn[cNode][vNode] = true
+					changed = true
+				}
+			}
+			for _, vNode := range uses {
+				if (!liveIn[cNode][vNode]) {
+					liveIn[cNode][vNode] = true
+					changed = true
+				}
+			}
 		}
-		initStmt.forRoot = forStmt 
 	}
+	return liveIn, liveOut, true
+}
 
-	if (conditionStmt != nil) {
-		statements = append(statements,conditionStmt)
-		// there must always be a block statement 
-		conditionStmt.addStmtSuccessor(blockHead)
-		blockHead.addStmtPredecessor(conditionStmt)
-		conditionStmt.forRoot = forStmt 
-	} else {
-		blockHead.addStmtPredecessor(forStmt)		
-	}
-	
-	if (len(blocklist) > 0) {
-		statements = append(statements,blocklist...)
-	}
-	
-	if (postStmt != nil) {
-		statements = append(statements,postStmt)
-		postStmt.forRoot = forStmt 
-		if (len(blocklist) > 0) {
-			postStmt.addStmtPredecessor(blockTail)
-			blockTail.addStmtSuccessor(postStmt)
+// compute the definitions reaching each control node. For each variable these are
+// the control nodes which write it on some path to the node, with no other write
+// of the variable in between. The predecessors of a node are its control flow
+// graph predecessors, so all the functions are computed at once 
+func (l *argoListener) getReachingDefs() map[*CfgNode]map[*VariableNode]map[*CfgNode]bool {
+	var reachIn, reachOut map[*CfgNode]map[*VariableNode]map[*CfgNode]bool
+	var defsOf map[*CfgNode][]*VariableNode
+	var killed map[*VariableNode]bool
+	var changed bool
+
+	reachIn = make(map[*CfgNode]map[*VariableNode]map[*CfgNode]bool)
+	reachOut = make(map[*CfgNode]map[*VariableNode]map[*CfgNode]bool)
+	defsOf = make(map[*CfgNode][]*VariableNode)
+	for _, cNode := range l.controlFlowGraph {
+		reachIn[cNode] = make(map[*VariableNode]map[*CfgNode]bool)
+		reachOut[cNode] = make(map[*VariableNode]map[*CfgNode]bool)
+		_, defsOf[cNode] = l.cfgUseDef(cNode)
+	}
+	addDef := func(reach map[*VariableNode]map[*CfgNode]bool,vNode *VariableNode,def *CfgNode) bool {
+		if (reach[vNode] == nil) {
+			reach[vNode] = make(map[*CfgNode]bool)
 		}
-		if (conditionStmt != nil) {
-			postStmt.addStmtSuccessor(conditionStmt)
+		if (reach[vNode][def]) {
+			return false
 		}
-
-	} else {
-		if (conditionStmt != nil) {
-			blockTail.addStmtSuccessor(conditionStmt)
-			
-		} else {
-			// an if statement tail should go to a fake post statement
-			// try pointing back to the for node in this case 
-			blockTail.addStmtSuccessor(forStmt)
+		reach[vNode][def] = true
+		return true
+	}
+
+	// iterate forwards to a fixed point: in is the union of the predecessors' out,
+	// and out is the node's own writes and what reaches it and is not written 
+	changed = true
+	for (changed) {
+		changed = false
+		for _, cNode := range l.controlFlowGraph {
+			for _, pred := range append(append([]*CfgNode{},cNode.predecessors...),cNode.predecessors_taken...) {
+				if (pred == nil) {
+					continue
+				}
+				for vNode, defs := range reachOut[pred] {
+					for def := range defs {
+						if (addDef(reachIn[cNode],vNode,def)) {
+							changed = true
+						}
+					}
+				}
+			}
+			killed = make(map[*VariableNode]bool)
+			for _, vNode := range defsOf[cNode] {
+				killed[vNode] = true
+				if (addDef(reachOut[cNode],vNode,cNode)) {
+					changed = true
+				}
+			}
+			for vNode, defs := range reachIn[cNode] {
+				if (killed[vNode]) {
+					continue
+				}
+				for def := range defs {
+					if (addDef(reachOut[cNode],vNode,def)) {
+						changed = true
+					}
+				}
+			}
 		}
 	}
-
-	
-	// if (len(statements) > 0) {
-	//   statements[len(statements)-1].setStmtPredNil()
-	//  }
-	
-	return statements 
+	return reachIn
 }
 
-func (l *argoListener) parseSwitchStmt(switchnode *ParseNode,funcDecl *ParseNode) [][]*StatementNode {
-	return nil
-}
+// share one register between local variables whose live ranges do not overlap,
+// as a register allocator would. Two variables interfere if one is written where
+// the other is live. Only integer variables of the same width and sign share, and
+// parameters, results and receivers are ports, so they keep their own registers.
+// A variable with an initial value, or live on entry to its function, keeps its
+// own register, as it relies on its reset value. Each variable is given to the
+// first register it does not interfere with.
+// Returns the number of variables sharing another's register 
+func (l *argoListener) shareRegisters() int {
+	var liveIn, liveOut map[*CfgNode]map[*VariableNode]bool
+	var interfere map[*VariableNode]map[*VariableNode]bool
+	var entered map[*CfgNode]bool
+	var atEntry map[*VariableNode]bool
+	var registers [][]*VariableNode
+	var numShared int
+	var ok, placed bool
+
+	numShared = 0
+	for _, funcNode := range l.funcNodeList {
+		if (funcNode.inlineCall != nil) {
+			continue  // its variables are allocated with those of its caller 
+		}
+		liveIn, liveOut, ok = l.getLiveness(funcNode.funcName)
+		if (!ok) {
+			continue
+		}
 
+		interfere = make(map[*VariableNode]map[*VariableNode]bool)
+		addEdge := func(a *VariableNode,b *VariableNode) {
+			if (interfere[a] == nil) {
+				interfere[a] = make(map[*VariableNode]bool)
+			}
+			interfere[a][b] = true
+		}
+		entered = make(map[*CfgNode]bool)
+		nextNode, _ := fsmTransitions(l,funcNode.funcName)
+		for _, cNode := range nextNode {
+			entered[cNode] = true
+		}
+		atEntry = make(map[*VariableNode]bool)
+		for cNode, live := range liveOut {
+			_, defs := l.cfgUseDef(cNode)
+			for _, written := range append(defs,cNode.writeVars...) {
+				for vNode := range live {
+					if (vNode != written) {
+						addEdge(vNode,written)
+						addEdge(written,vNode)
+					}
+				}
+			}
+			if (!entered[cNode]) {
+				for vNode := range liveIn[cNode] {
+					atEntry[vNode] = true
+				}
+			}
+		}
 
-func (l *argoListener) parseSelectStmt(selectnode *ParseNode,funcDecl *ParseNode) [][]*StatementNode {
-	return nil
+		registers = nil
+		for _, vNode := range l.varNodeList {
+			if (l.moduleFuncName(vNode.funcName) != funcNode.funcName) || (vNode.goLangType != "numeric") || (vNode.structType != nil) ||
+				(vNode.isParameter) || (vNode.isResult) || (vNode.isReceiver) || (vNode.initValue != "") ||
+				(vNode.numBits <= 0) || (len(vNode.cfgNodes) == 0) || (atEntry[vNode]) {
+				continue
+			}
+			placed = false
+			for k, members := range registers {
+				if (members[0].numBits != vNode.numBits) || (verilogSigned(members[0]) != verilogSigned(vNode)) {
+					continue
+				}
+				conflict := false
+				for _, member := range members {
+					if (interfere[vNode][member]) {
+						conflict = true
+						break
+					}
+				}
+				if (!conflict) {
+					vNode.sharedWith = members[0]
+					registers[k] = append(members,vNode)
+					numShared++
+					placed = true
+					break
+				}
+			}
+			if (!placed) {
+				registers = append(registers,[]*VariableNode{vNode})
+			}
+		}
+	}
+	return numShared
 }
 
+// Rules for edge dangles:
+// Returns always jump to the exit node
+// If statements ends jump to the sucessor of the If statement
+// for statements return to the top of the for statement 
+// continue return to the outer for statement
+// break jumps to the successor of the enclosing for statement
 
-// create a return variable node. When a return happens, we store the value
-// in this generated variable and treat the return as an expression with
-// additional control flow 
-func (l *argoListener) makeReturnVariable(identifierR_type *ParseNode,funcName string) *VariableNode {
-	var varTypeStr string
-	var numBits int 
-	var retVarNode *VariableNode
+// link the 
+func (l *argoListener) linkDangles(parentHead,parentTail *StatementNode) int {
+	var nextChild *StatementNode
+	var childTail *StatementNode 
+	// var forPost *StatementNode
+	// get the successor of the parent.
 
-	retVarNode = nil
-	varTypeStr,numBits = identifierR_type.getPrimitiveType()
-	
-	if (varTypeStr != "") {
-		retVarNode = new (VariableNode)
-		retVarNode.id = l.nextVarID ; l.nextVarID++		
-		
-		lineStartStr := strconv.Itoa(identifierR_type.sourceLineStart)
-		colStartStr := strconv.Itoa(identifierR_type.sourceColStart)
-		
-		retVarNode.parseDef = identifierR_type 
-		retVarNode.parseDefNum = identifierR_type.id 
-		retVarNode.astClass =  identifierR_type.ruleType
-		retVarNode.funcName = funcName
-		retVarNode.sourceName  = "_" + funcName + "_" + lineStartStr + "_" + colStartStr  + "_"
-		retVarNode.sourceRow = identifierR_type.sourceLineStart
-		retVarNode.sourceCol = identifierR_type.sourceColStart
-		retVarNode.primType = varTypeStr
-		retVarNode.numBits = numBits
-		retVarNode.visited = false
-		retVarNode.isParameter = false
-		retVarNode.isResult = true 
-		retVarNode.goLangType = "numeric"  // default
+	var count int
 
-		l.varNodeList = append(l.varNodeList,retVarNode)
-		
-		
-	} else {
-		fmt.Printf("Error: at %s no type information for return variable\n",_file_line_())		
+	
+	if (parentHead == nil) {
+		l.reportError(0,0,"linkDangles called with a nil parent")
+		return 0 
 	}
-	return retVarNode
-}
 
-// get a list of all the functions
-// assumes variables are already parsed to look up the parameters 
-func (l *argoListener) getAllFunctions() {
-	var funcName *ParseNode    // name of the function -- AST node 
-	var funcStr string       // name of the function as a string. Must be unique
-	var resultNode *ParseNode  // node for the result 
-	var retParams *ParseNode    // the parameters for the return values for a function call
-	var identifierR_type *ParseNode  // node for getting the primitive type 
-	var fNode *FunctionNode   // node of the function we are creating 
-
-	var retVarNode *VariableNode // the variable node for the return value 
 	
-	// get parameters assumes we have the variables already parsed
-	if (len(l.varNodeList) <= 0) {
-		fmt.Printf("Error: at %s, warning no variables in getallfunctions\n",_file_line_())
+	if (parentHead.child == nil) {
+		return 0 
 	}
 
-	for i, funcDecl := range l.ParseNodeList {
-		if (funcDecl.ruleType == "functionDecl") {
-			if (len(funcDecl.children) < 2) {  // need assertions here 
-				fmt.Printf("Error at %s: %d no function name",_file_line_(),i)
+	if (parentHead.visited == true) {
+		return 0 
+	}
+
+	count = 0
+	nextChild = parentHead.child
+	childTail = nil
+	parentHead.visited = true
+
+	// find the tail of the 
+	for {
+		switch nextChild.stmtType { 
+		case "declaration": 
+		case "labeledStmt":
+		case "goStmt":
+		case "deferStmt":
+		case "returnStmt":
+		case "breakStmt":
+		case "continueStmt":
+		case "gotoStmt":
+		case "fallthroughStmt":
+		case "ifStmt": // go down each branch to set the links to the successor if this if statement 
+			if (nextChild.ifTaken != nil) {
+				count += l.linkDangles(nextChild.ifTaken,nextChild.successors[0])
 			}
-			funcName = funcDecl.children[1]
-			funcStr = funcName.ruleType
-			if (len(funcStr) > 0) {
-				fNode = new(FunctionNode)
-				fNode.id = l.nextFuncID; l.nextFuncID++
-				fNode.funcName = funcStr
-				fNode.sourceRow = funcDecl.sourceLineStart
-				fNode.sourceCol = funcDecl.sourceColStart
-				l.funcNodeList = append(l.funcNodeList,fNode)
-				l.funcNameMap[funcStr] = fNode
+			if (nextChild.ifElse != nil) {
+				count += l.linkDangles(nextChild.ifElse,nextChild.successors[0])
+			}						
+		case "switchStmt":
+		case "selectStmt":
+		case "forStmt":
+			if (nextChild.child != nil) {
+				count += l.linkDangles(nextChild,nextChild.successors[0])
+			}									
+		case "sendStmt":
+		case "expressionStmt":
+		case "incDecStmt":
+		case "assignment":
+		case "shortVarDecl":
+		case "emptyStmt":
 
-				// get the parameters 
-				for _, varNode := range (l.varNodeList) {
-					if ((varNode.funcName == fNode.funcName) && (varNode.isParameter) ) {
-						fNode.parameters = append(fNode.parameters,varNode)
-						fNode.parameterIDs = append(fNode.parameterIDs,varNode.id)
-					}
-				}
-				// get the return values and add them to the list of variables
-				resultNode = funcDecl.walkDownToRule("result")
-				if (resultNode != nil) { 
-					retParams = resultNode.walkDownToRule("parameterList")
+		default:
+			Pass()
+		}
+		
 
-					if (retParams == nil) { // this is case we have single parameter
-						identifierR_type = resultNode.walkDownToRule("r_type")
-						retVarNode =  l.makeReturnVariable(identifierR_type,funcStr)
-						fNode.retVars = append(fNode.retVars,retVarNode)
-						fNode.retVarsIDs = append(fNode.retVarsIDs,retVarNode.id)
-					} else { // we have a parameter list 
-						for _, typeNode := range retParams.children {
-							identifierR_type = typeNode.walkDownToRule("r_type")
-							if (identifierR_type == nil) { continue }  // skip 
-							retVarNode =  l.makeReturnVariable(identifierR_type,funcStr)
-							if (retVarNode == nil) {
-								fmt.Printf("Error making return var node\n")
-							} else {
-								fNode.retVars = append(fNode.retVars,retVarNode)
-								fNode.retVarsIDs = append(fNode.retVarsIDs,retVarNode.id)
-							}
-						}
-					}
-					
-				} else {
-					// function does not have any results 
-				}
-				
-			} else {	
-				fmt.Printf("Error at %s: AST node %d zero length function name\n",_file_line_(),i)
+		if (len(nextChild.successors) > 0) {  
+			nextChild = nextChild.successors[0] // walk to the next successor 
+		} else { // no succesor, link to the target
+			childTail = nextChild
+			if (parentHead.stmtType == "forStmt") {
+				childTail.addStmtSuccessor(parentHead) // loop back to top of the for loop
+			} else { 
+				parentTail.addStmtPredecessor(childTail)  // fall through to the outer statement 
+				childTail.addStmtSuccessor(parentTail)   
 			}
-			
+			count ++
+			break 
 		}
-	}
+	} // end for 
 
+	return count 
 }
 
-
-// Given a statementlist, return a list of StatementNodes
-// Uses recursion to follow if and for, case and select statements
-// Evey list must end with an End-Of-Statement (EOS) which is the terminal node for this list and
-// Past as the terminal statement for various sub-statements
-func (l *argoListener) getListOfStatements(listnode *ParseNode,parentStmt *StatementNode,funcDecl *ParseNode) []*StatementNode {
-	var funcName *ParseNode  // name of the function for the current statement
-	var funcStr  string   //  string name of the function
-	var numChildren,i int 
-	var subNode *ParseNode  // current statement node
-	var eosNode *ParseNode  // the end of the statement list node
-	var eosStmt *StatementNode  // the node of the end-of-statement node 
-	var stmtTypeNode *ParseNode // which simpleStmt type is this?, e.g ifStmt, shortVarDecl, forStmt.
-	var statementList []*StatementNode
-	var predecessorStmt *StatementNode 
-	var stateNode *StatementNode
-	var slist []*StatementNode
-	var varDeclList []*VariableNode // a list of variables for a declaration node 
-	//var numChildren int
+// parse an ifStmt AST node into a statement graph nodes
+// return a list of lists of any sub-statements from the blocks 
+// The structure is to create new statement nodes for all the childern in a main loop looking for
+// the types of the children nodes. Then the function creates the predecessors and successor edges
+func (l *argoListener) parseIfStmt(ifNode *ParseNode,funcDecl *ParseNode,ifStmt *StatementNode,eosStmt *StatementNode) []*StatementNode {
+	var funcStr  string                 // name of the function as a string 
+	var subNode        *ParseNode         // sub-simple statement type
+	var ifSubStmtNode *ParseNode          // if we have an ifSubstatement, put a pointer to it here 
 	
-	if (len(funcDecl.children) < 2) {  // need assertions here 
-		fmt.Printf("Major Error")
-	}
-	funcName = funcDecl.children[1]
-	funcStr = funcName.sourceCode
+	var childStmt, simpleStmt, testStmt, takenStmt, elseStmt,subIfStmt *StatementNode
+	var takenHead,takenTail, elseHead,elseTail, headStmt, tailStmt *StatementNode
+	
+	var seenBlockCount int;             // Counts blocks in the children. First It is the taken branch, second the else 
+	var statements []*StatementNode       // list of statmements 
+	var blocklist []*StatementNode          // list of statements for the block 
+	childStmt = nil; simpleStmt = nil;  testStmt = nil; takenStmt = nil; elseStmt = nil; subIfStmt = nil 
+	
+	seenBlockCount = 0
+	statements = nil
 
-	predecessorStmt = nil
+	// get the name of the function the ifStmt is in  
+	funcStr = funcDecl.getFuncDeclName()
+	ifSubStmtNode = nil 
+	// loop for each child and create the appropriate sub-statement node
+	// after looping through all the children, we fix up the successors and predecessors edges
+	ifNode.visited = true 
+	for _, childNode := range ifNode.children {
 
+		childStmt = nil
+		// note the child statement can be nil around this loop if the type is not one of
 
-	// every statement is separated by and EOS statement separator
-	// This loop advances through the list in groups of 2, one for the main statement and one for the eos 
-	// We will need to pass the eos to the recursively defined statements such as if, for and case
-	// that have a block/list as a sub-statement 
-	numChildren = len(listnode.children)
-	if ( (numChildren % 2) !=0 ) { // check we have an even number of children as every statement has and eos separator 
-		fmt.Printf("Error: got statement list with odd number of statements %d \n",_file_line_())
-		return nil
-	}
+		// simpleStmt, expression, block of ifStmt
+		if (childNode.visited == false) {
 
-	// here is the main loop walking down a list of statements 
-	for i =0; i < (numChildren-1) ; i=i+2 {
+			childNode.visited = true 
 
-		childNode := listnode.children[i]
-		if (childNode.visited == true) {
-			continue 
-		}
-		childNode.visited = true
+			// create a new node and populate it, set the type later 
+			if ( (childNode.ruleType == "simpleStmt") || (childNode.ruleType == "expression") ||
+				(childNode.ruleType == "ifStmt")) {
+				childStmt = new(StatementNode)
+				childStmt.id = l.nextStatementID; l.nextStatementID++ 
+				childStmt.parseDef = childNode
+				childStmt.parseDefID =  childNode.id 
+				childStmt.funcName = funcStr
+				childStmt.sourceRow =  childNode.sourceLineStart 
+				childStmt.sourceCol =  childNode.sourceColStart
+				childStmt.parent = ifStmt
+				childStmt.parentID = ifStmt.id
+				
+				// set the variable scope to be the same as the if statement's scope 
+				childStmt.vScope = ifStmt.vScope 
+				childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
+				
+				// add this to the global list of statements 
+				l.statementGraph = append(l.statementGraph,childStmt)
 
-		
-		// check the type if we want to continue 
-		if (len(childNode.children) > 0) {
-			subNode = childNode.children[0] // subnode should be a statement
-			
-			// skip decls 
-			if (subNode.ruleType != "declaration" )&& (subNode.ruleType != ";") && (len(subNode.children) >0) {
-				// simple statements have to go one level down to get the actual type 
-				if (subNode.ruleType == "simpleStmt") { 
-					stmtTypeNode = subNode.children[0]
-				} else { 
-					stmtTypeNode = subNode
-				}
-			} else {
-				if (subNode.ruleType == "declaration") {
-					varDeclList = l.getParseVariables(subNode.children[0])
-					// fmt.Printf("got a declaration %d %d \n",subNode.id,len(varDeclList))
-					
-				} else if (subNode.ruleType == "shortVarDecl")  {
-					varDeclList = l.getParseVariables(subNode)
-					//fmt.Printf("got other declaration %d %d \n",subNode.id,len(varDeclList))
 			}
 
-				continue;
+			// set the node type 
+			if (childNode.ruleType == "simpleStmt") {
+				simpleStmt = childStmt
+
+				subNode =  childNode.children[0]
+				simpleStmt.stmtType = subNode.ruleType
+				simpleStmt.parseSubDef = subNode 
+				simpleStmt.parseSubDefID =  subNode.id
 
 			}
 
-		} else {
-			continue;
-		}
+			if (childNode.ruleType == "expression") {
+				testStmt = childStmt
+				subNode =  childNode.children[0]
+				testStmt.stmtType = subNode.ruleType
+				testStmt.parseSubDef = subNode 
+				testStmt.parseSubDefID =  subNode.id
+			}
 
-		// create the new statement node 
-		stateNode = new(StatementNode)
-		stateNode.id = l.nextStatementID; l.nextStatementID++
-		stateNode.parseDef = childNode
-		stateNode.parseDefID =  childNode.id
-		stateNode.parseSubDef = stmtTypeNode
-		stateNode.parseSubDefID =  stmtTypeNode.id
-		stateNode.stmtType = stmtTypeNode.ruleType
-		stateNode.funcName = funcStr
-		stateNode.sourceRow =  stmtTypeNode.sourceLineStart 
-		stateNode.sourceCol =  stmtTypeNode.sourceColStart
-		stateNode.parent = parentStmt
-		stateNode.parentID = parentStmt.id
-		stateNode.child = nil
-		stateNode.childID = -1
-		stateNode.ifSimple = nil 
-		stateNode.ifTaken = nil
-		stateNode.ifElse  = nil
-		stateNode.forInit = nil
-		stateNode.forCond = nil 
-		stateNode.forPost = nil
-		stateNode.forBlock = nil
-		stateNode.caseList = nil
-		// append to the local and global lists of statements 
-		statementList = append(statementList,stateNode)	 // local list 					
-		l.statementGraph = append(l.statementGraph,stateNode) // global list 
-		
-		// create the eos statement node, we will need it later
-		// The eos is the exit point for a sub list/block 
-		eosNode = listnode.children[i+1]
-		eosNode.visited = true
+			// if we have  block, recurse down the block to get the resulting statement list
+			// also fix up the child to go to the statement, not the statementlist 
+			if  (childNode.ruleType == "block") { 
+				statementListNode := childNode.children[1] // get the list of statements in the block 
+				blocklist = l.getListOfStatements(statementListNode,ifStmt,funcDecl)
+				
+				// get the list of statements from the statementlist 
+				if (len(blocklist) >0)  {
+					// statements = append(statements,slist...) dont make global list 
+					// connect the taken of else clause back to this node 
+					headStmt = blocklist[0]
+					tailStmt = blocklist[len(blocklist)-1] 
+				}
+			}
 
-		eosStmt = new(StatementNode)
-		eosStmt.id = l.nextStatementID; l.nextStatementID++
-		eosStmt.parseDef = eosNode
-		eosStmt.parseDefID =  eosNode.id
-		eosStmt.parseSubDef = eosNode
-		eosStmt.parseSubDefID =  eosNode.id
-		eosStmt.stmtType = eosNode.ruleType
-		eosStmt.funcName = funcStr
-		eosStmt.sourceRow =  eosNode.sourceLineStart 
-		eosStmt.sourceCol =  eosNode.sourceColStart
-		eosStmt.parent = parentStmt
-		eosStmt.parentID = parentStmt.id
-		eosStmt.child = nil
-		eosStmt.childID = -1
-		eosStmt.ifSimple = nil 
-		eosStmt.ifTaken = nil
-		eosStmt.ifElse  = nil
-		eosStmt.forInit = nil
-		eosStmt.forCond = nil 
-		eosStmt.forPost = nil
-		eosStmt.forBlock = nil
-		eosStmt.caseList = nil
+			// this is the else block, unless the else is another if statement 
+			if (seenBlockCount == 1)  && (childNode.ruleType == "block") {
+				elseStmt = headStmt
+				seenBlockCount = 2
+				elseHead = headStmt
+				elseTail = tailStmt 
+			}
 
-		statementList = append(statementList,eosStmt)						
-		l.statementGraph = append(l.statementGraph,eosStmt)
-		
-		// attach the predecessor to the newly generated node
-		if (predecessorStmt != nil) {
-			predecessorStmt.addStmtSuccessor(stateNode)
-		}
-		stateNode.addStmtSuccessor(eosStmt)
-		stateNode.addStmtPredecessor(predecessorStmt)
-		eosStmt.addStmtPredecessor(stateNode)
+			// this is the taken branch block
+			// this has to come after the previous statement to make this work 
+			if (seenBlockCount == 0) && (childNode.ruleType == "block") {
+				takenStmt = headStmt
+				seenBlockCount = 1 
+				takenHead = headStmt
+				takenTail = tailStmt
+			}
 
-		
-		// this added to the scope of the statement
-		// inheret the variable scope from either the predecessor or the	
-	// parent statement 
-		if (predecessorStmt != nil) {
-			if (predecessorStmt.vScope != nil) {
-				stateNode.vScope = predecessorStmt.vScope
-				eosStmt.vScope = predecessorStmt.vScope
-			} else {
-				if (parentStmt.vScope != nil ) {
-					stateNode.vScope = parentStmt.vScope
-					eosStmt.vScope = parentStmt.vScope
-				} else {
-					fmt.Printf("Error at %s no variable scope stmt id %d\n",_file_line_(),stateNode.id)
+			// this is the if () {block } else if {} construct when the else is an if statement 
+			if (childNode.ruleType == "ifStmt") {
+				childStmt.stmtType = "ifStmt"
+				ifSubStmtNode = childNode
+				subIfStmt = childStmt
+				blocklist = l.parseIfStmt(ifSubStmtNode,funcDecl,subIfStmt,eosStmt)
+				if (len(blocklist) >0) {
+					elseHead = blocklist[0]
+					elseTail = blocklist[len(blocklist)-1]
 				}
 			}
-		} else {
-			if (parentStmt.vScope != nil ) {
-				stateNode.vScope = parentStmt.vScope
-				eosStmt.vScope = parentStmt.vScope
-			} else {
-				fmt.Printf("Error at %s no variable scope stmt id %d\n",_file_line_(),stateNode.id)
-			}				
-		}
-
-
-		stateNode.vScope = parentStmt.vScope
-		stateNode.vScope.statements = append(stateNode.vScope.statements,stateNode)
-		
-		eosStmt.vScope = parentStmt.vScope
-		eosStmt.vScope.statements = append(eosStmt.vScope.statements,eosStmt)
+		} // end if not visited 
 		
-		// add variables to the scope for this statement
-		// only adds statements with a var keyword in front. 
-		for _, vNode := range(varDeclList) {
-			//fmt.Printf("stmt: %d adding declaration var %s \n",stateNode.id,vNode.sourceName)
-			// find the variable name in the global list of vatiables.
-			stateNode.vScope.varNameMap[vNode.sourceName] = vNode
-			// once the node is added, clear the list from the loop
-			varDeclList = nil
-		}
+	} // end for children of isStmt 
+
 	
-		// Get sub statement lists for this node
-		switch stateNode.stmtType { 
-		case "declaration":
-			// add the variable to the scope context
-			fmt.Printf("got declaration %d \n",stateNode.id)
-		case "labeledStmt":
-			
-		case "goStmt":
-		case "returnStmt":
-		case "breakStmt":
-		case "continueStmt":
-		case "gotoStmt":
-		case "fallthroughStmt":
-		case "ifStmt":
-			// get the eos of the next node
-			// this will become the successor in the statement graph
-			// create a new variable scope for this statement
-			
-			slist = l.parseIfStmt(subNode,funcDecl,stateNode,eosStmt)
-			slistLen := len(slist)
-			if (slistLen > 0) {
-				stateNode.child = slist[0]
-				stateNode.childID = slist[0].id
+	// add links to the main if statement for the control flow graph.
+	// Every if in an else-if chain exits to the eos of the outermost if 
+	ifStmt.ifSimple = simpleStmt
+	ifStmt.ifTest = testStmt
+	ifStmt.ifTaken = takenStmt
+	ifStmt.ifExit = eosStmt
 
-				// note the successor of the last statment in the
-				// sub-block is the eos of the if statement 
-				//slist[slistLen-1].addStmtSuccessor(eosStmt)
-			}
-						
-		case "switchStmt":
-		case "selectStmt":
-		case "forStmt":
-			// create a new variable scope for this statement
-			slist = l.parseForStmt(subNode,funcDecl,stateNode,eosStmt)
-			slistLen := len(slist)			
-			if (slistLen > 0) {
-				stateNode.child = slist[0]
-				stateNode.childID = slist[0].id
-				//slist[slistLen-1].addStmtSuccessor(eosStmt)				
-			}
-						
-		case "sendStmt":
-		case "expressionStmt":
-		case "incDecStmt":
-		case "assignment":
-		case "shortVarDecl":
-			fmt.Printf("got short var decl %d \n",stateNode.id)
-		case "emptyStmt":
-						
-		default:
-			fmt.Printf("Error! at %s no such statement type: %s\n",_file_line_(),stateNode.stmtType)
-		}
+	// Assertions that must hold for every if statements 
+	if (testStmt == nil) {
+		l.reportError(ifNode.sourceLineStart,ifNode.sourceColStart,"no test with the if statement at AST node id %d",ifNode.id)
+		return nil 
+	}  else {
+		testStmt.ifRoot = ifStmt
+	}
 
-		predecessorStmt = eosStmt
-	} // end for loop of statementlist 
-	
-	if (statementList == nil) {
-		fmt.Printf("Error! return from statementlist has zero statements %s\n", _file_line_())
+	if (takenStmt == nil) {
+		l.reportError(ifNode.sourceLineStart,ifNode.sourceColStart,"no taken block with the if statement at AST node id %d",ifNode.id)
 		return nil 
+	} else {
+		// takenStmt.ifRoot = ifStmt 
 	}
-	
-	return statementList 
-}
 
-// fix the edges of the return statements to their successors to the exit nodes of the function/
-// this assumes the function declaration is linearly ordered in the statementgraph with the function
-// calls. If not, we need a walkUpToRule call. Assuming linear ordering for now.
-func (l *argoListener) addInternalReturnEdges() {
-	var functionEntry, functionExit *StatementNode
-	// find the exit node
 
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false 
+	// santiy check, both the else an sub if statement can not be set 
+	if (elseStmt != nil) && (subIfStmt != nil) {
+		l.reportError(ifNode.sourceLineStart,ifNode.sourceColStart,"both the else and the if sub-statement are set at AST node id %d, %d statements",
+			ifNode.id,len(statements))
+		//elseStmt.ifRoot = ifStmt 		
 	}
-	
-	functionExit = nil 
-	for _, stmtNode := range(l.statementGraph) {
 
-		if (stmtNode.stmtType == "functionDecl") {
-			functionEntry = stmtNode 
-			functionExit = functionEntry.successors[0]
-		}
+	// if we passed the above sanity check/assertion, we can set the sub-if statement.
+	// The not-taken edge of this test goes to the sub-if, whose branches reach the shared eos 
+	if (subIfStmt != nil) {
+		ifStmt.ifElse = subIfStmt
+		subIfStmt.addStmtSuccessor(eosStmt)
+		//subIfStmt.ifRoot = ifStmt 
+	} else if (elseStmt != nil) {
+		ifStmt.ifElse = elseStmt
 
-		if (stmtNode.stmtType == "returnStmt") {
-			if (functionExit != nil) {
-				stmtNode.setStmtSuccNil()
-				stmtNode.addStmtSuccessor(functionExit)
-				// make sure the function exit gets the return node as a predecessors
-				functionExit.addStmtPredecessor(stmtNode)
-			}else {
-				fmt.Printf("Error! return edges called with mil function parent %s\n", _file_line_())
-			}
-		}
 	}
-}
 
-// remove the predecessor edges for the EOS nodes
-// then add them back in based on the successor edges
-func (l *argoListener) fixEosPredecessors() {
+	// This sets the pred and successor links for the simple statement 
+	if (simpleStmt != nil) {
+				
+		statements = append(statements,simpleStmt)
 
-	// remove all the predecessor edges 
-	for _, stmtNode := range(l.statementGraph) {
-		if stmtNode.stmtType == "eos" {
-			stmtNode.predecessors = nil
-			stmtNode.predIDs = nil 
-		}
+		simpleStmt.addStmtSuccessor(testStmt)
+		testStmt.addStmtPredecessor(simpleStmt)
+		simpleStmt.ifRoot = ifStmt 
 	}
 
-	// if we found an EOS as a target, add a predecessor edge 
-	for _, stmtNode := range(l.statementGraph) {
-		if len(stmtNode.successors) > 0 {
-			succNode := stmtNode.successors[0]
-			if succNode.stmtType == "eos" {
-				succNode.addStmtPredecessor(stmtNode)
-			}
-		}
-	}
+	// there must always be a test and taken statement
+	statements = append(statements,testStmt)
+	testStmt.addStmtSuccessor(takenStmt)
+	takenStmt.addStmtPredecessor(testStmt)
+	//takenStmt.addStmtSuccessor(eosStmt)
+	//takenStmt.ifRoot = ifStmt 
 
-}
+	statements = append(statements,takenStmt)
 
-// get a function statement Node by the functions name 
-func (l *argoListener) getFunctionStmtEntry(funcName string) *StatementNode {
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false
+	if (takenTail != nil) {
+		takenTail.addStmtSuccessor(eosStmt)		
+	}
 
-		if (stmtNode.stmtType == "functionDecl") {
-			if (stmtNode.funcName == funcName) {
-				return stmtNode
-			}
-		}
+	// the else and subIf are interchangable as the 2nd clause 
+	if (elseStmt != nil) && (subIfStmt == nil) {
+		testStmt.addStmtSuccessor(elseStmt)
+		elseStmt.addStmtPredecessor(testStmt)
+		elseTail.addStmtSuccessor(eosStmt)
+	} 
+	if (subIfStmt != nil) {
+		testStmt.addStmtSuccessor(subIfStmt)
+		subIfStmt.addStmtPredecessor(testStmt)
 	}
-	return nil 
+
+	if (takenHead != nil) || (takenTail != nil) || (elseHead != nil) || (elseTail !=nil) {
+		//fmt.Printf("IF statement at %s statement len %d \n",_file_line_(),len(statements))
+		Pass()
+	}
+	
+	return statements 
 }
 
+// This parses a for statement.
+// It tried to get the block and forClause first. Then it walks the children of the for clause and creates new
+// statement nodes as it walks the forClause. The end of the function creates the edges between the statement nodes 
+func (l *argoListener) parseForStmt(forNode *ParseNode,funcDecl *ParseNode,forStmt,eosStmt *StatementNode) []*StatementNode {
+	var funcStr  string                 // name of the function
+	var forClauseNode  *ParseNode              //  if this statement has a for clause
+	var rangeClauseNode *ParseNode             // if this statement has a range clause
+	var forBlockNode   *ParseNode             // the block of statements for the for
+	var subNode        *ParseNode         // sub-simple statement type
+	var statements []*StatementNode       // list of statmements 
+	var blocklist []*StatementNode          // list of statements for the block
 
-// add edges to the caller 
-func (l *argoListener) addCallandReturnEdges() {
-	var funcEntryNode,functionExitNode *StatementNode
-	var retList []*ParseNode
-	var parentNode, operandNameNode *ParseNode
-	var calleeNameStr string
+	var childStmt *StatementNode       
+	var blockStmt, initStmt, conditionStmt, postStmt  *StatementNode // statement nodes for the for statement
+	var blockHead, blockTail *StatementNode   // the head and tail of the block statement 
+	var seenSimple int
+	
+	statements = nil
+	blocklist = nil
+	
+	// get the name of the function the ifStmt is in  
+	funcStr = funcDecl.getFuncDeclName()
 
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false 
-	}
+	// loop for each child and create the appropriate sub-statement node
+	// after looping through all the children, we fix up the successors and predecessors edges
+	forStmt.visited = true
+	forClauseNode = nil
+	forBlockNode = nil
+	blockStmt = nil
+	seenSimple = 0  // how many simple statements have we seen?
 
-	// these statement types may have a function call 
-	for _, stmtNode := range(l.statementGraph) {
-		// statement types which may make a function call 
-		if ((stmtNode.stmtType == "expression") || (stmtNode.stmtType == "assignment") ||
-			(stmtNode.stmtType == "shortVarDecl") || (stmtNode.stmtType == "expressionStmt") ||
-			(stmtNode.stmtType == "unaryExpr")  || (stmtNode.stmtType == "returnStmt") ||
-		        (stmtNode.stmtType == "goStmt")) {
+	// walk the for statement clauses
+	// create a new statement node, except for the block statement 
+	for _, childNode := range forNode.children {
 
-			// if something in the AST has parameters, we declare it having at least one functio
-			retList = stmtNode.parseDef.walkDownToAllRules("arguments")
+		// create a new statement node 
+		if (childNode.ruleType == "expression") {
+			childStmt = new(StatementNode)
+			childStmt.id = l.nextStatementID; l.nextStatementID++ // create new ID
+			childStmt.parseDef = childNode
+			childStmt.parseDefID =  childNode.id 
+			childStmt.funcName = funcStr
+			childStmt.sourceRow =  childNode.sourceLineStart
+			childStmt.sourceCol =  childNode.sourceColStart
+			childStmt.parent = forStmt 
+			childStmt.parentID = forStmt.id
 
-			// check if we have multiple calls in this statement. If so we walk the list of
-			// called functions and add a successor edge in the statementgraph for each one 
-			for _, argNode := range retList {
-				parentNode = argNode.parent
-				operandNameNode = parentNode.walkDownToRule("operandName")
-				if (operandNameNode != nil) {
-					calleeNameStr = operandNameNode.children[0].ruleType 
-					// find the functionDecl node with this name
-					funcEntryNode = l.getFunctionStmtEntry(calleeNameStr)
-					// if we can't find the function, then abort this node 
-					if (funcEntryNode != nil) {
-						// add predecessor to the function entry node 
-						// funcEntryNode.addStmtPredecessor(stmtNode)
-						funcEntryNode.callers = append(funcEntryNode.callers,stmtNode)
-						// non-go statements add a return edge
-						if (stmtNode.stmtType != "goStmt") {
-							stmtNode.callTargets=append(stmtNode.callTargets,funcEntryNode)
-							functionExitNode = funcEntryNode.successors[0]
-							functionExitNode.returnTargets = append(functionExitNode.returnTargets,stmtNode)
-							
-						} else {
-							stmtNode.goTargets=append(stmtNode.goTargets,funcEntryNode)
-						}
-					} else {
-						// check if a fmt.printf, make or cast statements. 
-						// need to add check if this is a printf statement 
-						// fmt.Printf("function %s not found \n",calleeNameStr)
-					}
-					
-				} else {
-					fmt.Printf("Error! no operandNode at %d\n", _file_line_())			
-				}
-			}
+			// set the variable scope to be the same as the for statement's scope 
+			childStmt.vScope = forStmt.vScope 
+			childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
+
+			l.statementGraph = append(l.statementGraph,childStmt)
 
+			conditionStmt = childStmt
+			subNode =  childNode.children[0]
+			conditionStmt.stmtType = subNode.ruleType
+			conditionStmt.parseSubDef = subNode 
+			conditionStmt.parseSubDefID =  subNode.id
+			
+		}
+		if (childNode.ruleType == "block") {
+			forBlockNode = childNode
+			
 		}
-	}
-}
 
+		if (childNode.ruleType == "forClause") {
+			forClauseNode = childNode 
+		}
 
-// convert an expression to a string
-// walk the parse tree. If there are no children, return the string
-// concatenate all the return strings separated by a space 
-func expressionToString(pNode *ParseNode) string {
-	var returnStr,newStr string ;
-	// if we are a terminal node, just return the ruletype (or sourcecode) 
-	if (len(pNode.children) == 0) {
-		return pNode.ruleType
-	}
+		if (childNode.ruleType == "rangeClause") {
+			rangeClauseNode = childNode 
+		}
 
-	// concatenate all the child strings 
-	returnStr = ""
-	for _, childNode := range(pNode.children) {
-		returnStr = returnStr + expressionToString(childNode) + " "
-	}
+	} 
 
-	// remove extra spaces.
-	// loop through the string and add a space only if the character is not a space or the
-	// previous character is not a space 
-	for i, c := range returnStr {
-		if c != ' ' {
-			newStr = newStr + string(c);
+	// a range over an integer is the counted loop for i := 0; i < n; i++ 
+	if (rangeClauseNode != nil) && (l.isRangeOverInt(rangeClauseNode,funcStr)) {
+		counter := l.getRangeCounter(rangeClauseNode,funcStr)
+		if (counter == nil) {
+			l.reportError(rangeClauseNode.sourceLineStart,rangeClauseNode.sourceColStart,"no counter for range over an integer")
 		} else {
-			if (i >0) {
-				if returnStr[i-1] != ' ' {
-					newStr = newStr + string(c);
-				}
-			}
+			initStmt = l.newRangeStmt(rangeClauseNode,forStmt,funcStr,"rangeInit")
+			initStmt.writeVars = append(initStmt.writeVars,counter)
+			conditionStmt = l.newRangeStmt(rangeClauseNode,forStmt,funcStr,"rangeCond")
+			conditionStmt.readVars = append(conditionStmt.readVars,counter)
+			postStmt = l.newRangeStmt(rangeClauseNode,forStmt,funcStr,"rangePost")
+			postStmt.readVars = append(postStmt.readVars,counter)
+			postStmt.writeVars = append(postStmt.writeVars,counter)
 		}
 	}
-	
-	// return the concat of the children 
-	return newStr; 
-}
-	
-// for assignment and short var decls, add the left and right hand sides of the assignment expression
-func (l *argoListener) addVarAssignments() {
-	var funcStr string
-	var varStrList []string 
-	var parsedNode, funcParseNode, funcNameNode,lhsNode,operandNameNode *ParseNode
-	var operandNameNodeList []*ParseNode 
-	var varNode *VariableNode
-	var funcNode *FunctionNode
-	var varStr string
-	
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false 
-	}
-
-	// these statement types may have a variable assignment 
-	for _, stmtNode := range(l.statementGraph) {
 
-
-		// get the function header 
-		if ((stmtNode.stmtType == "assignment") || (stmtNode.stmtType == "shortVarDecl") || 
-			(stmtNode.stmtType == "sendStmt") || (stmtNode.stmtType == "funcDecl") ||
-			(stmtNode.stmtType == "returnStmt")) {
+	// if we have a forClause, walk these children 
+	if (forClauseNode != nil) { 
+		for _, childNode := range forClauseNode.children {
 			
-			parsedNode = stmtNode.parseSubDef
-			funcParseNode = parsedNode.walkUpToRule("functionDecl")
-			funcNameNode = funcParseNode.children[1]
-			funcStr = funcNameNode.ruleType
-
-			operandNameNodeList = make([]*ParseNode,0)
-			varStrList = make([]string,0)
-		}
-		// statement types which may have an assignment
-		if ((stmtNode.stmtType == "assignment") || (stmtNode.stmtType == "shortVarDecl") || 
-			(stmtNode.stmtType == "sendStmt")) {
-
-			// get the left hand side of the statement 
+			// note the child statement can be nil around this loop if the type is not one of
+			// simpleStmt, expression, block of ifStmt
+			if (childNode.visited == false) {
 
-			parsedNode = stmtNode.parseSubDef
-			lhsNode = parsedNode.children[0]
-			
-			funcParseNode = parsedNode.walkUpToRule("functionDecl")
-			funcNameNode = funcParseNode.children[1]
-			funcStr = funcNameNode.ruleType
+				if (childNode.ruleType == "block") {
+					forBlockNode = childNode
+					
+				}
+				
+				// create the new node 
+				if (childNode.ruleType == "expression") || (childNode.ruleType == "simpleStmt")  {
+					childStmt = new(StatementNode)
+					childStmt.id = l.nextStatementID; l.nextStatementID++ // create new ID
+					childStmt.parseDef = childNode
+					childStmt.parseDefID =  childNode.id 
+					childStmt.funcName = funcStr
+					childStmt.sourceRow =  childNode.sourceLineStart
+					childStmt.sourceCol =  childNode.sourceColStart
+					childStmt.parent = forStmt 
+					childStmt.parentID = forStmt.id
 
-			// make sure we get all the variables in the assignment for
-			// when there are multiple ones in a return statement
-			if (stmtNode.stmtType == "assignment") {
-					operandNameNodeList = lhsNode.walkDownToAllRules("operandName")
-				for _, opNode := range(operandNameNodeList) {
-					varStrList = append(varStrList,opNode.children[0].ruleType)
+					// set the variable scope to be the same as the if statement's scope 
+					childStmt.vScope = forStmt.vScope 
+					childStmt.vScope.statements = append(childStmt.vScope.statements,childStmt)
+					
+					// add this to the global list of statements 
+					l.statementGraph = append(l.statementGraph,childStmt)
+					
 				}
 				
-			}
+				if (childNode.ruleType == "expression") {
+					conditionStmt = childStmt
+					subNode =  childNode.children[0]
+					conditionStmt.stmtType = subNode.ruleType
+					conditionStmt.parseSubDef = subNode 
+					conditionStmt.parseSubDefID =  subNode.id
+				}
 
-			// channels cannot accept mulitple values 	
-			if (stmtNode.stmtType == "sendStmt") { 
-				operandNameNode = parsedNode.walkDownToRule("operandName")
-				varStrList = append(varStrList,operandNameNode.children[0].ruleType)
-			}
+				// the second simple statement is the post-condition 
+				if (seenSimple == 1) && (childNode.ruleType == "simpleStmt")  {
+					postStmt = childStmt
 
-			// TODO: need to fix this to be able to return multiple values for a short vardecl
-			// that returns multiple values. 
-			if (stmtNode.stmtType == "shortVarDecl")  { 
-				operandNameNode = parsedNode.walkDownToRule("identifierList")
-				varStrList = append(varStrList,operandNameNode.children[0].ruleType)
-			}
+					subNode =  childNode.children[0]
+					postStmt.stmtType = subNode.ruleType
+					postStmt.parseSubDef = subNode 
+					postStmt.parseSubDefID =  subNode.id
 
-			// parsedNode.sourceLineStart,parsedNode.sourceColStart,varStr)
+					seenSimple++
+				}
 
-			// iterate through the variables names and and them to the LHS expression 
-			for _, varStr = range(varStrList) {
-				varNode = l.getVarNodeByNames("",funcStr,varStr)
-				if (varNode == nil) {
-					fmt.Printf("Error!, at %d no variable func %s name %s\n",_file_line_(),funcStr,varStr)
-					continue
+				// the first simple statement is the initialization statement
+				// add check here if there is a short var decl; create a new
+				// var context for this statement 
+				if (seenSimple == 0 ) && (childNode.ruleType == "simpleStmt")  {
+					initStmt = childStmt
+
+					subNode =  childNode.children[0]
+					initStmt.stmtType = subNode.ruleType
+					initStmt.parseSubDef = subNode 
+					initStmt.parseSubDefID =  subNode.id
+
+					seenSimple++ 
 				}
-				stmtNode.writeVars = append(stmtNode.writeVars,varNode)
-			}
+				
 
+			} // end if not visited 
+		} // end of forcause children loop
+	} // end if for clause != nil 
 
-			// get the expression on the right hand side 
+	if (forBlockNode != nil) {
+		statementListNode := forBlockNode.children[1] // get the list of statements in the block 
+		blocklist = l.getListOfStatements(statementListNode,forStmt,funcDecl)
+
+		// get the list of statements from the statementlist 
+		if (len(blocklist) >0)  {
+
+			// connect the taken of else clause back to this node
+			blockHead = blocklist[0]
+			blockTail = blocklist[len(blocklist)-1] 
+			blockStmt = blockHead 
+		} else {
+			// should not happen, zero length block
+			l.reportError(forNode.sourceLineStart,forNode.sourceColStart,"for statement with a zero length statement block")
 		}
+		
+	}
 
-		// function entry copies the parameters
-		// function return copies the RHS into the list of return values 
-		if ( (stmtNode.stmtType == "funcDecl") || (stmtNode.stmtType == "returnStmt")) {
-			
-			funcNode = l.getFuncNodeByNames("",funcStr)
-			if (funcNode == nil) {
-				fmt.Printf("Error! at %d no func %s name \n",_file_line_(),funcStr)
-				continue
-			}
+	// add links to the main if statement for the control flow graph 
+	forStmt.forInit = initStmt
+	forStmt.forCond = conditionStmt
+	forStmt.forPost = postStmt 
+	forStmt.forBlock = blockStmt
+	forStmt.forTail = blockTail
+	
+	// This sets the pred and successor links for the initialization statement
+	// note this assume the block is defined, which is might not be
+	
+	if (initStmt != nil) {
+		statements = append(statements,initStmt)
+		if ( conditionStmt != nil) {
+			initStmt.addStmtSuccessor(conditionStmt)
+			conditionStmt.addStmtPredecessor(initStmt)
+		}
+		initStmt.forRoot = forStmt 
+	}
 
-			if (stmtNode.stmtType == "funcDecl") {
-				for _, funcVar := range(funcNode.parameters) {
-					stmtNode.writeVars = append(stmtNode.writeVars,funcVar)
-				}
-			}
+	if (conditionStmt != nil) {
+		statements = append(statements,conditionStmt)
+		// there must always be a block statement 
+		conditionStmt.addStmtSuccessor(blockHead)
+		blockHead.addStmtPredecessor(conditionStmt)
+		conditionStmt.forRoot = forStmt 
+	} else {
+		blockHead.addStmtPredecessor(forStmt)		
+	}
+	
+	if (len(blocklist) > 0) {
+		statements = append(statements,blocklist...)
+	}
+	
+	if (postStmt != nil) {
+		statements = append(statements,postStmt)
+		postStmt.forRoot = forStmt 
+		if (len(blocklist) > 0) {
+			postStmt.addStmtPredecessor(blockTail)
+			blockTail.addStmtSuccessor(postStmt)
+		}
+		if (conditionStmt != nil) {
+			postStmt.addStmtSuccessor(conditionStmt)
+		}
+
+	} else {
+		if (conditionStmt != nil) {
+			blockTail.addStmtSuccessor(conditionStmt)
 			
-			if (stmtNode.stmtType == "returnStmt") {
-				for _, retVar := range(funcNode.retVars) {
-					stmtNode.writeVars = append(stmtNode.writeVars,retVar)
-				}				
-			}
+		} else {
+			// an if statement tail should go to a fake post statement
+			// try pointing back to the for node in this case 
+			blockTail.addStmtSuccessor(forStmt)
 		}
+	}
 
-	} // end for all statements 
+	
+	// if (len(statements) > 0) {
+	//   statements[len(statements)-1].setStmtPredNil()
+	//  }
+	
+	return statements 
 }
 
-// Generate a control flow graph (CFG) at the statement level.
-// We look for statement lists. If we find one, back up to the
-// enclosing function to find the function def to use as and entry
-// point. Then recursively decend down the statement list
-// Assumes there is only one statement list per function (fixme)
+// create one of the init, condition or post statements of a for loop ranging over an
+// integer. These have no parse nodes of their own, so they are defined by the range clause 
+func (l *argoListener) newRangeStmt(rangeNode *ParseNode,forStmt *StatementNode,funcStr string,stmtType string) *StatementNode {
+	var stmt *StatementNode
+
+	stmt = new(StatementNode)
+	stmt.id = l.nextStatementID; l.nextStatementID++ 
+	stmt.parseDef = rangeNode
+	stmt.parseDefID = rangeNode.id
+	stmt.parseSubDef = rangeNode
+	stmt.parseSubDefID = rangeNode.id
+	stmt.stmtType = stmtType
+	stmt.funcName = funcStr
+	stmt.sourceRow = rangeNode.sourceLineStart
+	stmt.sourceCol = rangeNode.sourceColStart
+	stmt.parent = forStmt
+	stmt.parentID = forStmt.id
+	stmt.vScope = forStmt.vScope
+	stmt.vScope.statements = append(stmt.vScope.statements,stmt)
+	l.statementGraph = append(l.statementGraph,stmt)
+	return stmt
+}
 
-func (l *argoListener) getStatementGraph() int {
-	var sourceFile *ParseNode // source file high level node
-	var funcDecl *ParseNode  // Function Declaration 
-	var funcName *ParseNode  // name of the function for the current statement
-	var blockNode *ParseNode
-	var stmtListNode *ParseNode   // the statement node
-	var funcEOS  *ParseNode // exit node of the function 
-	var funcStr  string   //  string name of the function
-	
-	var startNode, entryNode,exitNode,finishNode *StatementNode // the entry node and end nodes in the entire graph
-	var statements []*StatementNode // list of statement nodes
+// parse the case clauses of a switch or select statement into a list of statement lists,
+// one list per case clause. The statements in each case have the switch/select statement
+// as the parent, so a break inside a case finds the switch/select as the enclosing breakable
+// statement. The tail of each case falls through to the eos of the switch/select statement 
+func (l *argoListener) parseCaseClauses(clauseParent *ParseNode,funcDecl *ParseNode,caseStmt,eosStmt *StatementNode) [][]*StatementNode {
+	var caseList [][]*StatementNode
+	var blocklist []*StatementNode          // list of statements for a case clause 
+	var stmtListNode *ParseNode            // the statement list of a case clause 
+
+	caseList = nil
+	for _, clauseNode := range clauseParent.children {
+		if (clauseNode.ruleType != "exprCaseClause") && (clauseNode.ruleType != "typeCaseClause") &&
+			(clauseNode.ruleType != "commClause") {
+			continue
+		}
+		clauseNode.visited = true
 
-	
-	// mark all nodes as not visited 
-	for _, node := range l.ParseNodeList {
-		node.visited = false
+		// an empty case clause has no statements 
+		stmtListNode = clauseNode.walkDownToRule("statementList")
+		if (stmtListNode == nil) || (len(stmtListNode.children) == 0) {
+			caseList = append(caseList,nil)
+			continue
+		}
+
+		blocklist = l.getListOfStatements(stmtListNode,caseStmt,funcDecl)
+		if (len(blocklist) > 0) {
+			blocklist[len(blocklist)-1].addStmtSuccessor(eosStmt)
+		}
+		caseList = append(caseList,blocklist)
 	}
 
-	// find the high level function declarations in the source file
-	sourceFile = l.ParseNodeList[0]
-	if (sourceFile.ruleType != "SourceFile") {
-		fmt.Printf("Error! first AST node not a SourceFile\n")
-		return 0 
+	caseStmt.caseList = caseList
+	return caseList 
+}
+
+// parse a switch statement. The switchStmt rule has a single exprSwitchStmt or
+// typeSwitchStmt child which holds the case clauses 
+func (l *argoListener) parseSwitchStmt(switchNode *ParseNode,funcDecl *ParseNode,switchStmt,eosStmt *StatementNode) [][]*StatementNode {
+
+	switchNode.visited = true 
+	if (len(switchNode.children) == 0) {
+		l.reportError(switchNode.sourceLineStart,switchNode.sourceColStart,"no switch clause at AST node id %d",switchNode.id)
+		return nil
 	}
+	
+	return l.parseCaseClauses(switchNode.children[0],funcDecl,switchStmt,eosStmt)
+}
 
+// parse a select statement. The commClauses are direct children of the selectStmt rule 
+func (l *argoListener) parseSelectStmt(selectNode *ParseNode,funcDecl *ParseNode,selectStmt,eosStmt *StatementNode) [][]*StatementNode {
 
-	// the start node is a special root node to begin the execution of the
-	// system. We look for main() function which is the successor of the start statement node
-	startNode = new(StatementNode)
-	startNode.id = l.nextStatementID; l.nextStatementID++
-	startNode.parseDef = nil
-	startNode.parseDefID =  0
-	startNode.parseSubDef = nil 
-	startNode.parseSubDefID =  0
-	startNode.stmtType = "startNode"
-	startNode.funcName = "__start__"
-	startNode.sourceRow =  0
-	startNode.sourceCol =  0
-	startNode.parent = nil
-	startNode.parentID = -1
-	startNode.vScope = nil
-	l.statementGraph = append(l.statementGraph,startNode)
+	selectNode.visited = true 
+	return l.parseCaseClauses(selectNode,funcDecl,selectStmt,eosStmt)
+}
+
+
+// create a return variable node. When a return happens, we store the value
+// in this generated variable and treat the return as an expression with
+// additional control flow 
+func (l *argoListener) makeReturnVariable(identifierR_type *ParseNode,funcName string) *VariableNode {
+	var varTypeStr string
+	var numBits int 
+	var retVarNode *VariableNode
+
+	retVarNode = nil
+	if (identifierR_type == nil) {
+		return nil
+	}
+	varTypeStr,numBits,err := identifierR_type.getPrimitiveType(l.intBits)
+	if (err != nil) {
+		l.declError(identifierR_type,err)
+		return nil
+	}
 	
-	// for each function in the source file, parse the linear list of statements 
-	for i, childNode := range sourceFile.children {
+	if (varTypeStr != "") {
+		retVarNode = new (VariableNode)
+		retVarNode.id = l.nextVarID ; l.nextVarID++		
+		
+		lineStartStr := strconv.Itoa(identifierR_type.sourceLineStart)
+		colStartStr := strconv.Itoa(identifierR_type.sourceColStart)
+		
+		retVarNode.parseDef = identifierR_type 
+		retVarNode.parseDefNum = identifierR_type.id 
+		retVarNode.astClass =  identifierR_type.ruleType
+		retVarNode.funcName = funcName
+		retVarNode.sourceName  = "_" + funcName + "_" + lineStartStr + "_" + colStartStr  + "_"
+		retVarNode.sourceRow = identifierR_type.sourceLineStart
+		retVarNode.sourceCol = identifierR_type.sourceColStart
+		retVarNode.primType = varTypeStr
+		retVarNode.numBits = numBits
+		retVarNode.visited = false
+		retVarNode.isParameter = false
+		retVarNode.isResult = true 
+		retVarNode.goLangType = "numeric"  // default
 
-		// pull out the function entry and exit points. 
-		if (childNode.ruleType == "functionDecl") {
+		l.varNodeList = append(l.varNodeList,retVarNode)
+		
+		
+	} else {
+		l.reportError(0,0,"no type information for a return variable of function %s",funcName)
+	}
+	return retVarNode
+}
 
-			// this is the End-of-String (EOS) of the function, which is the exit point
-			funcDecl = childNode 
-			funcEOS = sourceFile.children[i+1]
-				
+// get a list of all the functions
+// assumes variables are already parsed to look up the parameters 
+func (l *argoListener) getAllFunctions() {
+	var funcStr string       // name of the function as a string. Must be unique
+	var resultNode *ParseNode  // node for the result 
+	var retParams *ParseNode    // the parameters for the return values for a function call
+	var identifierR_type *ParseNode  // node for getting the primitive type 
+	var fNode *FunctionNode   // node of the function we are creating 
+
+	var retVarNode *VariableNode // the variable node for the return value 
+	
+	// get parameters assumes we have the variables already parsed
+	if (len(l.varNodeList) <= 0) {
+		l.reportWarning(0,0,"the program has no variables")
+	}
+
+	for i, funcDecl := range l.ParseNodeList {
+		if (funcDecl.ruleType == "functionDecl") {
 			if (len(funcDecl.children) < 2) {  // need assertions here 
-				fmt.Printf("Error at %d, functionDecl not enough children\n",_file_line_())
+				l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"AST node %d has no function name",i)
 			}
-			
-			funcName = funcDecl.children[1]
-			funcStr = funcName.ruleType // RPM-was sourcecode 
+			funcStr = funcDecl.getFuncDeclName()
+			if (len(funcStr) > 0) {
+				fNode = new(FunctionNode)
+				fNode.id = l.nextFuncID; l.nextFuncID++
+				fNode.funcName = funcStr
+				fNode.fileName = l.fileName
+				fNode.sourceRow = funcDecl.sourceLineStart
+				fNode.sourceCol = funcDecl.sourceColStart
+				l.funcNodeList = append(l.funcNodeList,fNode)
+				l.funcNameMap[funcStr] = fNode
 
-			// add the function declaration to the statement graph as the
-			// entry point for the statements in the function
-			// this entry point becomes the copy-in for parameters in the block-graph
-			blockNode = funcDecl.walkDownToRule("block")
-			if (blockNode == nil) {
-				fmt.Printf("Error at %d, no block in function %s\n",_file_line_(),funcStr)
-				continue 
-			}
-			stmtListNode = blockNode.walkDownToRule("statementList")
-			if (stmtListNode == nil) {
-				fmt.Printf("Error at %d, no statement list in function %s\n",_file_line_(),funcStr)
-				continue 
-			}			
+				// the receiver of a method is an input of its module, not a parameter.
+				// A pointer receiver would need the caller's register written back 
+				if (funcDecl.children[1].ruleType == "receiver") && (funcDecl.children[1].walkDownToRule("pointerType") != nil) {
+					l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"method %s has a pointer receiver, only value receivers are supported",
+						funcStr)
+				}
+				for _, varNode := range (l.varNodeList) {
+					if (varNode.funcName == fNode.funcName) && (varNode.isReceiver) {
+						fNode.receiver = varNode
+					}
+				}
 
-			// We need to include function declarations in the statement graph because
-			// they are the place node of copying in the arguments in the graph.
-			if (funcDecl.visited == false ) {
-				funcDecl.visited = true
+				// get the parameters 
+				for _, varNode := range (l.varNodeList) {
+					if ((varNode.funcName == fNode.funcName) && (varNode.isParameter) && (!varNode.isReceiver) ) {
+						fNode.parameters = append(fNode.parameters,varNode)
+						fNode.parameterIDs = append(fNode.parameterIDs,varNode.id)
+					}
+				}
+				// get the return values and add them to the list of variables
+				resultNode = funcDecl.walkDownToRule("result")
+				if (resultNode != nil) { 
+					retParams = resultNode.walkDownToRule("parameterList")
 
-				entryNode = new(StatementNode)
-				entryNode.id = l.nextStatementID; l.nextStatementID++
-				entryNode.parseDef = funcDecl
-				entryNode.parseDefID =  funcDecl.id
-				entryNode.parseSubDef = nil 
-				entryNode.parseSubDefID =  0
-				entryNode.stmtType = funcDecl.ruleType
-				entryNode.funcName = funcStr
-				entryNode.sourceRow =  funcDecl.sourceLineStart 
-				entryNode.sourceCol =  funcDecl.sourceColStart
-				entryNode.parent = nil
-				entryNode.parentID = -1
-				entryNode.vScope = new(VarScope)
-				entryNode.vScope.varNameMap = make(map[string] *VariableNode)
-				entryNode.vScope.id = entryNode.id
-				entryNode.vScope.statements = append(entryNode.vScope.statements,entryNode)
-				// add the variables in the function declaration to the scope
-				// if the function and parameter fields match, add to the
-				// scope 
-				for _, node := range l.varNodeList {
-					if (node.funcName == funcStr) {
-						if (node.isParameter) {
-							entryNode.vScope.varNameMap[node.sourceName]=node
-							entryNode.vScope.statements = append(entryNode.vScope.statements,entryNode)
+					if (retParams == nil) { // this is case we have single parameter
+						identifierR_type = resultNode.walkDownToRule("r_type")
+						retVarNode =  l.makeReturnVariable(identifierR_type,funcStr)
+						if (retVarNode == nil) {
+							l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"can not make a return variable for function %s",funcStr)
+						} else {
+							fNode.retVars = append(fNode.retVars,retVarNode)
+							fNode.retVarsIDs = append(fNode.retVarsIDs,retVarNode.id)
+						}
+					} else { // we have a parameter list 
+						for _, typeNode := range retParams.children {
+							identifierR_type = typeNode.walkDownToRule("r_type")
+							if (identifierR_type == nil) { continue }  // skip 
+							retVarNode =  l.makeReturnVariable(identifierR_type,funcStr)
+							if (retVarNode == nil) {
+								l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"can not make a return variable for function %s",funcStr)
+							} else {
+								fNode.retVars = append(fNode.retVars,retVarNode)
+								fNode.retVarsIDs = append(fNode.retVarsIDs,retVarNode.id)
+							}
 						}
 					}
+					
+				} else {
+					// function does not have any results 
 				}
 				
-				// add this to the global list of statements 
-				l.statementGraph = append(l.statementGraph,entryNode)
-				
-				statements = make([]*StatementNode,1)
-				statements = l.getListOfStatements(stmtListNode,entryNode,funcDecl)
+			} else {	
+				l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"AST node %d has a zero length function name",i)
+			}
+			
+		}
+	}
 
-				// if this is the main function, make the predicessor the startNode and
-				// the successor of the start node the main() definition
-				// else the predecessors 
-				if (funcStr == "main") {
-					startNode.child = entryNode
-					startNode.childID = entryNode.id
-					entryNode.parent = startNode
-					startNode.addStmtSuccessor(entryNode)
-					entryNode.addStmtPredecessor(startNode)
-				} 
-					
-				exitNode = new(StatementNode)
-				exitNode.id = l.nextStatementID; l.nextStatementID++
-				exitNode.parseDef = funcEOS
-				exitNode.parseDefID =  funcEOS.id
-				exitNode.parseSubDef = nil 
-				exitNode.parseSubDefID =  0
-				exitNode.stmtType = "FuncExit"
-				exitNode.funcName = funcStr
-				exitNode.sourceRow =  funcEOS.sourceLineStart 
-				exitNode.sourceCol =  funcEOS.sourceColStart
-				exitNode.parent = entryNode
-				exitNode.parentID = entryNode.id
-				exitNode.child = nil
-				exitNode.childID = -1
+}
 
-				exitNode.vScope = entryNode.vScope
-				exitNode.vScope.statements = append(exitNode.vScope.statements,exitNode)
-				// add this to the global list of statements 
-				l.statementGraph = append(l.statementGraph,exitNode)
 
-				if (funcStr == "main") {
-					finishNode = new(StatementNode)
-					finishNode.id = l.nextStatementID; l.nextStatementID++
-					finishNode.parseDef = nil
-					finishNode.parseDefID =  0
-					finishNode.parseSubDef = nil 
-					finishNode.parseSubDefID =  0
-					finishNode.stmtType = "finishNode"
-					finishNode.funcName = "__finish__"
-					finishNode.sourceRow =  exitNode.sourceRow + 1
-					finishNode.sourceCol =  0
-					finishNode.parent = nil
-					finishNode.parentID = -1
-					l.statementGraph = append(l.statementGraph,finishNode)
+// Given a statementlist, return a list of StatementNodes
+// Uses recursion to follow if and for, case and select statements
+// Evey list must end with an End-Of-Statement (EOS) which is the terminal node for this list and
+// Past as the terminal statement for various sub-statements
+func (l *argoListener) getListOfStatements(listnode *ParseNode,parentStmt *StatementNode,funcDecl *ParseNode) []*StatementNode {
+	var funcStr  string   //  string name of the function
+	var numChildren,i int 
+	var subNode *ParseNode  // current statement node
+	var eosNode *ParseNode  // the end of the statement list node
+	var eosStmt *StatementNode  // the node of the end-of-statement node 
+	var stmtTypeNode *ParseNode // which simpleStmt type is this?, e.g ifStmt, shortVarDecl, forStmt.
+	var statementList []*StatementNode
+	var predecessorStmt *StatementNode 
+	var stateNode *StatementNode
+	var slist []*StatementNode
+	var varDeclList []*VariableNode // a list of variables for a declaration node 
+	//var numChildren int
+	
+	if (len(funcDecl.children) < 2) {  // need assertions here 
+		fmt.Printf("Major Error")
+	}
+	funcStr = funcDecl.getFuncDeclName()
 
-					exitNode.addStmtSuccessor(finishNode)
-					finishNode.addStmtPredecessor(exitNode)
-				} 
+	predecessorStmt = nil
 
-				if (len(statements) > 0) {
-					exitNode.addStmtPredecessor(statements[len(statements)-1])
-				}
-				
-				// fix the predecessor/successor edges 
-				entryNode.addStmtSuccessor(exitNode)
-				// note the predecessor of an exit nodes are the return statements
-				// exitNode.addStmtPredecessor(entryNode)
-				
-				// set the edges from the entry point to this list
-				
-				if ( len(statements) > 0) {
-					entryNode.child = statements[0]
-					entryNode.childID = statements[0].id
-					// make sure to add the funcDecl as a predecessor
-					entryNode.child.addStmtPredecessor(entryNode)
-					lastNode := statements[len(statements)-1]
-					lastNode.addStmtSuccessor(exitNode)
-				} else {
-					fmt.Printf("Warning: Function %s has zero statements \n",funcStr)
+
+	// every statement is separated by and EOS statement separator
+	// This loop advances through the list in groups of 2, one for the main statement and one for the eos 
+	// We will need to pass the eos to the recursively defined statements such as if, for and case
+	// that have a block/list as a sub-statement 
+	numChildren = len(listnode.children)
+	if ( (numChildren % 2) !=0 ) { // check we have an even number of children as every statement has and eos separator 
+		l.reportError(listnode.sourceLineStart,listnode.sourceColStart,"statement list with an odd number of children %d",numChildren)
+		return nil
+	}
+
+	// here is the main loop walking down a list of statements 
+	for i =0; i < (numChildren-1) ; i=i+2 {
+
+		childNode := listnode.children[i]
+		if (childNode.visited == true) {
+			continue 
+		}
+		childNode.visited = true
+
+		// the statements of a nested block, e.g. { ... } or L: { ... }, are spliced
+		// into this list between the statements around the block 
+		if blockNode := childNode.getNestedBlock() ; blockNode != nil {
+			listnode.children[i+1].visited = true
+			if (len(blockNode.children[1].children) == 0) {
+				continue  // an empty block has no statements 
+			}
+			slist = l.getListOfStatements(blockNode.children[1],parentStmt,funcDecl)
+			if (len(slist) == 0) {
+				continue
+			}
+			if (predecessorStmt != nil) {
+				predecessorStmt.addStmtSuccessor(slist[0])
+				slist[0].addStmtPredecessor(predecessorStmt)
+			}
+			statementList = append(statementList,slist...)
+			predecessorStmt = slist[len(slist)-1]
+			continue
+		}
+
+		// a labeled statement is the statement it labels, e.g. the for of L: for { ... }.
+		// The label names the target of a break or continue 
+		label := ""
+		for (len(childNode.children) == 1) && (childNode.children[0].ruleType == "labeledStmt") && (len(childNode.children[0].children) == 3) {
+			label = childNode.children[0].children[0].ruleType
+			childNode = childNode.children[0].children[2]
+			childNode.visited = true
+		}
+		
+		// check the type if we want to continue 
+		if (len(childNode.children) > 0) {
+			subNode = childNode.children[0] // subnode should be a statement
+			
+			// skip decls 
+			if (subNode.ruleType != "declaration" )&& (subNode.ruleType != ";") && (len(subNode.children) >0) {
+				// simple statements have to go one level down to get the actual type 
+				if (subNode.ruleType == "simpleStmt") { 
+					stmtTypeNode = subNode.children[0]
+				} else { 
+					stmtTypeNode = subNode
 				}
+			} else {
+				if (subNode.ruleType == "declaration") {
+					varDeclList = l.getParseVariables(subNode.children[0])
+					// fmt.Printf("got a declaration %d %d \n",subNode.id,len(varDeclList))
+					
+				} else if (subNode.ruleType == "shortVarDecl")  {
+					varDeclList = l.getParseVariables(subNode)
+					//fmt.Printf("got other declaration %d %d \n",subNode.id,len(varDeclList))
+			}
+
+				continue;
+
+			}
+
+		} else {
+			continue;
+		}
+
+		// create the new statement node 
+		stateNode = new(StatementNode)
+		stateNode.id = l.nextStatementID; l.nextStatementID++
+		stateNode.parseDef = childNode
+		stateNode.parseDefID =  childNode.id
+		stateNode.parseSubDef = stmtTypeNode
+		stateNode.parseSubDefID =  stmtTypeNode.id
+		stateNode.stmtType = stmtTypeNode.ruleType
+		stateNode.funcName = funcStr
+		stateNode.sourceRow =  stmtTypeNode.sourceLineStart 
+		stateNode.sourceCol =  stmtTypeNode.sourceColStart
+		stateNode.parent = parentStmt
+		stateNode.parentID = parentStmt.id
+		stateNode.child = nil
+		stateNode.childID = -1
+		stateNode.ifSimple = nil 
+		stateNode.ifTaken = nil
+		stateNode.ifElse  = nil
+		stateNode.forInit = nil
+		stateNode.forCond = nil 
+		stateNode.forPost = nil
+		stateNode.forBlock = nil
+		stateNode.caseList = nil
+		stateNode.label = label
+		// append to the local and global lists of statements 
+		statementList = append(statementList,stateNode)	 // local list 					
+		l.statementGraph = append(l.statementGraph,stateNode) // global list 
+		
+		// create the eos statement node, we will need it later
+		// The eos is the exit point for a sub list/block 
+		eosNode = listnode.children[i+1]
+		eosNode.visited = true
 
+		eosStmt = new(StatementNode)
+		eosStmt.id = l.nextStatementID; l.nextStatementID++
+		eosStmt.parseDef = eosNode
+		eosStmt.parseDefID =  eosNode.id
+		eosStmt.parseSubDef = eosNode
+		eosStmt.parseSubDefID =  eosNode.id
+		eosStmt.stmtType = eosNode.ruleType
+		eosStmt.funcName = funcStr
+		eosStmt.sourceRow =  eosNode.sourceLineStart 
+		eosStmt.sourceCol =  eosNode.sourceColStart
+		eosStmt.parent = parentStmt
+		eosStmt.parentID = parentStmt.id
+		eosStmt.child = nil
+		eosStmt.childID = -1
+		eosStmt.ifSimple = nil 
+		eosStmt.ifTaken = nil
+		eosStmt.ifElse  = nil
+		eosStmt.forInit = nil
+		eosStmt.forCond = nil 
+		eosStmt.forPost = nil
+		eosStmt.forBlock = nil
+		eosStmt.caseList = nil
+
+		statementList = append(statementList,eosStmt)						
+		l.statementGraph = append(l.statementGraph,eosStmt)
+		
+		// attach the predecessor to the newly generated node
+		if (predecessorStmt != nil) {
+			predecessorStmt.addStmtSuccessor(stateNode)
+		}
+		stateNode.addStmtSuccessor(eosStmt)
+		stateNode.addStmtPredecessor(predecessorStmt)
+		eosStmt.addStmtPredecessor(stateNode)
+
+		
+		// this added to the scope of the statement
+		// inheret the variable scope from either the predecessor or the	
+	// parent statement 
+		if (predecessorStmt != nil) {
+			if (predecessorStmt.vScope != nil) {
+				stateNode.vScope = predecessorStmt.vScope
+				eosStmt.vScope = predecessorStmt.vScope
+			} else {
+				if (parentStmt.vScope != nil ) {
+					stateNode.vScope = parentStmt.vScope
+					eosStmt.vScope = parentStmt.vScope
+				} else {
+					l.reportError(stateNode.sourceRow,stateNode.sourceCol,"no variable scope for statement %d",stateNode.id)
+				}
+			}
+		} else {
+			if (parentStmt.vScope != nil ) {
+				stateNode.vScope = parentStmt.vScope
+				eosStmt.vScope = parentStmt.vScope
+			} else {
+				l.reportError(stateNode.sourceRow,stateNode.sourceCol,"no variable scope for statement %d",stateNode.id)
+			}				
+		}
+
+
+		stateNode.vScope = parentStmt.vScope
+		stateNode.vScope.statements = append(stateNode.vScope.statements,stateNode)
+		
+		eosStmt.vScope = parentStmt.vScope
+		eosStmt.vScope.statements = append(eosStmt.vScope.statements,eosStmt)
+		
+		// add variables to the scope for this statement
+		// only adds statements with a var keyword in front. 
+		for _, vNode := range(varDeclList) {
+			//fmt.Printf("stmt: %d adding declaration var %s \n",stateNode.id,vNode.sourceName)
+			// find the variable name in the global list of vatiables.
+			stateNode.vScope.varNameMap[vNode.sourceName] = vNode
+			// once the node is added, clear the list from the loop
+			varDeclList = nil
+		}
+	
+		// Get sub statement lists for this node
+		switch stateNode.stmtType { 
+		case "declaration":
+			// add the variable to the scope context
+			fmt.Printf("got declaration %d \n",stateNode.id)
+		case "labeledStmt":
+			
+		case "goStmt":
+		case "deferStmt":
+			// record the defer on the function so the call can run at every exit 
+			deferFunc := l.getFuncNodeByNames("",funcStr)
+			if (deferFunc != nil) {
+				deferFunc.deferStmts = append(deferFunc.deferStmts,stateNode)
+			} else {
+				l.reportError(stateNode.sourceRow,stateNode.sourceCol,"no function %s for the defer statement %d",funcStr,stateNode.id)
+			}
+		case "returnStmt":
+		case "breakStmt":
+		case "continueStmt":
+		case "gotoStmt":
+		case "fallthroughStmt":
+		case "ifStmt":
+			// get the eos of the next node
+			// this will become the successor in the statement graph
+			// create a new variable scope for this statement
+			
+			slist = l.parseIfStmt(subNode,funcDecl,stateNode,eosStmt)
+			slistLen := len(slist)
+			if (slistLen > 0) {
+				stateNode.child = slist[0]
+				stateNode.childID = slist[0].id
+
+				// note the successor of the last statment in the
+				// sub-block is the eos of the if statement 
+				//slist[slistLen-1].addStmtSuccessor(eosStmt)
+			}
+						
+		case "switchStmt", "selectStmt":
+			var caseList [][]*StatementNode
+			if (stateNode.stmtType == "switchStmt") {
+				caseList = l.parseSwitchStmt(subNode,funcDecl,stateNode,eosStmt)
+			} else {
+				caseList = l.parseSelectStmt(subNode,funcDecl,stateNode,eosStmt)
+			}
+			// the child is the head of the first non-empty case 
+			for _, caseStmts := range caseList {
+				if (len(caseStmts) > 0) {
+					stateNode.child = caseStmts[0]
+					stateNode.childID = caseStmts[0].id
+					break
+				}
+			}
+		case "forStmt":
+			// create a new variable scope for this statement
+			slist = l.parseForStmt(subNode,funcDecl,stateNode,eosStmt)
+			slistLen := len(slist)			
+			if (slistLen > 0) {
+				stateNode.child = slist[0]
+				stateNode.childID = slist[0].id
+				//slist[slistLen-1].addStmtSuccessor(eosStmt)				
+			}
+						
+		case "sendStmt":
+		case "expressionStmt":
+		case "incDecStmt":
+		case "assignment":
+		case "shortVarDecl":
+			fmt.Printf("got short var decl %d \n",stateNode.id)
+		case "emptyStmt":
+						
+		default:
+			l.reportError(stateNode.sourceRow,stateNode.sourceCol,"no such statement type: %s",stateNode.stmtType)
+		}
+
+		predecessorStmt = eosStmt
+	} // end for loop of statementlist 
+	
+	if (statementList == nil) {
+		l.reportError(listnode.sourceLineStart,listnode.sourceColStart,"the statement list has zero statements")
+		return nil 
+	}
+	
+	return statementList 
+}
+
+// if a statement is a nested block, e.g. { ... }, or a labeled block, return the
+// block AST node, else nil 
+func (node *ParseNode) getNestedBlock() *ParseNode {
+	var stmtNode *ParseNode
+
+	stmtNode = node
+	for (stmtNode != nil) && (stmtNode.ruleType == "statement") && (len(stmtNode.children) == 1) {
+		switch stmtNode.children[0].ruleType {
+		case "block":
+			if (len(stmtNode.children[0].children) == 3) {
+				return stmtNode.children[0]
+			}
+			return nil
+		case "labeledStmt":
+			// the labeled statement is the last child, after the label and colon 
+			labeled := stmtNode.children[0]
+			stmtNode = labeled.children[len(labeled.children)-1]
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// fix the edges of the return statements to their successors to the exit nodes of the function/
+// this assumes the function declaration is linearly ordered in the statementgraph with the function
+// calls. If not, we need a walkUpToRule call. Assuming linear ordering for now.
+func (l *argoListener) addInternalReturnEdges() {
+	var functionEntry, functionExit *StatementNode
+	// find the exit node
+
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false 
+	}
+	
+	functionExit = nil 
+	for _, stmtNode := range(l.statementGraph) {
+
+		if (stmtNode.stmtType == "functionDecl") {
+			functionEntry = stmtNode 
+			functionExit = functionEntry.successors[0]
+		}
+
+		if (stmtNode.stmtType == "returnStmt") {
+			if (functionExit != nil) {
+				stmtNode.setStmtSuccNil()
+				stmtNode.addStmtSuccessor(functionExit)
+				// make sure the function exit gets the return node as a predecessors
+				functionExit.addStmtPredecessor(stmtNode)
+			}else {
+				l.reportError(stmtNode.sourceRow,stmtNode.sourceCol,"no function exit for the return statement %d",stmtNode.id)
+			}
+		}
+	}
+}
+
+// remove the predecessor edges for the EOS nodes
+// then add them back in based on the successor edges
+func (l *argoListener) fixEosPredecessors() {
+
+	// remove all the predecessor edges 
+	for _, stmtNode := range(l.statementGraph) {
+		if stmtNode.stmtType == "eos" {
+			stmtNode.predecessors = nil
+			stmtNode.predIDs = nil 
+		}
+	}
+
+	// if we found an EOS as a target, add a predecessor edge 
+	for _, stmtNode := range(l.statementGraph) {
+		if len(stmtNode.successors) > 0 {
+			succNode := stmtNode.successors[0]
+			if succNode.stmtType == "eos" {
+				succNode.addStmtPredecessor(stmtNode)
+			}
+		}
+	}
+
+	// a break jumps to the EOS after its for, switch or select statement, so
+	// the break is also a predecessor of that EOS 
+	for _, stmtNode := range(l.statementGraph) {
+		if (stmtNode.stmtType != "breakStmt") {
+			continue
+		}
+		breakHead := getBreakHead(stmtNode)
+		if (breakHead != nil) && (len(breakHead.successors) > 0) && (breakHead.successors[0].stmtType == "eos") {
+			if (!stmtInList(breakHead.successors[0].predecessors,stmtNode)) {
+				breakHead.successors[0].addStmtPredecessor(stmtNode)
+			}
+		}
+	}
+}
+
+// get a function statement Node by the functions name 
+func (l *argoListener) getFunctionStmtEntry(funcName string) *StatementNode {
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false
+
+		if (stmtNode.stmtType == "functionDecl") {
+			if (stmtNode.funcName == funcName) {
+				return stmtNode
+			}
+		}
+	}
+	return nil 
+}
+
+
+// check the number of values a call site uses against the number of results
+// the called function returns. A call statement may drop all the results, a
+// single call on the right side of an assignment or a return gives one value
+// to each name, and a call anywhere else in an expression must return exactly
+// one value. Returns the number of errors found
+func (l *argoListener) checkResultArity(argNode *ParseNode, calleeName string) int {
+	var funcNode,callerNode *FunctionNode
+	var callNode,topNode,listNode,ctxNode *ParseNode
+	var numUsed,numNames int
+	var ok bool
+
+	if funcNode, ok = l.funcNameMap[calleeName] ; !ok {
+		return 0
+	}
+	callNode = argNode.parent
+	// climb out of the parentheses and single expressions around the call
+	topNode = callNode
+	for (topNode.parent != nil) {
+		if ((topNode.parent.ruleType == "expression") || (topNode.parent.ruleType == "unaryExpr") ||
+			(topNode.parent.ruleType == "primaryExpr")) && (len(topNode.parent.children) == 1) {
+			topNode = topNode.parent
+		} else if (topNode.parent.ruleType == "operand") && (len(topNode.parent.children) == 3) {
+			topNode = topNode.parent
+		} else {
+			break
+		}
+	}
+	if (topNode.parent == nil) {
+		return 0
+	}
+
+	numUsed = 1
+	switch topNode.parent.ruleType {
+	case "expressionStmt", "goStmt", "deferStmt":
+		return 0
+	case "expressionList":
+		listNode = topNode.parent
+		ctxNode = listNode.parent
+		if (ctxNode == nil) || (len(listNode.getExpressionList()) != 1) {
+			break
+		}
+		switch ctxNode.ruleType {
+		case "shortVarDecl":
+			numNames = 0
+			for _, child := range ctxNode.children[0].children {
+				if (child.ruleType != ",") {
+					numNames++
+				}
+			}
+			numUsed = numNames
+		case "assignment":
+			if (ctxNode.getAssignOp() == "") && (len(ctxNode.children) == 3) && (ctxNode.children[2] == listNode) {
+				numUsed = len(ctxNode.children[0].getExpressionList())
+			}
+		case "returnStmt":
+			if callerNode, ok = l.funcNameMap[argNode.getEnclosingFuncName()] ; !ok {
+				return 0
+			}
+			numUsed = len(callerNode.retVars)
+		case "arguments":
+			// f(g()) passes all the results of g as the arguments of f
+			if (len(funcNode.retVars) > 0) {
+				return 0
+			}
+		}
+	}
+
+	if (len(funcNode.retVars) == numUsed) {
+		return 0
+	}
+	if (len(funcNode.retVars) == 0) {
+		l.reportError(callNode.sourceLineStart,callNode.sourceColStart,"the call of %s uses its result, but %s declared at %d:%d returns no value",
+			calleeName,
+			calleeName,funcNode.sourceRow,funcNode.sourceCol)
+	} else {
+		l.reportError(callNode.sourceLineStart,callNode.sourceColStart,"the call of %s uses %d values, but %s declared at %d:%d returns %d",
+			calleeName,numUsed,
+			calleeName,funcNode.sourceRow,funcNode.sourceCol,len(funcNode.retVars))
+	}
+	return 1
+}
+
+// add edges to the caller 
+func (l *argoListener) addCallandReturnEdges() {
+	var funcEntryNode,functionExitNode *StatementNode
+	var retList []*ParseNode
+	var calleeNameStr string
+	var arityChecked map[*ParseNode]bool
+
+	arityChecked = make(map[*ParseNode]bool)
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false 
+	}
+
+	// these statement types may have a function call 
+	for _, stmtNode := range(l.statementGraph) {
+		// statement types which may make a function call 
+		if ((stmtNode.stmtType == "expression") || (stmtNode.stmtType == "assignment") ||
+			(stmtNode.stmtType == "shortVarDecl") || (stmtNode.stmtType == "expressionStmt") ||
+			(stmtNode.stmtType == "unaryExpr")  || (stmtNode.stmtType == "returnStmt") ||
+		        (stmtNode.stmtType == "goStmt") || (stmtNode.stmtType == "deferStmt")) {
+
+			// if something in the AST has parameters, we declare it having at least one functio
+			retList = stmtNode.parseDef.walkDownToAllRules("arguments")
+
+			// check if we have multiple calls in this statement. If so we walk the list of
+			// called functions and add a successor edge in the statementgraph for each one 
+			for _, argNode := range retList {
+				calleeNameStr = l.getCalleeName(argNode)
+				if (calleeNameStr != "") {
+					// find the functionDecl node with this name
+					funcEntryNode = l.getFunctionStmtEntry(calleeNameStr)
+					// if we can't find the function, then abort this node 
+					if (funcEntryNode != nil) {
+						if (!arityChecked[argNode]) {
+							arityChecked[argNode] = true
+							l.checkResultArity(argNode,calleeNameStr)
+						}
+						// add predecessor to the function entry node 
+						// funcEntryNode.addStmtPredecessor(stmtNode)
+						funcEntryNode.callers = append(funcEntryNode.callers,stmtNode)
+						// non-go statements add a return edge
+						if (stmtNode.stmtType != "goStmt") {
+							stmtNode.callTargets=append(stmtNode.callTargets,funcEntryNode)
+							functionExitNode = funcEntryNode.successors[0]
+							functionExitNode.returnTargets = append(functionExitNode.returnTargets,stmtNode)
+							
+						} else {
+							stmtNode.goTargets=append(stmtNode.goTargets,funcEntryNode)
+						}
+					} else if (l.getIgnoredCall(argNode) != "") {
+						// a library call with no hardware meaning, e.g. time.Sleep, is
+						// lowered by lowerIgnoredCalls, and has no call edge 
+					} else {
+						// check if a fmt.printf, make or cast statements. 
+						// need to add check if this is a printf statement 
+						// fmt.Printf("function %s not found \n",calleeNameStr)
+					}
+					
+				} else {
+					l.reportError(argNode.sourceLineStart,argNode.sourceColStart,"no operand for the call")
+				}
+			}
+
+		}
+	}
+}
+
+
+// convert an expression to a string
+// walk the parse tree. If there are no children, return the string
+// concatenate all the return strings separated by a space 
+func expressionToString(pNode *ParseNode) string {
+	var returnStr,newStr string ;
+	// if we are a terminal node, just return the ruletype (or sourcecode) 
+	if (len(pNode.children) == 0) {
+		return pNode.ruleType
+	}
+
+	// concatenate all the child strings 
+	returnStr = ""
+	for _, childNode := range(pNode.children) {
+		returnStr = returnStr + expressionToString(childNode) + " "
+	}
+
+	// remove extra spaces.
+	// loop through the string and add a space only if the character is not a space or the
+	// previous character is not a space 
+	for i, c := range returnStr {
+		if c != ' ' {
+			newStr = newStr + string(c);
+		} else {
+			if (i >0) {
+				if returnStr[i-1] != ' ' {
+					newStr = newStr + string(c);
+				}
+			}
+		}
+	}
+	
+	// return the concat of the children 
+	return newStr; 
+}
+	
+// return the list of expression nodes in an expressionList parse node 
+func (node *ParseNode) getExpressionList() []*ParseNode {
+	var exprList []*ParseNode
+
+	if (node == nil) {
+		return nil
+	}
+	for _, child := range node.children {
+		if (child.ruleType == "expression") {
+			exprList = append(exprList,child)
+		}
+	}
+	return exprList 
+}
+
+// if a short var decl or assignment is the comma-ok form of a receive, e.g.
+// v, ok := <-ch, return the receive expression, else nil. The first value is
+// the data received and the second is false if the channel is closed 
+func (node *ParseNode) getCommaOkRecv() *ParseNode {
+	var exprList []*ParseNode
+	var recvNode *ParseNode
+	var numNames int
+
+	if (node == nil) || (len(node.children) < 3) {
+		return nil
+	}
+	switch node.ruleType {
+	case "shortVarDecl":
+		numNames = 0
+		for _, child := range node.children[0].children {
+			if (child.ruleType != ",") {
+				numNames++
+			}
+		}
+	case "assignment":
+		if (node.getAssignOp() != "") {
+			return nil
+		}
+		numNames = len(node.children[0].getExpressionList())
+	default:
+		return nil
+	}
+	exprList = node.children[2].getExpressionList()
+	if (numNames != 2) || (len(exprList) != 1) {
+		return nil
+	}
+	recvNode = exprList[0].stripParens()
+	if (recvNode.ruleType != "unaryExpr") || (len(recvNode.children) != 2) || (recvNode.children[0].ruleType != "<-") {
+		return nil
+	}
+	return recvNode
+}
+
+// return the operator of a compound assignment, e.g. "+" for x += y. A plain
+// assignment, or a node which is not an assignment, returns an empty string 
+func (node *ParseNode) getAssignOp() string {
+	var opNode *ParseNode
+
+	if (node == nil) || (node.ruleType != "assignment") || (len(node.children) < 3) {
+		return ""
+	}
+	opNode = node.children[1]
+	if (opNode.ruleType != "assign_op") || (len(opNode.children) != 2) {
+		return ""
+	}
+	return opNode.children[0].ruleType
+}
+
+// return the variable names on the left hand side of an assignment or short var decl, in order.
+// Names of LHS expressions which are not plain variables (e.g. a[i]) are empty strings 
+func (stmt *StatementNode) getLhsNames() []string {
+	var names []string
+	var lhsNode *ParseNode
+
+	if (stmt.parseSubDef == nil) || (len(stmt.parseSubDef.children) == 0) {
+		return nil
+	}
+	lhsNode = stmt.parseSubDef.children[0]
+
+	if (stmt.stmtType == "shortVarDecl") {
+		for _, child := range lhsNode.children {
+			if (child.ruleType != ",") {
+				names = append(names,child.ruleType)
+			}
+		}
+	} else if (stmt.stmtType == "assignment") {
+		for _, exprNode := range lhsNode.getExpressionList() {
+			names = append(names,exprNode.stripDeref().getPlainOperandName())
+		}
+	}
+	return names 
+}
+
+// if the callee of an arguments node is a selector, e.g. p.route(), return the
+// expression the method is selected from and the name of the method 
+func (argNode *ParseNode) getMethodCall() (*ParseNode, string) {
+	var callee *ParseNode
+
+	if (argNode.parent == nil) || (len(argNode.parent.children) != 2) {
+		return nil, ""
+	}
+	callee = argNode.parent.children[0]
+	if (callee.ruleType != "primaryExpr") || (len(callee.children) != 2) || (callee.children[1].ruleType != "selector") ||
+		(len(callee.children[1].children) < 2) {
+		return nil, ""
+	}
+	return callee.children[0], callee.children[1].children[1].ruleType
+}
+
+// get the name of the function called with an arguments node. A method call is
+// resolved by the struct type of the receiver variable, e.g. p.route() calls
+// RouterPkt_route when p is a RouterPkt. Other calls are named by their first
+// operand, e.g. fmt for fmt.Printf, which is not a function of the program 
+func (l *argoListener) getCalleeName(argNode *ParseNode) string {
+	var operandNameNode *ParseNode
+	var vNode *VariableNode
+
+	if (argNode.parent == nil) {
+		return ""
+	}
+	if recvNode, method := argNode.getMethodCall() ; recvNode != nil {
+		vNode = l.getVarNodeInScope(argNode.getEnclosingFuncName(),recvNode.getPlainOperandName(),recvNode)
+		if (vNode != nil) && (vNode.structType != nil) && (vNode.structType.typeName != "") {
+			if _, ok := l.funcNameMap[vNode.structType.typeName + "_" + method] ; ok {
+				return vNode.structType.typeName + "_" + method
+			}
+		}
+	}
+	operandNameNode = argNode.parent.walkDownToRule("operandName")
+	if (operandNameNode == nil) || (len(operandNameNode.children) == 0) {
+		return ""
+	}
+	// a call of a function variable is a call of the function it holds 
+	vNode = l.getVarNodeInScope(argNode.getEnclosingFuncName(),operandNameNode.children[0].ruleType,operandNameNode)
+	if (vNode != nil) && (vNode.goLangType == "function") {
+		return vNode.funcTarget
+	}
+	return operandNameNode.children[0].ruleType
+}
+
+// return true if an expression is a function value: the name of a function of the
+// program, or of a function variable 
+func (l *argoListener) isFuncValue(expr *ParseNode,funcName string) bool {
+	var name string
+
+	if (expr == nil) {
+		return false
+	}
+	name = expr.stripParens().getPlainOperandName()
+	if (name == "") {
+		return false
+	}
+	if vNode := l.getVarNodeInScope(funcName,name,expr) ; vNode != nil {
+		return vNode.goLangType == "function"
+	}
+	_, ok := l.funcNameMap[name]
+	return ok
+}
+
+// get the name of the function a function value is, or "" if it is not known yet 
+func (l *argoListener) funcValueName(expr *ParseNode,funcName string) string {
+	var name string
+
+	name = expr.stripParens().getPlainOperandName()
+	if vNode := l.getVarNodeInScope(funcName,name,expr) ; vNode != nil {
+		if (vNode.goLangType == "function") {
+			return vNode.funcTarget
+		}
+		return ""
+	}
+	if _, ok := l.funcNameMap[name] ; ok {
+		return name
+	}
+	return ""
+}
+
+// bind each function variable to the one function it holds, so a call of the
+// variable is a call of the module of that function. A function variable is
+// declared with a function type, or by a short var decl of a function value, and
+// a parameter of a function type holds the argument of every call. There is no
+// dynamic dispatch, so all the values given to a variable must be the same
+// function. Returns the number of variables which can not be resolved 
+func (l *argoListener) resolveFuncVariables() int {
+	var values map[*VariableNode][]*ParseNode
+	var numErrors int
+	var changed bool
+
+	// a short var decl of a function value declares a function variable 
+	for _, vNode := range l.varNodeList {
+		if (vNode.astClass == "shortVarDecl") && (l.isFuncValue(vNode.initExpr,vNode.funcName)) {
+			vNode.goLangType = "function"
+			vNode.numBits = 0
+		}
+	}
+
+	values = make(map[*VariableNode][]*ParseNode)
+	for _, vNode := range l.varNodeList {
+		if (vNode.goLangType == "function") && (vNode.initExpr != nil) {
+			values[vNode] = append(values[vNode],vNode.initExpr)
+		}
+	}
+	for _, node := range l.ParseNodeList {
+		switch node.ruleType {
+		case "assignment":
+			if (node.getAssignOp() != "") || (len(node.children) != 3) {
+				continue
+			}
+			funcName := node.getEnclosingFuncName()
+			lhsList := node.children[0].getExpressionList()
+			rhsList := node.children[2].getExpressionList()
+			for k, lhs := range lhsList {
+				vNode := l.getVarNodeInScope(funcName,lhs.getPlainOperandName(),lhs)
+				if (vNode != nil) && (vNode.goLangType == "function") && (k < len(rhsList)) {
+					values[vNode] = append(values[vNode],rhsList[k])
+				}
+			}
+		case "arguments":
+			// the arguments of a call are the values of the function parameters 
+			funcNode, ok := l.funcNameMap[l.getCalleeName(node)]
+			if (!ok) {
+				continue
+			}
+			exprListNode := node.walkDownToRule("expressionList")
+			if (exprListNode == nil) {
+				continue
+			}
+			args := exprListNode.getExpressionList()
+			for k, param := range funcNode.parameters {
+				if (param.goLangType == "function") && (k < len(args)) {
+					values[param] = append(values[param],args[k])
+				}
+			}
+		}
+	}
+
+	// a value may be another function variable, so repeat until nothing changes 
+	changed = true
+	for (changed) {
+		changed = false
+		for vNode, exprs := range values {
+			if (vNode.funcTarget != "") {
+				continue
+			}
+			for _, expr := range exprs {
+				if name := l.funcValueName(expr,expr.getEnclosingFuncName()) ; name != "" {
+					vNode.funcTarget = name
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	numErrors = 0
+	for _, vNode := range l.varNodeList {
+		if (vNode.goLangType != "function") {
+			continue
+		}
+		if (vNode.funcTarget == "") {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"function variable %s is not given a known function",vNode.sourceName)
+			numErrors++
+			continue
+		}
+		for _, expr := range values[vNode] {
+			if name := l.funcValueName(expr,expr.getEnclosingFuncName()) ; name != vNode.funcTarget {
+				if (name == "") {
+					name = strings.TrimSpace(expr.getSourceCode())
+				}
+				l.reportError(expr.sourceLineStart,expr.sourceColStart,"function variable %s holds %s and %s, which can not be resolved to one function",
+					vNode.sourceName,vNode.funcTarget,name)
+				numErrors++
+				break
+			}
+		}
+	}
+	return numErrors
+}
+
+// the builtin functions of Go. A call of a builtin is not a call of a module 
+var builtinFuncs = map[string]bool{
+	"append": true, "cap": true, "close": true, "copy": true, "delete": true, "len": true,
+	"make": true, "new": true, "panic": true, "print": true, "println": true,
+}
+
+// get the name of the builtin called with an arguments node, or "" if the call is not
+// of a builtin. A function of the program with the same name shadows the builtin 
+func (l *argoListener) getBuiltinCall(argNode *ParseNode) string {
+	var name string
+
+	name = l.getCalleeName(argNode)
+	if (!builtinFuncs[name]) || (l.getFuncNodeByNames("",name) != nil) {
+		return ""
+	}
+	return name
+}
+
+// the library calls of the CSP programs which have no hardware meaning. A noop is
+// removed from the control flow, a yield takes one cycle and does nothing, as a
+// module always runs concurrently with the others. -ignorecalls adds noops 
+var defaultIgnoredCalls = map[string]string{
+	"time.Sleep": "noop", "runtime.GC": "noop", "runtime.GOMAXPROCS": "noop",
+	"runtime.LockOSThread": "noop", "runtime.UnlockOSThread": "noop",
+	"runtime.Gosched": "yield",
+}
+
+// get how a call of an ignored library function is lowered, "noop" or "yield", or
+// "" if the arguments node is not a call of one. A variable of the program with the
+// name of the package, e.g. time, shadows the package 
+func (l *argoListener) getIgnoredCall(argNode *ParseNode) string {
+	var callee *ParseNode
+	var name string
+
+	if (argNode.parent == nil) || (len(argNode.parent.children) == 0) {
+		return ""
+	}
+	callee = argNode.parent.children[0]
+	if (callee.ruleType != "primaryExpr") || (len(callee.children) != 2) || (callee.children[1].ruleType != "selector") {
+		return ""
+	}
+	name = strings.Join(strings.Fields(callee.getSourceCode()),"")
+	if (l.getVarNodeInScope(argNode.getEnclosingFuncName(),callee.children[0].getPlainOperandName(),callee) != nil) {
+		return ""
+	}
+	if kind, ok := l.ignoredCalls[name] ; ok {
+		return kind
+	}
+	return defaultIgnoredCalls[name]
+}
+
+// get the channel expression of a call of close, e.g. ch in close(ch), or nil if
+// the arguments node is not a call of close 
+func (l *argoListener) getCloseArg(argNode *ParseNode) *ParseNode {
+	var exprList []*ParseNode
+
+	if (l.getBuiltinCall(argNode) != "close") {
+		return nil
+	}
+	exprList = argNode.walkDownToRule("expressionList").getExpressionList()
+	if (len(exprList) != 1) {
+		return nil
+	}
+	return exprList[0]
+}
+
+// return true if an r_type AST node is a pointer type, e.g. *RouterState 
+func (node *ParseNode) isPointerType() bool {
+	if (node == nil) || (len(node.children) == 0) || (node.children[0].ruleType != "typeLit") {
+		return false
+	}
+	return (len(node.children[0].children) > 0) && (node.children[0].children[0].ruleType == "pointerType")
+}
+
+// get the type of an expression which is only a call of new, e.g. T in new(T), as
+// an r_type AST node, else nil. A hardware design has no heap, so new allocates
+// the registers of the value statically, and the pointer names them.
+// A type name argument parses as an expression, so it is the r_type of its declaration 
+func (l *argoListener) getNewType(exprNode *ParseNode) *ParseNode {
+	var inner, argNode, typeNode *ParseNode
+	var exprList []*ParseNode
+	var name string
+
+	if (exprNode == nil) {
+		return nil
+	}
+	inner = exprNode.stripParens()
+	if (inner.ruleType != "primaryExpr") || (len(inner.children) != 2) || (inner.children[1].ruleType != "arguments") {
+		return nil
+	}
+	argNode = inner.children[1]
+	if (l.getBuiltinCall(argNode) != "new") {
+		return nil
+	}
+	typeNode = argNode.walkDownToRule("r_type")
+	if (typeNode != nil) && (typeNode.parent == argNode) {
+		return typeNode
+	}
+	exprList = argNode.walkDownToRule("expressionList").getExpressionList()
+	if (len(exprList) != 1) {
+		return nil
+	}
+	name = exprList[0].getPlainOperandName()
+	if typeSpec, ok := l.typeSpecMap[name] ; ok {
+		return typeSpec.children[1]
+	}
+	// a primitive type, e.g. new(int), is the operand name node standing in for a typeName 
+	if (isPrimitiveTypeName(name)) {
+		return exprList[0].walkDownToRule("operandName")
+	}
+	return nil
+}
+
+// if the right hand side of an assignment or short var decl is a single call to
+// a function in the program, return the function node of the callee, else nil 
+func (l *argoListener) getRhsCallee(stmt *StatementNode) *FunctionNode {
+	var rhsList []*ParseNode
+	var argNode *ParseNode
+
+	if (stmt.stmtType != "shortVarDecl") && (stmt.stmtType != "assignment") {
+		return nil
+	}
+	if (stmt.parseSubDef == nil) || (len(stmt.parseSubDef.children) < 3) {
+		return nil
+	}
+
+	rhsList = stmt.parseSubDef.children[2].getExpressionList()
+	if (len(rhsList) != 1) {
+		return nil
+	}
+	argNode = rhsList[0].walkDownToRule("arguments")
+	if (argNode == nil) || (argNode.parent == nil) {
+		return nil
+	}
+	return l.getFuncNodeByNames("",l.getCalleeName(argNode))
+}
+
+// get the variables read by the index expressions of an array or map element 
+func (l *argoListener) getIndexReadVars(indexes []*ParseNode,funcName string) []*VariableNode {
+	var readVars []*VariableNode
+
+	for _, index := range indexes {
+		for _, opNode := range index.walkDownToAllRules("operandName") {
+			if (len(opNode.children) == 0) {
+				continue
+			}
+			if varNode := l.getVarNodeInScope(funcName,opNode.children[0].ruleType,opNode) ; varNode != nil {
+				readVars = append(readVars,varNode)
+			}
+		}
+	}
+	return readVars
+}
+
+// for assignment and short var decls, add the left and right hand sides of the assignment expression
+func (l *argoListener) addVarAssignments() {
+	var funcStr string
+	var varStrList []string 
+	var parsedNode, funcParseNode, lhsNode,operandNameNode *ParseNode
+	var operandNameNodeList []*ParseNode 
+	var useNodeList []*ParseNode 
+	var varNode *VariableNode
+	var funcNode *FunctionNode
+	
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false 
+	}
+
+	// these statement types may have a variable assignment 
+	for _, stmtNode := range(l.statementGraph) {
+
+
+		// get the function header 
+		if ((stmtNode.stmtType == "assignment") || (stmtNode.stmtType == "shortVarDecl") || 
+			(stmtNode.stmtType == "sendStmt") || (stmtNode.stmtType == "funcDecl") ||
+			(stmtNode.stmtType == "returnStmt")) {
+			
+			parsedNode = stmtNode.parseSubDef
+			funcParseNode = parsedNode.walkUpToRule("functionDecl")
+			funcStr = funcParseNode.getFuncDeclName()
+
+			operandNameNodeList = make([]*ParseNode,0)
+			varStrList = make([]string,0)
+		}
+		// statement types which may have an assignment
+		if ((stmtNode.stmtType == "assignment") || (stmtNode.stmtType == "shortVarDecl") || 
+			(stmtNode.stmtType == "sendStmt")) {
+
+			// get the left hand side of the statement 
+
+			parsedNode = stmtNode.parseSubDef
+			lhsNode = parsedNode.children[0]
+			
+			funcParseNode = parsedNode.walkUpToRule("functionDecl")
+			funcStr = funcParseNode.getFuncDeclName()
+
+			// make sure we get all the variables in the assignment for
+			// when there are multiple ones in a return statement
+			// the names are resolved in the scope of where they are written 
+			useNodeList = make([]*ParseNode,0)
+			if (stmtNode.stmtType == "assignment") {
+				for _, lhsExpr := range lhsNode.getExpressionList() {
+					// an element of an array or map, e.g. a[i] = x or m[k] = v, writes the
+					// array or map at the index, and reads the variables of the index 
+					if element := lhsExpr.stripParens() ; (element.ruleType == "primaryExpr") && (len(element.children) == 2) && (element.children[1].ruleType == "index") {
+						name, indexes := getArrayElement(element)
+						if (name == "") {
+							l.reportError(lhsExpr.sourceLineStart,lhsExpr.sourceColStart,"the indexed assignment is not to an array or map variable")
+							continue
+						}
+						opNode := element.walkDownToRule("operandName")
+						varNode = l.getVarNodeInScope(funcStr,name,opNode)
+						if (varNode == nil) {
+							l.reportError(lhsExpr.sourceLineStart,lhsExpr.sourceColStart,"no variable %s in function %s",name,funcStr)
+							continue
+						}
+						if (stmtNode.writeIndexes == nil) {
+							stmtNode.writeIndexes = make(map[*VariableNode][]*ParseNode)
+						}
+						stmtNode.writeIndexes[varNode] = indexes
+						varStrList = append(varStrList,name)
+						useNodeList = append(useNodeList,opNode)
+						for _, indexVar := range l.getIndexReadVars(indexes,funcStr) {
+							stmtNode.readVars = append(stmtNode.readVars,indexVar)
+						}
+						continue
+					}
+					operandNameNodeList = lhsExpr.walkDownToAllRules("operandName")
+					for _, opNode := range(operandNameNodeList) {
+						varStrList = append(varStrList,opNode.children[0].ruleType)
+						useNodeList = append(useNodeList,opNode)
+					}
+				}
+			}
+
+			// channels cannot accept mulitple values. The channel is the left hand
+			// side, and the value sent is any expression on the right 
+			if (stmtNode.stmtType == "sendStmt") { 
+				operandNameNode = lhsNode.walkDownToRule("operandName")
+				if (operandNameNode == nil) || (len(operandNameNode.children) == 0) {
+					l.reportError(parsedNode.sourceLineStart,parsedNode.sourceColStart,"the send is not on a channel variable")
+					continue
+				}
+				varStrList = append(varStrList,operandNameNode.children[0].ruleType)
+				useNodeList = append(useNodeList,operandNameNode)
+			}
+
+			// a short var decl can declare multiple values, e.g. from a function
+			// that returns multiple values 
+			if (stmtNode.stmtType == "shortVarDecl")  { 
+				for _, name := range stmtNode.getLhsNames() {
+					varStrList = append(varStrList,name)
+					useNodeList = append(useNodeList,parsedNode)
+				}
+			}
+
+			// parsedNode.sourceLineStart,parsedNode.sourceColStart,varStr)
+
+			// iterate through the variables names and and them to the LHS expression 
+			for k, varStr := range(varStrList) {
+				// the blank identifier discards its value 
+				if (varStr == BLANKIDENT) {
+					continue
+				}
+				varNode = l.getVarNodeInScope(funcStr,varStr,useNodeList[k])
+				if (varNode == nil) {
+					l.reportError(parsedNode.sourceLineStart,parsedNode.sourceColStart,"no variable %s in function %s",varStr,funcStr)
+					continue
+				}
+				stmtNode.writeVars = append(stmtNode.writeVars,varNode)
+				// a compound assignment, e.g. x += y, also reads the left hand side 
+				if (stmtNode.stmtType == "assignment") && (parsedNode.getAssignOp() != "") {
+					stmtNode.readVars = append(stmtNode.readVars,varNode)
+				}
+			}
+
+
+			// get the expression on the right hand side 
+		}
+
+		// function entry copies the parameters
+		// function return copies the RHS into the list of return values 
+		if ( (stmtNode.stmtType == "funcDecl") || (stmtNode.stmtType == "returnStmt")) {
+			
+			funcNode = l.getFuncNodeByNames("",funcStr)
+			if (funcNode == nil) {
+				l.reportError(stmtNode.sourceRow,stmtNode.sourceCol,"no function %s",funcStr)
+				continue
+			}
+
+			if (stmtNode.stmtType == "funcDecl") {
+				for _, funcVar := range(funcNode.parameters) {
+					stmtNode.writeVars = append(stmtNode.writeVars,funcVar)
+				}
+			}
+			
+			if (stmtNode.stmtType == "returnStmt") {
+				for _, retVar := range(funcNode.retVars) {
+					stmtNode.writeVars = append(stmtNode.writeVars,retVar)
+				}				
+			}
+		}
+
+	} // end for all statements 
+}
+
+// Generate a control flow graph (CFG) at the statement level.
+// We look for statement lists. If we find one, back up to the
+// enclosing function to find the function def to use as and entry
+// point. Then recursively decend down the statement list.
+// The statement lists of nested blocks are spliced into the list around them 
+
+func (l *argoListener) getStatementGraph() int {
+	var sourceFile *ParseNode // source file high level node
+	var funcDecl *ParseNode  // Function Declaration 
+	var blockNode *ParseNode
+	var stmtListNode *ParseNode   // the statement node
+	var funcEOS  *ParseNode // exit node of the function 
+	var funcStr  string   //  string name of the function
+	
+	var startNode, entryNode,exitNode,finishNode *StatementNode // the entry node and end nodes in the entire graph
+	var statements []*StatementNode // list of statement nodes
+
+	
+	// mark all nodes as not visited 
+	for _, node := range l.ParseNodeList {
+		node.visited = false
+	}
+
+	// find the high level function declarations in the source file
+	sourceFile = l.ParseNodeList[0]
+	if (sourceFile.ruleType != "SourceFile") {
+		l.reportError(0,0,"the first AST node is not a SourceFile")
+		return 0 
+	}
+
+
+	// the start node is a special root node to begin the execution of the
+	// system. We look for main() function which is the successor of the start statement node
+	startNode = new(StatementNode)
+	startNode.id = l.nextStatementID; l.nextStatementID++
+	startNode.parseDef = nil
+	startNode.parseDefID =  0
+	startNode.parseSubDef = nil 
+	startNode.parseSubDefID =  0
+	startNode.stmtType = "startNode"
+	startNode.funcName = "__start__"
+	startNode.sourceRow =  0
+	startNode.sourceCol =  0
+	startNode.parent = nil
+	startNode.parentID = -1
+	startNode.vScope = nil
+	l.statementGraph = append(l.statementGraph,startNode)
+	
+	// for each function in the source file, parse the linear list of statements 
+	for i, childNode := range sourceFile.children {
+
+		// pull out the function entry and exit points. 
+		if (childNode.ruleType == "functionDecl") {
+
+			// this is the End-of-String (EOS) of the function, which is the exit point
+			funcDecl = childNode 
+			funcEOS = sourceFile.children[i+1]
+				
+			if (len(funcDecl.children) < 2) {  // need assertions here 
+				l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"the function declaration has too few children")
+			}
+			
+			funcStr = funcDecl.getFuncDeclName()
+
+			// add the function declaration to the statement graph as the
+			// entry point for the statements in the function
+			// this entry point becomes the copy-in for parameters in the block-graph
+			blockNode = funcDecl.walkDownToRule("block")
+			if (blockNode == nil) {
+				l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"no block in function %s",funcStr)
+				continue 
+			}
+			stmtListNode = blockNode.walkDownToRule("statementList")
+			if (stmtListNode == nil) {
+				l.reportError(funcDecl.sourceLineStart,funcDecl.sourceColStart,"no statement list in function %s",funcStr)
+				continue 
+			}			
+
+			// We need to include function declarations in the statement graph because
+			// they are the place node of copying in the arguments in the graph.
+			if (funcDecl.visited == false ) {
+				funcDecl.visited = true
+
+				entryNode = new(StatementNode)
+				entryNode.id = l.nextStatementID; l.nextStatementID++
+				entryNode.parseDef = funcDecl
+				entryNode.parseDefID =  funcDecl.id
+				entryNode.parseSubDef = nil 
+				entryNode.parseSubDefID =  0
+				entryNode.stmtType = funcDecl.ruleType
+				entryNode.funcName = funcStr
+				entryNode.sourceRow =  funcDecl.sourceLineStart 
+				entryNode.sourceCol =  funcDecl.sourceColStart
+				entryNode.parent = nil
+				entryNode.parentID = -1
+				entryNode.vScope = new(VarScope)
+				entryNode.vScope.varNameMap = make(map[string] *VariableNode)
+				entryNode.vScope.id = entryNode.id
+				entryNode.vScope.statements = append(entryNode.vScope.statements,entryNode)
+				// add the variables in the function declaration to the scope
+				// if the function and parameter fields match, add to the
+				// scope 
+				for _, node := range l.varNodeList {
+					if (node.funcName == funcStr) {
+						if (node.isParameter) {
+							entryNode.vScope.varNameMap[node.sourceName]=node
+							entryNode.vScope.statements = append(entryNode.vScope.statements,entryNode)
+						}
+					}
+				}
+				
+				// add this to the global list of statements 
+				l.statementGraph = append(l.statementGraph,entryNode)
+				
+				statements = make([]*StatementNode,1)
+				statements = l.getListOfStatements(stmtListNode,entryNode,funcDecl)
+
+				// if this is the main function, make the predicessor the startNode and
+				// the successor of the start node the main() definition
+				// else the predecessors 
+				if (funcStr == "main") {
+					startNode.child = entryNode
+					startNode.childID = entryNode.id
+					entryNode.parent = startNode
+					startNode.addStmtSuccessor(entryNode)
+					entryNode.addStmtPredecessor(startNode)
+				} 
+					
+				exitNode = new(StatementNode)
+				exitNode.id = l.nextStatementID; l.nextStatementID++
+				exitNode.parseDef = funcEOS
+				exitNode.parseDefID =  funcEOS.id
+				exitNode.parseSubDef = nil 
+				exitNode.parseSubDefID =  0
+				exitNode.stmtType = "FuncExit"
+				exitNode.funcName = funcStr
+				exitNode.sourceRow =  funcEOS.sourceLineStart 
+				exitNode.sourceCol =  funcEOS.sourceColStart
+				exitNode.parent = entryNode
+				exitNode.parentID = entryNode.id
+				exitNode.child = nil
+				exitNode.childID = -1
+
+				exitNode.vScope = entryNode.vScope
+				exitNode.vScope.statements = append(exitNode.vScope.statements,exitNode)
+				// add this to the global list of statements 
+				l.statementGraph = append(l.statementGraph,exitNode)
+
+				if (funcStr == "main") {
+					finishNode = new(StatementNode)
+					finishNode.id = l.nextStatementID; l.nextStatementID++
+					finishNode.parseDef = nil
+					finishNode.parseDefID =  0
+					finishNode.parseSubDef = nil 
+					finishNode.parseSubDefID =  0
+					finishNode.stmtType = "finishNode"
+					finishNode.funcName = "__finish__"
+					finishNode.sourceRow =  exitNode.sourceRow + 1
+					finishNode.sourceCol =  0
+					finishNode.parent = nil
+					finishNode.parentID = -1
+					l.statementGraph = append(l.statementGraph,finishNode)
+
+					exitNode.addStmtSuccessor(finishNode)
+					finishNode.addStmtPredecessor(exitNode)
+				} 
+
+				if (len(statements) > 0) {
+					exitNode.addStmtPredecessor(statements[len(statements)-1])
+				}
+				
+				// fix the predecessor/successor edges 
+				entryNode.addStmtSuccessor(exitNode)
+				// note the predecessor of an exit nodes are the return statements
+				// exitNode.addStmtPredecessor(entryNode)
+				
+				// set the edges from the entry point to this list
+				
+				if ( len(statements) > 0) {
+					entryNode.child = statements[0]
+					entryNode.childID = statements[0].id
+					// make sure to add the funcDecl as a predecessor
+					entryNode.child.addStmtPredecessor(entryNode)
+					lastNode := statements[len(statements)-1]
+					lastNode.addStmtSuccessor(exitNode)
+				} else {
+					l.reportWarning(funcDecl.sourceLineStart,funcDecl.sourceColStart,"function %s has zero statements",funcStr)
+				}
+
+
+			} else {
+				//fmt.Printf("Graph: AST Node %d was visited \n",astnode.id)
+				Pass()
+			}
+			
+		}
+	}
+
+	// because linkdanges is recursive, we have these outer loops 
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false 
+	}
+
+	// Remove dangling pointers by adding return edges 
+	for _, stmtNode := range(l.statementGraph) {
+		if (stmtNode.stmtType == "functionDecl") {
+			// l.linkDangles(stmtNode,stmtNode.successors[0])
+		}
+	}
+
+	// adding return edges is not recursize, so we have a flat call 
+	for _, stmtNode := range(l.statementGraph) {
+		stmtNode.visited = false 
+	}
+
+	// fix the target end-of-statements predecessors 
+	l.fixEosPredecessors() 
+
+	// fix up various edges
+	l.addInternalReturnEdges()
+	// Add call and return edges
+	l.addCallandReturnEdges()
+
+	// start the data flow section with the assignments 
+	l.addVarAssignments()
+		
+	return 1
+} // end getStatementGraph 
+
+
+func (l *argoListener) generateNewScope(stmt *StatementNode) {
+	var pNode *ParseNode
+
+	pNode = stmt.parseSubDef
+	// fmt.Printf("  Generate New Scope called stmt %d parse %d \n",stmt.id,pNode.id)
+	
+	// match the variable to this parse node 
+	for _, vNode := range l.varNodeList {
+		// fmt.Printf(" parsedef is %d \n",vNode.parseDef.id)
+		if (vNode.parseDef.id == pNode.id) {
+			// fmt.Printf("matched short var decl stmt %d\n",stmt.id)
+			stmt.vScope.varNameMap[vNode.sourceName] = vNode
+			stmt.vScope.id++;
+		}
+	}
+
+}
+
+// do a recursive traversal of the statement graph and find short var declarations
+// change the variable scope nodes in the graph with the short var decls
+func (l *argoListener) fixVarScopesRoot(stmt *StatementNode,pScope *VarScope)  {
+	var shortDecl *StatementNode
+	var newScope *VarScope
+	var saveId int
+	
+	shortDecl = nil
+
+	if (stmt == nil) {
+		return 
+	}
+	if (stmt.visited == true) {
+		return
+	}
+	stmt.visited = true
+
+	// do a shallow copy of the parent scope into the current scope
+	if (stmt.vScope == nil) {
+		return
+	}
+
+
+	saveId = stmt.vScope.id 
+	*stmt.vScope = *pScope  // make the copy
+	stmt.vScope.id = saveId        // save the id
+	
+	// fmt.Printf("fixVarScopes copying from %d to %d \n",pScope.id,stmt.id)
+
+
+	// find and replace any short var declarations in the scope rules for the statements
+	if (stmt.ifSimple != nil) {
+		if (stmt.ifSimple.stmtType == "shortVarDecl") {
+			shortDecl = stmt.ifSimple
+			l.generateNewScope(shortDecl)
+			newScope = stmt.ifSimple.vScope;
+			if (stmt.ifTest != nil){
+				*stmt.ifTest.vScope = *newScope
+			}
+
+			// go down the taken branch 
+			if (stmt.ifTaken != nil){
+				*stmt.ifTaken.vScope = *newScope
+				l.fixVarScopesRoot(stmt.ifTaken,newScope)
+			}
+			
+			
+			if (stmt.ifElse != nil){
+				*stmt.ifElse.vScope = *newScope
+				l.fixVarScopesRoot(stmt.ifElse,newScope)
+			}
+
+			
+			
+		}
+	} else if (stmt.forInit != nil) {
+		if (stmt.forInit.stmtType == "shortVarDecl") {
+			shortDecl = stmt.forInit
+			l.generateNewScope(shortDecl)
+			newScope = stmt.ifSimple.vScope;
+
+			if (stmt.forCond != nil){
+				*stmt.forCond.vScope = *newScope
+			}			
+
+			if (stmt.forPost != nil){
+				*stmt.forPost.vScope = *newScope
+			}
+
+			if (stmt.forBlock != nil){
+				*stmt.forBlock.vScope = *newScope
+				l.fixVarScopesRoot(stmt.forBlock,newScope)
+			}			
+
+			
+		}
+	}
+
+	// this is the recursive call 
+	if (stmt.child != nil) {
+		l.fixVarScopesRoot(stmt.child,stmt.vScope) 
+	}
+
+	for _,succ := range (stmt.successors) {
+		if (succ != nil) { 
+			
+			l.fixVarScopesRoot(succ,stmt.vScope)
+		}
+	}
+
+}
+
+// top-level function for fixing scope.
+// go linearly through the statementgraph, then recursively fix the scope
+// for each function 
+func (l *argoListener) fixVariableScopes() int {
+
+	// if this is a short var declaration, change the scope
+	// traverse the rest of the graph 
+
+	// set all nodes visited to false 
+	for _, node := range l.statementGraph {
+		node.visited = false; 
+	}
+
+	// recurse down the statementgraph for each function 
+	for _, node := range l.statementGraph {
+		if node.stmtType == "functionDecl" {
+			l.fixVarScopesRoot(node,node.vScope)
+		}
+	}
+	return 1 
+}
+	
+/* ***************  Control Flow Graph and DataFlow Section   ********************** */
+
+func (l *argoListener) newCFGnode(stmt *StatementNode, subID int) (int,*CfgNode) {
+	var id int 
+	var node *CfgNode
+
+	id = l.nextCfgID
+	l.nextCfgID++
+	node = new(CfgNode)
+	node.cannName = "c_bit_" + strconv.Itoa(stmt.sourceRow) + "_" + strconv.Itoa(stmt.sourceCol) + "_" +
+		strconv.Itoa(subID) 
+	node.id = id
+	node.statement = stmt
+	node.stmtID = stmt.id
+	stmt.cfgNodes = append(stmt.cfgNodes,node)
+	return id,node
+}
+
+// maintain order, remove from list
+// update in place 
+func removeCfgFromList(cNodeList []*CfgNode, nodeToRemove *CfgNode) []*CfgNode {
+	for i, current := range cNodeList {
+		if (current == nodeToRemove ) {
+			copy(cNodeList[i:], cNodeList[i+1:]) // Shift a[i+1:] left one index.
+			cNodeList[len(cNodeList)-1] = nil     // Erase last element (write zero value).
+			cNodeList = cNodeList[:len(cNodeList)-1]
+			return  cNodeList 
+		}
+	}
+	return cNodeList
+}
+
+
+// Get the statement which holds the control flow nodes for a statement.
+// The simple and test statements of an if and the init, condition and post
+// statements of a for have no control nodes of their own; their nodes are in
+// the if or for statement. Follow the roots until we reach that statement
+func getRootStmt(stmt *StatementNode) *StatementNode {
+	for (stmt != nil) {
+		if (stmt.ifRoot != nil) && (stmt.ifRoot != stmt) {
+			stmt = stmt.ifRoot
+		} else if (stmt.forRoot != nil) && (stmt.forRoot != stmt) {
+			stmt = stmt.forRoot
+		} else {
+			break
+		}
+	}
+	return stmt
+}
+
+// Add a statements predecessor and successor cfg node to a cfg node 
+// This is the normal linear case, where we have a linear sequence
+// add the control flow node from the next statement to the CFG
+// That is, appened the next cfg node to the first arg from
+// the statement
+// FIXME: add return error code
+func addLinearToCfg(cnode *CfgNode, stmt *StatementNode) {
+	var succStmt, succ *StatementNode
+	succStmt = nil
+
+	// if this is a for or if sub-statement, then skip to the for statement
+	stmt = getRootStmt(stmt)
+
+	// if we have successors 
+	if len(stmt.successors) >0 {
+		// add the head cfgnode for each successor statement 
+		for _, succStmt = range stmt.successors {
+
+			succ = getRootStmt(succStmt)
+			if (succ == nil) {
+				continue
+			}
+
+			// if the cfg node is an eos and the statement is a for statement, add the
+			// edge to the for conditional cfgNode
+			if (len(succ.cfgNodes) > 0) {
+
+				// hack for an eos node to a for statement
+				// 
+				if ((succ.stmtType == "forStmt") && ( cnode.cfgType == "eos"))  {
+					var cond,init,post *CfgNode 
+					
+					for _, cfgNode := range succ.cfgNodes {
+						if (cfgNode.cfgType == "forInit") {
+							init = cfgNode 
+						}
+
+						if (cfgNode.cfgType == "forCond") {
+							cond = cfgNode 
+						}
+						
+						if (cfgNode.cfgType == "forPost") {
+							post = cfgNode 
+						}						
+					}
+
+					// if the eos occurs before the succesor, use the
+					// init as the successor, else use the condition
+					// assumes there is always a condition, even if implied 
+					if (cnode.id < succ.id) {
+						if (init != nil) {
+							cnode.successors = append(cnode.successors,init)
+						} else {
+							cnode.successors = append(cnode.successors,cond)
+						}
+					} else { // eos is after the other node 
+						if (post != nil) { // no post statemetn 
+							cnode.successors = append(cnode.successors,post)
+						} else {
+							cnode.successors = append(cnode.successors,cond)
+						}
+					}
+
+				} else { 
+					cnode.successors = append(cnode.successors,succ.cfgNodes[0])
+				}
+			} else {
+				// fmt.Printf("Error at %s stmt node %d no cfg successor \n",_file_line_(),succ.id)
+			}
+		}
+	}
+	if (stmt.stmtType != "startNode") { 
+		// cnode.predecessors = append(cnode.predecessors, getPredStmtCfg(stmt))
+	}
+
+}
+
+// for statements where the child in the next logical statement not the successor statement
+func addChildToCfg(cnode *CfgNode, stmt *StatementNode) {
+	var succStmt, predStmt, prev *StatementNode
+
+	succStmt = nil
+	predStmt = nil
+
+	// if this is a for or if sub-statement, then skip to the for statement
+	stmt = getRootStmt(stmt)
+	
+	// if we have successors 
+	if stmt.child != nil {
+		succStmt = stmt.child
+		if (len(succStmt.cfgNodes) > 0) {
+			cnode.successors = append(cnode.successors,succStmt.cfgNodes[0])
+		}
+	}
+	// if we have predecessors 
+	if len(stmt.predecessors ) > 0 {
+
+		// add the tail cfgNode for each predecessor statement 	
+		for _, predStmt = range stmt.predecessors {
+			
+			prev = getRootStmt(predStmt)
+
+			if (prev != nil) && (len(prev.cfgNodes) > 0) {
+				cnode.predecessors = append(cnode.predecessors,prev.cfgNodes[len(prev.cfgNodes)-1])
+			}
+		}
+	}	
+}
+
+
+// Given a statement Node, return the predecessor control flow graph node
+// Must have a function for this as various expressions in for an if statements must get bumped up to
+// the parent statement. Returns nil if control can not fall into the statement,
+// which is the case when the only predecessors are a break or continue 
+func (l *argoListener) getPredStmtCfg(stmt *StatementNode) *CfgNode {
+
+	var predStmt, prev *StatementNode
+	var jumpsOnly bool
+
+	jumpsOnly = false 
+	// add the tail cfgNode for each predecessor statement 	
+	for _, predStmt = range stmt.predecessors {
+			
+		prev = getRootStmt(predStmt)
+		if (prev == nil) {
+			continue
+		}
+
+		// break and continue jump away, so control never falls through them;
+		// keep looking as a nested if or for can have other predecessors 
+		if (prev.stmtType == "breakStmt") || (prev.stmtType == "continueStmt") {
+			jumpsOnly = true 
+			continue
+		}
+		
+		if (len(prev.cfgNodes) > 0) {
+			if (prev.forCond != nil) { // is the previous node has a for conditional, make the cond the target
+				// not the last node in the list 
+				for _, cfgTarget := range prev.cfgNodes {
+					if cfgTarget.cfgType == "forCond" { 
+						return cfgTarget
+					}
+				}
+			} else { 
+				return prev.cfgNodes[len(prev.cfgNodes)-1]
+			}
+		} else {
+			// fmt.Printf("Error at %s stmt node %d no cfg predecessor \n",_file_line_(),prev.id)
+		}
+	}
+	if (!jumpsOnly) { 
+		l.reportError(stmt.sourceRow,stmt.sourceCol,"no control node before statement %d",stmt.id)
+	}
+	return nil
+}
+
+// get the label of a break or continue statement, e.g. L in break L, or "" if it has none 
+func (stmt *StatementNode) branchLabel() string {
+	if (stmt.parseSubDef == nil) || (len(stmt.parseSubDef.children) != 2) {
+		return ""
+	}
+	return stmt.parseSubDef.children[1].ruleType
+}
+
+// get the target of a break statement by walking up the parents until we find the
+// innermost enclosing for, switch or select statement. A break inside a switch or
+// select case exits the switch/select, not an enclosing loop. A break with a label
+// exits the enclosing for, switch or select with that label. Go does not break out
+// of a labeled block, so the label of a block is not a target. Returns nil if
+// there is no target, which the control flow pass reports 
+func getBreakHead(stmt *StatementNode) *StatementNode {
+	var parent *StatementNode
+	var label string
+
+	// start at the parent, as the break itself is not breakable 
+	label = stmt.branchLabel()
+	parent = stmt.parent
+	for (parent != nil) {
+		if (parent.stmtType == "forStmt") || (parent.stmtType == "switchStmt") || (parent.stmtType == "selectStmt") {
+			if (label == "") || (parent.label == label) {
+				return parent 
+			}
+		}
+		parent = parent.parent 
+	}
+
+	return nil 
+}
+
+// get the loop head by walking up the parent until we find a for statement. A
+// continue with a label continues the enclosing for with that label. Returns nil
+// if there is no such for 
+func getLoopHead(stmt *StatementNode) *StatementNode {
+	var foundLoop bool
+	var parent *StatementNode
+	var label string
+
+	parent = stmt
+	label = ""
+	if (stmt.stmtType == "continueStmt") {
+		label = stmt.branchLabel()
+	}
+	
+	if (parent.stmtType == "forStmt") && (label == "") {
+		foundLoop = true 
+	}
+	
+	// walk up the parents looking for the loop head 
+	for (foundLoop == false) && (parent != nil) {
+		if (parent.stmtType == "forStmt") && ((label == "") || (parent.label == label)) {
+			foundLoop = true 
+		} else {
+			parent = parent.parent 
+		}
+	}
+	
+	// nil if the statement is not in a for with the label 
+	return parent 
+}
+
+	
+// build the control flow graph and data flow from the statement graph
+/* 
+  assignment
+  breakStmt
+  continueStmt
+  eos
+  expression
+  expressionStmt
+  forStmt
+  FuncExit
+  functionDecl
+  goStmt
+  ifStmt
+  incDecStmt
+  returnStmt
+  selectStmt
+  sendStmt
+  shortVarDecl
+  unaryExpr
+*/
+
+
+// create the list of control flow nodes
+// The approach is to create control flow graph nodes 1-to-1 with statement nodes
+// and then add/remove edges. Not all of the control flow nodes will end up
+// reachable, but we can rely on the 1-1 statement to cfg node propety
+// for the forward pass. 
+func (l *argoListener) forwardCfgPass() {
+	var currentStmt *StatementNode 
+	var currentCfgNode *CfgNode 
+	var i int 
+
+	// create all the control-flow graph nodes
+	// add edges later after all nodes are created 
+	for i, currentStmt = range(l.statementGraph) {
+
+		if (currentStmt.visited == true) {
+			continue ;
+		}
+		
+		currentStmt.visited = true 
+
+		if (currentStmt.ifRoot != nil ) || (currentStmt.forRoot != nil ) {
+			// need assertion to check if the root is pointing to a simple or test statement only 
+			continue;
+		}
+		
+		switch currentStmt.stmtType {
+		case "assignment":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "assignment"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "breakStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "break"			
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "continueStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "continue"						
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
+		case "eos":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "eos"						
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "emptyStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "emptyStmt"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "expression":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "expression"						
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "expressionStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "expression"						
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+
+			// create control flow graph nodes for a for statement
+			// if the statement is missing conditional and post nodes
+			// we go ahead and add ghost node version of them 
+		case "forStmt":
+
+			if (currentStmt.forInit != nil) { 
+				_, forCfgInit := l.newCFGnode(currentStmt,0)
+				forCfgInit.cfgType = "forInit"
+				forCfgInit.subStmt = currentStmt.forInit
+				forCfgInit.subStmtID = currentStmt.forInit.id
+				l.controlFlowGraph = append(l.controlFlowGraph,forCfgInit)	
+				currentStmt.forInit.visited = true 
+			}
+			if (currentStmt.forCond != nil ) {
+				_, forCfgCond := l.newCFGnode(currentStmt, 1)
+				forCfgCond.cfgType = "forCond"
+				forCfgCond.subStmt = currentStmt.forCond
+				forCfgCond.subStmtID = currentStmt.forCond.id 
+				l.controlFlowGraph = append(l.controlFlowGraph,forCfgCond)
+				currentStmt.forCond.visited = true 
+			} else {
+				_, forCfgCond := l.newCFGnode(currentStmt, 4)
+				forCfgCond.cfgType = "forCond"
+				forCfgCond.subStmt = nil
+				forCfgCond.subStmtID = -1
+				l.controlFlowGraph = append(l.controlFlowGraph,forCfgCond)
+			}
 
+			if (currentStmt.forPost != nil ) {
+				_, forCfgPost := l.newCFGnode(currentStmt, 2)
+				forCfgPost.cfgType = "forPost"
+				forCfgPost.subStmt = currentStmt.forPost
+				forCfgPost.subStmtID = currentStmt.forPost.id
+				l.controlFlowGraph = append(l.controlFlowGraph,forCfgPost)	
+				currentStmt.forPost.visited = true 
 			} else {
-				//fmt.Printf("Graph: AST Node %d was visited \n",astnode.id)
-				Pass()
+				_, forCfgPost := l.newCFGnode(currentStmt, 5)
+				forCfgPost.cfgType = "forPost"
+				forCfgPost.subStmt = nil 
+				forCfgPost.subStmtID = -1
+				l.controlFlowGraph = append(l.controlFlowGraph,forCfgPost)
 			}
 			
-		}
-	}
+		case "FuncExit":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "funcExit"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "functionDecl":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "funcEntry"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "goStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "goStmt"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
+		case "deferStmt":
+			// the defer node is a place holder in the statement sequence.
+			// The deferred call node is linked in front of the function exit 
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "defer"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+			_, deferCallCfg := l.newCFGnode(currentStmt, 1)
+			deferCallCfg.cfgType = "deferCall"
+			l.controlFlowGraph = append(l.controlFlowGraph,deferCallCfg)
+		case "ifStmt":
 
-	// because linkdanges is recursive, we have these outer loops 
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false 
-	}
+			if (currentStmt.ifSimple != nil) { 
+				_, ifSimpleCfg := l.newCFGnode(currentStmt,0)
+				ifSimpleCfg.cfgType = "ifSimple"
+				ifSimpleCfg.subStmt = currentStmt.ifSimple
+				ifSimpleCfg.subStmtID = currentStmt.ifSimple.id 
+				l.controlFlowGraph = append(l.controlFlowGraph,ifSimpleCfg)
+				currentStmt.ifSimple.visited = true 
+			}
+			
+			if (currentStmt.ifTest != nil ) {
+				_, ifTestCfg := l.newCFGnode(currentStmt, 1)
+				ifTestCfg.cfgType = "ifTest"
+				ifTestCfg.subStmt = currentStmt.ifTest
+				ifTestCfg.subStmtID = currentStmt.ifTest.id 
+				l.controlFlowGraph = append(l.controlFlowGraph,ifTestCfg)
+				currentStmt.ifTest.visited = true 
 
-	// Remove dangling pointers by adding return edges 
-	for _, stmtNode := range(l.statementGraph) {
-		if (stmtNode.stmtType == "functionDecl") {
-			// l.linkDangles(stmtNode,stmtNode.successors[0])
+			}
+			
+		case "incDecStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "incDec"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "returnStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "return"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "sendStmt":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "send"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
+		case "selectStmt":
+			// one node checks the ready flags of all the channels in the select 
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "select"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "switchStmt":
+			// one node compares the tag with the expressions of all the cases 
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "switch"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "shortVarDecl":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "shortVarDecl"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)						
+		case "unaryExpr":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "unaryExpr"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "startNode":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
+			currentCfgNode.cfgType = "startNode"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "finishNode":
+			_, currentCfgNode = l.newCFGnode(currentStmt, 1)
+			currentCfgNode.cfgType = "finishNode"
+			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
+		case "gotoStmt", "fallthroughStmt":
+			// statements of Go which are parsed but have no control flow yet 
+			l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"%s statements are not supported",
+				strings.TrimSuffix(currentStmt.stmtType,"Stmt"))
+		default:
+			l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"statement %d has an unknown statement type: %s",i,currentStmt.stmtType)
 		}
-	}
-
-	// adding return edges is not recursize, so we have a flat call 
-	for _, stmtNode := range(l.statementGraph) {
-		stmtNode.visited = false 
-	}
-
-	// fix the target end-of-statements predecessors 
-	l.fixEosPredecessors() 
-
-	// fix up various edges
-	l.addInternalReturnEdges()
-	// Add call and return edges
-	l.addCallandReturnEdges()
 
-	// start the data flow section with the assignments 
-	l.addVarAssignments()
 		
-	return 1
-} // end getStatementGraph 
-
-
-func (l *argoListener) generateNewScope(stmt *StatementNode) {
-	var pNode *ParseNode
-
-	pNode = stmt.parseSubDef
-	// fmt.Printf("  Generate New Scope called stmt %d parse %d \n",stmt.id,pNode.id)
-	
-	// match the variable to this parse node 
-	for _, vNode := range l.varNodeList {
-		// fmt.Printf(" parsedef is %d \n",vNode.parseDef.id)
-		if (vNode.parseDef.id == pNode.id) {
-			// fmt.Printf("matched short var decl stmt %d\n",stmt.id)
-			stmt.vScope.varNameMap[vNode.sourceName] = vNode
-			stmt.vScope.id++;
-		}
 	}
 
-}
-
-// do a recursive traversal of the statement graph and find short var declarations
-// change the variable scope nodes in the graph with the short var decls
-func (l *argoListener) fixVarScopesRoot(stmt *StatementNode,pScope *VarScope)  {
-	var shortDecl *StatementNode
-	var newScope *VarScope
-	var saveId int
-	
-	shortDecl = nil
-
-	if (stmt == nil) {
-		return 
-	}
-	if (stmt.visited == true) {
-		return
-	}
-	stmt.visited = true
 
-	// do a shallow copy of the parent scope into the current scope
-	if (stmt.vScope == nil) {
-		return
+	for _, currentStmt = range(l.statementGraph) {
+		currentStmt.visited = false 
 	}
+	// proceeded linearly down the sequence of statements
+	// and create edges in the control flow graph 
+	for _, currentStmt = range(l.statementGraph) {
+		if (currentStmt.visited == true) {
+			continue 
+		}
+		
+		currentStmt.visited = true
+		if len(currentStmt.cfgNodes) == 0 {
+			continue 
+		} else { 
+			currentCfgNode = currentStmt.cfgNodes[0]
+		}
 
+		switch currentStmt.stmtType {
 
-	saveId = stmt.vScope.id 
-	*stmt.vScope = *pScope  // make the copy
-	stmt.vScope.id = saveId        // save the id
-	
-	// fmt.Printf("fixVarScopes copying from %d to %d \n",pScope.id,stmt.id)
-
-
-	// find and replace any short var declarations in the scope rules for the statements
-	if (stmt.ifSimple != nil) {
-		if (stmt.ifSimple.stmtType == "shortVarDecl") {
-			shortDecl = stmt.ifSimple
-			l.generateNewScope(shortDecl)
-			newScope = stmt.ifSimple.vScope;
-			if (stmt.ifTest != nil){
-				*stmt.ifTest.vScope = *newScope
+		case "startNode":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "assignment":
+			addLinearToCfg(currentCfgNode,currentStmt)
+			// get the write variable and add it to the list of variables 
+			for _, varNode := range( currentStmt.writeVars) {
+				varNode.cfgNodes = append(varNode.cfgNodes,currentCfgNode) 
 			}
-
-			// go down the taken branch 
-			if (stmt.ifTaken != nil){
-				*stmt.ifTaken.vScope = *newScope
-				l.fixVarScopesRoot(stmt.ifTaken,newScope)
+		case "breakStmt": // walk up to the innermost loop, switch or select 
+			var breakHead *StatementNode
+
+			breakHead = getBreakHead(currentStmt)
+			if (breakHead != nil) && (len(breakHead.successors) > 0) && (len(breakHead.successors[0].cfgNodes) > 0) {
+				// the exit is the eos following the for, switch or select statement,
+				// which is entered from the break 
+				targetSuccessor := breakHead.successors[0].cfgNodes[0]
+				currentCfgNode.addUniqueSuccessor(targetSuccessor)
+				if (!cfgInList(targetSuccessor.predecessors,currentCfgNode)) {
+					targetSuccessor.predecessors = append(targetSuccessor.predecessors,currentCfgNode)
+				}
+			} else if (currentStmt.branchLabel() != "") {
+				l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"break %s is not to an enclosing for, switch or select statement",
+					currentStmt.branchLabel())
+			} else {
+				l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"break is not in a for, switch or select statement")
 			}
 			
-			
-			if (stmt.ifElse != nil){
-				*stmt.ifElse.vScope = *newScope
-				l.fixVarScopesRoot(stmt.ifElse,newScope)
+			if predCfg := l.getPredStmtCfg(currentStmt) ; predCfg != nil {
+				currentCfgNode.predecessors = append(currentCfgNode.predecessors,predCfg)
 			}
-
 			
+		case "continueStmt":
+			var loopHead *StatementNode
+			var condCfg  *CfgNode
 			
-		}
-	} else if (stmt.forInit != nil) {
-		if (stmt.forInit.stmtType == "shortVarDecl") {
-			shortDecl = stmt.forInit
-			l.generateNewScope(shortDecl)
-			newScope = stmt.ifSimple.vScope;
-
-			if (stmt.forCond != nil){
-				*stmt.forCond.vScope = *newScope
-			}			
-
-			if (stmt.forPost != nil){
-				*stmt.forPost.vScope = *newScope
+			// a continue goes back to the post statement of the loop if there is
+			// one, else to the condition, which is a ghost node if the loop has none 
+			loopHead = getLoopHead(currentStmt)
+			condCfg = nil
+			if (loopHead != nil) {
+				for _, cfgNode := range loopHead.cfgNodes {
+					if (cfgNode.cfgType == "forCond") && (condCfg == nil) {
+						condCfg = cfgNode
+					}
+					if (cfgNode.cfgType == "forPost") {
+						condCfg = cfgNode
+					}
+				}
+			}
+			if (condCfg != nil) {
+				currentCfgNode.addUniqueSuccessor(condCfg)
+				if (!cfgInList(condCfg.predecessors,currentCfgNode)) {
+					condCfg.predecessors = append(condCfg.predecessors,currentCfgNode)
+				}
+			} else if (currentStmt.branchLabel() != "") {
+				l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"continue %s is not to an enclosing for statement",
+					currentStmt.branchLabel())
+			} else {
+				l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"continue is not in a for statement")
 			}
-
-			if (stmt.forBlock != nil){
-				*stmt.forBlock.vScope = *newScope
-				l.fixVarScopesRoot(stmt.forBlock,newScope)
-			}			
 
 			
-		}
-	}
-
-	// this is the recursive call 
-	if (stmt.child != nil) {
-		l.fixVarScopesRoot(stmt.child,stmt.vScope) 
-	}
+			if predCfg := l.getPredStmtCfg(currentStmt) ; predCfg != nil {
+				currentCfgNode.predecessors = append(currentCfgNode.predecessors,predCfg)
+			}
+			
+		case "eos":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "emptyStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "expression":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "expressionStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "finishNode":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "forStmt":
+			var initCfg,condCfg,postCfg,blockCfg,prevCfg,eosCfg *CfgNode
+			var endStmt *StatementNode
 
-	for _,succ := range (stmt.successors) {
-		if (succ != nil) { 
+			// last (tail) control node for the prev statement 
+			//prevCfg = prevStmt.cfgNodes[len(prevStmt.cfgNodes)-1]
+			prevCfg = l.getPredStmtCfg(currentStmt)
 			
-			l.fixVarScopesRoot(succ,stmt.vScope)
-		}
-	}
-
-}
-
-// top-level function for fixing scope.
-// go linearly through the statementgraph, then recursively fix the scope
-// for each function 
-func (l *argoListener) fixVariableScopes() int {
+			// the head of the block for the loop 
+			if (currentStmt.forBlock != nil) { 
+				blockCfg = currentStmt.forBlock.cfgNodes[0]
+			}
 
-	// if this is a short var declaration, change the scope
-	// traverse the rest of the graph 
+			// the EOS for the loop; this is the loop exit 
+			endStmt = currentStmt.successors[0]
+			eosCfg = endStmt.cfgNodes[0]
 
-	// set all nodes visited to false 
-	for _, node := range l.statementGraph {
-		node.visited = false; 
-	}
+			for _,controlNode := range (currentStmt.cfgNodes) {
+				
+				switch controlNode.cfgType {
+				case "forInit":
+					initCfg = controlNode
+				case "forCond":
+					condCfg = controlNode 
+				case "forPost":
+					postCfg = controlNode 
+				default:
+					l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"control node %d of the for statement has an unknown type %s",controlNode.id,controlNode.cfgType)
+				}
+			}
 
-	// recurse down the statementgraph for each function 
-	for _, node := range l.statementGraph {
-		if node.stmtType == "functionDecl" {
-			l.fixVarScopesRoot(node,node.vScope)
-		}
-	}
-	return 1 
-}
-	
-/* ***************  Control Flow Graph and DataFlow Section   ********************** */
+			// if there is an initialization node, connect the conditional node
+			// if there is one. 
+			if (initCfg != nil){
+				// connect to the tail of the previous node 
+				if (prevCfg != nil) { 
+					initCfg.predecessors = append(initCfg.predecessors,prevCfg)
+				}
 
-func (l *argoListener) newCFGnode(stmt *StatementNode, subID int) (int,*CfgNode) {
-	var id int 
-	var node *CfgNode
+				// if there is a config node 
+				if (condCfg != nil) {
+					initCfg.successors = append(initCfg.successors,condCfg)
+					condCfg.predecessors = append(condCfg.predecessors,initCfg)
+				} else {
+					initCfg.successors = append(initCfg.successors,blockCfg)
+				}
 
-	id = l.nextCfgID
-	l.nextCfgID++
-	node = new(CfgNode)
-	node.cannName = "c_bit_" + strconv.Itoa(stmt.sourceRow) + "_" + strconv.Itoa(stmt.sourceCol) + "_" +
-		strconv.Itoa(subID) 
-	node.id = id
-	node.statement = stmt
-	node.stmtID = stmt.id
-	stmt.cfgNodes = append(stmt.cfgNodes,node)
-	return id,node
-}
+				// we need to add the list of variales to the init statement 
+				stmtNode := initCfg.subStmt
+				// add the init condition variable node 
+				for _, varNode := range(stmtNode.writeVars) {
+					varNode.cfgNodes = append(varNode.cfgNodes,initCfg) 
+				}
+				
+			}
 
-// maintain order, remove from list
-// update in place 
-func removeCfgFromList(cNodeList []*CfgNode, nodeToRemove *CfgNode) []*CfgNode {
-	for i, current := range cNodeList {
-		if (current == nodeToRemove ) {
-			copy(cNodeList[i:], cNodeList[i+1:]) // Shift a[i+1:] left one index.
-			cNodeList[len(cNodeList)-1] = nil     // Erase last element (write zero value).
-			cNodeList = cNodeList[:len(cNodeList)-1]
-			return  cNodeList 
-		}
-	}
-	return cNodeList
-}
+			// main clause if there is a config node 
+			if (condCfg != nil) {
+				condCfg.successors_taken = append(condCfg.successors_taken,blockCfg)
+				condCfg.successors = append(condCfg.successors,eosCfg)
+			}
+			// with no init, control falls from the previous node into the condition 
+			if (initCfg == nil) && (condCfg != nil) && (prevCfg != nil) {
+				condCfg.predecessors = append(condCfg.predecessors,prevCfg)
+			}
+			
 
+			// main clause if there is a post config statement 
+			// if there is a post (end of loop) statement
 
-// Add a statements predecessor and successor cfg node to a cfg node 
-// This is the normal linear case, where we have a linear sequence
-// add the control flow node from the next statement to the CFG
-// That is, appened the next cfg node to the first arg from
-// the statement
-// FIXME: add return error code
-func addLinearToCfg(cnode *CfgNode, stmt *StatementNode) {
-	var succStmt, succ *StatementNode
-	succStmt = nil
+			if (postCfg != nil) {
+				tailStmt := currentStmt.forTail
+				tailCfg := tailStmt.cfgNodes[0]
 
-	// if this is a for or if sub-statement, then skip to the for statement
-	if (stmt.ifRoot != nil) {
-		stmt = stmt.ifRoot 
-	}
+				
+				if (condCfg != nil) {
+					postCfg.successors = append(postCfg.successors,condCfg)
+					condCfg.predecessors = append(condCfg.predecessors,postCfg)
+				} else if (blockCfg != nil) {
+					postCfg.successors = append(postCfg.successors,blockCfg)
+				}
+				postCfg.predecessors = append(postCfg.predecessors,tailCfg)
 
-	// if we have successors 
-	if len(stmt.successors) >0 {
-		// add the head cfgnode for each successor statement 
-		for _, succStmt = range stmt.successors {
 
-			if (succStmt.forRoot != nil) {
-				succ = succStmt.forRoot 
+				/// the post statement might have write variables
+				if postCfg.subStmt != nil { 
+					stmtNode := postCfg.subStmt
+					// add the post condition variable node 
+					for _, varNode := range(stmtNode.writeVars) {
+						varNode.cfgNodes = append(varNode.cfgNodes,postCfg) 
+					}
+				}
+				
 			} else {
-				succ = succStmt
+				if (condCfg != nil) {
+					eosCfg.successors= append(eosCfg.successors,condCfg)
+				} else {
+					eosCfg.successors= append(eosCfg.successors,blockCfg)
+				}
 			}
+				
+		case "FuncExit":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "functionDecl":
+			addChildToCfg(currentCfgNode,currentStmt)
+		case "goStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "deferStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "ifStmt":
+			var simpleCfg,testCfg, takenCfg, elseCfg *CfgNode
 
-			// if the cfg node is an eos and the statement is a for statement, add the
-			// edge to the for conditional cfgNode
-			if (len(succ.cfgNodes) > 0) {
-
-				// hack for an eos node to a for statement
-				// 
-				if ((succ.stmtType == "forStmt") && ( cnode.cfgType == "eos"))  {
-					var cond,init,post *CfgNode 
-					
-					for _, cfgNode := range succ.cfgNodes {
-						if (cfgNode.cfgType == "forInit") {
-							init = cfgNode 
-						}
-
-						if (cfgNode.cfgType == "forCond") {
-							cond = cfgNode 
-						}
-						
-						if (cfgNode.cfgType == "forPost") {
-							post = cfgNode 
-						}						
-					}
+			// add the simple statement to the front of the test 
+			if (currentStmt.ifSimple != nil) {
+				simpleCfg =  currentStmt.cfgNodes[0]
+				testCfg =   currentStmt.cfgNodes[1]
+				simpleCfg.successors = 	append(simpleCfg.successors, testCfg)
+				testCfg.predecessors = 	append(testCfg.predecessors,simpleCfg) 
+			} else {
+				testCfg =   currentStmt.cfgNodes[0]				
+			}
 
-					// if the eos occurs before the succesor, use the
-					// init as the successor, else use the condition
-					// assumes there is always a condition, even if implied 
-					if (cnode.id < succ.id) {
-						if (init != nil) {
-							cnode.successors = append(cnode.successors,init)
-						} else {
-							cnode.successors = append(cnode.successors,cond)
-						}
-					} else { // eos is after the other node 
-						if (post != nil) { // no post statemetn 
-							cnode.successors = append(cnode.successors,post)
-						} else {
-							cnode.successors = append(cnode.successors,cond)
-						}
-					}
+			if (len(currentStmt.ifTaken.cfgNodes) > 0) {
+				takenCfg = currentStmt.ifTaken.cfgNodes[0]
+			} else {
+				l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"the taken block of if statement %d has no control node",currentStmt.id)
+			}
 
-				} else { 
-					cnode.successors = append(cnode.successors,succ.cfgNodes[0])
+			
+			//create the links to the test node 
+			testCfg.successors_taken = append(testCfg.successors_taken,takenCfg)
+			if (currentStmt.ifElse != nil) {
+				if (len(currentStmt.ifElse.cfgNodes) > 0) {
+					elseCfg = currentStmt.ifElse.cfgNodes[0]
+				} else {
+					l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"the else block of if statement %d has no control node",currentStmt.id)
 				}
+				// for an else-if, the else is the head of the sub-if statement 
+				testCfg.successors = append(testCfg.successors,elseCfg)
 			} else {
-				// fmt.Printf("Error at %s stmt node %d no cfg successor \n",_file_line_(),succ.id)
+				// the last test in an else-if chain with no else goes to the shared eos 
+				exitStmt := currentStmt.ifExit
+				if (exitStmt == nil) {
+					exitStmt = currentStmt.successors[0]
+				}
+				testCfg.successors = append(testCfg.successors,exitStmt.cfgNodes[0])
+			}
+			
+		case "incDecStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "returnStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+			// each return value is written in the return node 
+			for _, varNode := range( currentStmt.writeVars) {
+				varNode.cfgNodes = append(varNode.cfgNodes,currentCfgNode) 
+			}
+		case "sendStmt":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		case "selectStmt":
+			l.addSelectEdges(currentCfgNode,currentStmt)
+		case "switchStmt":
+			l.addSwitchEdges(currentCfgNode,currentStmt)
+		case "shortVarDecl":
+			addLinearToCfg(currentCfgNode,currentStmt)
+			for _, varNode := range( currentStmt.writeVars) {
+				varNode.cfgNodes = append(varNode.cfgNodes,currentCfgNode) 
 			}
+		case "unaryExpr":
+			addLinearToCfg(currentCfgNode,currentStmt)
+		default:
+			l.reportError(currentStmt.sourceRow,currentStmt.sourceCol,"statement %d has an unknown statement type: %s",currentStmt.id,currentStmt.stmtType)
 		}
-	}
-	if (stmt.stmtType != "startNode") { 
-		// cnode.predecessors = append(cnode.predecessors, getPredStmtCfg(stmt))
-	}
-
-}
 
-// for statements where the child in the next logical statement not the successor statement
-func addChildToCfg(cnode *CfgNode, stmt *StatementNode) {
-	var succStmt, predStmt, prev *StatementNode
+	}
 
-	succStmt = nil
-	predStmt = nil
+	// change the iftest targets predecessors to the predecessors taken 
+	for _, currentCfgNode = range(l.controlFlowGraph) {
+		if (currentCfgNode.cfgType == "ifTest") || (currentCfgNode.cfgType == "forCond") {
+			for _, cNode := range currentCfgNode.successors_taken {
+				cNode.predecessors_taken = append(cNode.predecessors_taken,currentCfgNode)
+				cNode.predecessors = removeCfgFromList(cNode.predecessors, currentCfgNode)
+			}
+			
+		}
 
-	// if this is a for or if sub-statement, then skip to the for statement
-	if (stmt.ifRoot != nil) {
-		stmt = stmt.ifRoot 
 	}
+
 	
-	// if we have successors 
-	if stmt.child != nil {
-		succStmt = stmt.child
-		if (len(succStmt.cfgNodes) > 0) {
-			cnode.successors = append(cnode.successors,succStmt.cfgNodes[0])
+}
+
+// add the edges from a select node to the head of each of its clauses. A comm
+// clause is taken when its channel is ready. The default clause is taken when no
+// channel is ready; a select with no default waits by looping on itself.
+// An empty clause goes straight to the eos of the select 
+func (l *argoListener) addSelectEdges(selectCfg *CfgNode, stmt *StatementNode) {
+	var eosCfg, target *CfgNode
+	var commCase *ParseNode
+	var k int
+
+	if (len(stmt.successors) == 0) || (len(stmt.successors[0].cfgNodes) == 0) || (stmt.parseSubDef == nil) {
+		l.reportError(stmt.sourceRow,stmt.sourceCol,"select statement %d has no exit",stmt.id)
+		return
+	}
+	eosCfg = stmt.successors[0].cfgNodes[0]
+
+	k = 0
+	for _, clauseNode := range stmt.parseSubDef.children {
+		if (clauseNode.ruleType != "commClause") || (len(clauseNode.children) == 0) {
+			continue
 		}
+		target = eosCfg
+		if (k < len(stmt.caseList)) && (len(stmt.caseList[k]) > 0) && (len(stmt.caseList[k][0].cfgNodes) > 0) {
+			target = stmt.caseList[k][0].cfgNodes[0]
+		}
+		k++
+
+		commCase = clauseNode.children[0]
+		if (len(commCase.children) > 0) && (commCase.children[0].ruleType == "default") {
+			selectCfg.defaultTarget = target
+		} else if (len(commCase.children) > 1) {
+			selectCfg.caseComms = append(selectCfg.caseComms,commCase.children[1])
+			selectCfg.caseTargets = append(selectCfg.caseTargets,target)
+		}
+		selectCfg.addUniqueSuccessor(target)
 	}
-	// if we have predecessors 
-	if len(stmt.predecessors ) > 0 {
 
-		// add the tail cfgNode for each predecessor statement 	
-		for _, predStmt = range stmt.predecessors {
-			
-			if (predStmt.ifRoot != nil) {
-				prev = predStmt.ifRoot 
-			} else {
-				prev = predStmt 
-			}
+	if (selectCfg.defaultTarget == nil) {
+		selectCfg.defaultTarget = selectCfg
+		selectCfg.addUniqueSuccessor(selectCfg)
+	}
+}
 
-			if (len(prev.cfgNodes) > 0) {
-				cnode.predecessors = append(cnode.predecessors,prev.cfgNodes[len(prev.cfgNodes)-1])
-			}
+// add a successor to a control node unless it is already a successor 
+func (node *CfgNode) addUniqueSuccessor(succ *CfgNode) {
+	for _, s := range node.successors {
+		if (s == succ) {
+			return
 		}
-	}	
+	}
+	node.successors = append(node.successors,succ)
 }
 
+// add the edges from a switch node to the head of each of its case clauses. The
+// node compares the tag with the expressions of each case in order, or tests the
+// expressions when there is no tag. The default clause is taken when no case
+// matches, or the eos when there is no default. An empty clause goes straight
+// to the eos of the switch 
+func (l *argoListener) addSwitchEdges(switchCfg *CfgNode, stmt *StatementNode) {
+	var eosCfg, target *CfgNode
+	var exprSwitch, switchCase *ParseNode
+	var k int
+
+	if (len(stmt.successors) == 0) || (len(stmt.successors[0].cfgNodes) == 0) || (stmt.parseSubDef == nil) ||
+		(len(stmt.parseSubDef.children) == 0) {
+		l.reportError(stmt.sourceRow,stmt.sourceCol,"switch statement %d has no exit",stmt.id)
+		return
+	}
+	eosCfg = stmt.successors[0].cfgNodes[0]
 
-// Given a statement Node, return the predecessor control flow graph node
-// Must have a function for this as various expressions in for an if statements must get bumped up to
-// the parent statement
-func getPredStmtCfg(stmt *StatementNode) *CfgNode {
-
-	var predStmt, prev *StatementNode
-	// add the tail cfgNode for each predecessor statement 	
-	for _, predStmt = range stmt.predecessors {
-			
-		if (predStmt.ifRoot != nil) {
-			prev = predStmt.ifRoot 
-		} else {
-			if (predStmt.forRoot != nil) {
-				prev = predStmt.forRoot 				
-			} else {
-				prev = predStmt 
-			}
+	exprSwitch = stmt.parseSubDef.children[0]
+	if (exprSwitch.ruleType != "exprSwitchStmt") {
+		l.reportError(stmt.sourceRow,stmt.sourceCol,"type switch statements are not supported")
+		return
+	}
+	for _, child := range exprSwitch.children {
+		switch child.ruleType {
+		case "simpleStmt":
+			l.reportError(child.sourceLineStart,child.sourceColStart,"the init statement of a switch is not supported")
+			return
+		case "expression":
+			switchCfg.caseTag = child
 		}
+	}
 
-		// break and continue need to break the predecessor edges 
-		if (prev.stmtType == "breakStmt") || (prev.stmtType == "continueStmt") {
-			return nil 
+	k = 0
+	for _, clauseNode := range exprSwitch.children {
+		if (clauseNode.ruleType != "exprCaseClause") || (len(clauseNode.children) == 0) {
+			continue
 		}
-		
-		if (len(prev.cfgNodes) > 0) {
-			if (prev.forCond != nil) { // is the previous node has a for conditional, make the cond the target
-				// not the last node in the list 
-				for _, cfgTarget := range prev.cfgNodes {
-					if cfgTarget.cfgType == "forCond" { 
-						return cfgTarget
-					}
-				}
-			} else { 
-				return prev.cfgNodes[len(prev.cfgNodes)-1]
+		target = eosCfg
+		if (k < len(stmt.caseList)) && (len(stmt.caseList[k]) > 0) && (len(stmt.caseList[k][0].cfgNodes) > 0) {
+			target = stmt.caseList[k][0].cfgNodes[0]
+		}
+		k++
+
+		switchCase = clauseNode.children[0]
+		if (len(switchCase.children) > 0) && (switchCase.children[0].ruleType == "default") {
+			switchCfg.defaultTarget = target
+		} else if (len(switchCase.children) > 1) {
+			// the case is tested in the same cycle, so it can not wait for a call or receive 
+			if (len(l.getProgramCalls(switchCase.children[1])) > 0) || (strings.Contains(switchCase.children[1].getSourceCode(),"<-")) {
+				l.reportError(switchCase.sourceLineStart,switchCase.sourceColStart,"a call or receive in a switch case is not supported")
 			}
-		} else {
-			// fmt.Printf("Error at %s stmt node %d no cfg predecessor \n",_file_line_(),prev.id)
+			switchCfg.caseComms = append(switchCfg.caseComms,switchCase.children[1])
+			switchCfg.caseTargets = append(switchCfg.caseTargets,target)
 		}
+		switchCfg.addUniqueSuccessor(target)
 	}
-	fmt.Printf("Error at %s stmt node %d no cfg node \n",_file_line_(),stmt.id)
-	return nil
-}
-
-// get the loop head by walking up the parent until we find a for statement 
-func getLoopHead(stmt *StatementNode) *StatementNode {
-	var foundLoop bool
-	var parent,initStmt *StatementNode
-
-	initStmt = stmt 
-	parent = stmt
-	
-	if (parent.stmtType == "forStmt") {
-		foundLoop = true 
-	}
-	
-	// walk up the parents looking for the loop head 
-	for (foundLoop == false) && (parent != nil) {
-		if (parent.stmtType == "forStmt") {
-			foundLoop = true 
-		} else {
-			parent = parent.parent 
-		}
+	if (switchCfg.caseTag != nil) && ( (len(l.getProgramCalls(switchCfg.caseTag)) > 0) || (strings.Contains(switchCfg.caseTag.getSourceCode(),"<-")) ) {
+		l.reportError(switchCfg.caseTag.sourceLineStart,switchCfg.caseTag.sourceColStart,"a call or receive in a switch tag is not supported")
 	}
-	
-	// sanity check 
-	if (parent == nil) {
-		fmt.Printf("Error at %s stmt node %d no loop parent for stmt node %d \n",_file_line_(),initStmt.id)
+
+	if (switchCfg.defaultTarget == nil) {
+		switchCfg.defaultTarget = eosCfg
+		switchCfg.addUniqueSuccessor(eosCfg)
 	}
-	
-	return parent 
 }
 
+// fix the backward edges and make sure the graph is consistent
+// every forward edge must have a backward edge
+// assumes all the forward edges are correct 
+func (l *argoListener) fixBackwardCfgEdges() {
+	var foundit bool
 	
-// build the control flow graph and data flow from the statement graph
-/* 
-  assignment
-  breakStmt
-  continueStmt
-  eos
-  expression
-  expressionStmt
-  forStmt
-  FuncExit
-  functionDecl
-  goStmt
-  ifStmt
-  incDecStmt
-  returnStmt
-  sendStmt
-  shortVarDecl
-  unaryExpr
-*/
+	// for each node in the cfg graph 
+	for _, cNode := range(l.controlFlowGraph) {
+		// for each successor of the cfgnode
+		for _,succ := range(cNode.successors) {
+			foundit = false 
+			for _, pred := range(succ.predecessors) {
+			// look for the parent node in the predecessor list
+				if (pred.id == cNode.id ) {
+					foundit = true 
+					break; 
+				}
 
+			}
+			// didn't find the parent in the list of successors 
+			if foundit == false {
+				succ.predecessors = append(succ.predecessors,cNode)
+			}
+		} // for each successor
 
-// create the list of control flow nodes
-// The approach is to create control flow graph nodes 1-to-1 with statement nodes
-// and then add/remove edges. Not all of the control flow nodes will end up
-// reachable, but we can rely on the 1-1 statement to cfg node propety
-// for the forward pass. 
-func (l *argoListener) forwardCfgPass() {
-	var currentStmt *StatementNode 
-	var currentCfgNode *CfgNode 
-	var i int 
 
-	// create all the control-flow graph nodes
-	// add edges later after all nodes are created 
-	for i, currentStmt = range(l.statementGraph) {
+		// now do the sucessors-taken nodes
+		for _,succ := range(cNode.successors_taken) {
+			foundit = false 
+			for _, pred := range(succ.predecessors_taken) {
+				// look for the parent node in the predecessor list
+				if (pred.id == cNode.id ) {
+					foundit = true 
+					break; 
+				}
 
-		if (currentStmt.visited == true) {
-			continue ;
-		}
+			}
+			// didn't find the parent in the list of successors 
+			if foundit == false {
+				succ.predecessors_taken = append(succ.predecessors_taken,cNode)
+			}
+		} // for each successors_taken 
 		
-		currentStmt.visited = true 
+	} // for each node in the control-flow graph 
+}
 
-		if (currentStmt.ifRoot != nil ) || (currentStmt.forRoot != nil ) {
-			// need assertion to check if the root is pointing to a simple or test statement only 
-			continue;
+// add the list of variables that write in a CFG node to the CFG graph
+func (l *argoListener) addVarsToCfgNodes() {
+	for _, vNode := range(l.varNodeList) {
+		for _, cNode := range vNode.cfgNodes {
+			cNode.writeVars = append(cNode.writeVars,vNode)
 		}
-		
-		switch currentStmt.stmtType {
-		case "assignment":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "assignment"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "breakStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "break"			
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "continueStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "continue"						
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
-		case "eos":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "eos"						
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "expression":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "expression"						
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "expressionStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "expression"						
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-
-			// create control flow graph nodes for a for statement
-			// if the statement is missing conditional and post nodes
-			// we go ahead and add ghost node version of them 
-		case "forStmt":
+	}
+}
+// add the variables read by each control node. For assignments only the right hand
+// side is read. The channel of a send statement is a write, not a read 
+func (l *argoListener) addReadVarsToCfgNodes() {
+	var pNode *ParseNode
+	var seen map[*VariableNode]bool
+	var funcStr string
 
-			if (currentStmt.forInit != nil) { 
-				_, forCfgInit := l.newCFGnode(currentStmt,0)
-				forCfgInit.cfgType = "forInit"
-				forCfgInit.subStmt = currentStmt.forInit
-				forCfgInit.subStmtID = currentStmt.forInit.id
-				l.controlFlowGraph = append(l.controlFlowGraph,forCfgInit)	
-				currentStmt.forInit.visited = true 
-			}
-			if (currentStmt.forCond != nil ) {
-				_, forCfgCond := l.newCFGnode(currentStmt, 1)
-				forCfgCond.cfgType = "forCond"
-				forCfgCond.subStmt = currentStmt.forCond
-				forCfgCond.subStmtID = currentStmt.forCond.id 
-				l.controlFlowGraph = append(l.controlFlowGraph,forCfgCond)
-				currentStmt.forCond.visited = true 
-			} else {
-				_, forCfgCond := l.newCFGnode(currentStmt, 4)
-				forCfgCond.cfgType = "forCond"
-				forCfgCond.subStmt = nil
-				forCfgCond.subStmtID = -1
-				l.controlFlowGraph = append(l.controlFlowGraph,forCfgCond)
-			}
+	for _, cNode := range(l.controlFlowGraph) {
+		var exprs []*ParseNode
 
-			if (currentStmt.forPost != nil ) {
-				_, forCfgPost := l.newCFGnode(currentStmt, 2)
-				forCfgPost.cfgType = "forPost"
-				forCfgPost.subStmt = currentStmt.forPost
-				forCfgPost.subStmtID = currentStmt.forPost.id
-				l.controlFlowGraph = append(l.controlFlowGraph,forCfgPost)	
-				currentStmt.forPost.visited = true 
-			} else {
-				_, forCfgPost := l.newCFGnode(currentStmt, 5)
-				forCfgPost.cfgType = "forPost"
-				forCfgPost.subStmt = nil 
-				forCfgPost.subStmtID = -1
-				l.controlFlowGraph = append(l.controlFlowGraph,forCfgPost)
+		if pNode = cNode.getReadExpr() ; pNode != nil {
+			exprs = append(exprs,pNode)
+		}
+		// a select reads the values of the comm clauses which send 
+		for _, comm := range cNode.caseComms {
+			if (comm.ruleType == "sendStmt") && (len(comm.children) >= 3) {
+				exprs = append(exprs,comm.children[2])
 			}
-			
-		case "FuncExit":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "funcExit"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "functionDecl":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "funcEntry"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "goStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "goStmt"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
-		case "ifStmt":
-
-			if (currentStmt.ifSimple != nil) { 
-				_, ifSimpleCfg := l.newCFGnode(currentStmt,0)
-				ifSimpleCfg.cfgType = "ifSimple"
-				ifSimpleCfg.subStmt = currentStmt.ifSimple
-				ifSimpleCfg.subStmtID = currentStmt.ifSimple.id 
-				l.controlFlowGraph = append(l.controlFlowGraph,ifSimpleCfg)
-				currentStmt.ifSimple.visited = true 
+		}
+		// a switch reads its tag and the expressions of its cases 
+		if (cNode.cfgType == "switch") {
+			if (cNode.caseTag != nil) {
+				exprs = append(exprs,cNode.caseTag)
 			}
-			
-			if (currentStmt.ifTest != nil ) {
-				_, ifTestCfg := l.newCFGnode(currentStmt, 1)
-				ifTestCfg.cfgType = "ifTest"
-				ifTestCfg.subStmt = currentStmt.ifTest
-				ifTestCfg.subStmtID = currentStmt.ifTest.id 
-				l.controlFlowGraph = append(l.controlFlowGraph,ifTestCfg)
-				currentStmt.ifTest.visited = true 
+			exprs = append(exprs,cNode.caseComms...)
+		}
 
+		funcStr = cNode.statement.funcName
+		seen = make(map[*VariableNode]bool)
+		for _, expr := range exprs {
+			for _, opNode := range expr.walkDownToAllRules("operandName") {
+				if (len(opNode.children) == 0) {
+					continue
+				}
+				varNode := l.getVarNodeInScope(funcStr,opNode.children[0].ruleType,opNode)
+				if (varNode != nil) && (!seen[varNode]) {
+					seen[varNode] = true
+					cNode.readVars = append(cNode.readVars,varNode)
+				}
+			}
+		}
+		// the index of a written array element, e.g. i of a[i] = x, is read 
+		writer := cNode.statement
+		if (cNode.subStmt != nil) {
+			writer = cNode.subStmt
+		}
+		if (cNode.cfgType == "assignment") || (cNode.cfgType == "forInit") || (cNode.cfgType == "forPost") || (cNode.cfgType == "ifSimple") {
+			for _, written := range writer.writeVars {
+				for _, varNode := range l.getIndexReadVars(writer.writeIndexes[written],funcStr) {
+					if (!seen[varNode]) {
+						seen[varNode] = true
+						cNode.readVars = append(cNode.readVars,varNode)
+					}
+				}
 			}
-			
-		case "incDecStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "incDec"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "returnStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "return"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "sendStmt":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "send"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)			
-		case "shortVarDecl":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "shortVarDecl"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)						
-		case "unaryExpr":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "unaryExpr"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "startNode":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 0)
-			currentCfgNode.cfgType = "startNode"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		case "finishNode":
-			_, currentCfgNode = l.newCFGnode(currentStmt, 1)
-			currentCfgNode.cfgType = "finishNode"
-			l.controlFlowGraph = append(l.controlFlowGraph,currentCfgNode)
-		default:
-			fmt.Printf("Error at %s node %d unknown statement type: %s \n",_file_line_(),i,currentStmt.stmtType)
 		}
+	}
+}
 
-		
+// get the part of the statement of a control node which it reads: the right hand
+// side of an assignment or send, else the whole statement or sub-statement.
+// Returns nil for nodes which read nothing 
+func (node *CfgNode) getReadExpr() *ParseNode {
+	var pNode *ParseNode
+
+	if (node.subStmt != nil) {
+		pNode = node.subStmt.parseDef
+	} else if (node.statement.parseSubDef != nil) {
+		pNode = node.statement.parseSubDef
+	}
+	// the select only tests the ready flags of its channels, and the switch reads
+	// its tag and cases, not the statements of its clauses 
+	if (pNode == nil) || (node.cfgType == "funcEntry") || (node.cfgType == "eos") || (node.cfgType == "select") || (node.cfgType == "switch") {
+		return nil
 	}
 
+	switch pNode.ruleType {
+	case "assignment", "shortVarDecl", "sendStmt":
+		if (len(pNode.children) < 3) {
+			return nil
+		}
+		// a compound assignment reads both sides 
+		if (pNode.getAssignOp() != "") {
+			return pNode
+		}
+		pNode = pNode.children[2]
+	}
+	return pNode
+}
 
-	for _, currentStmt = range(l.statementGraph) {
-		currentStmt.visited = false 
+// return true if a control node uses a variable 
+func (node *CfgNode) usesVar(vNode *VariableNode) bool {
+	for _, v := range node.readVars {
+		if (v == vNode) {
+			return true
+		}
 	}
-	// proceeded linearly down the sequence of statements
-	// and create edges in the control flow graph 
-	for _, currentStmt = range(l.statementGraph) {
-		if (currentStmt.visited == true) {
-			continue 
+	for _, v := range node.writeVars {
+		if (v == vNode) {
+			return true
 		}
-		
-		currentStmt.visited = true
-		if len(currentStmt.cfgNodes) == 0 {
-			continue 
-		} else { 
-			currentCfgNode = currentStmt.cfgNodes[0]
+	}
+	return false 
+}
+
+// return true if a successor of the control node must wait a cycle for an array
+// or channel operation of this node. A read of an array follows any write to the
+// array, and all the operations on the same channel are serialized 
+func (node *CfgNode) hasMemoryHazard() bool {
+	var successors []*CfgNode
+
+	successors = append(append(successors,node.successors...),node.successors_taken...)
+	for _, succ := range successors {
+		if (succ == nil) {
+			continue
+		}
+		for _, vNode := range node.writeVars {
+			if ((vNode.goLangType == "array") || (vNode.goLangType == "channel")) && (succ.usesVar(vNode)) {
+				return true
+			}
+		}
+		for _, vNode := range node.readVars {
+			if (vNode.goLangType == "channel") && (succ.usesVar(vNode)) {
+				return true
+			}
 		}
+	}
+	return false
+}
 
-		switch currentStmt.stmtType {
+// get the multiplies a control node evaluates: each multiply expression, and a
+// compound assignment x *= y. Operands come before the multiplies which use them,
+// and the depth is the longest chain of multiplies feeding one another 
+func (node *CfgNode) getMultiplies() ([]*ParseNode, int) {
+	var mults []*ParseNode
+	var walk func(pNode *ParseNode) int
+	var depth int
 
-		case "startNode":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "assignment":
-			addLinearToCfg(currentCfgNode,currentStmt)
-			// get the write variable and add it to the list of variables 
-			for _, varNode := range( currentStmt.writeVars) {
-				varNode.cfgNodes = append(varNode.cfgNodes,currentCfgNode) 
+	walk = func(pNode *ParseNode) int {
+		var d int
+
+		if (pNode == nil) {
+			return 0
+		}
+		for _, child := range pNode.children {
+			if cd := walk(child) ; cd > d {
+				d = cd
 			}
-		case "breakStmt": // walk up to the first loop 
-			var loopHead *StatementNode
+		}
+		if ((pNode.ruleType == "expression") && (len(pNode.children) == 3) && (pNode.children[1].ruleType == "*")) ||
+			(pNode.getAssignOp() == "*") {
+			mults = append(mults,pNode)
+			d++
+		}
+		return d
+	}
+	depth = walk(node.getReadExpr())
+	return mults, depth
+}
 
-			loopHead = getLoopHead(currentStmt)
-			targetSuccessor := loopHead.successors[0].cfgNodes[0]
-			currentCfgNode.successors = append(currentCfgNode.successors,targetSuccessor)
-			
-			predCfg := getPredStmtCfg(currentStmt)
-			currentCfgNode.predecessors = append(currentCfgNode.predecessors,predCfg)
-			
-		case "continueStmt":
-			var loopHead *StatementNode
-			var condCfg  *CfgNode
-			
-			loopHead = getLoopHead(currentStmt)
-			// find the conditional node 
-			for _, condCfg = range loopHead.cfgNodes {
-				if condCfg.cfgType == "forCond" {
-					break
+// the longest chain of multiplies a control node evaluates 
+func (node *CfgNode) mulDepth() int {
+	_, depth := node.getMultiplies()
+	return depth
+}
+
+// the longest chain of assignments evaluated in one control node with -coalesce 
+const MAXCOALESCE = 4
+
+// return true if a control node is an assignment of one integer variable that
+// can be evaluated in the same cycle as the assignments before it. The right hand
+// side must not call a function, read an array or channel, or use a pipelined multiply 
+func (l *argoListener) isCoalescable(cNode *CfgNode) bool {
+	var stmt *StatementNode
+	var vNode *VariableNode
+	var rhs *ParseNode
+
+	stmt = cNode.statement
+	if ((cNode.cfgType != "assignment") && (cNode.cfgType != "shortVarDecl")) || (cNode.subStmt != nil) ||
+		(stmt.parseSubDef == nil) || (len(cNode.writeVars) != 1) || (cNode.hasMemoryHazard()) {
+		return false
+	}
+	vNode = cNode.writeVars[0]
+	if (vNode.goLangType != "numeric") || (vNode.structType != nil) || (vNode.numBits <= 0) {
+		return false
+	}
+	if (stmt.stmtType == "assignment") {
+		lhsList := stmt.parseSubDef.children[0].getExpressionList()
+		if (stmt.parseSubDef.getAssignOp() != "") || (len(lhsList) != 1) || (lhsList[0].getPlainOperandName() != vNode.sourceName) {
+			return false
+		}
+	} else if (len(stmt.getLhsNames()) != 1) {
+		return false
+	}
+	rhs = stmt.getRhsExpr(vNode.sourceName)
+	if (rhs == nil) || (rhs.walkDownToRule("arguments") != nil) || (rhs.walkDownToRule("index") != nil) ||
+		(strings.Contains(rhs.getSourceCode(),"<-")) {
+		return false
+	}
+	if (l.mulStyle == "seq") && (strings.Contains(rhs.getSourceCode(),"*")) {
+		return false
+	}
+	return true
+}
+
+// with -coalesce, evaluate a straight-line chain of up to MAXCOALESCE assignments
+// in the first node of the chain, e.g. i = 4 ; k = i + i. Each right hand side reads
+// the values assigned before it in the chain, which are substituted for the
+// variables, and every variable commits its last value in the one cycle. The later
+// nodes of the chain are removed, so the chain has one bubble instead of one for
+// each assignment. A node joined from elsewhere, or after a branch, starts a new
+// chain, so a chain never reads a value which is only updated on some paths.
+// Returns the number of nodes removed 
+func (l *argoListener) coalesceAssignments() int {
+	var chain, removed []*CfgNode
+	var inChain map[*CfgNode]bool
+	var cur, next *CfgNode
+
+	inChain = make(map[*CfgNode]bool)
+	for _, head := range l.controlFlowGraph {
+		if (inChain[head]) || (!l.isCoalescable(head)) {
+			continue
+		}
+		chain = []*CfgNode{head}
+		for (len(chain) < MAXCOALESCE) {
+			cur = chain[len(chain)-1]
+			if (len(cur.successors) != 1) || (len(cur.successors_taken) > 0) {
+				break
+			}
+			next = cur.successors[0]
+			if (next == nil) || (inChain[next]) || (next == head) || (next.numPredecessors() != 1) || (len(next.predecessors_taken) > 0) ||
+				(!cfgInList(next.predecessors,cur)) || (next.statement.funcName != head.statement.funcName) || (!l.isCoalescable(next)) {
+				break
+			}
+			chain = append(chain,next)
+		}
+		if (len(chain) < 2) {
+			continue
+		}
+		for _, cNode := range chain {
+			inChain[cNode] = true
+			head.coalesced = append(head.coalesced,cNode.statement)
+			head.coalescedVars = append(head.coalescedVars,cNode.writeVars[0])
+		}
+		for _, cNode := range chain[1:] {
+			for _, vNode := range cNode.writeVars {
+				vNode.cfgNodes = removeCfgFromList(vNode.cfgNodes,cNode)
+				if (!cfgInList(vNode.cfgNodes,head)) {
+					vNode.cfgNodes = append(vNode.cfgNodes,head)
+				}
+				if (!varInList(head.writeVars,vNode)) {
+					head.writeVars = append(head.writeVars,vNode)
+				}
+			}
+			for _, vNode := range cNode.readVars {
+				if (!varInList(head.readVars,vNode)) {
+					head.readVars = append(head.readVars,vNode)
+				}
+			}
+			cNode.bypassCfgNode()
+			removed = append(removed,cNode)
+		}
+	}
+	for _, cNode := range removed {
+		l.controlFlowGraph = removeCfgFromList(l.controlFlowGraph,cNode)
+	}
+	return len(removed)
+}
+
+// return true if the control node of an empty statement, or of the eos after a
+// simple statement, only passes its control bit on. Such an eos has no other
+// predecessor, e.g. a break, and is not the exit of an if, for, switch or select 
+func (cNode *CfgNode) isEmptyNode() bool {
+	var stmt, prev *StatementNode
+
+	if (len(cNode.successors) != 1) || (len(cNode.successors_taken) > 0) || (len(cNode.predecessors_taken) > 0) ||
+		(cNode.numPredecessors() != 1) {
+		return false
+	}
+	stmt = cNode.statement
+	switch cNode.cfgType {
+	case "emptyStmt":
+		return true
+	case "eos":
+		if (len(stmt.predecessors) != 1) {
+			return false
+		}
+		prev = stmt.predecessors[0]
+		switch prev.stmtType {
+		case "assignment", "shortVarDecl", "expressionStmt", "expression", "incDecStmt", "sendStmt", "emptyStmt":
+			for _, pred := range cNode.predecessors {
+				if (pred != nil) && (pred.statement != prev) {
+					return false
 				}
 			}
+			return true
+		}
+	}
+	return false
+}
 
-			currentCfgNode.successors = append(currentCfgNode.successors,condCfg)
+// with -skip-empty, take the control nodes of empty statements, e.g. the first ;
+// of ;;, and of the eos after simple statements out of the control flow. Each
+// removed node is a cycle and a control bit which do nothing, and the dataflow
+// hazards are resolved after, so the program runs the same.
+// Returns the number of nodes removed 
+func (l *argoListener) removeEmptyNodes() int {
+	var removed []*CfgNode
+
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.isEmptyNode()) && (cNode.bypassCfgNode()) {
+			removed = append(removed,cNode)
+		}
+	}
+	for _, cNode := range removed {
+		l.controlFlowGraph = removeCfgFromList(l.controlFlowGraph,cNode)
+	}
+	return len(removed)
+}
 
-			
-			predCfg := getPredStmtCfg(currentStmt)
-			currentCfgNode.predecessors = append(currentCfgNode.predecessors,predCfg)
-			
-		case "eos":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "expression":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "expressionStmt":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "finishNode":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "forStmt":
-			var initCfg,condCfg,postCfg,blockCfg,prevCfg,eosCfg *CfgNode
-			var endStmt *StatementNode
+// for now, insert an empty control flow node after every write node
+// need to fix this to property look for the read/write vars and only
+// add a bubble if there is a read after a write of the same variable.
+// Array and channel operations also get a bubble before a dependent successor, as
+// does a pipelined multiply, which stalls its node for the multiplier's latency 
 
-			// last (tail) control node for the prev statement 
-			//prevCfg = prevStmt.cfgNodes[len(prevStmt.cfgNodes)-1]
-			prevCfg = getPredStmtCfg(currentStmt)
-			
-			// the head of the block for the loop 
-			if (currentStmt.forBlock != nil) { 
-				blockCfg = currentStmt.forBlock.cfgNodes[0]
-			}
+func (l *argoListener) resolveDataflowHazards() {
+	var stmtNode  *StatementNode
+	var bubbleCfgNode *CfgNode
+	var cfgPosition int
+	
+	for _, cNode := range(l.controlFlowGraph) {
 
-			// the EOS for the loop; this is the loop exit 
-			endStmt = currentStmt.successors[0]
-			eosCfg = endStmt.cfgNodes[0]
+	
+		// if there are writevars, add a new control node which does nothing
+		// the links will change as below:
+		// predecessors p_taken         Pred   p_takn
+		// |               |             V      V
+		// V               V            orig Node
+		//   -----------                     | successor edge 
+		//  | Orig node|                     V  
+		//   ----------                |---bubble--_|
+		// V              V            V            V
+		// sucessors     s_taken      sucessors s_taken 
+		if (len(cNode.writeVars) > 0) || (cNode.hasMemoryHazard()) || ( (l.mulStyle == "seq") && (cNode.mulDepth() > 0) ) {  // fixme: change to check for read after write 
+			// create a new CFG node
+			stmtNode = cNode.statement
 
-			for _,controlNode := range (currentStmt.cfgNodes) {
-				
-				switch controlNode.cfgType {
-				case "forInit":
-					initCfg = controlNode
-				case "forCond":
-					condCfg = controlNode 
-				case "forPost":
-					postCfg = controlNode 
-				default:
-					fmt.Printf("Error: at %s forStmt %d has unknown type %s \n",_file_line_(),controlNode.id,controlNode.cfgType)					
+			// in order to give the node a unique name, we have to find the positions
+			// of the cfg node in the statement node and add 7 to it
+			for i, cfgPos := range stmtNode.cfgNodes {
+				if cfgPos.id == cNode.id {
+					cfgPosition = i;
+					break; 
 				}
 			}
+			_, bubbleCfgNode = l.newCFGnode(stmtNode, cfgPosition + 9)
+			
+			bubbleCfgNode.cfgType = "bubble"
+			l.controlFlowGraph = append(l.controlFlowGraph,bubbleCfgNode)
 
-			// if there is an initialization node, connect the conditional node
-			// if there is one. 
-			if (initCfg != nil){
-				// connect to the tail of the previous node 
-				initCfg.predecessors = append(initCfg.predecessors,prevCfg)
+			// copy the successors edges to the bubble node 
+			bubbleCfgNode.successors_taken = append(bubbleCfgNode.successors_taken,cNode.successors_taken...)
+			bubbleCfgNode.successors = append(bubbleCfgNode.successors,cNode.successors...)
 
-				// if there is a config node 
-				if (condCfg != nil) {
-					initCfg.successors = append(initCfg.successors,condCfg)
-					condCfg.predecessors = append(condCfg.predecessors,initCfg)
-				} else {
-					initCfg.successors = append(initCfg.successors,blockCfg)
+			// set the successors_taken and successors of the cNode predecessors to the bubble
+			// for each successor 
+			for _, succNode := range cNode.successors {
+				// for each predecessors in the successsor 
+				for j, predNode := range succNode.predecessors {
+					// swap the orignal cnode with the bubble 
+					if predNode.id == cNode.id {
+						succNode.predecessors[j] = bubbleCfgNode
+					}
 				}
+			}
+
+			// do the same thing for the successors_taken node 
+			for _, succNode := range cNode.successors_taken {
+				// for each predecessors in the successsor 
+				for j, predNode := range succNode.predecessors_taken {
+					// swap the orignal cnode with the bubble 
+					if predNode.id == cNode.id {
+						succNode.predecessors_taken[j] = bubbleCfgNode
+					}
+				}
+			}
+
+			
+			// set the orig node successor to the bubble
+			cNode.successors = nil
+			cNode.successors_taken = nil
+			cNode.successors = append(cNode.successors, bubbleCfgNode)
+
+			// set the bubble predecessor to the orig node 
+			bubbleCfgNode.predecessors =  append(bubbleCfgNode.predecessors, cNode)
+
+		}
+	}
+}
+
+// check a defer statement can be run at the function exit. The deferred call only
+// runs if its defer statement was reached, which is one flag per statement, so a
+// defer in a loop is not supported. Go evaluates the arguments at the defer
+// statement, but the call reads them at the exit, so the arguments may only be
+// constants or channels 
+func (l *argoListener) checkDeferStmt(deferStmt *StatementNode) bool {
+	var ok bool
+
+	ok = true
+	if (getLoopHead(deferStmt) != nil) {
+		l.reportError(deferStmt.sourceRow,deferStmt.sourceCol,"a defer statement in a for loop is not supported")
+		ok = false
+	}
+	if (deferStmt.parseSubDef == nil) {
+		return ok
+	}
+	for _, opNode := range deferStmt.parseSubDef.walkDownToAllRules("operandName") {
+		if (len(opNode.children) == 0) {
+			continue
+		}
+		varNode := l.getVarNodeInScope(deferStmt.funcName,opNode.children[0].ruleType,opNode)
+		if (varNode != nil) && (varNode.goLangType != "channel") {
+			l.reportError(opNode.sourceLineStart,opNode.sourceColStart,"the deferred call reads %s, which is evaluated at the defer statement; only constants and channels are supported",varNode.sourceName)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// route every edge into a function's exit node through the deferred calls of the
+// function. The deferred calls run in LIFO order, the last defer first, and the
+// first defer falls through to the exit node. A deferred call is passed over on
+// the way out if its defer statement was not reached, see OutputDeferFlags 
+func (l *argoListener) addDeferEdges() {
+	var exitCfg, headCfg, callCfg *CfgNode
+	var chain []*CfgNode
+	var inChain map[*CfgNode]bool
+
+	for _, funcNode := range l.funcNodeList {
+		if (len(funcNode.deferStmts) == 0) {
+			continue
+		}
 
-				// we need to add the list of variales to the init statement 
-				stmtNode := initCfg.subStmt
-				// add the init condition variable node 
-				for _, varNode := range(stmtNode.writeVars) {
-					varNode.cfgNodes = append(varNode.cfgNodes,initCfg) 
-				}
-				
+		// find the control node of the function exit 
+		exitCfg = nil
+		for _, stmtNode := range l.statementGraph {
+			if (stmtNode.stmtType == "FuncExit") && (stmtNode.funcName == funcNode.funcName) && (len(stmtNode.cfgNodes) > 0) {
+				exitCfg = stmtNode.cfgNodes[0]
+				break
 			}
+		}
+		if (exitCfg == nil) {
+			l.reportError(funcNode.sourceRow,funcNode.sourceCol,"no exit control node for function %s",funcNode.funcName)
+			continue
+		}
 
-			// main clause if there is a config node 
-			if (condCfg != nil) {
-				condCfg.successors_taken = append(condCfg.successors_taken,blockCfg)
-				condCfg.successors = append(condCfg.successors,eosCfg)
+		// chain the deferred calls, last defer first 
+		chain = nil
+		inChain = make(map[*CfgNode]bool)
+		for i := len(funcNode.deferStmts)-1; i >= 0; i-- {
+			deferStmt := funcNode.deferStmts[i]
+			if (len(deferStmt.cfgNodes) < 2) {
+				l.reportError(deferStmt.sourceRow,deferStmt.sourceCol,"no deferred call control node for the defer statement %d",deferStmt.id)
+				continue
+			}
+			if (!l.checkDeferStmt(deferStmt)) {
+				continue
+			}
+			callCfg = deferStmt.cfgNodes[len(deferStmt.cfgNodes)-1]
+			chain = append(chain,callCfg)
+			inChain[callCfg] = true 
+		}
+		if (len(chain) == 0) {
+			continue
+		}
+		for i, cNode := range chain {
+			if (i < len(chain)-1) {
+				cNode.successors = append(cNode.successors,chain[i+1])
 			} else {
-				if (initCfg == nil) {
-					condCfg.predecessors = append(condCfg.successors,prevCfg)
+				cNode.successors = append(cNode.successors,exitCfg)
+			}
+		}
+		headCfg = chain[0]
+
+		// the returns and the fall-through at the end of the function now
+		// go to the head of the chain instead of the exit 
+		for _, cNode := range l.controlFlowGraph {
+			if (inChain[cNode]) {
+				continue
+			}
+			for j, succ := range cNode.successors {
+				if (succ == exitCfg) {
+					cNode.successors[j] = headCfg
+					exitCfg.predecessors = removeCfgFromList(exitCfg.predecessors,cNode)
 				}
 			}
-			
+			for j, succ := range cNode.successors_taken {
+				if (succ == exitCfg) {
+					cNode.successors_taken[j] = headCfg
+					exitCfg.predecessors_taken = removeCfgFromList(exitCfg.predecessors_taken,cNode)
+				}
+			}
+		}
+	}
+}
 
-			// main clause if there is a post config statement 
-			// if there is a post (end of loop) statement
+// 
+func (l *argoListener) addCFGcallReturnEdges() {
 
-			if (postCfg != nil) {
-				tailStmt := currentStmt.forTail
-				tailCfg := tailStmt.cfgNodes[0]
+	//for _, stateNode := range(l.statementGraph) {
+	// 
+	/// }
 
-				
-				if (condCfg != nil) {
-					postCfg.successors = append(postCfg.successors,condCfg)
-					condCfg.predecessors = append(condCfg.predecessors,postCfg)
-				} else {
-					eosCfg.successors= append(eosCfg.successors,prevCfg)
-				}
-				postCfg.predecessors = append(postCfg.predecessors,tailCfg)
+}
 
+// Top level function to get the control flow graph
+func (l *argoListener) getControlFlowGraph() int {
 
-				/// the post statement might have write variables
-				if postCfg.subStmt != nil { 
-					stmtNode := postCfg.subStmt
-					// add the post condition variable node 
-					for _, varNode := range(stmtNode.writeVars) {
-						varNode.cfgNodes = append(varNode.cfgNodes,postCfg) 
-					}
-				}
-				
-			} else {
-				if (condCfg != nil) {
-					eosCfg.successors= append(eosCfg.successors,condCfg)
-				} else {
-					eosCfg.successors= append(eosCfg.successors,blockCfg)
-				}
-			}
-				
-		case "FuncExit":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "functionDecl":
-			addChildToCfg(currentCfgNode,currentStmt)
-		case "goStmt":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "ifStmt":
-			var simpleCfg,testCfg, takenCfg, elseCfg *CfgNode
+	// call the forward pass on the control-flow graph 
+	l.forwardCfgPass()
+	// run the deferred calls before each function exit 
+	l.addDeferEdges()
+	// fix backward edges
+	l.fixBackwardCfgEdges() 
+	// link the variable write/reads to the control flow graph nodes 
+	l.addVarsToCfgNodes()
+	l.addReadVarsToCfgNodes()
+	// add call and return edges 
+	l.addCFGcallReturnEdges()
+	// make the calls in conditions before the test 
+	l.hoistConditionCalls()
+	// take the noop library calls out of the control flow 
+	l.lowerIgnoredCalls()
+	// remove the nodes which do nothing, with -skip-empty 
+	if (l.skipEmpty) {
+		l.removeEmptyNodes()
+	}
+	// evaluate short chains of assignments in one node, with -coalesce 
+	if (l.coalesce) {
+		l.coalesceAssignments()
+	}
+	// add delays in the cfg when there are data flow hazards	
+	l.resolveDataflowHazards()
 
-			// add the simple statement to the front of the test 
-			if (currentStmt.ifSimple != nil) {
-				simpleCfg =  currentStmt.cfgNodes[0]
-				testCfg =   currentStmt.cfgNodes[1]
-				simpleCfg.successors = 	append(simpleCfg.successors, testCfg)
-				testCfg.predecessors = 	append(testCfg.predecessors,simpleCfg) 
-			} else {
-				testCfg =   currentStmt.cfgNodes[0]				
-			}
+	// replace function calls with 
 
-			if (len(currentStmt.ifTaken.cfgNodes) > 0) {
-				takenCfg = currentStmt.ifTaken.cfgNodes[0]
-			} else {
-				fmt.Printf("Error: at %s ifstmt taken %d has no cfg node \n",_file_line_(),currentStmt.id)
+	// replace function calls in expressions
+	
+	return 1 
+}
+
+// a call of a function in the condition of an if or for statement. The call is
+// made by a control node before the test, which starts the callee, waits for it
+// to be done and latches its result. The test reads the latched result 
+type HoistedCall struct {
+	cfgNode *CfgNode      // the control node making the call 
+	callee *FunctionNode  // the called function 
+	call *ParseNode       // the primaryExpr of the call in the condition 
+}
+
+// the condition of an if or for control node, or nil 
+func (cNode *CfgNode) conditionParseNode() *ParseNode {
+	switch {
+	case (cNode.cfgType == "ifTest") && (cNode.statement.ifTest != nil):
+		return cNode.statement.ifTest.parseDef
+	case (cNode.cfgType == "forCond") && (cNode.subStmt != nil) && (cNode.subStmt.stmtType != "rangeCond"):
+		return cNode.subStmt.parseDef
+	}
+	return nil
+}
+
+// the argument lists of the calls of functions of the program in an expression,
+// in source order. The arguments of a conversion or a builtin are searched too,
+// e.g. f in int(f(x)), but not those of a call of the program 
+func (l *argoListener) getProgramCalls(pNode *ParseNode) []*ParseNode {
+	var calls []*ParseNode
+
+	for _, argNode := range pNode.walkDownToAllRules("arguments") {
+		if _, ok := l.funcNameMap[l.getCalleeName(argNode)] ; ok {
+			calls = append(calls,argNode)
+			continue
+		}
+		for _, child := range argNode.children {
+			calls = append(calls,l.getProgramCalls(child)...)
+		}
+	}
+	return calls
+}
+
+// insert a control node on every edge into a node, so the new node runs first 
+func insertCfgBefore(newNode *CfgNode,cNode *CfgNode) {
+	for _, pred := range cNode.predecessors {
+		if (pred == nil) {
+			continue
+		}
+		for j, succ := range pred.successors {
+			if (succ == cNode) {
+				pred.successors[j] = newNode
+			}
+		}
+		// a select enters its clauses by their case bits 
+		for j, target := range pred.caseTargets {
+			if (target == cNode) {
+				pred.caseTargets[j] = newNode
+			}
+		}
+		if (pred.defaultTarget == cNode) {
+			pred.defaultTarget = newNode
+		}
+	}
+	for _, pred := range cNode.predecessors_taken {
+		for j, succ := range pred.successors_taken {
+			if (succ == cNode) {
+				pred.successors_taken[j] = newNode
 			}
+		}
+	}
+	newNode.predecessors = cNode.predecessors
+	newNode.predecessors_taken = cNode.predecessors_taken
+	newNode.successors = []*CfgNode{cNode}
+	cNode.predecessors = []*CfgNode{newNode}
+	cNode.predecessors_taken = nil
+}
 
-			
-			//create the links to the test node 
-			testCfg.successors_taken = append(testCfg.successors_taken,takenCfg)
-			if (currentStmt.ifElse != nil) {
-				if (len(currentStmt.ifElse.cfgNodes) > 0) {
-					elseCfg = currentStmt.ifElse.cfgNodes[0]
-				} else {
-					fmt.Printf("Error at %s ifstmt else %d else haa no cfg node \n",_file_line_(),currentStmt.id)
-				}
-				testCfg.successors = append(takenCfg.successors,elseCfg)
-			} else {
-				succStmt := currentStmt.successors[0]
-				testCfg.successors = append(takenCfg.successors,succStmt.cfgNodes[0])
+// hoist the calls in the conditions of if and for statements, e.g. the two calls
+// of blammo in if k <= (i + blammo(i,j) + blammo(j,i)), into control nodes before
+// the test, one for each call in source order. Every entry into the test, e.g.
+// each iteration of a loop, makes the calls again. Returns the number of calls hoisted 
+func (l *argoListener) hoistConditionCalls() int {
+	var tests []*CfgNode
+	var callee *FunctionNode
+	var hoisted *CfgNode
+	var ok bool
+
+	l.hoistedCalls = nil
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.conditionParseNode() != nil) {
+			tests = append(tests,cNode)
+		}
+	}
+	for _, test := range tests {
+		funcName := test.statement.funcName
+		for k, argNode := range l.getProgramCalls(test.conditionParseNode()) {
+			callee, ok = l.funcNameMap[l.getCalleeName(argNode)]
+			if (!ok) || (len(callee.retVars) != 1) {
+				continue  // the arity of the call is checked with the call edges 
 			}
-			
-		case "incDecStmt":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "returnStmt":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "sendStmt":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "shortVarDecl":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		case "unaryExpr":
-			addLinearToCfg(currentCfgNode,currentStmt)
-		default:
-			fmt.Printf("Error at %s unknown statement type: %s \n",_file_line_(),currentStmt.id)			
+			if (callee.funcName == funcName) {
+				l.reportError(argNode.sourceLineStart,argNode.sourceColStart,"the recursive call of %s in a condition is not supported",
+					callee.funcName)
+				continue
+			}
+			if (len(l.getProgramCalls(argNode)) > 0) {
+				l.reportError(argNode.sourceLineStart,argNode.sourceColStart,"a call in the arguments of the call of %s in a condition is not supported",
+					callee.funcName)
+				continue
+			}
+			_, hoisted = l.newCFGnode(test.statement,20 + k)
+			hoisted.cfgType = "condCall"
+			hoisted.subStmt = test.subStmt
+			hoisted.subStmtID = test.subStmtID
+			l.controlFlowGraph = append(l.controlFlowGraph,hoisted)
+			insertCfgBefore(hoisted,test)
+			l.hoistedCalls = append(l.hoistedCalls,&HoistedCall{hoisted,callee,argNode.parent})
+			// a function called from a condition has its own module, as it is not
+			// called from a single assignment 
+			if entry := l.getFunctionStmtEntry(callee.funcName) ; entry != nil {
+				entry.callers = append(entry.callers,test.statement)
+			}
+		}
+	}
+	return len(l.hoistedCalls)
+}
+
+// the hoisted call of a call expression in a condition, or nil 
+func (l *argoListener) getHoistedCall(pNode *ParseNode) *HoistedCall {
+	for _, hoisted := range l.hoistedCalls {
+		if (hoisted.call == pNode) {
+			return hoisted
 		}
+	}
+	return nil
+}
 
+// lower the expression statements which only call an ignored library function.
+// A noop, e.g. time.Sleep, is removed from the control flow: its predecessors go
+// to its successor. A yield, e.g. runtime.Gosched, keeps its node, which takes one
+// cycle and has no dataflow. Returns the number of nodes removed 
+func (l *argoListener) lowerIgnoredCalls() int {
+	var argList []*ParseNode
+	var removed []*CfgNode
+	
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.cfgType != "expression") || (cNode.statement.parseDef == nil) {
+			continue
+		}
+		argList = cNode.statement.parseDef.walkDownToAllRules("arguments")
+		if (len(argList) != 1) || (l.getIgnoredCall(argList[0]) == "") {
+			continue
+		}
+		cNode.readVars = nil
+		cNode.writeVars = nil
+		if (l.getIgnoredCall(argList[0]) != "noop") || (!cNode.bypassCfgNode()) {
+			continue
+		}
+		removed = append(removed,cNode)
+	}
+	for _, cNode := range removed {
+		l.controlFlowGraph = removeCfgFromList(l.controlFlowGraph,cNode)
 	}
+	return len(removed)
+}
 
-	// change the iftest targets predecessors to the predecessors taken 
-	for _, currentCfgNode = range(l.controlFlowGraph) {
-		if (currentCfgNode.cfgType == "ifTest") || (currentCfgNode.cfgType == "forCond") {
-			for _, cNode := range currentCfgNode.successors_taken {
-				cNode.predecessors_taken = append(cNode.predecessors_taken,currentCfgNode)
-				cNode.predecessors = removeCfgFromList(cNode.predecessors, currentCfgNode)
+// take a control node with one successor out of the control flow: its
+// predecessors go to its successor instead. The node is removed from its
+// statement, the caller removes it from the graph. Returns false if the node
+// has more than one successor, or loops to itself 
+func (cNode *CfgNode) bypassCfgNode() bool {
+	var succ *CfgNode
+
+	if (len(cNode.successors) != 1) || (len(cNode.successors_taken) > 0) {
+		return false
+	}
+	succ = cNode.successors[0]
+	if (succ == nil) || (succ == cNode) {
+		return false
+	}
+	succ.predecessors = removeCfgFromList(succ.predecessors,cNode)
+	for _, pred := range cNode.predecessors {
+		if (pred != nil) {
+			pred.replaceSuccessor(cNode,succ)
+			if (!cfgInList(succ.predecessors,pred)) {
+				succ.predecessors = append(succ.predecessors,pred)
 			}
-			
 		}
+	}
+	for _, pred := range cNode.predecessors_taken {
+		pred.replaceSuccessor(cNode,succ)
+		if (!cfgInList(succ.predecessors_taken,pred)) {
+			succ.predecessors_taken = append(succ.predecessors_taken,pred)
+		}
+	}
+	cNode.statement.cfgNodes = removeCfgFromList(cNode.statement.cfgNodes,cNode)
+	return true
+}
 
+/* ******************  Basic Block Section   ************************* */
+
+// the number of control edges into and out of a control flow node 
+func (node *CfgNode) numPredecessors() int {
+	var count int
+
+	count = len(node.predecessors_taken)
+	for _, pred := range node.predecessors {
+		if (pred != nil) {
+			count++
+		}
 	}
+	return count 
+}
 
-	
+func (node *CfgNode) numSuccessors() int {
+	return len(node.successors) + len(node.successors_taken)
 }
 
-// fix the backward edges and make sure the graph is consistent
-// every forward edge must have a backward edge
-// assumes all the forward edges are correct 
-func (l *argoListener) fixBackwardCfgEdges() {
-	var foundit bool
-	
-	// for each node in the cfg graph 
-	for _, cNode := range(l.controlFlowGraph) {
-		// for each successor of the cfgnode
-		for _,succ := range(cNode.successors) {
-			foundit = false 
-			for _, pred := range(succ.predecessors) {
-			// look for the parent node in the predecessor list
-				if (pred.id == cNode.id ) {
-					foundit = true 
-					break; 
-				}
+// the function whose module holds the control and data of a function. A function
+// inlined with -inline is in the module of the function it is inlined into
+func (l *argoListener) moduleFuncName(funcName string) string {
+	for funcNode, ok := l.funcNameMap[funcName] ; (ok) && (funcNode.inlineCall != nil) ; funcNode, ok = l.funcNameMap[funcName] {
+		funcName = funcNode.inlineCall.funcName
+	}
+	return funcName
+}
 
-			}
-			// didn't find the parent in the list of successors 
-			if foundit == false {
-				succ.predecessors = append(succ.predecessors,cNode)
-			}
-		} // for each successor
+// the statement a function is inlined into with -inline, or nil if it can not be
+// inlined. The function must be a leaf with only integer variables, called from
+// exactly one statement, which is not a go or defer and only assigns its results
+// or discards them. A leaf makes no calls, so it is not recursive
+func (l *argoListener) getInlineCall(funcNode *FunctionNode) *StatementNode {
+	var entryStmt, callStmt *StatementNode
+
+	if (funcNode.funcName == "main") || (funcNode.receiver != nil) || (len(funcNode.deferStmts) > 0) {
+		return nil
+	}
+	entryStmt = l.getFunctionStmtEntry(funcNode.funcName)
+	if (entryStmt == nil) || (len(entryStmt.callers) != 1) || (len(entryStmt.cfgNodes) == 0) ||
+		(len(entryStmt.successors) == 0) || (len(entryStmt.successors[0].cfgNodes) == 0) {
+		return nil
+	}
+	callStmt = entryStmt.callers[0]
+	if (callStmt.funcName == funcNode.funcName) || (len(callStmt.callTargets) != 1) || (len(callStmt.cfgNodes) != 1) {
+		return nil
+	}
+	switch callStmt.stmtType {
+	case "assignment", "shortVarDecl":
+		if (l.getRhsCallee(callStmt) != funcNode) {
+			return nil
+		}
+	case "expressionStmt":
+	default:
+		return nil
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcNode.funcName) && ((vNode.goLangType != "numeric") || (vNode.structType != nil)) {
+			return nil
+		}
+	}
+	for _, stmt := range l.statementGraph {
+		if (stmt.funcName == funcNode.funcName) && ((len(stmt.callTargets) > 0) || (len(stmt.goTargets) > 0)) {
+			return nil
+		}
+	}
+	return callStmt
+}
 
+// the argument expressions of the call of an inlined function, in parameter order
+func (l *argoListener) inlineArguments(funcNode *FunctionNode) []*ParseNode {
+	if (funcNode.inlineCall == nil) || (funcNode.inlineCall.parseDef == nil) {
+		return nil
+	}
+	for _, argNode := range funcNode.inlineCall.parseDef.walkDownToAllRules("arguments") {
+		if (l.getCalleeName(argNode) == funcNode.funcName) {
+			return argNode.walkDownToRule("expressionList").getExpressionList()
+		}
+	}
+	return nil
+}
 
-		// now do the sucessors-taken nodes
-		for _,succ := range(cNode.successors_taken) {
-			foundit = false 
-			for _, pred := range(succ.predecessors_taken) {
-				// look for the parent node in the predecessor list
-				if (pred.id == cNode.id ) {
-					foundit = true 
-					break; 
-				}
+// point the edges of a control node into one node at another node instead
+func (node *CfgNode) replaceSuccessor(oldSucc *CfgNode,newSucc *CfgNode) {
+	for i := range node.successors {
+		if (node.successors[i] == oldSucc) {
+			node.successors[i] = newSucc
+		}
+	}
+	for i := range node.successors_taken {
+		if (node.successors_taken[i] == oldSucc) {
+			node.successors_taken[i] = newSucc
+		}
+	}
+	for i := range node.caseTargets {
+		if (node.caseTargets[i] == oldSucc) {
+			node.caseTargets[i] = newSucc
+		}
+	}
+	if (node.defaultTarget == oldSucc) {
+		node.defaultTarget = newSucc
+	}
+}
+
+// inline each function called from one statement into its caller, with -inline.
+// This saves the module and the start and done handshake of the call. The control
+// flow into the calling statement enters the function entry instead, which
+// assigns the parameters from the arguments, and the function exit enters the
+// calling statement, which assigns the results. The call edge is removed, and the
+// variables of the function are renamed with the function name, as they are
+// registers in the module of the caller. Returns the number of functions inlined
+func (l *argoListener) inlineFunctions() int {
+	var entryStmt, exitStmt, callStmt *StatementNode
+	var entryCfg, exitCfg, callCfg *CfgNode
+	var numInlined int
+
+	numInlined = 0
+	for _, funcNode := range l.funcNodeList {
+		callStmt = l.getInlineCall(funcNode)
+		if (callStmt == nil) {
+			continue
+		}
+		entryStmt = l.getFunctionStmtEntry(funcNode.funcName)
+		exitStmt = entryStmt.successors[0]
+		entryCfg = entryStmt.cfgNodes[0]
+		exitCfg = exitStmt.cfgNodes[0]
+		callCfg = callStmt.cfgNodes[0]
+
+		callStmt.callTargets = nil
+		for i, target := range exitStmt.returnTargets {
+			if (target == callStmt) {
+				exitStmt.returnTargets = append(exitStmt.returnTargets[:i],exitStmt.returnTargets[i+1:]...)
+				break
+			}
+		}
 
+		for _, pred := range callCfg.predecessors {
+			if (pred != nil) {
+				pred.replaceSuccessor(callCfg,entryCfg)
 			}
-			// didn't find the parent in the list of successors 
-			if foundit == false {
-				succ.predecessors_taken = append(succ.predecessors_taken,cNode)
+		}
+		for _, pTaken := range callCfg.predecessors_taken {
+			pTaken.replaceSuccessor(callCfg,entryCfg)
+		}
+		entryCfg.predecessors = callCfg.predecessors
+		entryCfg.predecessors_taken = callCfg.predecessors_taken
+		exitCfg.successors = append(exitCfg.successors,callCfg)
+		callCfg.predecessors = []*CfgNode{exitCfg}
+		callCfg.predecessors_taken = nil
+
+		funcNode.inlineCall = callStmt
+		for _, param := range funcNode.parameters {
+			entryCfg.writeVars = append(entryCfg.writeVars,param)
+			param.cfgNodes = append(param.cfgNodes,entryCfg)
+		}
+		for _, argExpr := range l.inlineArguments(funcNode) {
+			for _, opNode := range argExpr.walkDownToAllRules("operandName") {
+				if vNode := l.getVarNodeInScope(callStmt.funcName,opNode.children[0].ruleType,opNode) ; vNode != nil {
+					entryCfg.readVars = append(entryCfg.readVars,vNode)
+				}
+			}
+		}
+		for _, vNode := range l.varNodeList {
+			if (vNode.funcName != funcNode.funcName) {
+				continue
+			}
+			if (vNode.verilogName == "") {
+				vNode.verilogName = verilogModuleName(funcNode) + "_" + vNode.sourceName
+			} else {
+				vNode.verilogName = verilogModuleName(funcNode) + "_" + vNode.verilogName
 			}
-		} // for each successors_taken 
-		
-	} // for each node in the control-flow graph 
-}
-
-// add the list of variables that write in a CFG node to the CFG graph
-func (l *argoListener) addVarsToCfgNodes() {
-	for _, vNode := range(l.varNodeList) {
-		for _, cNode := range vNode.cfgNodes {
-			cNode.writeVars = append(cNode.writeVars,vNode)
 		}
+		numInlined++
 	}
+	return numInlined
 }
-// for now, insert an empty control flow node after every write node
-// need to fix this to property look for the read/write vars and only
-// add a bubble if there is a read after a write of the same variable 
 
-func (l *argoListener) resolveDataflowHazards() {
-	var stmtNode  *StatementNode
-	var bubbleCfgNode *CfgNode
-	var cfgPosition int
+// a node is the leader (first node) of a basic block if it is an entry point, a
+// join point, or follows a branch or a join 
+func (node *CfgNode) isBlockLeader() bool {
+	var pred *CfgNode
 	
-	for _, cNode := range(l.controlFlowGraph) {
+	if (node.cfgType == "funcEntry") || (node.cfgType == "startNode") {
+		return true
+	}
+	if (node.numPredecessors() != 1) || (len(node.predecessors_taken) > 0) {
+		return true
+	}
+	for _, pred = range node.predecessors {
+		if (pred != nil) {
+			break
+		}
+	}
+	if (pred.numSuccessors() != 1) || (pred.statement.funcName != node.statement.funcName) {
+		return true
+	}
+	return false 
+}
 
-	
-		// if there are writevars, add a new control node which does nothing
-		// the links will change as below:
-		// predecessors p_taken         Pred   p_takn
-		// |               |             V      V
-		// V               V            orig Node
-		//   -----------                     | successor edge 
-		//  | Orig node|                     V  
-		//   ----------                |---bubble--_|
-		// V              V            V            V
-		// sucessors     s_taken      sucessors s_taken 
-		if len(cNode.writeVars) > 0 {  // fixme: change to check for read after write 
-			// create a new CFG node
-			stmtNode = cNode.statement
+func (l *argoListener) newBasicBlock(leader *CfgNode) *BasicBlock {
+	var block *BasicBlock
 
-			// in order to give the node a unique name, we have to find the positions
-			// of the cfg node in the statement node and add 7 to it
-			for i, cfgPos := range stmtNode.cfgNodes {
-				if cfgPos.id == cNode.id {
-					cfgPosition = i;
-					break; 
-				}
-			}
-			_, bubbleCfgNode = l.newCFGnode(stmtNode, cfgPosition + 9)
-			
-			bubbleCfgNode.cfgType = "bubble"
-			l.controlFlowGraph = append(l.controlFlowGraph,bubbleCfgNode)
+	block = new(BasicBlock)
+	block.id = l.nextBlockID; l.nextBlockID++
+	block.funcName = leader.statement.funcName
+	l.basicBlocks = append(l.basicBlocks,block)
+	return block
+}
 
-			// copy the successors edges to the bubble node 
-			bubbleCfgNode.successors_taken = append(bubbleCfgNode.successors_taken,cNode.successors_taken...)
-			bubbleCfgNode.successors = append(bubbleCfgNode.successors,cNode.successors...)
+// Use the control flow graph to create the Basic-Block CFG (BBCFG).
+// Maximal runs of control nodes with a single predecessor and a single successor
+// and no branch are coalesced into one basic block 
+func (l *argoListener) getBasicBlocks() int {
+	var block *BasicBlock
+	var current, next *CfgNode
+	
+	l.basicBlocks = nil
+	l.nextBlockID = 0
+	for _, cNode := range l.controlFlowGraph {
+		cNode.block = nil
+	}
+	
+	// sort by id number so the blocks are numbered in program order 
+	sort.Slice(l.controlFlowGraph, func(i, j int) bool {
+		return l.controlFlowGraph[i].id < l.controlFlowGraph[j].id
+	})
 
-			// set the successors_taken and successors of the cNode predecessors to the bubble
-			// for each successor 
-			for _, succNode := range cNode.successors {
-				// for each predecessors in the successsor 
-				for j, predNode := range succNode.predecessors {
-					// swap the orignal cnode with the bubble 
-					if predNode.id == cNode.id {
-						succNode.predecessors[j] = bubbleCfgNode
-					}
-				}
+	// start a block at every leader and extend it down the straight-line run 
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.block != nil) || (!cNode.isBlockLeader()) {
+			continue
+		}
+		block = l.newBasicBlock(cNode)
+		current = cNode
+		for {
+			current.block = block
+			block.cfgNodes = append(block.cfgNodes,current)
+			if (len(current.successors) != 1) || (len(current.successors_taken) > 0) {
+				break
 			}
-
-			// do the same thing for the successors_taken node 
-			for _, succNode := range cNode.successors_taken {
-				// for each predecessors in the successsor 
-				for j, predNode := range succNode.predecessors_taken {
-					// swap the orignal cnode with the bubble 
-					if predNode.id == cNode.id {
-						succNode.predecessors_taken[j] = bubbleCfgNode
-					}
-				}
+			next = current.successors[0]
+			if (next == nil) || (next.block != nil) || (next.isBlockLeader()) {
+				break
 			}
-
-			
-			// set the orig node successor to the bubble
-			cNode.successors = nil
-			cNode.successors_taken = nil
-			cNode.successors = append(cNode.successors, bubbleCfgNode)
-
-			// set the bubble predecessor to the orig node 
-			bubbleCfgNode.predecessors =  append(bubbleCfgNode.predecessors, cNode)
-
+			current = next 
 		}
 	}
-}
 
-// 
-func (l *argoListener) addCFGcallReturnEdges() {
+	// any node not reached from a leader, e.g. in a cycle with no entry, gets its own block 
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.block == nil) {
+			block = l.newBasicBlock(cNode)
+			cNode.block = block
+			block.cfgNodes = append(block.cfgNodes,cNode)
+		}
+	}
 
-	//for _, stateNode := range(l.statementGraph) {
-	// 
-	/// }
+	// the block edges are the edges out of the last node of each block 
+	for _, block = range l.basicBlocks {
+		tail := block.cfgNodes[len(block.cfgNodes)-1]
+		for _, succ := range append(append([]*CfgNode{},tail.successors...),tail.successors_taken...) {
+			if (succ == nil) || (succ.block == nil) {
+				continue
+			}
+			block.successors = append(block.successors,succ.block)
+			succ.block.predecessors = append(succ.block.predecessors,block)
+		}
+	}
 
+	return len(l.basicBlocks)
 }
 
-// Top level function to get the control flow graph
-func (l *argoListener) getControlFlowGraph() int {
+/* ******************  Print Structures Section   ************************* */
 
-	// call the forward pass on the control-flow graph 
-	l.forwardCfgPass()
-	// fix backward edges
-	l.fixBackwardCfgEdges() 
-	// link the variable write/reads to the control flow graph nodes 
-	l.addVarsToCfgNodes()
-	// add call and return edges 
-	l.addCFGcallReturnEdges()
-	// add delays in the cfg when there are data flow hazards	
-	l.resolveDataflowHazards()
+// print the basic blocks in text or graphViz format 
+func (l *argoListener) printBasicBlocks(format string) {
 
-	// replace function calls with 
+	if (format == "graphViz") {
+		fmt.Printf("Digraph G { \n")
+	}
+	
+	for _, block := range l.basicBlocks {
+		if (format == "text") {
+			fmt.Printf("Block: %d func: %s cntl: ",block.id,block.funcName)
+			for _, cNode := range block.cfgNodes {
+				fmt.Printf("%d:%s ",cNode.id,cNode.cfgType)
+			}
+			fmt.Printf(" succ: ")
+			for _, succ := range block.successors {
+				fmt.Printf("%d ",succ.id)
+			}
+			fmt.Printf(" pred: ")
+			for _, pred := range block.predecessors {
+				fmt.Printf("%d ",pred.id)
+			}
+			fmt.Printf("\n")
+		}
 
-	// replace function calls in expressions
+		if (format == "graphViz") {
+			fmt.Printf("\"bb%d\" [ label = \"bb%d %s (%d nodes)\" ]; \n",block.id,block.id,block.funcName,len(block.cfgNodes))
+			for _, succ := range block.successors {
+				fmt.Printf("\"bb%d\" -> \"bb%d\" ; \n",block.id,succ.id)
+			}
+		}
+	}
 	
-	return 1 
+	if (format == "graphViz") {
+		fmt.Printf("} \n")
+	}
 }
 
-/* ******************  Print Structures Section   ************************* */
+
+// print the dataflow of each control node, by basic block: the variables the node
+// defines and uses, and for each use the def-use chain, the control nodes whose
+// definitions reach it. A use with no reaching definition reads the reset value 
+func (l *argoListener) printBasicBlockDataflow() {
+	var reachIn map[*CfgNode]map[*VariableNode]map[*CfgNode]bool
+	var seen map[*VariableNode]bool
+	var defIDs []int
+	var defStrs []string
+
+	reachIn = l.getReachingDefs()
+	for _, block := range l.basicBlocks {
+		fmt.Printf("Block: %d func: %s \n",block.id,block.funcName)
+		for _, cNode := range block.cfgNodes {
+			uses, defs := l.cfgUseDef(cNode)
+			fmt.Printf("\t Cntl: %d:%s defs: ",cNode.id,cNode.cfgType)
+			seen = make(map[*VariableNode]bool)
+			for _, vNode := range defs {
+				if (!seen[vNode]) {
+					seen[vNode] = true
+					fmt.Printf("%s ",vNode.sourceName)
+				}
+			}
+			fmt.Printf(" uses: ")
+			seen = make(map[*VariableNode]bool)
+			for _, vNode := range uses {
+				if (seen[vNode]) {
+					continue
+				}
+				seen[vNode] = true
+				defIDs = defIDs[:0]
+				for def := range reachIn[cNode][vNode] {
+					defIDs = append(defIDs,def.id)
+				}
+				sort.Ints(defIDs)
+				defStrs = defStrs[:0]
+				for _, id := range defIDs {
+					defStrs = append(defStrs,strconv.Itoa(id))
+				}
+				if (len(defStrs) == 0) {
+					defStrs = append(defStrs,"reset")
+				}
+				fmt.Printf("%s<-%s ",vNode.sourceName,strings.Join(defStrs,","))
+			}
+			fmt.Printf("\n")
+		}
+	}
+}
 
 func (l *argoListener) printControlFlowGraph() {
 	// sort by id number 
@@ -3548,9 +7330,9 @@ func (l *argoListener) printParseTreeNodes(outputStyle string) {
 	
 	if (outputStyle == "rawWithText") { 
 		for _, node := range l.ParseNodeList {
-			fmt.Printf("AST Nodes: %d: %s ::%s:: @(%d,%d),(%d,%d) parent: %d children: ", node.id, node.ruleType, node.sourceCode, node.sourceLineStart, node.sourceColStart, node.sourceLineEnd, node.sourceColEnd, node.parentID )
- 			for _, childID := range node.childIDs {
-				fmt.Printf("%d ",childID)
+			fmt.Printf("AST Nodes: %d: %s ::%s:: @(%d,%d),(%d,%d) parent: %d children: ", node.id, node.ruleType, node.getSourceCode(), node.sourceLineStart, node.sourceColStart, node.sourceLineEnd, node.sourceColEnd, node.parentID )
+ 			for _, child := range node.children {
+				fmt.Printf("%d ",child.id)
 			}
 			fmt.Printf("\n")
 		}
@@ -3565,8 +7347,8 @@ func (l *argoListener) printParseTreeNodes(outputStyle string) {
 		// build a map of IDs to names 
 		for _, node := range l.ParseNodeList {
 			nodeStr = strconv.Itoa(node.id) + ":" + node.ruleType
-			if len(node.sourceCode) <= 5 {
-				nodeStr = nodeStr+":"+node.sourceCode
+			if len(node.getSourceCode()) <= 5 {
+				nodeStr = nodeStr+":"+node.getSourceCode()
 			}
 			// remove quotes
 			nodeStr = strings.Replace(nodeStr,"\"","",-1)
@@ -3576,57 +7358,270 @@ func (l *argoListener) printParseTreeNodes(outputStyle string) {
 		// now print the graph 
 		fmt.Printf("Digraph G { \n") 
 		for _, node := range l.ParseNodeList {
-			if len(node.childIDs) > 0 { 
+			if len(node.children) > 0 { 
 				nodeStr = nodeID2Name[node.id]
-				for _, childID := range node.childIDs {
-					fmt.Printf("\t %s -> %s ; \n", nodeStr,nodeID2Name[childID] )
+				for _, child := range node.children {
+					fmt.Printf("\t %s -> %s ; \n", nodeStr,nodeID2Name[child.id] )
 					
 				}
 			}
 		}
-		fmt.Printf("}\n") 
+		fmt.Printf("}\n") 
+	}
+}
+
+func (l *argoListener) printVarNodes() {
+
+	for _, node := range l.varNodeList {
+		fmt.Printf("Variable: %d name: %s func: %s pos:(%d,%d) class:%s prim:%s size:%d param:%t result:%t ",
+			node.id,node.sourceName,node.funcName,node.sourceRow,node.sourceCol,
+			node.goLangType,node.primType, node.numBits,node.isParameter,node.isResult)
+		if (node.isPointer) {
+			fmt.Printf("pointer ")
+		}
+		if (node.sharedWith != nil) {
+			fmt.Printf("shares: %s ",node.sharedWith.canName)
+		}
+		if (node.verilogName != "") {
+			fmt.Printf("verilog: %s ",node.verilogName)
+		}
+		switch (node.goLangType) {
+
+		case "array":
+			fmt.Printf("dimensions: ")
+			for i,size := range node.dimensions {
+				fmt.Printf(" %d:%d ",i+1,size)
+			}
+		case "map":
+		case "channel":
+			fmt.Printf("depth %d dir %s ",node.depth,node.chanDir)
+			for i,size := range node.dimensions {
+				fmt.Printf(" %d:%d ",i+1,size)
+			}
+		case "function":
+			fmt.Printf("holds %s ",node.funcTarget)
+		case "numeric":
+			if (node.structType != nil) {
+				fmt.Printf("struct %d fields ",len(node.structType.fields))
+			}
+			if (node.initValue != "") {
+				fmt.Printf("init %s ",node.initValue)
+			}
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// print the number of nodes in the parse tree and each intermediate representation,
+// and the wall-clock time of each phase of the compiler 
+func (l *argoListener) printIRStats(phaseNames []string,phaseTimes []time.Duration) {
+	var numBubbles int
+
+	numBubbles = 0
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.cfgType == "bubble") {
+			numBubbles++
+		}
+	}
+
+	fmt.Printf("file: %s \n",l.fileName)
+	fmt.Printf("parse nodes: %d \n",len(l.ParseNodeList))
+	fmt.Printf("variables: %d \n",len(l.varNodeList))
+	fmt.Printf("functions: %d \n",len(l.funcNodeList))
+	fmt.Printf("statement nodes: %d \n",len(l.statementGraph))
+	fmt.Printf("cfg nodes: %d \n",len(l.controlFlowGraph))
+	fmt.Printf("bubble nodes: %d \n",numBubbles)
+	fmt.Printf("basic blocks: %d \n",len(l.basicBlocks))
+	for i, name := range phaseNames {
+		fmt.Printf("time %s: %s \n",name,phaseTimes[i])
+	}
+}
+
+// print the list of functions 
+func (l *argoListener) printFuncNodes() {
+	for _, node := range l.funcNodeList {
+		fmt.Printf("Func: %d: name %s at (%d,%d) ", node.id,node.funcName,node.sourceRow,node.sourceCol)
+		if (len(node.parameters) >0) {
+			fmt.Printf("params: ")
+			for _, param := range(node.parameters) {
+				fmt.Printf("[%s:%s:%d] ",param.sourceName,param.goLangType,param.numBits)
+			}
+		}
+		if ( len(node.retVars) >0 ) {
+			fmt.Printf("retVars: ")
+			for _, param := range(node.retVars) {
+				fmt.Printf("[%s:%s:%d] ",param.sourceName,param.goLangType,param.numBits)
+			}			
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// a call edge of the call graph: the statement in the caller, and if it is a
+// go statement, which launches the callee instead of calling it 
+type CallEdge struct {
+	caller string
+	callee string
+	stmt *StatementNode
+	launch bool
+}
+
+// get the edges of the call graph from the call and go targets of the statements,
+// in statement order, then the calls in conditions 
+func (l *argoListener) getCallEdges() []CallEdge {
+	var edges []CallEdge
+
+	sort.Slice(l.statementGraph, func(i, j int) bool {
+		return l.statementGraph[i].id < l.statementGraph[j].id
+	})
+	for _, stmt := range l.statementGraph {
+		for _, target := range stmt.callTargets {
+			edges = append(edges,CallEdge{stmt.funcName,target.funcName,stmt,false})
+		}
+		for _, target := range stmt.goTargets {
+			edges = append(edges,CallEdge{stmt.funcName,target.funcName,stmt,true})
+		}
+	}
+	for _, hoisted := range l.hoistedCalls {
+		stmt := hoisted.cfgNode.statement
+		edges = append(edges,CallEdge{stmt.funcName,hoisted.callee.funcName,stmt,false})
+	}
+	return edges
+}
+
+// the functions which main does not reach through calls or go statements, in the
+// order of the function list. A function called only from unused functions is
+// unused too. Returns nil if the program has no main 
+func (l *argoListener) unusedFunctions() []*FunctionNode {
+	var callees map[string][]string
+	var reached map[string]bool
+	var reach func(funcName string)
+	var unused []*FunctionNode
+
+	if (l.getFuncNodeByNames("","main") == nil) {
+		return nil
+	}
+	callees = make(map[string][]string)
+	for _, edge := range l.getCallEdges() {
+		callees[edge.caller] = append(callees[edge.caller],edge.callee)
+	}
+	reached = make(map[string]bool)
+	reach = func(funcName string) {
+		if (reached[funcName]) {
+			return
+		}
+		reached[funcName] = true
+		for _, callee := range callees[funcName] {
+			reach(callee)
+		}
+	}
+	reach("main")
+	for _, funcNode := range l.funcNodeList {
+		if (!reached[funcNode.funcName]) {
+			unused = append(unused,funcNode)
+		}
+	}
+	return unused
+}
+
+// with -prune-unused, remove the functions main does not reach, so no module is
+// output for them. Returns the number of functions removed 
+func (l *argoListener) pruneUnusedFunctions() int {
+	var unused map[*FunctionNode]bool
+	var kept []*FunctionNode
+
+	unused = make(map[*FunctionNode]bool)
+	for _, funcNode := range l.unusedFunctions() {
+		unused[funcNode] = true
+		fmt.Printf("Pruned function %s, which is never called \n",funcNode.funcName)
+	}
+	for _, funcNode := range l.funcNodeList {
+		if (!unused[funcNode]) {
+			kept = append(kept,funcNode)
+		}
+	}
+	l.funcNodeList = kept
+	return len(unused)
+}
+
+// the functions which can reach themselves through calls or go statements. A
+// module cannot instance itself, so these have no hardware translation 
+func recursiveFunctions(edges []CallEdge) map[string]bool {
+	var callees map[string][]string
+	var recursive map[string]bool
+	var reaches func(from string,to string,visited map[string]bool) bool
+
+	callees = make(map[string][]string)
+	for _, edge := range edges {
+		callees[edge.caller] = append(callees[edge.caller],edge.callee)
+	}
+	reaches = func(from string,to string,visited map[string]bool) bool {
+		for _, callee := range callees[from] {
+			if (callee == to) {
+				return true
+			}
+			if (!visited[callee]) {
+				visited[callee] = true
+				if (reaches(callee,to,visited)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	recursive = make(map[string]bool)
+	for caller := range callees {
+		if (reaches(caller,caller,make(map[string]bool))) {
+			recursive[caller] = true
+		}
 	}
+	return recursive
 }
 
-func (l *argoListener) printVarNodes() {
+// print the call graph in text or graphViz format. The nodes are the functions and
+// the edges the statements calling them. In graphViz a call is a solid edge and a
+// go statement a dashed one, and recursive functions are drawn in red 
+func (l *argoListener) printCallGraph(format string) {
+	var edges []CallEdge
+	var recursive map[string]bool
+	var kind, style string
 
-	for _, node := range l.varNodeList {
-		fmt.Printf("Variable: %d name: %s func: %s pos:(%d,%d) class:%s prim:%s size:%d param:%t result:%t ",
-			node.id,node.sourceName,node.funcName,node.sourceRow,node.sourceCol,
-			node.goLangType,node.primType, node.numBits,node.isParameter,node.isResult)
-		switch (node.goLangType) {
+	edges = l.getCallEdges()
+	recursive = recursiveFunctions(edges)
 
-		case "array":
-			fmt.Printf("dimensions: ")
-			for i,size := range node.dimensions {
-				fmt.Printf(" %d:%d ",i+1,size)
+	if (format == "graphViz") {
+		fmt.Printf("Digraph G { \n")
+	}
+	for _, fNode := range l.funcNodeList {
+		if (format == "text") {
+			fmt.Printf("Func: %d: %s at (%d,%d) ",fNode.id,fNode.funcName,fNode.sourceRow,fNode.sourceCol)
+			if (recursive[fNode.funcName]) {
+				fmt.Printf("recursive ")
 			}
-		case "map":
-		case "channel":
-			fmt.Printf("depth %d ",node.depth)
-		case "numeric":
+			fmt.Printf("\n")
 		}
-		fmt.Printf("\n")
-	}
-}
-
-// print the list of functions 
-func (l *argoListener) printFuncNodes() {
-	for _, node := range l.funcNodeList {
-		fmt.Printf("Func: %d: name %s at (%d,%d) ", node.id,node.funcName,node.sourceRow,node.sourceCol)
-		if (len(node.parameters) >0) {
-			fmt.Printf("params: ")
-			for _, param := range(node.parameters) {
-				fmt.Printf("[%s:%s:%d] ",param.sourceName,param.goLangType,param.numBits)
+		if (format == "graphViz") {
+			if (recursive[fNode.funcName]) {
+				fmt.Printf("\"%s\" [ label = \"%s\" color = red ]; \n",fNode.funcName,fNode.funcName)
+			} else {
+				fmt.Printf("\"%s\" [ label = \"%s\" ]; \n",fNode.funcName,fNode.funcName)
 			}
 		}
-		if ( len(node.retVars) >0 ) {
-			fmt.Printf("retVars: ")
-			for _, param := range(node.retVars) {
-				fmt.Printf("[%s:%s:%d] ",param.sourceName,param.goLangType,param.numBits)
-			}			
+	}
+	for _, edge := range edges {
+		kind, style = "call", "solid"
+		if (edge.launch) {
+			kind, style = "go", "dashed"
 		}
-		fmt.Printf("\n")
+		if (format == "text") {
+			fmt.Printf("Call: %s -> %s %s stmt: %d at (%d,%d) \n",edge.caller,edge.callee,kind,edge.stmt.id,edge.stmt.sourceRow,edge.stmt.sourceCol)
+		}
+		if (format == "graphViz") {
+			fmt.Printf("\"%s\" -> \"%s\" [ label = \"%d\" style = %s ]; \n",edge.caller,edge.callee,edge.stmt.sourceRow,style)
+		}
+	}
+	if (format == "graphViz") {
+		fmt.Printf("} \n")
 	}
 }
 
@@ -3688,10 +7683,10 @@ func (l *argoListener) printStatementGraph(format string) {
 		
 		// assertion checks:
 		if (len(node.predecessors) != len(node.predIDs)) {
-			fmt.Printf("Error: length of precedessors does not match %d %d \n",len(node.predecessors),len(node.predIDs))
+			l.reportError(node.sourceRow,node.sourceCol,"statement %d has %d predecessors but %d predecessor IDs",node.id,len(node.predecessors),len(node.predIDs))
 		}
 		if (len(node.successors) != len(node.succIDs)) {
-			fmt.Printf("Error: length of successors does not match %d %d \n",len(node.successors),len(node.succIDs))
+			l.reportError(node.sourceRow,node.sourceCol,"statement %d has %d successors but %d successor IDs",node.id,len(node.successors),len(node.succIDs))
 		}
 
 		for i,id := range node.predIDs { 
@@ -3795,6 +7790,7 @@ func (l *argoListener) printStatementGraph(format string) {
 		case "declaration": 
 		case "labeledStmt":
 		case "goStmt":
+		case "deferStmt":
 		case "returnStmt":
 		case "breakStmt":
 		case "continueStmt":
@@ -3873,11 +7869,455 @@ func (l *argoListener) printStatementGraph(format string) {
 	}
 }
 
+// a statement node as JSON. Edges are the IDs of the other statements, so
+// the graph has no pointer cycles. A missing sub-statement is -1 
+type StmtJSON struct {
+	ID          int      `json:"id"`
+	Type        string   `json:"type"`
+	Func        string   `json:"func"`
+	Row         int      `json:"row"`
+	Col         int      `json:"col"`
+	Source      string   `json:"source"`
+	Preds       []int    `json:"preds"`
+	Succs       []int    `json:"succs"`
+	Parent      int      `json:"parent"`
+	Child       int      `json:"child"`
+	IfRoot      int      `json:"ifRoot"`
+	IfSimple    int      `json:"ifSimple"`
+	IfTest      int      `json:"ifTest"`
+	IfTaken     int      `json:"ifTaken"`
+	IfElse      int      `json:"ifElse"`
+	ForRoot     int      `json:"forRoot"`
+	ForInit     int      `json:"forInit"`
+	ForCond     int      `json:"forCond"`
+	ForPost     int      `json:"forPost"`
+	ForBlock    int      `json:"forBlock"`
+	ForTail     int      `json:"forTail"`
+	Cases       [][]int  `json:"cases"`
+	CallTargets []int    `json:"callTargets"`
+	Callers     []int    `json:"callers"`
+	GoTargets   []int    `json:"goTargets"`
+	Returns     []int    `json:"returnTargets"`
+}
+
+// the IDs of a list of statements 
+func stmtIDs(stmts []*StatementNode) []int {
+	var ids []int
+
+	ids = make([]int,0,len(stmts))
+	for _, stmt := range stmts {
+		ids = append(ids,stmt.id)
+	}
+	return ids
+}
+
+// the ID of a statement, or -1 if there is none 
+func stmtIDorNone(stmt *StatementNode) int {
+	if (stmt == nil) {
+		return -1
+	}
+	return stmt.id
+}
+
+// print the statement graph as a JSON array of statements for external visualization tools
+func (l *argoListener) printStatementGraphJSON() {
+	var nodes []StmtJSON
+
+	sort.Slice(l.statementGraph, func(i, j int) bool {
+		return l.statementGraph[i].id < l.statementGraph[j].id
+	})
+
+	nodes = make([]StmtJSON,0,len(l.statementGraph))
+	for _, node := range l.statementGraph {
+		jNode := StmtJSON{
+			ID: node.id,
+			Type: node.stmtType,
+			Func: node.funcName,
+			Row: node.sourceRow,
+			Col: node.sourceCol,
+			Source: node.sourceName,
+			Preds: append(make([]int,0,len(node.predIDs)),node.predIDs...),
+			Succs: append(make([]int,0,len(node.succIDs)),node.succIDs...),
+			Parent: -1,
+			Child: -1,
+			IfRoot: stmtIDorNone(node.ifRoot),
+			IfSimple: node.ifSimpleID(),
+			IfTest: node.ifTestID(),
+			IfTaken: node.ifTakenID(),
+			IfElse: node.ifElseID(),
+			ForRoot: stmtIDorNone(node.forRoot),
+			ForInit: node.forInitID(),
+			ForCond: node.forCondID(),
+			ForPost: node.forPostID(),
+			ForBlock: node.forBlockID(),
+			ForTail: node.forTailID(),
+			Cases: make([][]int,0,len(node.caseList)),
+			CallTargets: stmtIDs(node.callTargets),
+			Callers: stmtIDs(node.callers),
+			GoTargets: stmtIDs(node.goTargets),
+			Returns: stmtIDs(node.returnTargets),
+		}
+		// the stored parent and child IDs are only meaningful if there is a parent or child 
+		if (node.parent != nil) {
+			jNode.Parent = node.parentID
+		}
+		if (node.child != nil) {
+			jNode.Child = node.childID
+		}
+		for _, caseStmts := range node.caseList {
+			jNode.Cases = append(jNode.Cases,stmtIDs(caseStmts))
+		}
+		nodes = append(nodes,jNode)
+	}
+
+	out, err := json.MarshalIndent(nodes,"","  ")
+	if (err != nil) {
+		l.reportError(0,0,"converting the statement graph to JSON: %s",err)
+		return
+	}
+	fmt.Printf("%s\n",out)
+}
+
+// the hardware generated from one source position, for -manifest. A debugger can
+// map the control bits in a simulation trace back to the source line 
+type ManifestJSON struct {
+	Func        string   `json:"func"`
+	Row         int      `json:"row"`
+	Col         int      `json:"col"`
+	ControlBits []string `json:"controlBits"`
+	Registers   []string `json:"registers"`
+}
+
+// write the manifest of the generated hardware: for each source position of a
+// control node or variable declaration, the control bits of the nodes and the
+// registers of the variables, in source order 
+func (l *argoListener) writeManifest(fileName string) {
+	var entries []*ManifestJSON
+	var entryMap map[string]*ManifestJSON
+	var row, col int
+
+	entryMap = make(map[string]*ManifestJSON)
+	getEntry := func(funcName string,row int,col int) *ManifestJSON {
+		key := fmt.Sprintf("%s:%d:%d",funcName,row,col)
+		entry, ok := entryMap[key]
+		if (!ok) {
+			entry = &ManifestJSON{Func: funcName, Row: row, Col: col, ControlBits: []string{}, Registers: []string{}}
+			entryMap[key] = entry
+			entries = append(entries,entry)
+		}
+		return entry
+	}
+
+	for _, funcNode := range l.funcNodeList {
+		for _, cNode := range moduleCfgNodes(l,funcNode.funcName) {
+			row, col = cNode.statement.sourceRow, cNode.statement.sourceCol
+			if (cNode.subStmt != nil) {
+				row, col = cNode.subStmt.sourceRow, cNode.subStmt.sourceCol
+			}
+			entry := getEntry(cNode.statement.funcName,row,col)
+			entry.ControlBits = append(entry.ControlBits,nodeExitBits(cNode)...)
+		}
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.goLangType != "numeric") {
+			continue  // arrays are memories and channels FIFOs 
+		}
+		entry := getEntry(vNode.funcName,vNode.sourceRow,vNode.sourceCol)
+		entry.Registers = append(entry.Registers,verilogVarName(vNode))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if (entries[i].Row != entries[j].Row) {
+			return entries[i].Row < entries[j].Row
+		}
+		return entries[i].Col < entries[j].Col
+	})
+
+	out, err := json.MarshalIndent(entries,"","  ")
+	if (err != nil) {
+		l.reportError(0,0,"converting the manifest to JSON: %s",err)
+		return
+	}
+	err = os.WriteFile(fileName,append(out,'\n'),0666)
+	if (err != nil) {
+		l.reportError(0,0,"writing the manifest file %s: %s",fileName,err)
+	}
+}
+
+
+// the largest number of cycles of a static schedule, for -schedule. A longer
+// schedule is not useful to look at 
+const MAXSCHEDULE = 100000
+
+// one cycle of the static schedule of a function: the control node active in the cycle 
+type ScheduleStepJSON struct {
+	Cycle       int      `json:"cycle"`
+	Node        string   `json:"node"`
+	Type        string   `json:"type"`
+	Row         int      `json:"row"`
+	Col         int      `json:"col"`
+}
+
+// the static schedule of a function, for -schedule. A function with data-dependent
+// control flow has no schedule, and the reason says why 
+type ScheduleJSON struct {
+	Func        string   `json:"func"`
+	Static      bool     `json:"static"`
+	Reason      string   `json:"reason,omitempty"`
+	Cycles      int      `json:"cycles"`
+	Steps       []ScheduleStepJSON `json:"steps"`
+}
+
+// the value of an expression of constants, or of a variable with a known value,
+// for a static schedule 
+func (l *argoListener) staticValue(pNode *ParseNode,values map[*VariableNode]int64,funcName string) (int64, bool) {
+	if value, ok := l.evalConstExpr(pNode,funcName) ; ok {
+		return value, true
+	}
+	vNode := l.getVarNodeInScope(funcName,pNode.getPlainOperandName(),pNode)
+	if value, ok := values[vNode] ; ok {
+		return value, true
+	}
+	return 0, false
+}
+
+// the number of cycles a control node is active, or an error if it depends on data.
+// A read of an array waits a cycle for the memory, and a pipelined multiply for
+// the latency of the multiplier 
+func (l *argoListener) staticNodeCycles(cNode *CfgNode,funcName string) (int, error) {
+	var cycles int
+
+	switch cNode.cfgType {
+	case "ifTest":
+		return 0, fmt.Errorf("the if at %d:%d depends on data",cNode.sourceRow,cNode.sourceCol)
+	case "switch":
+		return 0, fmt.Errorf("the switch at %d:%d depends on data",cNode.sourceRow,cNode.sourceCol)
+	case "select", "send":
+		return 0, fmt.Errorf("the channel operation at %d:%d waits for another process",cNode.sourceRow,cNode.sourceCol)
+	case "goStmt":
+		return 0, fmt.Errorf("the go statement at %d:%d starts a process",cNode.sourceRow,cNode.sourceCol)
+	case "condCall":
+		return 0, fmt.Errorf("the call at %d:%d waits for the callee",cNode.sourceRow,cNode.sourceCol)
+	}
+	if (cNode.cfgType != "funcEntry") && (len(cNode.statement.callTargets) > 0) {
+		return 0, fmt.Errorf("the call of %s at %d:%d waits for the callee",cNode.statement.callTargets[0].funcName,cNode.sourceRow,cNode.sourceCol)
+	}
+	for _, vNode := range append(append([]*VariableNode{},cNode.readVars...),cNode.writeVars...) {
+		if (vNode.goLangType == "channel") {
+			return 0, fmt.Errorf("the use of channel %s at %d:%d waits for another process",vNode.sourceName,cNode.sourceRow,cNode.sourceCol)
+		}
+	}
+
+	cycles = 1
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcName) && (vNode.goLangType == "array") && (len(l.arrayIndexReads(cNode,vNode)) > 0) {
+			cycles = 2
+		}
+	}
+	if (l.mulStyle == "seq") && (cNode.mulDepth() > 0) && (cNode.mulDepth()*MULLATENCY+1 > cycles) {
+		cycles = cNode.mulDepth()*MULLATENCY + 1
+	}
+	return cycles, nil
+}
+
+// update the values of the variables a control node writes, for the conditions
+// of counted loops. An assignment of a constant expression, e.g. the pre-statement
+// i := 0, sets the value, and i++ or i += 2 steps it. Any other write makes the
+// value of the variable unknown 
+func (l *argoListener) staticValueWrites(cNode *CfgNode,values map[*VariableNode]int64,funcName string) {
+	var stmt *StatementNode
+	var value, step int64
+	var ok, stepOk bool
+
+	stmt = cNode.assigningStmt()
+	for _, vNode := range cNode.writeVars {
+		ok = false
+		switch {
+		case (stmt.stmtType == "rangeInit"):
+			value, ok = 0, true
+		case (stmt.stmtType == "rangePost"):
+			value, ok = values[vNode]
+			value = value + 1
+		case (stmt.stmtType == "incDecStmt"):
+			value, ok = values[vNode]
+			if (stmt.parseSubDef.children[1].ruleType == "++") {
+				value = value + 1
+			} else {
+				value = value - 1
+			}
+		case (stmt.parseSubDef.getAssignOp() == "+") || (stmt.parseSubDef.getAssignOp() == "-"):
+			value, ok = values[vNode]
+			step, stepOk = l.staticValue(stmt.parseSubDef.children[2],values,funcName)
+			ok = (ok) && (stepOk)
+			if (stmt.parseSubDef.getAssignOp() == "+") {
+				value = value + step
+			} else {
+				value = value - step
+			}
+		case (stmt.parseSubDef.getAssignOp() == ""):
+			if rhs := stmt.getRhsExpr(vNode.sourceName) ; rhs != nil {
+				value, ok = l.staticValue(rhs,values,funcName)
+			}
+		}
+		if (ok) {
+			values[vNode] = value
+		} else {
+			delete(values,vNode)
+		}
+	}
+}
+
+// evaluate the condition of a for loop from its values. A range over an integer
+// loops while the counter is less than the count 
+func (l *argoListener) staticLoopCondition(cNode *CfgNode,values map[*VariableNode]int64,funcName string) (bool, error) {
+	var cond *ParseNode
+	var lhs, rhs int64
+	var ok1, ok2 bool
+
+	if (cNode.subStmt == nil) {
+		return false, fmt.Errorf("the loop at %d:%d has no condition",cNode.sourceRow,cNode.sourceCol)
+	}
+	if (cNode.subStmt.stmtType == "rangeCond") && (len(cNode.subStmt.readVars) > 0) {
+		rangeNode := cNode.subStmt.parseDef
+		lhs, ok1 = values[cNode.subStmt.readVars[0]]
+		rhs, ok2 = l.staticValue(rangeNode.children[len(rangeNode.children)-1],values,funcName)
+		if (!ok1) || (!ok2) {
+			return false, fmt.Errorf("the loop at %d:%d has no constant trip count",cNode.sourceRow,cNode.sourceCol)
+		}
+		return lhs < rhs, nil
+	}
+	cond = cNode.subStmt.parseDef.stripParens()
+	if (cond.ruleType != "expression") || (len(cond.children) != 3) {
+		return false, fmt.Errorf("the loop at %d:%d has no constant trip count",cNode.sourceRow,cNode.sourceCol)
+	}
+	lhs, ok1 = l.staticValue(cond.children[0],values,funcName)
+	rhs, ok2 = l.staticValue(cond.children[2],values,funcName)
+	if (!ok1) || (!ok2) {
+		return false, fmt.Errorf("the loop at %d:%d has no constant trip count",cNode.sourceRow,cNode.sourceCol)
+	}
+	switch strings.TrimSpace(cond.children[1].getSourceCode()) {
+	case "<":
+		return lhs < rhs, nil
+	case "<=":
+		return lhs <= rhs, nil
+	case ">":
+		return lhs > rhs, nil
+	case ">=":
+		return lhs >= rhs, nil
+	case "!=":
+		return lhs != rhs, nil
+	case "==":
+		return lhs == rhs, nil
+	}
+	return false, fmt.Errorf("the loop at %d:%d has no constant trip count",cNode.sourceRow,cNode.sourceCol)
+}
+
+// compute the static schedule of a function from its entry to its exit, one
+// control node per cycle as the one-hot control steps through them. The loop
+// values are evaluated to take the body of a for loop or leave it. Only counted
+// loops of constants are static 
+func (l *argoListener) staticSchedule(funcName string) ([]ScheduleStepJSON, error) {
+	var steps []ScheduleStepJSON
+	var values map[*VariableNode]int64
+	var cNode, next *CfgNode
+	var cycle int
+
+	for _, entry := range l.controlFlowGraph {
+		if (entry.cfgType == "funcEntry") && (entry.statement.funcName == funcName) {
+			cNode = entry
+			break
+		}
+	}
+	if (cNode == nil) {
+		return nil, fmt.Errorf("no entry node")
+	}
+
+	values = make(map[*VariableNode]int64)
+	cycle = 0
+	for (cNode != nil) {
+		cycles, err := l.staticNodeCycles(cNode,funcName)
+		if (err != nil) {
+			return nil, err
+		}
+		for k := 0; k < cycles; k++ {
+			steps = append(steps,ScheduleStepJSON{Cycle: cycle, Node: cNode.cannName, Type: cNode.cfgType, Row: cNode.sourceRow, Col: cNode.sourceCol})
+			cycle++
+		}
+		if (cycle > MAXSCHEDULE) {
+			return nil, fmt.Errorf("the schedule is longer than %d cycles",MAXSCHEDULE)
+		}
+		if (cNode.cfgType == "funcExit") {
+			break
+		}
+		l.staticValueWrites(cNode,values,funcName)
+
+		targets := append(append([]*CfgNode{},cNode.successors...),cNode.successors_taken...)
+		next = nil
+		if (cNode.cfgType == "forCond") {
+			// the taken edge enters the body of the loop, the other leaves it 
+			loops, err := l.staticLoopCondition(cNode,values,funcName)
+			if (err != nil) {
+				return nil, err
+			}
+			targets = cNode.successors
+			if (loops) {
+				targets = cNode.successors_taken
+			}
+		}
+		if (len(targets) == 1) {
+			next = targets[0]
+		} else if (len(targets) > 1) {
+			return nil, fmt.Errorf("the node at %d:%d has more than one successor",cNode.sourceRow,cNode.sourceCol)
+		}
+		if (next == nil) {
+			return nil, fmt.Errorf("the node at %d:%d does not reach the exit",cNode.sourceRow,cNode.sourceCol)
+		}
+		cNode = next
+	}
+	return steps, nil
+}
+
+// write the static schedule of each function module as JSON, for -schedule. A
+// function whose control depends on data, e.g. an if or a channel operation, is
+// skipped with the reason 
+func (l *argoListener) writeSchedule(fileName string) {
+	var schedules []*ScheduleJSON
+
+	for _, funcNode := range l.funcNodeList {
+		if (l.moduleFuncName(funcNode.funcName) != funcNode.funcName) {
+			continue  // an inlined function is scheduled in its caller 
+		}
+		schedule := &ScheduleJSON{Func: funcNode.funcName, Steps: []ScheduleStepJSON{}}
+		steps, err := l.staticSchedule(funcNode.funcName)
+		if (err != nil) {
+			schedule.Reason = err.Error()
+			fmt.Printf("Schedule: function %s is not statically schedulable: %s \n",funcNode.funcName,err)
+		} else {
+			schedule.Static = true
+			schedule.Cycles = len(steps)
+			schedule.Steps = steps
+		}
+		schedules = append(schedules,schedule)
+	}
+
+	out, err := json.MarshalIndent(schedules,"","  ")
+	if (err != nil) {
+		l.reportError(0,0,"converting the schedule to JSON: %s",err)
+		return
+	}
+	err = os.WriteFile(fileName,append(out,'\n'),0666)
+	if (err != nil) {
+		l.reportError(0,0,"writing the schedule file %s: %s",fileName,err)
+	}
+}
 
 /* ******************  Parse Tree Contruction Section   ************************* */
 
-// recursive function to visit nodes in the Antlr4 graph 
-func VisitNode(l *argoListener,c antlr.Tree, parent *ParseNode,level int) ParseNode {
+// recursive function to visit nodes in the Antlr4 graph. Each node is made once;
+// the parent's children and the list of parse nodes point to the same node.
+// With -lowmem interior nodes keep only their positions and rebuild the source
+// code from the program lines when it is asked for 
+func VisitNode(l *argoListener,c antlr.Tree, parent *ParseNode,level int) *ParseNode {
 	var progText string
 	var err error
 	var id int 
@@ -3904,29 +8344,50 @@ func VisitNode(l *argoListener,c antlr.Tree, parent *ParseNode,level int) ParseN
 		startcol = start.GetColumn()
 		stopline = stop.GetLine()
 		stopcol = stop.GetColumn()
-		progText,err = rowscols2String(l.ProgramLines,startline,startcol,stopline,stopcol)
-		if (err != nil) {
-			//fmt.Printf("RowCols error on program text %s %d:%d to %d:%d ",err,startline,startcol,stopline,stopcol)
-			progText = "ERR"
+		if (!l.lowMem) {
+			progText,err = rowscols2String(l.ProgramLines,startline,startcol,stopline,stopcol)
+			if (err != nil) {
+				//fmt.Printf("RowCols error on program text %s %d:%d to %d:%d ",err,startline,startcol,stopline,stopcol)
+				progText = "ERR"
+			}
 		}
 	}
 	
 	ruleName := antlr.TreesGetNodeText(c,nil,l.recog)
 
-	thisNode := ParseNode{id : id , ruleType : ruleName, parentID: parent.id, parent: parent, sourceCode: progText , isTerminal : isTerminalNode, sourceLineStart: startline, sourceColStart : startcol, sourceLineEnd : stopline, sourceColEnd : stopcol }
+	thisNode := &ParseNode{id : id , ruleType : ruleName, parentID: parent.id, parent: parent, sourceCode: progText , isTerminal : isTerminalNode, sourceLineStart: startline, sourceColStart : startcol, sourceLineEnd : stopline, sourceColEnd : stopcol }
 	thisNode.visited = false
 	
 	for i := 0; i < c.GetChildCount(); i++ {
 		child := c.GetChild(i)
-		childParseNode := VisitNode(l,child,&thisNode,mylevel)
-		thisNode.children = append(thisNode.children,&childParseNode) 
-		thisNode.childIDs = append(thisNode.childIDs,childParseNode.id)
+		childParseNode := VisitNode(l,child,thisNode,mylevel)
+		thisNode.children = append(thisNode.children,childParseNode) 
 	}
 	
-	l.addParseNode(&thisNode)
+	l.addParseNode(thisNode)
 	return thisNode
 }
 
+// get the source code of a parse node. Interior nodes built with -lowmem rebuild
+// it from the program lines kept on the root node 
+func (node *ParseNode) getSourceCode() string {
+	var root *ParseNode
+
+	if (node.isTerminal) || (node.parent == nil) {
+		return node.sourceCode
+	}
+	for root = node.parent; root.parent != nil; root = root.parent {
+	}
+	if (root.programLines == nil) {
+		return node.sourceCode
+	}
+	progText, err := rowscols2String(root.programLines,node.sourceLineStart,node.sourceColStart,node.sourceLineEnd,node.sourceColEnd)
+	if (err != nil) {
+		return "ERR"
+	}
+	return progText
+}
+
 
 // EnterStart creates the AST by crawling the whole tree
 // it leaves a list of AST nodes in the listener struct sorted by ID
@@ -3938,21 +8399,23 @@ func (l *argoListener) EnterSourceFile(c *parser.SourceFileContext) {
 	id = l.getAstID(c)
 
 	// get the root AST node
-	root := ParseNode{ id : id, parentID : 0, parent : nil , ruleType: "SourceFile", sourceCode : "WholeProgramText" } 
+	root := &ParseNode{ id : id, parentID : 0, parent : nil , ruleType: "SourceFile", sourceCode : "WholeProgramText" } 
+	if (l.lowMem) {
+		root.programLines = l.ProgramLines
+	}
 
 
 	
 	for i := 0; i < c.GetChildCount(); i++ {
 		child := c.GetChild(i)
 		// fmt.Printf(" child %d: %p \n",i,child)
-		childNode := VisitNode(l,child,&root,level)
-		root.children = append(root.children, &childNode)
-		root.childIDs = append(root.childIDs, childNode.id)		
+		childNode := VisitNode(l,child,root,level)
+		root.children = append(root.children, childNode)
 		
 	}
 	// add the root back in
-	l.root = &root
-	l.addParseNode(&root)
+	l.root = root
+	l.addParseNode(root)
 
 	// sort all the nodes by nodeID in the list of nodes 
 	sort.Slice(l.ParseNodeList, func(i, j int) bool {
@@ -4065,6 +8528,17 @@ func getFileLines(fname string) ([]string, error) {
 	return retLines,nil
 }
 
+// getTextLines splits the text of a program into lines, the same as getFileLines 
+func getTextLines(text string) []string {
+	var retLines []string
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		retLines = append(retLines,scanner.Text())
+	}
+	return retLines
+}
+
 func (l *argoListener) EnterPackageClause(c *parser.PackageClauseContext) {
 	//fmt.Printf("entering Package\n")
 }
@@ -4074,32 +8548,111 @@ func (l *argoListener) EnterImportClause(c *parser.ImportClauseContext) {
 }
 	
 
-// parseArgo takes a string expression and returns the root node of the resulting AST
-func parseArgo(fname *string) *argoListener {
+// print the token stream of the lexer, one token per line with its type, text,
+// line and column. This is the input the parser sees, for finding why a construct
+// parses unexpectedly. The stream is filled here, the parser reads the buffered tokens 
+func printTokens(lexer *parser.ArgoLexer,stream *antlr.CommonTokenStream) {
+	var typeName string
+
+	stream.Fill()
+	for _, token := range stream.GetAllTokens() {
+		tType := token.GetTokenType()
+		if (tType == antlr.TokenEOF) {
+			typeName = "EOF"
+		} else if (tType > 0) && (tType < len(lexer.SymbolicNames)) && (lexer.SymbolicNames[tType] != "") {
+			typeName = lexer.SymbolicNames[tType]
+		} else if (tType > 0) && (tType < len(lexer.LiteralNames)) {
+			typeName = lexer.LiteralNames[tType]
+		} else {
+			typeName = strconv.Itoa(tType)
+		}
+		fmt.Printf("token %d: type %s (%d) text %q at %d:%d \n",token.GetTokenIndex(),typeName,tType,token.GetText(),token.GetLine(),token.GetColumn())
+	}
+}
+
+// run the passes from the parse tree to the basic blocks, which main and the
+// golden test share. Returns the names and times of the timed phases 
+func (l *argoListener) runPasses(narrowWidths bool,shareRegs bool) ([]string, []time.Duration) {
+	var phaseStart time.Time
+	var phaseNames []string
+	var phaseTimes []time.Duration
+
+	phaseStart = time.Now()
+	l.getAllStructTypes()  // struct types are needed for the widths of variables 
+	l.getAllConstants()  // constants are needed for array lengths 
+	l.getAllVariables()  // must call get all variables first 
+	l.setChannelArrayDepths()  // the FIFOs of arrays of channels are as deep as their makes 
+	l.nameShadowedVariables()  // shadowing variables get their own registers 
+	if (l.checkMemorySizes() > 0) {
+		l.printDiagnostics()
+		fmt.Printf("Memories are larger than -maxmem %d, exiting \n",l.maxMem)
+		os.Exit(1)
+	}
+	phaseNames = append(phaseNames,"getAllVariables")
+	phaseTimes = append(phaseTimes,time.Since(phaseStart))
+	l.getAllFunctions()  // then get all functions 
+	l.sanitizeIdentifiers()  // rename registers and modules which are Verilog keywords 
+	l.resolveStructVariables()  // set the widths of struct variables and results 
+	l.resolveRecvVariables()  // then the variables of comma-ok receives 
+	l.resolveFuncVariables()  // and bind function variables to the function they hold 
+	phaseStart = time.Now()
+	l.getStatementGraph()  // now make the statementgraph
+	phaseNames = append(phaseNames,"getStatementGraph")
+	phaseTimes = append(phaseTimes,time.Since(phaseStart))
+
+	// adding technical debit 
+	// FIXME need to add this back in to fix the scoping rules ... later
+	// l.fixVariableScopes()  fix the scoping rules to allow for short var decls
+	phaseStart = time.Now()
+	l.getControlFlowGraph()  // now make the statementgraph
+	phaseNames = append(phaseNames,"getControlFlowGraph")
+	phaseTimes = append(phaseTimes,time.Since(phaseStart))
+	if (l.inlineFuncs) {
+		l.inlineFunctions()  // splice single-use leaf functions into their callers 
+	}
+	l.getBasicBlocks()  // coalesce the control flow graph into basic blocks 
+	if (narrowWidths) {
+		l.inferVariableWidths()  // shrink registers with small value ranges 
+	}
+	l.applyDataWidth()  // then give every integer without a size the -datawidth 
+	if (shareRegs) {
+		l.shareRegisters()  // merge registers of variables which are not live at once 
+	}
+	return phaseNames, phaseTimes
+}
+
+// parseArgo takes a string expression and returns the root node of the resulting AST.
+// The file is read once, for both the lexer and the program lines. With lowMem
+// the interior parse nodes do not keep a copy of their source code. With tokens
+// the token stream is printed before the parse 
+func parseArgo(fname *string,lowMem bool,tokens bool) *argoListener {
 
-	var err error
 	var listener *argoListener
 
 	listener = new(argoListener)
+	listener.lowMem = lowMem
 
-	input, err := antlr.NewFileStream(*fname)
+	text, err := os.ReadFile(*fname)
+	if (err != nil) {
+		fmt.Printf("Error opening file %s: %s \n",*fname,err)
+		os.Exit(-1)
+	}
+	input := antlr.NewInputStream(string(text))
 	
 	lexer := parser.NewArgoLexer(input)
 	errorCount := new(ArgoErrorListener)
 	lexer.AddErrorListener(errorCount)
 	
 	stream := antlr.NewCommonTokenStream(lexer,0)
+	if (tokens) {
+		printTokens(lexer,stream)
+	}
 
 	p := parser.NewArgoParser(stream)
 	p.AddErrorListener(errorCount)
 	
 	listener.recog = p
-	progLines, err2 := getFileLines(*fname)
-	if (err2 != nil) {
-		fmt.Printf("Whoaa! Didn't get any program lines\n")
-		
-	}
-	listener.ProgramLines = progLines
+	listener.ProgramLines = getTextLines(string(text))
 
 	listener.nextParseID = 0
 	listener.ParseNode2ID = make(map[interface{}]int)
@@ -4110,6 +8663,8 @@ func parseArgo(fname *string) *argoListener {
 	listener.nextCfgID = 0
 	
 	listener.funcNameMap = make(map[string]*FunctionNode)
+	listener.intBits = 32
+	listener.constIota = -1
 	
 	listener.logIt.flags = make(map[string]bool,16)
 	listener.logIt.init()
@@ -4119,11 +8674,7 @@ func parseArgo(fname *string) *argoListener {
 	sNames := strings.Split(nName,"/")
 	
 	listener.moduleName = sNames[len(sNames)-1]
-	
-	if (err != nil) {
-		fmt.Printf("Getting program lines failed\n")
-		os.Exit(-1)
-	}
+	listener.fileName = *fname
 
 	//listener.logIt.DbgLog("MIN","testing the log %d %d %d \n",5,10,20)
 	
@@ -4138,6 +8689,29 @@ func parseArgo(fname *string) *argoListener {
 	return listener
 }
 
+// print the version of the compiler, the git commit it was built from and the
+// version of the ANTLR Go runtime. Without -ldflags the runtime version comes from
+// the module build information, if the binary has any 
+func printVersion() {
+	var runtimeVersion string
+
+	runtimeVersion = antlrVersion
+	if (runtimeVersion == "") {
+		runtimeVersion = "unknown"
+		if info, ok := debug.ReadBuildInfo() ; ok {
+			for _, dep := range info.Deps {
+				if (strings.HasPrefix(dep.Path,"github.com/antlr/antlr4")) {
+					runtimeVersion = dep.Version
+				}
+			}
+		}
+	}
+	fmt.Printf("argo2verilog version %s \n",VERSION)
+	fmt.Printf("git commit: %s \n",gitCommit)
+	fmt.Printf("ANTLR runtime: %s \n",runtimeVersion)
+	fmt.Printf("go: %s \n",runtime.Version())
+}
+
 // this is a global variable to abort when there are too
 // many errors
 var max_parse_errors int
@@ -4148,9 +8722,46 @@ func main() {
 	var printASTasGraphViz_p,printASTasText_p,printVarNames_p,printFuncNames_p,printStmtGraph_p,parseCheck_p,printScopes_p *bool
 	var genNoTestBench_p *bool // verilog test bench and max cycles
 	var genMaxCycles_p *int
+	var genCombo_p *bool
+	var genFSM_p *bool
+	var condWires_p *bool
+	var narrowWidths_p *bool
+	var shareRegs_p *bool
+	var inlineFuncs_p *bool
+	var strictCheck_p *bool
+	var genPerf_p *bool
+	var traceWrites_p *bool
+	var assertOneHot_p *bool
+	var manifestFileName_p *string
+	var scheduleFileName_p *string
+	var pruneUnused_p *bool
+	var initArrays_p *bool
+	var keywordPrefix_p *string
+	var mulStyle_p *string
+	var memStyle_p *string
+	var ioInterface_p *string
+	var resetSync_p *bool
+	var intBits_p *int
+	var dataWidth_p *int
+	var maxMem_p *int
+	var ignoreCalls_p *string
+	var coalesce_p *bool
+	var skipEmpty_p *bool
+	var timescale_p *string
+	var strictNets_p *bool
+	var lowMem_p *bool
+	var printVersion_p *bool
+	var dryRun bool  // only report the IR counts and phase times 
+	var phaseNames []string
+	var phaseTimes []time.Duration
+	var phaseStart time.Time
 	
 	var printStmtGraphGV_p *bool 
+	var printTokens_p *bool
+	var printStmtGraphJSON_p *bool 
 	var printCntlGraph_p *bool
+	var printBlocks_p,printBlocksGV_p,printBlockDataflow_p *bool
+	var printCallGraph_p,printCallGraphGV_p *bool
 	var debugFlags   uint64
 	var debugFlags_p,debugFileName_p *string
 	var genTestBench bool
@@ -4163,30 +8774,76 @@ func main() {
 	
 	printASTasGraphViz_p = flag.Bool("gv",false,"print the parse tree in GraphViz format")
 	printASTasText_p = flag.Bool("parse",false,"print the parse tree in text format")	
+	printTokens_p = flag.Bool("tokens",false,"print the token type, text, line and column of every lexer token before the parse")
 	printVarNames_p = flag.Bool("vars",false,"print all variables")
 	printStmtGraph_p = flag.Bool("stmt",false,"print the statement graph")
 	printStmtGraphGV_p = flag.Bool("stmtgv",false,"print the statement graph in graphviz format")
+	printStmtGraphJSON_p = flag.Bool("stmtjson",false,"print the statement graph in JSON format")
 	printFuncNames_p = flag.Bool("func",false,"print all functions")
+	printCallGraph_p = flag.Bool("callgraph",false,"print the call graph of the functions, with the statements of each call and go statement")
+	printCallGraphGV_p = flag.Bool("callgraphgv",false,"print the call graph in graphviz format, go statements as dashed edges and recursive functions in red")
 	printCntlGraph_p = flag.Bool("cntl",false,"print the control-flow graph")
+	printBlocks_p = flag.Bool("bb",false,"print the basic blocks")
+	printBlockDataflow_p = flag.Bool("bbdataflow",false,"print the definitions, uses and def-use chains of each control node, by basic block")
+	printBlocksGV_p = flag.Bool("bbgv",false,"print the basic blocks in graphviz format")
 	printScopes_p = flag.Bool("scope",false,"print variable scopes")
 	genNoTestBench_p   = flag.Bool("nobench",false,"do not generate a test bench")
 	genMaxCycles_p   = flag.Int("maxCy",2000,"maxium Verilog cycles")
+	genCombo_p   = flag.Bool("combo",false,"generate combinational modules for small functions with no loops, channels or calls")
 	
-	parseCheck_p     = flag.Bool("check",false,"check for correct syntax ")
-
-	debugFlags_p     = flag.String("dbg","","debug flags 1=verilog control ")
+	genFSM_p   = flag.Bool("fsm",false,"generate the control of each module as an encoded state machine instead of one-hot bits")
+	condWires_p   = flag.Bool("condwires",false,"assign each distinct if and for condition to a named wire used by the control logic")
+	narrowWidths_p   = flag.Bool("narrow",false,"narrow integer variables to the width proven by constant assignments and loop bounds")
+	shareRegs_p   = flag.Bool("sharemem",false,"share one register between local variables whose live ranges do not overlap")
+	inlineFuncs_p   = flag.Bool("inline",false,"inline leaf functions called from one statement into the caller, instead of a module")
+	genPerf_p   = flag.Bool("perf",false,"generate a cycle counter in every module. It is always generated with -dbg 1")
+	traceWrites_p   = flag.Bool("trace",false,"display the cycle, canonical name and new value of every variable write")
+	assertOneHot_p   = flag.Bool("assertonehot",false,"stop the simulation with a message if a module sets more control bits at once than its forks allow")
+	initArrays_p   = flag.Bool("initarrays",false,"clear the memory of every array to zero after reset, as Go zeroes arrays")
+	mulStyle_p   = flag.String("mulstyle","comb","comb multiplies in one cycle, seq uses a pipelined multiplier and stalls for its latency")
+	memStyle_p   = flag.String("memstyle","","the ram_style attribute of array memories: block, distributed, or auto for distributed RAM for small arrays. Empty for none")
+	resetSync_p     = flag.Bool("resetsync-logic",false,"wrap the top module in a two flop synchronizer of the active high reset, which the test bench drives asynchronously")
+	ioInterface_p   = flag.String("iointerface","start","start uses the start and done bits of the top module, readyvalid wraps them in ready/valid handshakes")
+	keywordPrefix_p   = flag.String("keywordprefix","go_","prefix of Go identifiers which are Verilog keywords, e.g. go_input. An empty prefix escapes them instead")
+	lowMem_p   = flag.Bool("lowmem",false,"only keep the source code of terminal parse nodes, rebuilding the rest from the source lines. For very large inputs")
+	intBits_p   = flag.Int("intbits",32,"number of bits for int, uint and untyped integer constants")
+	dataWidth_p   = flag.Int("datawidth",0,"number of bits for every integer variable, array and channel element and intermediate without an explicit size, e.g. 16. Overrides -intbits and -narrow. 0 for none")
+	maxMem_p   = flag.Int("maxmem",1048576,"largest number of elements of an array or the depth of a channel. 0 for no limit")
+	timescale_p   = flag.String("timescale","","emit a `timescale directive with this unit and precision, e.g. 1ns/1ps")
+	strictNets_p   = flag.Bool("strictnets",false,"emit `default_nettype none, and declare every port as a wire or reg")
+	pruneUnused_p   = flag.Bool("prune-unused",false,"do not output a module for the functions main does not reach through calls or go statements")
+	skipEmpty_p   = flag.Bool("skip-empty",false,"remove the control bits of empty statements and of the ; separating simple statements")
+	coalesce_p   = flag.Bool("coalesce",false,"evaluate short straight-line chains of assignments in one cycle, e.g. i = 4 ; k = i + i")
+	ignoreCalls_p   = flag.String("ignorecalls","","comma separated library calls to remove as noops, e.g. log.Println, besides time.Sleep and runtime.Gosched")
+	parseCheck_p     = flag.Bool("check",false,"check for correct syntax, channel usage and the channels connecting goroutines, and warn of possible deadlocks, of loop variables written in the loop body, of assignments which truncate and of functions which are never called ")
+	strictCheck_p    = flag.Bool("strict",false,"the checks of -check, and check the control bits for combinational loops")
+	flag.BoolVar(&dryRun,"dryrun",false,"print the parse and IR counts and phase times, do not generate Verilog")
+	flag.BoolVar(&dryRun,"ast-stats",false,"same as -dryrun")
+
+	debugFlags_p     = flag.String("dbg","","debug flags 1=verilog control 2=canonical control and dataflow trace ")
 	debugFileName_p     = flag.String("dbgFile","/dev/stdout","debug output file ")
 	inputFileName_p = flag.String("i","","the input file name")
+	manifestFileName_p = flag.String("manifest","","write a JSON file mapping source positions to their control bits and registers")
+	scheduleFileName_p = flag.String("schedule","","write a JSON file of the control node active in each cycle of the functions with no data-dependent control flow")
 	outputFileName_p = flag.String("o","","the output file name")
+	printVersion_p = flag.Bool("version",false,"print the compiler version, git commit and ANTLR runtime version")
 
 
 	flag.Parse()
 
+	if (*printVersion_p) {
+		printVersion()
+		return
+	}
+
 	if (*inputFileName_p == "") {
 		fmt.Printf("No input file specified, exiting \n")
 		os.Exit(-1)
 	} else { 
-		parsedProgram = parseArgo(inputFileName_p)
+		phaseStart = time.Now()
+		parsedProgram = parseArgo(inputFileName_p,*lowMem_p,*printTokens_p)
+		phaseNames = append(phaseNames,"parse")
+		phaseTimes = append(phaseTimes,time.Since(phaseStart))
 	}
 
 	if ( !( *debugFlags_p == "")) {
@@ -4207,16 +8864,65 @@ func main() {
 	}
 
 	parsedProgram.debugFlags = debugFlags
+	parsedProgram.genCombo = *genCombo_p
+	parsedProgram.genFSM = *genFSM_p
+	parsedProgram.condWires = *condWires_p
+	parsedProgram.genPerf = *genPerf_p
+	parsedProgram.traceWrites = *traceWrites_p
+	parsedProgram.assertOneHot = *assertOneHot_p
+	parsedProgram.inlineFuncs = *inlineFuncs_p
+	parsedProgram.initArrays = *initArrays_p
+	parsedProgram.keywordPrefix = *keywordPrefix_p
+	if (*mulStyle_p != "comb") && (*mulStyle_p != "seq") {
+		fmt.Printf("-mulstyle must be comb or seq, exiting \n")
+		os.Exit(-1)
+	}
+	parsedProgram.mulStyle = *mulStyle_p
+	if (*memStyle_p != "") && (*memStyle_p != "block") && (*memStyle_p != "distributed") && (*memStyle_p != "auto") {
+		fmt.Printf("-memstyle must be block, distributed or auto, exiting \n")
+		os.Exit(-1)
+	}
+	parsedProgram.memStyle = *memStyle_p
+	if (*ioInterface_p != "start") && (*ioInterface_p != "readyvalid") {
+		fmt.Printf("-iointerface must be start or readyvalid, exiting \n")
+		os.Exit(-1)
+	}
+	parsedProgram.ioInterface = *ioInterface_p
+	parsedProgram.resetSync = *resetSync_p
+	if (*intBits_p <= 0) || (*intBits_p > 64) {
+		fmt.Printf("-intbits must be between 1 and 64, exiting \n")
+		os.Exit(-1)
+	}
+	parsedProgram.intBits = *intBits_p
+	if (*dataWidth_p < 0) || (*dataWidth_p > 64) {
+		fmt.Printf("-datawidth must be between 1 and 64, or 0 for none, exiting \n")
+		os.Exit(-1)
+	}
+	// the intermediates of type int, e.g. int(x) and the untyped constants, have the data width 
+	parsedProgram.dataWidth = *dataWidth_p
+	if (parsedProgram.dataWidth > 0) {
+		parsedProgram.intBits = parsedProgram.dataWidth
+	}
+	if (*maxMem_p < 0) {
+		fmt.Printf("-maxmem must not be negative, exiting \n")
+		os.Exit(-1)
+	}
+	parsedProgram.maxMem = *maxMem_p
+	parsedProgram.timescale = strings.TrimSpace(*timescale_p)
+	parsedProgram.strictNets = *strictNets_p
+	parsedProgram.coalesce = *coalesce_p
+	parsedProgram.skipEmpty = *skipEmpty_p
+	parsedProgram.ignoredCalls = make(map[string]string)
+	for _, name := range strings.Split(*ignoreCalls_p,",") {
+		if (strings.TrimSpace(name) != "") {
+			parsedProgram.ignoredCalls[strings.TrimSpace(name)] = "noop"
+		}
+	}
 	
 	// these are the top-level main causes of the compiler 
-	parsedProgram.getAllVariables()  // must call get all variables first 
-	parsedProgram.getAllFunctions()  // then get all functions 
-	parsedProgram.getStatementGraph()  // now make the statementgraph
-
-	// adding technical debit 
-	// FIXME need to add this back in to fix the scoping rules ... later
-	// parsedProgram.fixVariableScopes()  fix the scoping rules to allow for short var decls
-	parsedProgram.getControlFlowGraph()  // now make the statementgraph
+	passNames, passTimes := parsedProgram.runPasses(*narrowWidths_p,*shareRegs_p)
+	phaseNames = append(phaseNames,passNames...)
+	phaseTimes = append(phaseTimes,passTimes...)
 
 	
 	if (*printASTasGraphViz_p) {
@@ -4236,6 +8942,12 @@ func main() {
 		parsedProgram.printFuncNodes()
 		
 	}
+	if (*printCallGraph_p) {
+		parsedProgram.printCallGraph("text")
+	}
+	if (*printCallGraphGV_p) {
+		parsedProgram.printCallGraph("graphViz")
+	}
 	
 	
 	if (*printStmtGraph_p) {
@@ -4244,6 +8956,9 @@ func main() {
 	if (*printStmtGraphGV_p) {
 		parsedProgram.printStatementGraph("graphViz")	
 	}
+	if (*printStmtGraphJSON_p) {
+		parsedProgram.printStatementGraphJSON()
+	}
 	
 
 	if (*printCntlGraph_p)  {
@@ -4251,11 +8966,30 @@ func main() {
 			
 	}
 
+	if (*printBlocks_p)  {
+		parsedProgram.printBasicBlocks("text")
+	}
+	if (*printBlocksGV_p)  {
+		parsedProgram.printBasicBlocks("graphViz")
+	}
+	if (*printBlockDataflow_p)  {
+		parsedProgram.printBasicBlockDataflow()
+	}
+
 	if (*printScopes_p) {
 		parsedProgram.printVarScopes()
 		
 	}
 
+	if (dryRun) {
+		parsedProgram.printIRStats(phaseNames,phaseTimes)
+		if numErrors := parsedProgram.printDiagnostics() ; numErrors > 0 {
+			fmt.Printf("Translation failed with %d errors \n",numErrors)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if (*genNoTestBench_p) {
 		genTestBench = false 
 	} else {
@@ -4268,15 +9002,40 @@ func main() {
 		max_cycles = 2000		
 	}
 	
-	if (*parseCheck_p) {
-		// fmt.Printf("parse check completed \n")
+	// static checks of the program before generating any hardware 
+	if (*parseCheck_p) || (*strictCheck_p) {
+		parsedProgram.checkChannels()
+		parsedProgram.checkPointers()
+		parsedProgram.checkStatementGraph()
+		parsedProgram.checkCfgEdges()
+		parsedProgram.checkGoChannels()
+		parsedProgram.checkDeadlocks()
+		parsedProgram.checkShortCircuit()
+		parsedProgram.checkLoopVariables()
+		parsedProgram.checkTruncation()
+		parsedProgram.checkUnusedFunctions()
+		if (*strictCheck_p) {
+			parsedProgram.checkControlLoops()
+		}
+		// the checks and the passes before them add to the same diagnostics 
+		numErrors := parsedProgram.printDiagnostics()
+		if (numErrors > 0) {
+			fmt.Printf("Check failed with %d errors \n",numErrors)
+			os.Exit(1)
+		}
 	} 
 
-	
-	if (*parseCheck_p) {
-		// fmt.Printf("parse check completed \n")
-	} 
 
+	if (*pruneUnused_p) {
+		parsedProgram.pruneUnusedFunctions()
+	}
+
+	// a recursive call would instantiate a module inside itself 
+	if (parsedProgram.checkRecursion() > 0) {
+		parsedProgram.printDiagnostics()
+		fmt.Printf("Recursive functions are not supported, exiting \n")
+		os.Exit(1)
+	}
 
 	if ( len(*outputFileName_p) > 0 ) {
 		var w *os.File 
@@ -4286,7 +9045,7 @@ func main() {
 		
 			file, err := os.OpenFile(*outputFileName_p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
 			if err != nil {
-				fmt.Printf("Error opening file %s \n ",outputFileName_p)
+				fmt.Printf("Error opening file %s \n ",*outputFileName_p)
 				os.Exit(1)
 			}
 			defer file.Close()
@@ -4295,4 +9054,17 @@ func main() {
 		parsedProgram.outputFile = w
 		OutputVerilog(parsedProgram,genTestBench,max_cycles);
 	}
+
+	if (len(*manifestFileName_p) > 0) {
+		parsedProgram.writeManifest(*manifestFileName_p)
+	}
+	if (len(*scheduleFileName_p) > 0) {
+		parsedProgram.writeSchedule(*scheduleFileName_p)
+	}
+
+	// print every error and warning of the passes, and fail if there are errors 
+	if numErrors := parsedProgram.printDiagnostics() ; numErrors > 0 {
+		fmt.Printf("Translation failed with %d errors \n",numErrors)
+		os.Exit(1)
+	}
 }
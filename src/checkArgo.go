@@ -0,0 +1,1276 @@
+/* Argo to Verilog Compiler
+    (c) 2020, Richard P. Martin and contributers
+
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    This program is distributed WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License Version 3 for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>
+*/
+
+
+/* Routines to statically check an Argo program before generating Verilog */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// the uses of a channel found by the channel checks. Channels passed as
+// parameters are aliased to the channel in the caller, so all the uses
+// of a channel are collected on the declared (root) channel
+type ChannelUse struct {
+	chanVar   *VariableNode     // the channel variable
+	aliases   []*VariableNode   // parameter channels bound to this channel
+	sends     []*ParseNode      // send statements on this channel
+	recvs     []*ParseNode      // receive expressions on this channel
+	closes    []*ParseNode      // calls of close on this channel
+}
+
+// a control signal of a module for the combinational loop check. A clocked
+// signal is a register set on the clock edge, so it breaks any loop through it 
+type ControlSignal struct {
+	name      string            // the Verilog name of the signal
+	cNode     *CfgNode          // the control node the signal belongs to, or nil
+	clocked   bool              // the signal is a register
+	deps      []string          // the signals the value of the signal is computed from
+}
+
+// return true if the type name is one of Go's primitive types
+func isPrimitiveTypeName(typeName string) bool {
+	rePrim := regexp.MustCompile(`^(u?int(8|16|32|64)?|uintptr|float(32|64)?|complex(64|128)|bool|byte|rune|string|integer|char|short|double)$`)
+	return rePrim.MatchString(strings.TrimSpace(typeName))
+}
+
+// get the element type of a channel variable as a source code string
+func (v *VariableNode) getChannelElemType() string {
+	var channelTypeNode, elemTypeNode *ParseNode
+
+	if (v.parseDef == nil) {
+		return ""
+	}
+	channelTypeNode = v.parseDef.walkDownToRule("channelType")
+	if (channelTypeNode == nil) {
+		return ""
+	}
+	elemTypeNode = channelTypeNode.walkDownToRule("elementType")
+	if (elemTypeNode == nil) {
+		return ""
+	}
+	return strings.TrimSpace(elemTypeNode.getSourceCode())
+}
+
+// get the name of the function enclosing a parse node
+func (node *ParseNode) getEnclosingFuncName() string {
+	var funcDecl *ParseNode
+
+	funcDecl = node.walkUpToRule("functionDecl")
+	if (funcDecl == nil) || (len(funcDecl.children) < 2) {
+		return ""
+	}
+	return funcDecl.getFuncDeclName()
+}
+
+// if an expression is only a variable name, return the name, else an empty string
+func (node *ParseNode) getPlainOperandName() string {
+	var operandNameNode *ParseNode
+
+	operandNameNode = node.walkDownToRule("operandName")
+	if (operandNameNode == nil) || (len(operandNameNode.children) == 0) {
+		return ""
+	}
+	if (strings.TrimSpace(node.getSourceCode()) != operandNameNode.children[0].ruleType) {
+		return ""
+	}
+	return operandNameNode.children[0].ruleType
+}
+
+// find the root (declared) channel for a channel which may be a parameter
+func chanRoot(aliasOf map[*VariableNode]*VariableNode, v *VariableNode) *VariableNode {
+	for {
+		parent, ok := aliasOf[v]
+		if (!ok) || (parent == v) {
+			return v
+		}
+		v = parent
+	}
+}
+
+// bind the channel arguments at every call site to the parameters of the callee.
+// The parameter channel becomes an alias of the channel in the caller
+func (l *argoListener) getChannelAliases() map[*VariableNode]*VariableNode {
+	var aliasOf map[*VariableNode]*VariableNode
+	var callerStr, calleeStr, argName string
+	var exprListNode *ParseNode
+	var funcNode *FunctionNode
+	var argVar, paramVar *VariableNode
+	var argNum int
+
+	aliasOf = make(map[*VariableNode]*VariableNode)
+	for _, argNode := range l.ParseNodeList {
+		if (argNode.ruleType != "arguments") || (argNode.parent == nil) {
+			continue
+		}
+		calleeStr = l.getCalleeName(argNode)
+		funcNode = l.getFuncNodeByNames("",calleeStr)
+		if (funcNode == nil) {
+			continue  // not a user function, e.g. fmt.Printf or make
+		}
+		exprListNode = argNode.walkDownToRule("expressionList")
+		if (exprListNode == nil) {
+			continue
+		}
+		callerStr = argNode.getEnclosingFuncName()
+
+		argNum = 0
+		for _, exprNode := range exprListNode.children {
+			if (exprNode.ruleType != "expression") {
+				continue
+			}
+			if (argNum < len(funcNode.parameters)) {
+				paramVar = funcNode.parameters[argNum]
+				argName = exprNode.getPlainOperandName()
+				argVar = l.getVarNodeByNames("",callerStr,argName)
+				if (argVar != nil) && (argVar.goLangType == "channel") && (paramVar.goLangType == "channel") {
+					aliasOf[paramVar] = argVar
+				}
+			}
+			argNum++
+		}
+	}
+	return aliasOf
+}
+
+// collect the sends, receives and closes on every channel in the program
+func (l *argoListener) getChannelUses() map[*VariableNode]*ChannelUse {
+	var aliasOf map[*VariableNode]*VariableNode
+	var chanUses map[*VariableNode]*ChannelUse
+	var chanName, funcStr string
+	var varNode, root *VariableNode
+	var use *ChannelUse
+
+	aliasOf = l.getChannelAliases()
+	chanUses = make(map[*VariableNode]*ChannelUse)
+
+	// every channel, including parameters, gets an entry on the root channel
+	for _, vNode := range l.varNodeList {
+		if (vNode.goLangType != "channel") {
+			continue
+		}
+		root = chanRoot(aliasOf,vNode)
+		use = chanUses[root]
+		if (use == nil) {
+			use = new(ChannelUse)
+			use.chanVar = root
+			chanUses[root] = use
+		}
+		if (root != vNode) {
+			use.aliases = append(use.aliases,vNode)
+		}
+	}
+
+	// find the send statements, the receive unary expressions and the calls of close
+	for _, node := range l.ParseNodeList {
+		chanName = ""
+		// a use of an element of an array of channels is a use of the array 
+		if (node.ruleType == "sendStmt") && (len(node.children) > 0) {
+			chanName, _ = getArrayElement(node.children[0].stripParens())
+		}
+		if (node.ruleType == "unaryExpr") && (len(node.children) > 1) && (node.children[0].ruleType == "<-") {
+			chanName, _ = getArrayElement(node.children[1].stripParens())
+		}
+		if (node.ruleType == "arguments") {
+			chanName = l.getCloseArg(node).getPlainOperandName()
+		}
+		if (chanName == "") {
+			continue
+		}
+
+		funcStr = node.getEnclosingFuncName()
+		varNode = l.getVarNodeByNames("",funcStr,chanName)
+		if (varNode == nil) || (varNode.goLangType != "channel") {
+			continue
+		}
+		use = chanUses[chanRoot(aliasOf,varNode)]
+		switch node.ruleType {
+		case "sendStmt":
+			use.sends = append(use.sends,node)
+		case "arguments":
+			use.closes = append(use.closes,node)
+		default:
+			use.recvs = append(use.recvs,node)
+		}
+	}
+	return chanUses
+}
+
+// count the sends and receives on a channel variable by name in the function
+// the variable is declared in 
+func (use *ChannelUse) countUsesOf(chanVar *VariableNode) (int, int) {
+	var sends, recvs int
+
+	sends = 0; recvs = 0
+	for _, sNode := range use.sends {
+		if (sNode.getEnclosingFuncName() == chanVar.funcName) && (sNode.children[0].getPlainOperandName() == chanVar.sourceName) {
+			sends++
+		}
+	}
+	for _, rNode := range use.recvs {
+		if (rNode.getEnclosingFuncName() == chanVar.funcName) && (rNode.children[1].getPlainOperandName() == chanVar.sourceName) {
+			recvs++
+		}
+	}
+	return sends, recvs
+}
+
+// static checks on channel usage:
+// every channel must have at least one sender and one receiver,
+// a channel can not be used with both struct and primitive element types,
+// a parameter channel is used in only one direction inside its function,
+// and directional channels (chan<- and <-chan) are only used in their direction
+// and a receive-only channel is not closed.
+// Returns the number of errors found
+func (l *argoListener) checkChannels() int {
+	var chanUses map[*VariableNode]*ChannelUse
+	var numErrors int
+	var rootType, aliasType string
+	var sendsIn, recvsIn int
+
+	numErrors = 0
+	chanUses = l.getChannelUses()
+
+	for _, vNode := range l.varNodeList {
+		use, ok := chanUses[vNode]
+		if (!ok) {
+			continue
+		}
+
+		// a channel nobody reads or writes deadlocks the design.
+		// Parameter channels not bound to a caller's channel can not be checked 
+		if (vNode.isParameter) {
+			Pass()
+		} else if (len(use.sends) == 0) {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"channel %s in function %s has no sender",vNode.sourceName,vNode.funcName)
+			numErrors++
+		}
+		if (!vNode.isParameter) && (len(use.recvs) == 0) {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"channel %s in function %s has no receiver",vNode.sourceName,vNode.funcName)
+			numErrors++
+		}
+
+		// the element types of all the aliases must all be structs or all primitive
+		rootType = vNode.getChannelElemType()
+		for _, alias := range use.aliases {
+			aliasType = alias.getChannelElemType()
+			if (rootType != "") && (aliasType != "") && (isPrimitiveTypeName(rootType) != isPrimitiveTypeName(aliasType)) {
+				l.reportError(alias.sourceRow,alias.sourceCol,"channel %s of type %s is bound to channel %s of type %s",
+					alias.sourceName,aliasType,vNode.sourceName,rootType)
+				numErrors++
+			}
+		}
+
+		// each parameter channel should be only sent on or only received from in its function
+		for _, alias := range use.aliases {
+			sendsIn, recvsIn = use.countUsesOf(alias)
+			if (sendsIn > 0) && (recvsIn > 0) {
+				l.reportError(alias.sourceRow,alias.sourceCol,"parameter channel %s in function %s is both sent on and received from",
+					alias.sourceName,alias.funcName)
+				numErrors++
+			}
+		}
+
+		// a send-only channel is never received from and a receive-only channel is never sent on 
+		for _, chanVar := range append([]*VariableNode{vNode},use.aliases...) {
+			sendsIn, recvsIn = use.countUsesOf(chanVar)
+			if (chanVar.chanDir == "send") && (recvsIn > 0) {
+				l.reportError(chanVar.sourceRow,chanVar.sourceCol,"send-only channel %s in function %s is received from",
+					chanVar.sourceName,chanVar.funcName)
+				numErrors++
+			}
+			if (chanVar.chanDir == "recv") && (sendsIn > 0) {
+				l.reportError(chanVar.sourceRow,chanVar.sourceCol,"receive-only channel %s in function %s is sent on",
+					chanVar.sourceName,chanVar.funcName)
+				numErrors++
+			}
+			// only the sending side may close a channel 
+			for _, cNode := range use.closes {
+				if (chanVar.chanDir == "recv") && (cNode.getEnclosingFuncName() == chanVar.funcName) &&
+					(l.getCloseArg(cNode).getPlainOperandName() == chanVar.sourceName) {
+					l.reportError(cNode.sourceLineStart,cNode.sourceColStart,"receive-only channel %s in function %s is closed",
+						chanVar.sourceName,chanVar.funcName)
+					numErrors++
+				}
+			}
+		}
+	}
+
+	return numErrors
+}
+
+/* ***************************************************** */
+// get the control signals of a module, in the order the module declares them.
+// This mirrors how the control flow, clock enable and call sections compute each
+// signal. Signals not in the map, such as the FIFO status, are module inputs or registers 
+func (l *argoListener) getControlSignals(funcName string) (map[string]*ControlSignal, []string) {
+	var signals map[string]*ControlSignal
+	var order []string
+	var addSignal func(name string, cNode *CfgNode, clocked bool, deps []string)
+	var nodes []*CfgNode
+	var deps, stallDeps []string
+	var calleeNames []string
+	var startBits map[string][]string
+	var callee *FunctionNode
+
+	signals = make(map[string]*ControlSignal)
+	addSignal = func(name string, cNode *CfgNode, clocked bool, deps []string) {
+		if sig, ok := signals[name] ; ok {
+			sig.deps = append(sig.deps,deps...)
+			return
+		}
+		signals[name] = &ControlSignal{name: name, cNode: cNode, clocked: clocked, deps: deps}
+		order = append(order,name)
+	}
+
+	// the control bits are registers, or wires decoded from the state register of a state machine 
+	nodes = moduleCfgNodes(l,funcName)
+	for i, cNode := range nodes {
+		deps = append(nodeEntryBits(cNode),"ce")
+		if (isCalledEntry(cNode)) || ((i == 0) && (funcName == "main")) {
+			deps = append(deps,"start")
+		}
+		if (l.useFSM(funcName)) {
+			addSignal("state",nil,true,deps)
+			deps = []string{"state"}
+		}
+		for _, bit := range nodeExitBits(cNode) {
+			addSignal(bit,cNode,!l.useFSM(funcName),deps)
+		}
+	}
+
+	// the clock enable is a wire of the stalled sends, receives and calls 
+	stallDeps = make([]string,0)
+	for _, cNode := range nodes {
+		for _, vNode := range append(append([]*VariableNode{},cNode.readVars...),cNode.writeVars...) {
+			if (vNode.goLangType == "channel") {
+				stallDeps = append(stallDeps,cNode.cannName)
+			}
+		}
+		_, _, bits := l.getSelectSends(cNode)
+		stallDeps = append(stallDeps,bits...)
+	}
+
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcName) && (vNode.goLangType == "array") {
+			stallDeps = append(stallDeps,l.arrayReadBits(vNode)...)
+		}
+	}
+
+	// a callee starts on a wire of the calling bits. A combinational callee is
+	// done when it is started, otherwise done is the callee's exit register 
+	calleeNames, startBits = callStartBits(l,funcName)
+	for _, calleeName := range calleeNames {
+		addSignal(calleeBusyName(calleeName),nil,true,append([]string{calleeDoneName(calleeName)},startBits[calleeName]...))
+		addSignal(calleeName + "_start",nil,false,append([]string{calleeBusyName(calleeName)},startBits[calleeName]...))
+		callee = l.getFuncNodeByNames("",calleeName)
+		if (callee != nil) && (l.genCombo) && (l.isCombinational(callee)) {
+			addSignal(calleeDoneName(calleeName),nil,false,[]string{calleeName + "_start"})
+		} else {
+			addSignal(calleeDoneName(calleeName),nil,true,nil)
+		}
+		stallDeps = append(append(stallDeps,startBits[calleeName]...),calleeDoneName(calleeName))
+	}
+
+	// a pipelined multiply is done when its counter register reaches the latency
+	for _, cNode := range l.multiplyNodes(funcName) {
+		addSignal(mulDoneName(cNode),nil,true,nil)
+		stallDeps = append(stallDeps,cNode.cannName,mulDoneName(cNode))
+	}
+	addSignal("ce",nil,false,stallDeps)
+
+	return signals, order
+}
+
+// find the loops of control signals which are not broken by a register. Each loop
+// is a control bit expression which depends on a signal set in the same cycle,
+// a combinational loop in hardware. Returns the number of loops found 
+func (l *argoListener) checkControlLoops() int {
+	var numErrors int
+	var signals map[string]*ControlSignal
+	var order []string
+	var onPath, done map[string]bool
+	var path []string
+	var findLoop func(name string) []string
+	var nodeIDs []string
+	var row, col int
+
+	numErrors = 0
+	for _, funcNode := range l.funcNodeList {
+		signals, order = l.getControlSignals(funcNode.funcName)
+
+		// a depth first search through the wires only. Registers end a path 
+		done = make(map[string]bool)
+		path = make([]string,0)
+		findLoop = func(name string) []string {
+			sig, ok := signals[name]
+			if (!ok) || (sig.clocked) || (done[name]) {
+				return nil
+			}
+			if (onPath[name]) {
+				for k, pathName := range path {
+					if (pathName == name) {
+						return append([]string{},path[k:]...)
+					}
+				}
+			}
+			onPath[name] = true
+			path = append(path,name)
+			for _, dep := range sig.deps {
+				if loop := findLoop(dep) ; loop != nil {
+					return loop
+				}
+			}
+			path = path[:len(path)-1]
+			onPath[name] = false
+			done[name] = true
+			return nil
+		}
+
+		for _, name := range order {
+			onPath = make(map[string]bool)
+			path = path[:0]
+			loop := findLoop(name)
+			if (loop == nil) {
+				continue
+			}
+			// report the loop at the first control node in it 
+			row, col = funcNode.sourceRow, funcNode.sourceCol
+			nodeIDs = make([]string,0)
+			for _, loopName := range loop {
+				if (signals[loopName].cNode != nil) {
+					if (len(nodeIDs) == 0) {
+						row, col = signals[loopName].cNode.sourceRow, signals[loopName].cNode.sourceCol
+					}
+					nodeIDs = append(nodeIDs,strconv.Itoa(signals[loopName].cNode.id))
+				}
+				done[loopName] = true
+			}
+			if (len(nodeIDs) == 0) {
+				nodeIDs = append(nodeIDs,"none")
+			}
+			l.reportError(row,col,"combinational loop in function %s through control signals %s (control nodes %s)",
+				funcNode.funcName,strings.Join(loop," -> "),strings.Join(nodeIDs,","))
+			numErrors++
+		}
+	}
+
+	return numErrors
+}
+
+/* ***************************************************** */
+// get the first channel operation a function blocks on, searching the control
+// flow graph from the function entry in breadth first order. Returns the channel
+// and control node of a receive, or nil if a send, select or no channel operation is first 
+func (l *argoListener) getFirstChannelOp(funcName string) (*VariableNode, *CfgNode) {
+	var queue []*CfgNode
+	var seen map[*CfgNode]bool
+	var cNode *CfgNode
+
+	seen = make(map[*CfgNode]bool)
+	for _, entry := range l.controlFlowGraph {
+		if (entry.cfgType == "funcEntry") && (entry.statement.funcName == funcName) {
+			queue = append(queue,entry)
+			seen[entry] = true
+		}
+	}
+	for len(queue) > 0 {
+		cNode = queue[0]
+		queue = queue[1:]
+		if (cNode.cfgType == "select") {
+			return nil, nil
+		}
+		if chanVar, _, _ := l.getChannelSend(cNode) ; chanVar != nil {
+			return nil, nil
+		}
+		if recvs, _ := l.getChannelRecvs(cNode) ; len(recvs) > 0 {
+			return recvs[0], cNode
+		}
+		for _, succ := range append(append([]*CfgNode{},cNode.successors...),cNode.successors_taken...) {
+			if (succ != nil) && (!seen[succ]) && (succ.statement.funcName == funcName) {
+				seen[succ] = true
+				queue = append(queue,succ)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// warn about calls which Go would skip by short-circuiting && or ||. Both operands
+// of a logical operator are wires evaluated every cycle, and a call in an expression
+// starts its callee when the statement runs, so in hardware the call is always made.
+// A call is to the right of the operator if it starts after the left operand ends.
+// The expression is the operator's enclosing binary expressions, up to parentheses.
+// Returns the number of warnings 
+func (l *argoListener) checkShortCircuit() int {
+	var numWarnings int
+	var top, lhs *ParseNode
+	var calleeName string
+
+	numWarnings = 0
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType != "expression") || (len(node.children) != 3) {
+			continue
+		}
+		if (node.children[1].ruleType != "&&") && (node.children[1].ruleType != "||") {
+			continue
+		}
+		lhs = node.children[0]
+		top = node
+		for (top.parent != nil) && (top.parent.ruleType == "expression") {
+			top = top.parent
+		}
+		for _, argNode := range top.walkDownToAllRules("arguments") {
+			calleeName = l.getCalleeName(argNode)
+			if (l.getFuncNodeByNames("",calleeName) == nil) {
+				continue  // not a module, e.g. a conversion or a builtin 
+			}
+			if (argNode.sourceLineStart < lhs.sourceLineEnd) ||
+				((argNode.sourceLineStart == lhs.sourceLineEnd) && (argNode.sourceColStart <= lhs.sourceColEnd)) {
+				continue
+			}
+			l.reportWarning(argNode.sourceLineStart,argNode.sourceColStart,"call of %s after %s is made even when the left operand decides the result",
+				calleeName,node.children[1].ruleType)
+			numWarnings++
+		}
+	}
+	return numWarnings
+}
+
+// get the names of the variables a simple statement writes, e.g. i for i++ or
+// i, j for i, j = j, i. The names of a short var decl are new variables 
+func (node *ParseNode) getWrittenNames() []*ParseNode {
+	var written []*ParseNode
+
+	switch node.ruleType {
+	case "incDecStmt":
+		if (len(node.children) == 2) {
+			written = append(written,node.children[0])
+		}
+	case "assignment":
+		if (len(node.children) == 3) {
+			written = append(written,node.children[0].getExpressionList()...)
+		}
+	}
+	return written
+}
+
+// warn about a for loop whose loop variable, one written by the post statement,
+// is also written in the loop body, e.g. j = snafu(j,k) in a loop counting j.
+// The write changes the number of iterations, so the generated loop may run a
+// different number of times than the for clause suggests.
+// Returns the number of warnings 
+func (l *argoListener) checkLoopVariables() int {
+	var numWarnings, numSemis int
+	var postStmt, block *ParseNode
+	var loopVars []*VariableNode
+	var funcName string
+
+	numWarnings = 0
+	for _, forClause := range l.ParseNodeList {
+		if (forClause.ruleType != "forClause") || (forClause.parent == nil) {
+			continue
+		}
+		// the post statement is after the second ; 
+		postStmt = nil
+		numSemis = 0
+		for _, child := range forClause.children {
+			if (child.ruleType == ";") {
+				numSemis++
+			} else if (child.ruleType == "simpleStmt") && (numSemis == 2) {
+				postStmt = child
+			}
+		}
+		block = nil
+		for _, child := range forClause.parent.children {
+			if (child.ruleType == "block") {
+				block = child
+			}
+		}
+		if (postStmt == nil) || (block == nil) || (len(postStmt.children) == 0) {
+			continue
+		}
+		funcName = forClause.getEnclosingFuncName()
+		loopVars = make([]*VariableNode,0)
+		for _, name := range postStmt.children[0].getWrittenNames() {
+			if vNode := l.getVarNodeInScope(funcName,name.getPlainOperandName(),name) ; vNode != nil {
+				loopVars = append(loopVars,vNode)
+			}
+		}
+
+		for _, write := range append(block.walkDownToAllRules("assignment"),block.walkDownToAllRules("incDecStmt")...) {
+			for _, name := range write.getWrittenNames() {
+				vNode := l.getVarNodeInScope(funcName,name.getPlainOperandName(),name)
+				if (vNode == nil) || (!varInList(loopVars,vNode)) {
+					continue
+				}
+				l.reportWarning(write.sourceLineStart,write.sourceColStart,"loop variable %s of the for loop at line %d is written in the loop body, which changes the number of iterations",
+					vNode.sourceName,forClause.sourceLineStart)
+				numWarnings++
+			}
+		}
+	}
+	return numWarnings
+}
+
+// the width of the value of the k-th right hand side of an assignment with n names,
+// or 0 if it is not known. A call returning several values gives the k-th result 
+func (l *argoListener) assignedWidth(rhsList []*ParseNode,k int,n int,funcName string) int {
+	var inner *ParseNode
+
+	if (len(rhsList) == 1) && (n > 1) {
+		inner = rhsList[0].stripParens()
+		if (inner.ruleType != "primaryExpr") || (len(inner.children) != 2) || (inner.children[1].ruleType != "arguments") {
+			return 0
+		}
+		if callee, ok := l.funcNameMap[l.getCalleeName(inner.children[1])] ; (ok) && (k < len(callee.retVars)) {
+			return callee.retVars[k].numBits
+		}
+		return 0
+	}
+	if (k >= len(rhsList)) {
+		return 0
+	}
+	inner = rhsList[k].stripParens()
+	if (inner.ruleType == "primaryExpr") && (len(inner.children) == 2) && (inner.children[1].ruleType == "arguments") {
+		if callee, ok := l.funcNameMap[l.getCalleeName(inner.children[1])] ; (ok) && (len(callee.retVars) == 1) {
+			return callee.retVars[0].numBits
+		}
+	}
+	bits, _ := l.exprType(rhsList[k],funcName)
+	return bits
+}
+
+// warn about an assignment which truncates its value: the value's type is wider
+// than the variable, array element or field assigned, or a constant does not fit
+// in it. Go would need a conversion, e.g. m1[1][1] = int64(m0[3]) converts an
+// element of an int array for an int64 array, and the hardware keeps the low bits.
+// Returns the number of warnings 
+func (l *argoListener) checkTruncation() int {
+	var numWarnings, destBits, srcBits int
+	var destSigned bool
+	var lhsList, rhsList []*ParseNode
+	var funcName, op string
+
+	numWarnings = 0
+	for _, assign := range l.ParseNodeList {
+		if (assign.ruleType != "assignment") || (len(assign.children) != 3) {
+			continue
+		}
+		op = assign.getAssignOp()
+		if (op == "<<") || (op == ">>") {
+			continue  // the amount of a shift is not assigned 
+		}
+		funcName = assign.getEnclosingFuncName()
+		lhsList = assign.children[0].getExpressionList()
+		rhsList = assign.children[2].getExpressionList()
+		for k, lhs := range lhsList {
+			if destBits, destSigned = l.exprType(lhs,funcName) ; destBits <= 0 {
+				continue
+			}
+			dest := strings.TrimSpace(lhs.getSourceCode())
+			// a constant must fit in the type 
+			if (k < len(rhsList)) && (len(rhsList) == len(lhsList)) {
+				if value, ok := l.evalConstExpr(rhsList[k],funcName) ; ok {
+					typeName := fmt.Sprintf("uint%d",destBits)
+					if (destSigned) {
+						typeName = fmt.Sprintf("int%d",destBits)
+					}
+					if (truncateConst(value,typeName,l.intBits) != value) {
+						l.reportWarning(assign.sourceLineStart,assign.sourceColStart,"the constant %d does not fit in the %d bits of %s",value,destBits,dest)
+						numWarnings++
+					}
+					continue
+				}
+			}
+			if srcBits = l.assignedWidth(rhsList,k,len(lhsList),funcName) ; srcBits > destBits {
+				l.reportWarning(assign.sourceLineStart,assign.sourceColStart,"a %d bit value is assigned to the %d bits of %s, which keeps the low bits. Go needs a conversion",
+					srcBits,destBits,dest)
+				numWarnings++
+			}
+		}
+	}
+	return numWarnings
+}
+
+// a conservative check for channel deadlocks. It warns about:
+// an unbuffered channel only used by one function, which has no concurrent
+// partner to complete a send, and a cycle of functions which each first wait to
+// receive from a channel only the next function in the cycle sends on.
+// Returns the number of warnings 
+func (l *argoListener) checkDeadlocks() int {
+	var aliasOf map[*VariableNode]*VariableNode
+	var chanUses map[*VariableNode]*ChannelUse
+	var funcs map[string]bool
+	var waitsOn map[string]string
+	var waitNode map[string]*CfgNode
+	var numWarnings int
+	var depth int
+	var err error
+
+	numWarnings = 0
+	aliasOf = l.getChannelAliases()
+	chanUses = l.getChannelUses()
+
+	for _, vNode := range l.varNodeList {
+		use, ok := chanUses[vNode]
+		if (!ok) || (vNode.isParameter) || (vNode.parseDef == nil) || (len(use.sends) == 0) {
+			continue
+		}
+		depth, err = l.getChannelDepth(vNode.parseDef,vNode.funcName)
+		if (err != nil) || (depth > 0) {
+			continue
+		}
+		funcs = make(map[string]bool)
+		for _, node := range append(append([]*ParseNode{},use.sends...),use.recvs...) {
+			funcs[node.getEnclosingFuncName()] = true
+		}
+		if (len(funcs) == 1) {
+			l.reportWarning(use.sends[0].sourceLineStart,use.sends[0].sourceColStart,
+				"send on unbuffered channel %s in function %s has no concurrent receiver and may deadlock",vNode.sourceName,vNode.funcName)
+			numWarnings++
+		}
+	}
+
+	// each function which first waits on a channel with a single sending function
+	// waits on that function 
+	waitsOn = make(map[string]string)
+	waitNode = make(map[string]*CfgNode)
+	for _, funcNode := range l.funcNodeList {
+		chanVar, cNode := l.getFirstChannelOp(funcNode.funcName)
+		if (chanVar == nil) {
+			continue
+		}
+		funcs = make(map[string]bool)
+		for _, sNode := range chanUses[chanRoot(aliasOf,chanVar)].sends {
+			funcs[sNode.getEnclosingFuncName()] = true
+		}
+		if (len(funcs) != 1) {
+			continue
+		}
+		for sender := range funcs {
+			waitsOn[funcNode.funcName] = sender
+		}
+		waitNode[funcNode.funcName] = cNode
+	}
+
+	// follow the waits from each function. A cycle is reported once, at the
+	// function of the cycle declared first 
+	for _, funcNode := range l.funcNodeList {
+		var cycle []string
+		var onPath map[string]bool
+
+		onPath = make(map[string]bool)
+		name := funcNode.funcName
+		for {
+			next, ok := waitsOn[name]
+			if (!ok) || (onPath[name]) {
+				break
+			}
+			onPath[name] = true
+			cycle = append(cycle,name)
+			name = next
+		}
+		if (name != funcNode.funcName) || (len(cycle) == 0) {
+			continue
+		}
+		first := true
+		for _, other := range l.funcNodeList {
+			if (onPath[other.funcName]) {
+				first = (other == funcNode)
+				break
+			}
+		}
+		if (!first) {
+			continue
+		}
+		cNode := waitNode[funcNode.funcName]
+		l.reportWarning(cNode.sourceRow,cNode.sourceCol,"functions %s each wait to receive from the next one first and may deadlock",
+			strings.Join(append(cycle,funcNode.funcName)," -> "))
+		numWarnings++
+	}
+
+	return numWarnings
+}
+
+// count the sends on and the receives from a channel in the function declaring
+// it, and in the functions it calls which are given the channel as an argument 
+func (l *argoListener) countChannelOps(vNode *VariableNode,visiting map[*VariableNode]bool) (int, int) {
+	var sends, recvs int
+	var chanName string
+
+	if (visiting[vNode]) {
+		return 0, 0
+	}
+	visiting[vNode] = true
+	for _, node := range l.ParseNodeList {
+		chanName = ""
+		if (node.ruleType == "sendStmt") && (len(node.children) > 0) {
+			chanName, _ = getArrayElement(node.children[0].stripParens())
+		}
+		if (node.ruleType == "unaryExpr") && (len(node.children) > 1) && (node.children[0].ruleType == "<-") {
+			chanName, _ = getArrayElement(node.children[1].stripParens())
+		}
+		if (chanName != vNode.sourceName) || (node.getEnclosingFuncName() != vNode.funcName) ||
+			(l.getVarNodeByNames("",vNode.funcName,chanName) != vNode) {
+			continue
+		}
+		if (node.ruleType == "sendStmt") {
+			sends++
+		} else {
+			recvs++
+		}
+	}
+	for _, chanArg := range l.getChannelArguments(vNode.funcName) {
+		if (chanArg.arg == vNode) {
+			calleeSends, calleeRecvs := l.countChannelOps(chanArg.param,visiting)
+			sends = sends + calleeSends
+			recvs = recvs + calleeRecvs
+		}
+	}
+	return sends, recvs
+}
+
+// check that the channels of the goroutines connect in the top module. Every FIFO
+// of the top module which is sent on by main or a goroutine must be received from
+// by one, and the other way around, else the read or write port of the FIFO is
+// not connected. A channel parameter of a goroutine which is never used is an
+// unconnected port of its module, which is a warning.
+// Returns the number of errors 
+func (l *argoListener) checkGoChannels() int {
+	var instances []*GoInstance
+	var fifos map[string]*VariableNode
+	var fifoNames, senders, receivers []string
+	var forwarded map[*VariableNode]bool
+	var numErrors int
+
+	numErrors = 0
+	if (!l.useHarness()) {
+		return 0
+	}
+	instances, fifos = l.getGoInstances()
+
+	// a channel passed on to another goroutine is used by that goroutine 
+	forwarded = make(map[*VariableNode]bool)
+	for _, stmt := range l.statementGraph {
+		if (stmt.stmtType == "goStmt") && (len(stmt.goTargets) > 0) && (len(stmt.cfgNodes) > 0) {
+			for _, arg := range l.goArguments(stmt) {
+				if (arg != nil) {
+					forwarded[arg] = true
+				}
+			}
+		}
+	}
+
+	for fifoName := range fifos {
+		fifoNames = append(fifoNames,fifoName)
+	}
+	sort.Strings(fifoNames)
+	for _, fifoName := range fifoNames {
+		senders = nil
+		receivers = nil
+		for _, inst := range instances {
+			for vNode, name := range inst.chans {
+				if (name != fifoName) {
+					continue
+				}
+				sends, recvs := l.countChannelOps(vNode,make(map[*VariableNode]bool))
+				if (sends > 0) {
+					senders = append(senders,inst.funcNode.funcName)
+				}
+				if (recvs > 0) {
+					receivers = append(receivers,inst.funcNode.funcName)
+				}
+				if (sends == 0) && (recvs == 0) && (vNode.isParameter) && (!forwarded[vNode]) {
+					l.reportWarning(vNode.sourceRow,vNode.sourceCol,"channel parameter %s of goroutine %s is never used, so its port is not connected",
+						vNode.sourceName,inst.funcNode.funcName)
+				}
+			}
+		}
+		vNode := fifos[fifoName]
+		if (len(senders) > 0) && (len(receivers) == 0) {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"channel %s is sent on by %s but no goroutine receives from it, so the read port of its FIFO is not connected",
+				vNode.sourceName,strings.Join(senders,", "))
+			numErrors++
+		}
+		if (len(receivers) > 0) && (len(senders) == 0) {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"channel %s is received from by %s but no goroutine sends on it, so the write port of its FIFO is not connected",
+				vNode.sourceName,strings.Join(receivers,", "))
+			numErrors++
+		}
+	}
+	return numErrors
+}
+
+// the statements a statement leads to in the statement graph: its successors, the
+// parts of an if, for, switch or select, and the entries of the functions it calls 
+func (stmt *StatementNode) stmtEdges() []*StatementNode {
+	var edges []*StatementNode
+
+	edges = append(edges,stmt.successors...)
+	edges = append(edges,stmt.child,stmt.ifSimple,stmt.ifTest,stmt.ifTaken,stmt.ifElse)
+	edges = append(edges,stmt.forInit,stmt.forCond,stmt.forPost,stmt.forBlock)
+	for _, caseStmts := range stmt.caseList {
+		edges = append(edges,caseStmts...)
+	}
+	edges = append(edges,stmt.callTargets...)
+	edges = append(edges,stmt.goTargets...)
+	return edges
+}
+
+// check pointers only name values allocated by new. Taking the address of a variable
+// would alias two registers, and a function writing through a pointer parameter
+// writes its own copy of the value, which is not written back to the caller.
+// Returns the number of errors found
+func (l *argoListener) checkPointers() int {
+	var numErrors int
+	var vNode *VariableNode
+
+	numErrors = 0
+	for _, node := range l.ParseNodeList {
+		if (node.ruleType == "unaryExpr") && (len(node.children) == 2) && (node.children[0].ruleType == "&") {
+			l.reportError(node.sourceLineStart,node.sourceColStart,"address of %s is taken, only pointers from new are supported",
+				strings.TrimSpace(node.children[1].getSourceCode()))
+			numErrors++
+		}
+	}
+
+	for _, stmt := range l.statementGraph {
+		if (stmt.stmtType != "assignment") || (stmt.parseSubDef == nil) || (len(stmt.parseSubDef.children) == 0) {
+			continue
+		}
+		// assigning the pointer itself, e.g. p = new(T), is local to the function 
+		for _, lhs := range stmt.parseSubDef.children[0].getExpressionList() {
+			if (lhs.getPlainOperandName() != "") {
+				continue
+			}
+			for _, opNode := range lhs.walkDownToAllRules("operandName") {
+				vNode = l.getVarNodeInScope(stmt.funcName,opNode.children[0].ruleType,opNode)
+				if (vNode != nil) && (vNode.isPointer) && (vNode.isParameter) {
+					l.reportError(stmt.sourceRow,stmt.sourceCol,"function %s writes through pointer parameter %s, which is not written back to the caller",
+						stmt.funcName,vNode.sourceName)
+					numErrors++
+				}
+			}
+		}
+	}
+	return numErrors
+}
+
+// check every statement is linked into the statement graph. An edge to no statement
+// is an error. A statement with no predecessor which is not the head of a block, or
+// a statement not reachable from the start node, is a warning, as it silently drops
+// out of the control flow graph. Returns the number of errors 
+func (l *argoListener) checkStatementGraph() int {
+	var numErrors int
+	var isHead, reached map[*StatementNode]bool
+	var calledFunc map[string]bool
+	var queue []*StatementNode
+	var stmt *StatementNode
+
+	numErrors = 0
+	isHead = make(map[*StatementNode]bool)
+	for _, stmt = range l.statementGraph {
+		for _, pred := range stmt.predecessors {
+			if (pred == nil) {
+				l.reportError(stmt.sourceRow,stmt.sourceCol,"statement %d (%s) has a missing predecessor",stmt.id,stmt.stmtType)
+				numErrors++
+			}
+		}
+		for _, succ := range stmt.successors {
+			if (succ == nil) {
+				l.reportError(stmt.sourceRow,stmt.sourceCol,"statement %d (%s) has a missing successor",stmt.id,stmt.stmtType)
+				numErrors++
+			}
+		}
+		// the heads of blocks and the parts of if and for statements are
+		// reached through their parent, not a predecessor 
+		for _, edge := range stmt.stmtEdges() {
+			if (edge != nil) && (!stmtInList(stmt.successors,edge)) {
+				isHead[edge] = true
+			}
+		}
+	}
+
+	for _, stmt = range l.statementGraph {
+		switch stmt.stmtType {
+		case "startNode", "functionDecl", "FuncExit":
+			continue
+		}
+		if (len(stmt.predecessors) == 0) && (!isHead[stmt]) {
+			l.reportWarning(stmt.sourceRow,stmt.sourceCol,"statement %d (%s) in function %s has no predecessor",stmt.id,stmt.stmtType,stmt.funcName)
+		}
+	}
+
+	// walk forward from the start node 
+	reached = make(map[*StatementNode]bool)
+	for _, stmt = range l.statementGraph {
+		if (stmt.stmtType == "startNode") {
+			reached[stmt] = true
+			queue = append(queue,stmt)
+		}
+	}
+	for len(queue) > 0 {
+		stmt = queue[0]
+		queue = queue[1:]
+		for _, edge := range stmt.stmtEdges() {
+			if (edge != nil) && (!reached[edge]) {
+				reached[edge] = true
+				queue = append(queue,edge)
+			}
+		}
+	}
+
+	// a function which is never called is reported by checkUnusedFunctions, so
+	// its statements are not reported as unreachable 
+	calledFunc = make(map[string]bool)
+	for _, stmt = range l.statementGraph {
+		if (stmt.stmtType == "functionDecl") {
+			calledFunc[stmt.funcName] = reached[stmt]
+		}
+	}
+	for _, stmt = range l.statementGraph {
+		if (reached[stmt]) || (stmt.stmtType == "functionDecl") {
+			continue
+		}
+		if called, ok := calledFunc[stmt.funcName] ; (ok) && (!called) {
+			continue
+		}
+		l.reportWarning(stmt.sourceRow,stmt.sourceCol,"statement %d (%s) in function %s can not be reached",stmt.id,stmt.stmtType,stmt.funcName)
+	}
+
+	return numErrors
+}
+
+// return true if the statement is in the list
+func stmtInList(list []*StatementNode, stmt *StatementNode) bool {
+	for _, node := range list {
+		if (node == stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// return true if the control node is in the list
+func cfgInList(list []*CfgNode, cNode *CfgNode) bool {
+	for _, node := range list {
+		if (node == cNode) {
+			return true
+		}
+	}
+	return false
+}
+
+// return true if the variable is in the list
+func varInList(list []*VariableNode, vNode *VariableNode) bool {
+	for _, node := range list {
+		if (node == vNode) {
+			return true
+		}
+	}
+	return false
+}
+
+// check the edges of the control flow graph are consistent. Every edge must
+// point to a node, and every successor must have the node as a predecessor.
+// Nested if and for statements which lose an edge show up here rather than
+// as a hang in the generated Verilog. A statement no control can reach
+// is only a warning, as the eos after a return or endless loop is never reached.
+// Returns the number of errors
+func (l *argoListener) checkCfgEdges() int {
+	var numErrors int
+	var reached map[*CfgNode]bool
+	var queue []*CfgNode
+	var cNode *CfgNode
+
+	numErrors = 0
+	for _, cNode = range l.controlFlowGraph {
+		for _, pred := range append(append([]*CfgNode{},cNode.predecessors...),cNode.predecessors_taken...) {
+			if (pred == nil) {
+				l.reportError(cNode.sourceRow,cNode.sourceCol,"control node %s has a missing predecessor",cNode.cannName)
+				numErrors++
+			}
+		}
+		for _, succ := range append(append([]*CfgNode{},cNode.successors...),cNode.successors_taken...) {
+			if (succ == nil) {
+				l.reportError(cNode.sourceRow,cNode.sourceCol,"control node %s has a missing successor",cNode.cannName)
+				numErrors++
+				continue
+			}
+			if (!cfgInList(succ.predecessors,cNode)) && (!cfgInList(succ.predecessors_taken,cNode)) {
+				l.reportError(cNode.sourceRow,cNode.sourceCol,"control node %s is not a predecessor of its successor %s",cNode.cannName,succ.cannName)
+				numErrors++
+			}
+		}
+	}
+
+	// walk forward from each function entry 
+	reached = make(map[*CfgNode]bool)
+	for _, cNode = range l.controlFlowGraph {
+		if (cNode.cfgType == "funcEntry") || (cNode.cfgType == "startNode") {
+			reached[cNode] = true
+			queue = append(queue,cNode)
+		}
+	}
+	for len(queue) > 0 {
+		cNode = queue[0]
+		queue = queue[1:]
+		for _, succ := range append(append([]*CfgNode{},cNode.successors...),cNode.successors_taken...) {
+			if (succ != nil) && (!reached[succ]) {
+				reached[succ] = true
+				queue = append(queue,succ)
+			}
+		}
+	}
+	for _, cNode = range l.controlFlowGraph {
+		if (reached[cNode]) || (cNode.cfgType == "eos") || (cNode.cfgType == "funcExit") || (cNode.cfgType == "finishNode") {
+			continue
+		}
+		l.reportWarning(cNode.sourceRow,cNode.sourceCol,"control node %s can not be reached",cNode.cannName)
+	}
+
+	return numErrors
+}
+
+/* ***************************************************** */
+// find the recursive calls of the call graph. A call is a module instance in the
+// module of the caller, so a function which calls itself, directly or through
+// other functions, would instantiate its own module without end. A depth first
+// search from each function reports a cycle at the call which leaves the first
+// function of the cycle on the search path. Returns the number of cycles found 
+func (l *argoListener) checkRecursion() int {
+	var numErrors int
+	var callees map[string][]*StatementNode
+	var onPath, done map[string]bool
+	var path []string
+	var pathCalls []*StatementNode
+	var findCycles func(funcName string)
+
+	callees = make(map[string][]*StatementNode)
+	for _, stmt := range l.statementGraph {
+		if (len(stmt.callTargets) > 0) {
+			callees[stmt.funcName] = append(callees[stmt.funcName],stmt)
+		}
+	}
+
+	numErrors = 0
+	onPath = make(map[string]bool)
+	done = make(map[string]bool)
+	findCycles = func(funcName string) {
+		if (done[funcName]) {
+			return
+		}
+		onPath[funcName] = true
+		path = append(path,funcName)
+		for _, stmt := range callees[funcName] {
+			for _, target := range stmt.callTargets {
+				pathCalls = append(pathCalls,stmt)
+				if (onPath[target.funcName]) {
+					// the cycle is the part of the path from the target 
+					for k, name := range path {
+						if (name != target.funcName) {
+							continue
+						}
+						cycle := append(append([]string{},path[k:]...),target.funcName)
+						call := pathCalls[k]
+						if (len(cycle) == 2) {
+							l.reportError(call.sourceRow,call.sourceCol,"function %s calls itself. Recursion can not be made into hardware",target.funcName)
+						} else {
+							l.reportError(call.sourceRow,call.sourceCol,"functions %s call each other. Recursion can not be made into hardware",
+								strings.Join(cycle," -> "))
+						}
+						numErrors++
+						break
+					}
+				} else {
+					findCycles(target.funcName)
+				}
+				pathCalls = pathCalls[:len(pathCalls)-1]
+			}
+		}
+		path = path[:len(path)-1]
+		onPath[funcName] = false
+		done[funcName] = true
+	}
+	for _, funcNode := range l.funcNodeList {
+		findCycles(funcNode.funcName)
+	}
+	return numErrors
+}
+
+// warn of the functions main does not reach, which are output as modules no one
+// instances. Returns the number of warnings 
+func (l *argoListener) checkUnusedFunctions() int {
+	var called map[string]bool
+	var numWarnings int
+
+	called = make(map[string]bool)
+	for _, edge := range l.getCallEdges() {
+		called[edge.callee] = true
+	}
+	for _, funcNode := range l.unusedFunctions() {
+		if (called[funcNode.funcName]) {
+			l.reportWarning(funcNode.sourceRow,funcNode.sourceCol,"function %s is only called from functions which are never called",funcNode.funcName)
+		} else {
+			l.reportWarning(funcNode.sourceRow,funcNode.sourceCol,"function %s is never called",funcNode.funcName)
+		}
+		numWarnings++
+	}
+	return numWarnings
+}
+
+/* ***************************************************** */
+// check the size of the memory of every array and channel against -maxmem. An
+// array is one BRAM of all its elements, and a channel a FIFO of its depth, so a
+// dimension or depth from a constant which is larger than expected would make a
+// memory too large to build. Returns the number of memories which are too large 
+func (l *argoListener) checkMemorySizes() int {
+	var numErrors int
+
+	numErrors = 0
+	if (l.maxMem == 0) {
+		return numErrors
+	}
+	for _, vNode := range l.varNodeList {
+		if ((vNode.goLangType == "array") || (vNode.goLangType == "channel")) && (len(vNode.dimensions) > 0) {
+			// stop multiplying at the limit, so a huge size can not overflow 
+			size := 1
+			for _, dim := range vNode.dimensions {
+				if (size <= l.maxMem) {
+					size = size * dim
+				}
+			}
+			if (size > l.maxMem) {
+				l.reportError(vNode.sourceRow,vNode.sourceCol,"array %s of dimensions %v has more elements than the -maxmem limit of %d",
+					vNode.sourceName,vNode.dimensions,l.maxMem)
+				numErrors++
+			}
+		}
+		if (vNode.goLangType == "channel") && (vNode.depth > l.maxMem) {
+			l.reportError(vNode.sourceRow,vNode.sourceCol,"channel %s has depth %d, more than the -maxmem limit of %d",
+				vNode.sourceName,vNode.depth,l.maxMem)
+			numErrors++
+		}
+	}
+	return numErrors
+}
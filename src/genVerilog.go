@@ -24,15 +24,35 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"strconv"
 	"regexp"
 )
 
+// an array passed by reference to a called function. The callee reads the
+// caller's memory through its ports, and writes it if the parameter is read-write 
+type ArrayArgument struct {
+	callee    *FunctionNode     // the called function
+	param     *VariableNode     // the array parameter of the callee
+	arg       *VariableNode     // the array of the caller bound to the parameter
+}
+
+// a channel passed to a called function. The callee sends, receives and closes
+// on the FIFO of the caller's channel while the caller waits for it to be done 
+type ChannelArgument struct {
+	callee    *FunctionNode     // the called function
+	param     *VariableNode     // the channel parameter of the callee
+	arg       *VariableNode     // the channel of the caller bound to the parameter
+}
+
 // output a very simple test-bench program that starts main
-// with no parameters 
+// with no parameters. A program with goroutines starts the top module 
 func OutputTestBench(parsedProgram *argoListener, max_cycles int) {
 	var out *os.File
+	var topName string
 	out = parsedProgram.outputFile
 
+	topName = topModuleName(parsedProgram)
+
 	fmt.Fprintf(out,"module generic_bench(); \n")
 
 	fmt.Fprintf(out," \t parameter MAX_CYCLES = %d; \n",max_cycles)
@@ -41,10 +61,16 @@ func OutputTestBench(parsedProgram *argoListener, max_cycles int) {
 	fmt.Fprintf(out," \t reg start;  // start the main program 	\n")
 	fmt.Fprintf(out," \t reg [31:0]  cycle_count;\n")
 	fmt.Fprintf(out," \n")	
-	fmt.Fprintf(out," \t main MAIN (\n")
+	fmt.Fprintf(out," \t %s MAIN (\n",topName)
 	fmt.Fprintf(out," \t \t .clock(clk), \n")
 	fmt.Fprintf(out," \t \t .rst(rst), \n")
-	fmt.Fprintf(out," \t \t .start(start)\n")
+	if (parsedProgram.ioInterface == "readyvalid") {
+		// the start pulse is one input transfer, and the output is always taken 
+		fmt.Fprintf(out," \t \t .in_valid(start), \n")
+		fmt.Fprintf(out," \t \t .out_ready(1'b1)\n")
+	} else {
+		fmt.Fprintf(out," \t \t .start(start)\n")
+	}
 	fmt.Fprintf(out," \t );\n")
 	fmt.Fprintf(out," \n")	
 	fmt.Fprintf(out," \t initial begin\n")
@@ -56,12 +82,22 @@ func OutputTestBench(parsedProgram *argoListener, max_cycles int) {
 	fmt.Fprintf(out," \t \t #1; \n")
 	fmt.Fprintf(out," \t \t rst = 0;  // pull reset and clock low, then let clock run \n")
 	fmt.Fprintf(out," \t \t clk = 0; \n")
-	fmt.Fprintf(out," \t \t #1; \n")
-	fmt.Fprintf(out," \t \t start = 1; // start the main function \n")
-	fmt.Fprintf(out," \t \t clk = 1; \n")	
-	fmt.Fprintf(out," \t \t #1; \n")
-	fmt.Fprintf(out," \t \t start = 0; // start the main function \n")
-	fmt.Fprintf(out," \t \t clk = 0; \n")	
+	if (parsedProgram.resetSync) {
+		// the synchronizer releases the reset of the top module two clocks later 
+		fmt.Fprintf(out," \t \t start = 0; \n")
+		fmt.Fprintf(out," \t \t repeat (2) @(posedge clk); \n")
+		fmt.Fprintf(out," \t \t @(negedge clk); \n")
+		fmt.Fprintf(out," \t \t start = 1; // start the main function \n")
+		fmt.Fprintf(out," \t \t @(negedge clk); \n")
+		fmt.Fprintf(out," \t \t start = 0; \n")
+	} else {
+		fmt.Fprintf(out," \t \t #1; \n")
+		fmt.Fprintf(out," \t \t start = 1; // start the main function \n")
+		fmt.Fprintf(out," \t \t clk = 1; \n")	
+		fmt.Fprintf(out," \t \t #1; \n")
+		fmt.Fprintf(out," \t \t start = 0; // start the main function \n")
+		fmt.Fprintf(out," \t \t clk = 0; \n")	
+	}
 	fmt.Fprintf(out," \t end // initial \n")
 	fmt.Fprintf(out," \n")	
 	fmt.Fprintf(out," \t /* clock control for the test bench */   \n")
@@ -86,6 +122,36 @@ func OutputTestBench(parsedProgram *argoListener, max_cycles int) {
 	fmt.Fprintf(out,"endmodule // generic_bench   \n")
 }
 
+/* ***************************************************** */
+// return a comment line with the Go file, row and column and the source text
+// of a parse node, e.g. // simple.go:12:2: i = i + 1
+// only the first line of multi-line statements is used 
+func sourcePosComment(parsedProgram *argoListener, pNode *ParseNode, row int, col int) string {
+	var srcText string
+	var endLine, endCol int
+	var err error 
+
+	if (pNode == nil) {
+		return fmt.Sprintf("// %s:%d:%d: \n",parsedProgram.fileName,row,col)
+	}
+
+	endLine = pNode.sourceLineEnd
+	endCol = pNode.sourceColEnd
+	// truncate multi-line statements to the end of the first line 
+	if (endLine != pNode.sourceLineStart) && (pNode.sourceLineStart > 0) && (pNode.sourceLineStart <= len(parsedProgram.ProgramLines)) {
+		endLine = pNode.sourceLineStart
+		endCol = len(parsedProgram.ProgramLines[endLine-1]) - 1 
+	}
+	
+	srcText, err = rowscols2String(parsedProgram.ProgramLines,pNode.sourceLineStart,pNode.sourceColStart,endLine,endCol)
+	if (err != nil) {
+		srcText = pNode.getSourceCode()
+	}
+	srcText = strings.TrimSpace(srcText)
+
+	return fmt.Sprintf("// %s:%d:%d: %s \n",parsedProgram.fileName,row,col,srcText)
+}
+
 /* ***************************************************** */
 func OutputVariables(parsedProgram *argoListener,funcName string) {
 
@@ -101,7 +167,7 @@ func OutputVariables(parsedProgram *argoListener,funcName string) {
 	// FIXME: add count by function module name
 	numVnodes = 0
 	for _, vNode := range(parsedProgram.varNodeList) {
-		if (vNode.funcName == funcName) {
+		if (parsedProgram.moduleFuncName(vNode.funcName) == funcName) {
 			numVnodes ++ ;
 		}
 	}
@@ -111,22 +177,30 @@ func OutputVariables(parsedProgram *argoListener,funcName string) {
 	
 	for _, vNode := range(parsedProgram.varNodeList) {
 
-		// only print out variables names that match the current function 
-		if (vNode.funcName == funcName) { 
+		// only print out variables names that match the current function.
+		// The receiver of a method is an input port 
+		if (parsedProgram.moduleFuncName(vNode.funcName) == funcName) && (!vNode.isReceiver) && (vNode.sharedWith == nil) { 
 			if vNode.goLangType == "numeric" {
-				fmt.Fprintf(out," \t reg signed [%d:0] %s ; \n", vNode.numBits-1, vNode.sourceName)
+				fmt.Fprintf(out," \t reg %s[%d:0] %s ; \n", verilogSigned(vNode), vNode.numBits-1, verilogVarName(vNode))
 			} else if vNode.primType == "array" {
 			
 			}
 		}
 	}
 	fmt.Fprintf(out,"// --- Control Bits ---- \n")
-	fmt.Fprintf(out," \t reg [63:0] cycle_count ; \n")
+	if (parsedProgram.useCycleCounter()) {
+		fmt.Fprintf(out," \t reg [63:0] cycle_count ; \n")
+	}
 
+	// the control bits are decoded from the state register of a state machine 
+	if (parsedProgram.useFSM(funcName)) {
+		OutputStateRegister(parsedProgram,funcName)
+		return
+	}
 	
 	l := len(parsedProgram.controlFlowGraph)
 	if ( l == 0 ) {
-		fmt.Printf("Error: zero control flow nodes at line %d %s \n", l, _file_line_())
+		parsedProgram.reportError(0,0,"zero control flow nodes for function %s",funcName)
 		return ;
 	}
 
@@ -134,12 +208,15 @@ func OutputVariables(parsedProgram *argoListener,funcName string) {
 	fmt.Fprintf(out," \t reg %s ; \n",parsedProgram.controlFlowGraph[0].cannName)
 	for _, cNode := range(parsedProgram.controlFlowGraph) {
 
-		if (cNode.statement.funcName == funcName) { 
-			if ( (len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) >0) ) {
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) == funcName) { 
+			if ( (len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) >0) || (isCalledEntry(cNode)) ) {
 				fmt.Fprintf(out," \t reg %s ; \n",cNode.cannName)
 				if  (len(cNode.successors_taken) > 0) {
 					fmt.Fprintf(out," \t reg %s ; \n",cNode.cannName + "_taken" )				
 				}
+				for k := range cNode.caseComms {
+					fmt.Fprintf(out," \t reg %s ; \n",selectCaseName(cNode,k))
+				}
 			}
 		}
 	}
@@ -147,242 +224,2900 @@ func OutputVariables(parsedProgram *argoListener,funcName string) {
 }
 
 /* ***************************************************** */
-// ouput the initialization section for simulation 
-func OutputInitialization(parsedProgram *argoListener,funcName string) {
-	var out *os.File
-	out = parsedProgram.outputFile
-	
-	fmt.Fprintf(out,"// -------- Initialization Section  ---------- \n")
-	fmt.Fprintf(out,"initial begin \n")
-	fmt.Fprintf(out," \t clock = 0 ; \n ")
-	fmt.Fprintf(out," \t rst = 0 ; \n ")
-	fmt.Fprintf(out," \t cycle_count = 0 ; \n")
-	fmt.Fprintf(out," \t %s = 1 ; \n",parsedProgram.controlFlowGraph[0].cannName)
-	fmt.Fprintf(out,"end \n")
+// the prefix of the localparam names for a variable, derived from the cannonical name 
+func verilogParamPrefix(vNode *VariableNode) string {
+	return strings.ToUpper(vNode.canName)
 }
 
-/* ***************************************************** */
-// ouput the I/O section for simulation
-// right now just change the printfs to $display statements 
-func OutputIO(parsedProgram *argoListener,funcName string) {
+// the number of address bits needed to index a memory of a given depth 
+func addrWidth(depth int) int {
+	var width int
+
+	width = 1
+	for (1 << uint(width)) < depth {
+		width++
+	}
+	return width 
+}
+
+// the data width of a channel or array element; unknown widths default to 32 bits 
+func dataWidth(vNode *VariableNode) int {
+	if (vNode.numBits <= 0) {
+		return 32
+	}
+	return vNode.numBits
+}
+
+// output the channel and array section. Each channel declared in the function is an
+// argo_fifo and each array a d_p_ram BRAM. The depths and widths are localparams
+// derived from the variable, so a single parameterized module is instantiated with
+// different parameters. Channel parameters are ports, not instances 
+func OutputChannels(parsedProgram *argoListener,funcName string) {
 	var out *os.File
-	var stmt *StatementNode
-	var pNode *ParseNode
-	var sourceCode string
-	var numCnodes int
+	var prefix, name string
+	var size int
 	
 	out = parsedProgram.outputFile
-	
-	fmt.Fprintf(out,"// -------- I/O Section  ---------- \n")
+	fmt.Fprintf(out,"// -------- Channel and Array Section  ----------\n")
 
+	for _, vNode := range(parsedProgram.varNodeList) {
+		if (vNode.funcName != funcName) {
+			continue
+		}
+		// an array or channel parameter is in the caller, and a channel passed to
+		// a goroutine in the top module. Both are reached through the ports 
+		if (vNode.isParameter) || (parsedProgram.isHoistedChannel(vNode)) {
+			if (vNode.goLangType == "array") {
+				fmt.Fprintf(out," \t reg %s ; \n",arrayReadValidName(vNode))
+			}
+			if (vNode.goLangType == "channel") && (vNode.chanDir != "send") {
+				fmt.Fprintf(out," \t reg %s ; \n",chanReadValidName(vNode))
+			}
+			continue
+		}
+		prefix = verilogParamPrefix(vNode)
+		name = vNode.sourceName
 
-	// count the number of printf nodes, if zero, do not output anything
-	// FIXME: add count by function module name
-	numCnodes = 0
-	for _, cNode := range(parsedProgram.controlFlowGraph) {
-		if (cNode.statement.funcName == funcName) {
-			if (cNode.cfgType == "expression" ) {
-				stmt = cNode.statement
-				pNode = stmt.parseDef
-				sourceCode = pNode.sourceCode
-				if strings.Contains(sourceCode,"fmt.Printf") {
-					numCnodes ++ ;
-				}
+		if (vNode.goLangType == "channel") && (vNode.numDim > 0) {
+			OutputFifoBank(out,prefix,name,vNode)
+			fmt.Fprintf(out," \t reg %s ; \n",chanReadValidName(vNode))
+		} else if (vNode.goLangType == "channel") {
+			OutputFifo(out,prefix,name,vNode)
+			fmt.Fprintf(out," \t reg %s ; \n",chanReadValidName(vNode))
+			if (parsedProgram.isClosedChannel(vNode)) {
+				fmt.Fprintf(out," \t wire %s ; \n",chanCloseName(vNode))
+				fmt.Fprintf(out," \t reg %s ; \n",chanClosedName(vNode))
 			}
 		}
-	}
-	if (numCnodes == 0) {
-		return; 
-	}
-	
-	fmt.Fprintf(out,"always @(posedge clock) begin \n")
-	
-	for _, cNode := range(parsedProgram.controlFlowGraph) {
 
-		if (cNode.statement.funcName == funcName) { 
-			if (cNode.cfgType == "expression" ) {
-				stmt = cNode.statement
-				pNode = stmt.parseDef
-				sourceCode = pNode.sourceCode
-				if strings.Contains(sourceCode,"fmt.Printf") {
-					exp := regexp.MustCompile(`\(.*\)`)
-					innerExp := exp.FindString(sourceCode)
-					displayStr := "$write" + innerExp + "; "
-					fmt.Fprintf(out," \t if (%s == 1) begin \n",cNode.cannName)
-					fmt.Fprintf(out," \t \t %s \n",displayStr)
-					fmt.Fprintf(out," \t end \n")
-				}
+		if (vNode.goLangType == "array") {
+			size = arraySize(vNode)
+			fmt.Fprintf(out," \t localparam %s_DATA_WIDTH = %d ; \n",prefix,dataWidth(vNode))
+			fmt.Fprintf(out," \t localparam %s_ADDR_WIDTH = %d ; \n",prefix,addrWidth(size))
+			fmt.Fprintf(out," \t localparam %s_SIZE = %d ; \n",prefix,size)
+			fmt.Fprintf(out," \t reg %s_write_en ; \n",name)
+			fmt.Fprintf(out," \t reg [%s_ADDR_WIDTH-1:0] %s_write_addr ; \n",prefix,name)
+			fmt.Fprintf(out," \t wire [%s_ADDR_WIDTH-1:0] %s_read_addr ; \n",prefix,name)
+			fmt.Fprintf(out," \t reg [%s_DATA_WIDTH-1:0] %s_input_data ; \n",prefix,name)
+			fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s_output_data ; \n",prefix,name)
+			fmt.Fprintf(out," \t reg %s ; \n",arrayReadValidName(vNode))
+			if (parsedProgram.initArrays) {
+				fmt.Fprintf(out," \t reg %s ; \n",arrayClearingName(vNode))
+				fmt.Fprintf(out," \t reg [%s_ADDR_WIDTH-1:0] %s_clear_addr ; \n",prefix,name)
 			}
+			// the memory ports are driven by the module, or by a callee given the array 
+			fmt.Fprintf(out," \t wire %s ; \n",arrayMemWireName(vNode,"write_en"))
+			fmt.Fprintf(out," \t wire [%s_ADDR_WIDTH-1:0] %s ; \n",prefix,arrayMemWireName(vNode,"write_addr"))
+			fmt.Fprintf(out," \t wire [%s_ADDR_WIDTH-1:0] %s ; \n",prefix,arrayMemWireName(vNode,"read_addr"))
+			fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s ; \n",prefix,arrayMemWireName(vNode,"input_data"))
+			fmt.Fprintf(out," \t %sd_p_ram #(.ADDR_WIDTH(%s_ADDR_WIDTH),.DATA_WIDTH(%s_DATA_WIDTH),.DEPTH(%s_SIZE)) %s_BRAM ( \n",
+				parsedProgram.ramStyleAttribute(vNode),prefix,prefix,prefix,prefix)
+			fmt.Fprintf(out," \t \t .clock(clock), .write_en(%s), \n",arrayMemWireName(vNode,"write_en"))
+			fmt.Fprintf(out," \t \t .write_addr(%s), .read_addr(%s), \n",arrayMemWireName(vNode,"write_addr"),arrayMemWireName(vNode,"read_addr"))
+			fmt.Fprintf(out," \t \t .input_data(%s), .output_data(%s_output_data) \n",arrayMemWireName(vNode,"input_data"),name)
+			fmt.Fprintf(out," \t ); \n")
 		}
 	}
-	fmt.Fprintf(out,"end \n")
+}
 
-	
+// output the argo_fifo of a channel and the wires of its read and write sides.
+// The wires are named after name, and the localparams after prefix 
+func OutputFifo(out *os.File,prefix string,name string,vNode *VariableNode) {
+	fmt.Fprintf(out," \t localparam %s_DATA_WIDTH = %d ; \n",prefix,dataWidth(vNode))
+	fmt.Fprintf(out," \t localparam %s_ADDR_WIDTH = %d ; \n",prefix,addrWidth(vNode.depth))
+	fmt.Fprintf(out," \t localparam %s_DEPTH = %d ; \n",prefix,vNode.depth)
+	fmt.Fprintf(out," \t wire %s_rd_en ; \n",name)
+	fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s_rd_data ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire %s_wr_en ; \n",name)
+	fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s_wr_data ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire %s_full ; \n",name)
+	fmt.Fprintf(out," \t wire %s_empty ; \n",name)
+	fmt.Fprintf(out," \t argo_fifo #(.ADDR_WIDTH(%s_ADDR_WIDTH),.DATA_WIDTH(%s_DATA_WIDTH),.DEPTH(%s_DEPTH),.FIFO_ID(%d)) %s_FIFO ( \n",
+		prefix,prefix,prefix,vNode.id,prefix)
+	fmt.Fprintf(out," \t \t .clk(clock), .rst(rst), \n")
+	fmt.Fprintf(out," \t \t .rd_en(%s_rd_en), .rd_data(%s_rd_data), \n",name,name)
+	fmt.Fprintf(out," \t \t .wr_en(%s_wr_en), .wr_data(%s_wr_data), \n",name,name)
+	fmt.Fprintf(out," \t \t .full(%s_full), .empty(%s_empty) \n",name,name)
+	fmt.Fprintf(out," \t ); \n")
 }
 
-/* ***************************************************** */
-// ouput the data flow section 
-func OutputDataflow(parsedProgram *argoListener,funcName string) {
-	var out *os.File
-	var sMainNode,sSubNode,sNode *StatementNode
-	var pNode *ParseNode
-	var sourceCode string
-	var debugFlags uint64 
-	var DBG_CONTROL_MASK uint64
-	
-	out = parsedProgram.outputFile
+// output the bank of argo_fifos of an array of channels, one for each element. The
+// read and write enables, full and empty of the elements are bit vectors and the
+// read data a wire array, all indexed by the element. The elements share the write
+// data, as only the enabled FIFO takes it 
+func OutputFifoBank(out *os.File,prefix string,name string,vNode *VariableNode) {
+	fmt.Fprintf(out," \t localparam %s_DATA_WIDTH = %d ; \n",prefix,dataWidth(vNode))
+	fmt.Fprintf(out," \t localparam %s_ADDR_WIDTH = %d ; \n",prefix,addrWidth(vNode.depth))
+	fmt.Fprintf(out," \t localparam %s_DEPTH = %d ; \n",prefix,vNode.depth)
+	fmt.Fprintf(out," \t localparam %s_SIZE = %d ; \n",prefix,arraySize(vNode))
+	fmt.Fprintf(out," \t wire [%s_SIZE-1:0] %s_rd_en ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s_rd_data [0:%s_SIZE-1] ; \n",prefix,name,prefix)
+	fmt.Fprintf(out," \t wire [%s_SIZE-1:0] %s_wr_en ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire [%s_DATA_WIDTH-1:0] %s_wr_data ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire [%s_SIZE-1:0] %s_full ; \n",prefix,name)
+	fmt.Fprintf(out," \t wire [%s_SIZE-1:0] %s_empty ; \n",prefix,name)
+	for k := 0; k < arraySize(vNode); k++ {
+		fmt.Fprintf(out," \t argo_fifo #(.ADDR_WIDTH(%s_ADDR_WIDTH),.DATA_WIDTH(%s_DATA_WIDTH),.DEPTH(%s_DEPTH),.FIFO_ID(%d)) %s_FIFO_%d ( \n",
+			prefix,prefix,prefix,vNode.id,prefix,k)
+		fmt.Fprintf(out," \t \t .clk(clock), .rst(rst), \n")
+		fmt.Fprintf(out," \t \t .rd_en(%s_rd_en[%d]), .rd_data(%s_rd_data[%d]), \n",name,k,name,k)
+		fmt.Fprintf(out," \t \t .wr_en(%s_wr_en[%d]), .wr_data(%s_wr_data), \n",name,k,name)
+		fmt.Fprintf(out," \t \t .full(%s_full[%d]), .empty(%s_empty[%d]) \n",name,k,name,k)
+		fmt.Fprintf(out," \t ); \n")
+	}
+}
 
-	debugFlags = parsedProgram.debugFlags
-	DBG_CONTROL_MASK = 0x1
-	
-	fmt.Fprintf(out,"// -------- Data Flow Section  ---------- \n")
-	for _, vNode := range(parsedProgram.varNodeList) {
+// the largest array, in bits, which -memstyle auto puts in distributed RAM 
+const DISTRAMBITS = 4096
 
+// the synthesis attribute of the memory of an array for -memstyle, e.g.
+// (* ram_style = "block" *), or empty. With auto a small array is distributed RAM
+// built from LUTs and a large one block RAM. The attribute is on the instance, so
+// it applies to the memory of the d_p_ram 
+func (l *argoListener) ramStyleAttribute(vNode *VariableNode) string {
+	var style string
 
-		if (vNode.funcName == funcName) { 
-		
-			fmt.Fprintf(out,"always @(posedge clock) begin // dataflow for variable %s \n", vNode.sourceName)
-			fmt.Fprintf(out,"\t if `RESET begin \n ")
-			fmt.Fprintf(out,"\t \t %s <= 0 ;  \n ",vNode.sourceName )
-			fmt.Fprintf(out," \t end \n")
-			fmt.Fprintf(out," \t else begin \n")			
-			for i, cNode := range vNode.cfgNodes {
-				sMainNode = cNode.statement
-				sSubNode = cNode.subStmt 
-				// if a cfg node has a sub-node, it is an if or for conditional/post 
-				if (sSubNode != nil) {
-					sNode = sSubNode 				
-				} else {
-					sNode = sMainNode 
-				}
-				pNode = sNode.parseDef 
-				sourceCode = pNode.sourceCode
+	style = l.memStyle
+	if (style == "auto") {
+		style = "block"
+		if (arraySize(vNode) * dataWidth(vNode) <= DISTRAMBITS) {
+			style = "distributed"
+		}
+	}
+	if (style == "") {
+		return ""
+	}
+	return "(* ram_style = \"" + style + "\" *) "
+}
 
-				// Fixme: Need to parse the expression and get the readvars
+/* ***************************************************** */
+// the number of elements of an array. Multi-dimensional arrays are flattened
+// into one memory 
+func arraySize(vNode *VariableNode) int {
+	var size int
 
-				sourceCode = expressionToString(pNode)
-				
-				sourceCode = strings.Replace(sourceCode,"=","<=",1)
+	size = 1
+	for _, dim := range vNode.dimensions {
+		size = size * dim
+	}
+	return size
+}
 
-				
-				if i == 0 {
-					fmt.Fprintf(out," \t \t if ( %s == 1 ) begin \n", cNode.cannName);
-				} else {
-					fmt.Fprintf(out," if ( %s == 1 ) begin \n", cNode.cannName);
-				}
-				fmt.Fprintf(out," \t \t \t %s ; \n", sourceCode)
+// the register which is set when the data read from an array is valid 
+func arrayReadValidName(vNode *VariableNode) string {
+	return vNode.sourceName + "_rd_valid"
+}
 
-				if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
-					fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, dataflow %%s \",cycle_count,`__FILE__,`__LINE__,\"" + sourceCode + "\" ) ; \n") ;
-				}
-			
-				fmt.Fprintf(out," \t \t end \n")
-				fmt.Fprintf(out," \t \t else ")
-				
-			}
-		
-			fmt.Fprintf(out," begin \n" )
-			fmt.Fprintf(out," \t \t \t %s <= %s ; \n", vNode.sourceName,vNode.sourceName);
-			fmt.Fprintf(out," \t \t end \n")
-			fmt.Fprintf(out," \t end \n")		
-			fmt.Fprintf(out,"end \n")
-		
+// the register which is set while the memory of an array is cleared after reset 
+func arrayClearingName(vNode *VariableNode) string {
+	return vNode.sourceName + "_clearing"
+}
+
+// the local arrays of a function which are cleared after reset. Arrays are
+// only cleared with -initarrays. An array parameter is cleared by its owner 
+func (l *argoListener) clearedArrays(funcName string) []*VariableNode {
+	var arrays []*VariableNode
+
+	if (!l.initArrays) {
+		return nil
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcName) && (vNode.goLangType == "array") && (!vNode.isParameter) {
+			arrays = append(arrays,vNode)
 		}
 	}
+	return arrays
 }
 
-/* ***************************************************** */
-// Ouput the control flow section 
-func OutputControlFlow(parsedProgram *argoListener,funcName string) {
+// the start signal of a module. A start while the arrays are being cleared is
+// held until the module runs 
+func (l *argoListener) startSignal(funcName string) string {
+	if (len(l.clearedArrays(funcName)) > 0) {
+		return "( start | start_held )"
+	}
+	return "start"
+}
+
+// return true if an array parameter is read-write. A function which writes any
+// element of an array parameter gets the write ports of the caller's memory,
+// otherwise the parameter is read-only and only has the read ports 
+func isReadWriteArray(vNode *VariableNode) bool {
+	return (vNode.isParameter) && (len(vNode.cfgNodes) > 0)
+}
+
+// the port names of an array parameter, without the array name 
+func arrayPortNames(vNode *VariableNode) []string {
+	var ports []string
+
+	ports = append(ports,"read_addr","output_data")
+	if (isReadWriteArray(vNode)) {
+		ports = append(ports,"write_en","write_addr","input_data")
+	}
+	return ports
+}
+
+// output the port declarations for an array parameter. The data read from
+// the memory is an input, the address and write side are outputs 
+func OutputArrayPorts(parsedProgram *argoListener,vNode *VariableNode) {
 	var out *os.File
-	var entryClauses []string
-	var allClauses string
-	var cName string
-	var stmtNode,testNode *StatementNode
-	var pNode  *ParseNode 
-	var condition string
-	var debugFlags uint64 
-	var DBG_CONTROL_MASK uint64 
-	
+	var addrBits, dataBits int
+
 	out = parsedProgram.outputFile
-	debugFlags = parsedProgram.debugFlags
-	DBG_CONTROL_MASK = 0x1
-	
-	fmt.Fprintf(out, "// -------- Control Flow Section  ---------- \n")
+	addrBits = addrWidth(arraySize(vNode))
+	dataBits = dataWidth(vNode)
+	fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "[%d:0] %s_read_addr;  // read side of array %s \n",addrBits-1,vNode.sourceName,vNode.sourceName)
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "[%d:0] %s_output_data; \n",dataBits-1,vNode.sourceName)
+	if (isReadWriteArray(vNode)) {
+		fmt.Fprintf(out,"\t output reg %s_write_en;  // write side of array %s \n",vNode.sourceName,vNode.sourceName)
+		fmt.Fprintf(out,"\t output reg [%d:0] %s_write_addr; \n",addrBits-1,vNode.sourceName)
+		fmt.Fprintf(out,"\t output reg [%d:0] %s_input_data; \n",dataBits-1,vNode.sourceName)
+	}
+}
 
-	for i, cNode := range(parsedProgram.controlFlowGraph) {
+// the wire driving a port of the memory of a local array 
+func arrayMemWireName(vNode *VariableNode,port string) string {
+	return vNode.sourceName + "_mem_" + port
+}
 
-		// The start node gets its own clause 
-		if (i == 0 ) && (funcName == "main") {
-			fmt.Fprintf(out,"\t always @(posedge clock) begin // control for %s \n",cNode.cannName)
-			fmt.Fprintf(out,"\t \t if `RESET begin \n ")
-			fmt.Fprintf(out,"\t \t \t %s <= 0 ; \n ", cNode.cannName)
-			fmt.Fprintf(out,"\t \t end \n ")
-			fmt.Fprintf(out,"\t \t else if (start == 1) begin \n ")
-			fmt.Fprintf(out,"\t \t \t " + cNode.cannName + " <=  1 ; \n")
-			fmt.Fprintf(out,"\t \t end \n ")						
-			fmt.Fprintf(out,"\t \t else begin\n ")			
-			fmt.Fprintf(out,"\t \t \t "  + cNode.cannName + " <=  0 ; \n")
-			fmt.Fprintf(out,"\t \t end \n ")
-			fmt.Fprintf(out,"\t end \n ")				
+// the wire in the caller connected to a port of an array parameter of a callee 
+func arrayArgWireName(arrayArg *ArrayArgument,port string) string {
+	return arrayArg.callee.funcName + "_" + arrayArg.param.sourceName + "_" + port
+}
+
+// bind the array arguments of the calls made by a function to the parameters of
+// the callees. A callee module has one instance, so every call must pass it the
+// same array, and an array parameter can not be passed on to another function 
+func (l *argoListener) getArrayArguments(funcName string) []*ArrayArgument {
+	var arrayArgs []*ArrayArgument
+	var bound map[*VariableNode]*ArrayArgument
+	var exprListNode *ParseNode
+	var funcNode *FunctionNode
+	var argVar, paramVar *VariableNode
+	var argNum int
+
+	bound = make(map[*VariableNode]*ArrayArgument)
+	for _, argNode := range l.ParseNodeList {
+		if (argNode.ruleType != "arguments") || (argNode.parent == nil) {
+			continue
+		}
+		funcNode = l.getFuncNodeByNames("",l.getCalleeName(argNode))
+		exprListNode = argNode.walkDownToRule("expressionList")
+		if (funcNode == nil) || (funcNode.funcName == funcName) || (exprListNode == nil) {
+			continue
+		}
+		if (argNode.getEnclosingFuncName() != funcName) {
 			continue
 		}
-		
-		if (cNode.statement.funcName == funcName) {
-			entryClauses = make([]string,0) 
-			allClauses = ""
-			cName = cNode.cannName 
-			// if there must be predecessors for the control node to be reachable 
-			if  ( len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) > 0) {
 
-				// eos nodes from break/continue statements do not have a predecessor
-				if ( len(cNode.predecessors) > 0 )  {
-					if (len(cNode.predecessors_taken) == 0) {
-						if (cNode.predecessors[0] == nil) {
-							continue 
-						}
-					}
+		argNum = 0
+		for _, exprNode := range exprListNode.children {
+			if (exprNode.ruleType != "expression") {
+				continue
+			}
+			if (argNum >= len(funcNode.parameters)) {
+				break
+			}
+			paramVar = funcNode.parameters[argNum]
+			argNum++
+			if (paramVar.goLangType != "array") {
+				continue
+			}
+			argVar = l.getVarNodeByNames("",funcName,exprNode.getPlainOperandName())
+			if (argVar == nil) || (argVar.goLangType != "array") {
+				l.reportError(exprNode.sourceLineStart,exprNode.sourceColStart,"argument %s of %s is not an array",
+					strings.TrimSpace(exprNode.getSourceCode()),funcNode.funcName)
+				continue
+			}
+			if (argVar.isParameter) {
+				l.reportError(exprNode.sourceLineStart,exprNode.sourceColStart,"array parameter %s can not be passed on to %s",
+					argVar.sourceName,funcNode.funcName)
+				continue
+			}
+			if prev, ok := bound[paramVar] ; ok {
+				if (prev.arg != argVar) {
+					l.reportError(exprNode.sourceLineStart,exprNode.sourceColStart,"%s is called with arrays %s and %s for parameter %s",
+						funcNode.funcName,prev.arg.sourceName,argVar.sourceName,paramVar.sourceName)
 				}
+				continue
+			}
+			bound[paramVar] = &ArrayArgument{callee: funcNode, param: paramVar, arg: argVar}
+			arrayArgs = append(arrayArgs,bound[paramVar])
+		}
+	}
+	return arrayArgs
+}
 
-				fmt.Fprintf(out,"always @(posedge clock) begin // control for %s \n",cNode.cannName)	
+// bind the channel arguments of the calls made by a function to the parameters
+// of the callees. A callee module has one instance, so every call must pass it
+// the same channel. The channels of a go statement are bound in the top module 
+func (l *argoListener) getChannelArguments(funcName string) []*ChannelArgument {
+	var chanArgs []*ChannelArgument
+	var bound map[*VariableNode]*ChannelArgument
+	var exprListNode *ParseNode
+	var funcNode *FunctionNode
+	var argVar, paramVar *VariableNode
+	var argNum int
 
-				fmt.Fprintf(out,"\t if `RESET begin \n ")
-			
-				fmt.Fprintf(out,"\t \t %s <= 0 ; \n ", cNode.cannName)
+	bound = make(map[*VariableNode]*ChannelArgument)
+	for _, argNode := range l.ParseNodeList {
+		if (argNode.ruleType != "arguments") || (argNode.parent == nil) || (argNode.getEnclosingFuncName() != funcName) {
+			continue
+		}
+		funcNode = l.getFuncNodeByNames("",l.getCalleeName(argNode))
+		exprListNode = argNode.walkDownToRule("expressionList")
+		if (funcNode == nil) || (funcNode.funcName == funcName) || (exprListNode == nil) || (argNode.walkUpToRule("goStmt") != nil) {
+			continue
+		}
 
-				if (cNode.cfgType == "ifTest") || (cNode.cfgType == "forCond" ) {
-					fmt.Fprintf(out,"\t \t %s <= 0 ; \n ", cNode.cannName + "_taken" )
-				}
-				
-				fmt.Fprintf(out,"\t end else begin \n ")
-			
-				for _, pred := range cNode.predecessors {
-					entryClauses = append(entryClauses,"( " + pred.cannName + " == 1 )" )
-				}
-			
-				for _, p_taken := range cNode.predecessors_taken {
-					entryClauses = append(entryClauses,"( " + p_taken.cannName + "_taken == 1 )") 
-				}
-				
-				
-				last := len(entryClauses)-1
-				for j, clause := range entryClauses  {
-					allClauses = allClauses + clause
-					if  ( j < last )  {
-						allClauses = allClauses + " || "
-					}
+		argNum = 0
+		for _, exprNode := range exprListNode.children {
+			if (exprNode.ruleType != "expression") {
+				continue
+			}
+			if (argNum >= len(funcNode.parameters)) {
+				break
+			}
+			paramVar = funcNode.parameters[argNum]
+			argNum++
+			if (paramVar.goLangType != "channel") {
+				continue
+			}
+			argVar = l.getVarNodeInScope(funcName,exprNode.getPlainOperandName(),exprNode)
+			if (argVar == nil) || (argVar.goLangType != "channel") || (argVar.numDim > 0) {
+				l.reportError(exprNode.sourceLineStart,exprNode.sourceColStart,"argument %s of %s is not a channel",
+					strings.TrimSpace(exprNode.getSourceCode()),funcNode.funcName)
+				continue
+			}
+			if prev, ok := bound[paramVar] ; ok {
+				if (prev.arg != argVar) {
+					l.reportError(exprNode.sourceLineStart,exprNode.sourceColStart,"%s is called with channels %s and %s for parameter %s",
+						funcNode.funcName,prev.arg.sourceName,argVar.sourceName,paramVar.sourceName)
 				}
+				continue
+			}
+			bound[paramVar] = &ChannelArgument{callee: funcNode, param: paramVar, arg: argVar}
+			chanArgs = append(chanArgs,bound[paramVar])
+		}
+	}
+	return chanArgs
+}
 
-				fmt.Fprintf(out," \t \t if ( " + allClauses +  " ) begin \n")
-				
-				switch cNode.cfgType { 
-				case "ifTest":
-					stmtNode = cNode.statement
-					testNode = stmtNode.ifTest
-					pNode = testNode.parseDef
-					condition = pNode.sourceCode
-				
-					fmt.Fprintf(out," \t \t \t if %s begin \n ",condition)
+// the wire in the caller connected to an output port of a channel parameter of a
+// callee, e.g. the write enable 
+func chanArgWireName(chanArg *ChannelArgument,signal string) string {
+	return chanArg.callee.funcName + "_" + chanArg.param.sourceName + "_" + signal
+}
+
+// the signal driving a port of the memory of a local array. The callees given the
+// array drive the read port, and the write port if read-write, while they are busy 
+func (l *argoListener) arrayMemoryPort(vNode *VariableNode,port string) string {
+	var driver string
+
+	driver = vNode.sourceName + "_" + port
+	for _, arrayArg := range l.getArrayArguments(vNode.funcName) {
+		if (arrayArg.arg != vNode) {
+			continue
+		}
+		if (port != "read_addr") && (!isReadWriteArray(arrayArg.param)) {
+			continue
+		}
+		driver = calleeBusyName(arrayArg.callee.funcName) + " ? " + arrayArgWireName(arrayArg,port) + " : " + driver
+	}
+	// clearing the memory after reset writes a zero to each address in turn 
+	if (l.initArrays) {
+		switch port {
+		case "write_en":
+			driver = arrayClearingName(vNode) + " | ( " + driver + " )"
+		case "write_addr":
+			driver = arrayClearingName(vNode) + " ? " + vNode.sourceName + "_clear_addr : " + driver
+		case "input_data":
+			driver = arrayClearingName(vNode) + " ? 0 : " + driver
+		}
+	}
+	return driver
+}
+
+// get the element reads of an array by a control node, e.g. a[i] or a[i][j]. Only
+// the outermost index of an element of a multi-dimensional array is returned 
+func (l *argoListener) arrayIndexReads(cNode *CfgNode,vNode *VariableNode) []*ParseNode {
+	var pNode *ParseNode
+	var elements []*ParseNode
+
+	pNode = cNode.getReadExpr()
+	if (pNode == nil) || (!cNode.usesVar(vNode)) {
+		return nil
+	}
+	for _, primary := range pNode.walkDownToAllRules("primaryExpr") {
+		if (len(primary.children) != 2) || (primary.children[1].ruleType != "index") {
+			continue
+		}
+		parent := primary.parent
+		if (parent != nil) && (parent.ruleType == "primaryExpr") && (len(parent.children) == 2) && (parent.children[1].ruleType == "index") {
+			continue
+		}
+		if name, indexes := getArrayElement(primary) ; (name == vNode.sourceName) && (len(indexes) > 0) {
+			elements = append(elements,primary)
+		}
+	}
+	return elements
+}
+
+// get the control nodes of a module which write an element of an array, e.g. a[i] = x 
+func (l *argoListener) arrayIndexWrites(vNode *VariableNode,funcName string) []*CfgNode {
+	var writeNodes []*CfgNode
+
+	for _, cNode := range moduleCfgNodes(l,funcName) {
+		if _, ok := cNode.assigningStmt().writeIndexes[vNode] ; ok {
+			writeNodes = append(writeNodes,cNode)
+		}
+	}
+	return writeNodes
+}
+
+// the statement a control node assigns, the sub-statement of an if or for
+// pre-statement or post-statement, else the statement of the node 
+func (cNode *CfgNode) assigningStmt() *StatementNode {
+	if (cNode.subStmt != nil) {
+		return cNode.subStmt
+	}
+	return cNode.statement
+}
+
+// the data a control node writes to an element of an array. A compound assignment,
+// e.g. a[i] += y, reads the element from the memory and writes it back 
+func (l *argoListener) arrayWriteData(cNode *CfgNode,vNode *VariableNode,funcName string) (string, error) {
+	var pNode, element *ParseNode
+	var lhsList, rhsList []*ParseNode
+	var data string
+	var found bool
+
+	pNode = cNode.assigningStmt().parseSubDef
+	if (pNode == nil) || (pNode.ruleType != "assignment") || (len(pNode.children) < 3) {
+		return "", fmt.Errorf("array %s is not written by an assignment",vNode.sourceName)
+	}
+	lhsList = pNode.children[0].getExpressionList()
+	rhsList = pNode.children[2].getExpressionList()
+	for k, lhsExpr := range lhsList {
+		element = lhsExpr.stripParens()
+		if name, indexes := getArrayElement(element) ; (name != vNode.sourceName) || (len(indexes) == 0) {
+			continue
+		}
+		if (found) {
+			return "", fmt.Errorf("array %s is written at more than one index in a statement",vNode.sourceName)
+		}
+		found = true
+		if op := pNode.getAssignOp() ; op != "" {
+			lhs := l.exprToVerilog(element,funcName)
+			if product, ok := l.mulProducts[pNode] ; ok {
+				data = product
+				continue
+			}
+			rhs := l.truncatedOperand(pNode.children[2],funcName)
+			switch op {
+			case "&^":
+				data = lhs + " & ~( " + rhs + " )"
+				continue
+			case ">>":
+				op = ">>>"
+				rhs = l.indexToVerilog(pNode.children[2],funcName)
+			case "<<":
+				rhs = l.indexToVerilog(pNode.children[2],funcName)
+			}
+			data = lhs + " " + op + " ( " + rhs + " )"
+		} else if (k < len(rhsList)) {
+			data = l.sizedExpr(rhsList[k],funcName,vNode)
+		}
+	}
+	if (data == "") {
+		return "", fmt.Errorf("no value is written to array %s",vNode.sourceName)
+	}
+	return data, nil
+}
+
+// get the array name and index expressions of an element of an array, outermost
+// dimension first, e.g. a and i, j for a[i][j]. The name is empty if the expression
+// is not an element of a named array 
+func getArrayElement(primary *ParseNode) (string, []*ParseNode) {
+	var indexes []*ParseNode
+	var node *ParseNode
+
+	node = primary
+	for (node.ruleType == "primaryExpr") && (len(node.children) == 2) && (node.children[1].ruleType == "index") {
+		if (len(node.children[1].children) < 2) {
+			return "", nil
+		}
+		indexes = append([]*ParseNode{node.children[1].children[1]},indexes...)
+		node = node.children[0]
+	}
+	return node.getPlainOperandName(), indexes
+}
+
+// translate an array index or a shift amount. A constant expression, e.g. a named
+// constant or (1<<ROUTER_LOG), is folded to its value. Other expressions are sized
+// like the operand of a comparison, so the carry of a sum is not part of the index 
+func (l *argoListener) indexToVerilog(pNode *ParseNode,funcName string) string {
+	if value, ok := l.evalConstExpr(pNode,funcName) ; (ok) && (value >= 0) {
+		return strconv.FormatInt(value,10)
+	}
+	return l.truncatedOperand(pNode,funcName)
+}
+
+// the memory address of an element of an array. Multi-dimensional arrays are stored
+// in row major order, so the address of a[i][j] of a [4][8] array is i*8 + j 
+func (l *argoListener) arrayElementAddr(vNode *VariableNode,indexes []*ParseNode,funcName string) (string, error) {
+	var terms []string
+	var stride, offset int64
+	var allConst bool
+
+	if (len(indexes) != len(vNode.dimensions)) {
+		return "", fmt.Errorf("array %s has %d dimensions but %d indexes",vNode.sourceName,len(vNode.dimensions),len(indexes))
+	}
+	stride = 1
+	offset = 0
+	allConst = true
+	for k := len(indexes)-1; k >= 0; k-- {
+		if value, ok := l.evalConstExpr(indexes[k],funcName) ; ok {
+			offset = offset + value*stride
+		} else {
+			allConst = false
+			if (stride == 1) {
+				terms = append([]string{"( " + l.indexToVerilog(indexes[k],funcName) + " )"},terms...)
+			} else {
+				terms = append([]string{"( " + l.indexToVerilog(indexes[k],funcName) + " ) * " + strconv.FormatInt(stride,10)},terms...)
+			}
+		}
+		stride = stride * int64(vNode.dimensions[k])
+	}
+	if (allConst) || (offset != 0) {
+		terms = append(terms,strconv.FormatInt(offset,10))
+	}
+	return strings.Join(terms," + "), nil
+}
+
+// the control bits of the nodes of a module which read an array 
+func (l *argoListener) arrayReadBits(vNode *VariableNode) []string {
+	var bits []string
+
+	for _, cNode := range moduleCfgNodes(l,vNode.funcName) {
+		if (len(l.arrayIndexReads(cNode,vNode)) > 0) {
+			bits = append(bits,cNode.cannName)
+		}
+	}
+	return bits
+}
+
+// output the access to the arrays of a module, local or parameter. The read
+// address is the index of the active node reading the array. The memory has a
+// one cycle read, so a reading node stalls the clock enable for a cycle until the
+// read valid bit is set, then the node reads the data output. The memory ports
+// of a local array are switched to a callee while the callee is busy 
+func OutputArrayAccess(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var addrExpr, indexStr string
+	var indexes []*ParseNode
+	var readBits []string
+
+	out = parsedProgram.outputFile
+	for _, vNode := range parsedProgram.varNodeList {
+		if (vNode.funcName != funcName) || (vNode.goLangType != "array") {
+			continue
+		}
+		addrExpr = "0"
+		readBits = make([]string,0)
+		for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+			indexes = parsedProgram.arrayIndexReads(cNode,vNode)
+			if (len(indexes) == 0) {
+				continue
+			}
+			indexStr = ""
+			for k, element := range indexes {
+				_, elemIndexes := getArrayElement(element)
+				addr, err := parsedProgram.arrayElementAddr(vNode,elemIndexes,funcName)
+				if (err != nil) {
+					parsedProgram.reportError(element.sourceLineStart,element.sourceColStart,"%s",err)
+					continue
+				}
+				if (k == 0) {
+					indexStr = addr
+				} else if (addr != indexStr) {
+					parsedProgram.reportError(element.sourceLineStart,element.sourceColStart,"array %s is read at more than one index in a statement",
+						vNode.sourceName)
+				}
+			}
+			if (indexStr == "") {
+				indexStr = "0"
+			}
+			addrExpr = "( " + cNode.cannName + " ) ? ( " + indexStr + " ) : " + addrExpr
+			readBits = append(readBits,cNode.cannName)
+		}
+
+		fmt.Fprintf(out,"// -------- Array Access Section for %s ---------- \n",vNode.sourceName)
+		fmt.Fprintf(out," \t assign %s_read_addr = %s ; \n",vNode.sourceName,addrExpr)
+		OutputArrayWrites(parsedProgram,vNode,funcName)
+		if (!vNode.isParameter) {
+			for _, port := range []string{"write_en","write_addr","read_addr","input_data"} {
+				fmt.Fprintf(out," \t assign %s = %s ; \n",arrayMemWireName(vNode,port),parsedProgram.arrayMemoryPort(vNode,port))
+			}
+		}
+		fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+		fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",arrayReadValidName(vNode))
+		fmt.Fprintf(out," \t \t else if (ce) %s <= 0 ; \n",arrayReadValidName(vNode))
+		if (len(readBits) > 0) {
+			fmt.Fprintf(out," \t \t else if ( %s ) %s <= 1 ; \n",strings.Join(readBits," | "),arrayReadValidName(vNode))
+		}
+		fmt.Fprintf(out," \t end \n")
+	}
+}
+
+// output the write side of the memory of an array. The active node writing an
+// element drives the write enable, the address of the element and the data for
+// the cycle the clock enable lets it finish 
+func OutputArrayWrites(parsedProgram *argoListener,vNode *VariableNode,funcName string) {
+	var out *os.File
+	var writeNodes []*CfgNode
+	var name, condStr string
+
+	out = parsedProgram.outputFile
+	writeNodes = parsedProgram.arrayIndexWrites(vNode,funcName)
+	if (len(writeNodes) == 0) {
+		return
+	}
+	name = vNode.sourceName
+	fmt.Fprintf(out," \t always @(*) begin // writes of array %s \n",name)
+	fmt.Fprintf(out," \t \t %s_write_en = 0 ; %s_write_addr = 0 ; %s_input_data = 0 ; \n",name,name,name)
+	condStr = "if"
+	for _, cNode := range writeNodes {
+		addr, err := parsedProgram.arrayElementAddr(vNode,cNode.assigningStmt().writeIndexes[vNode],funcName)
+		if (err != nil) {
+			parsedProgram.reportError(cNode.sourceRow,cNode.sourceCol,"%s",err)
+			continue
+		}
+		data, err := parsedProgram.arrayWriteData(cNode,vNode,funcName)
+		if (err != nil) {
+			parsedProgram.reportError(cNode.sourceRow,cNode.sourceCol,"%s",err)
+			continue
+		}
+		fmt.Fprintf(out," \t \t %s ( %s & ce ) begin \n",condStr,cNode.cannName)
+		fmt.Fprintf(out," \t \t \t %s_write_en = 1 ; %s_write_addr = %s ; %s_input_data = %s ; \n",name,name,addr,name,data)
+		fmt.Fprintf(out," \t \t end \n")
+		condStr = "else if"
+	}
+	fmt.Fprintf(out," \t end \n")
+}
+
+/* ***************************************************** */
+// return true if a channel, or a channel bound to it, is closed in the program.
+// A closed channel has a closed flag next to its FIFO 
+func (l *argoListener) isClosedChannel(vNode *VariableNode) bool {
+	if (vNode.goLangType != "channel") {
+		return false
+	}
+	for _, use := range l.getChannelUses() {
+		if (len(use.closes) == 0) {
+			continue
+		}
+		if (use.chanVar == vNode) {
+			return true
+		}
+		for _, alias := range use.aliases {
+			if (alias == vNode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the register set once a channel is closed, or the port of the top module's register 
+func chanClosedName(vNode *VariableNode) string {
+	return vNode.sourceName + "_closed"
+}
+
+// the wire set in the cycle a module closes a channel 
+func chanCloseName(vNode *VariableNode) string {
+	return vNode.sourceName + "_close"
+}
+
+// get the channel a control node closes, e.g. close(ch) or defer close(ch), or nil 
+func (l *argoListener) getChannelClose(cNode *CfgNode) *VariableNode {
+	var vNode *VariableNode
+
+	if (cNode.cfgType != "expression") && (cNode.cfgType != "deferCall") {
+		return nil
+	}
+	for _, argNode := range cNode.statement.parseDef.walkDownToAllRules("arguments") {
+		if closeArg := l.getCloseArg(argNode) ; closeArg != nil {
+			vNode = l.getVarNodeInScope(cNode.statement.funcName,closeArg.getPlainOperandName(),closeArg)
+			if (vNode != nil) && (vNode.goLangType == "channel") {
+				return vNode
+			}
+		}
+	}
+	return nil
+}
+
+// the value of ok for a comma-ok receive from a channel. The receive gets a sent
+// value unless the channel is empty and closed 
+func (l *argoListener) chanRecvOk(vNode *VariableNode) string {
+	if (!l.isClosedChannel(vNode)) {
+		return "1'b1"
+	}
+	return "~( " + vNode.sourceName + "_empty & " + chanClosedName(vNode) + " )"
+}
+
+// a signal of the FIFO of a channel, e.g. ch_empty. For an element of an array
+// of channels the signal of the element's FIFO is selected by its index 
+func chanSignal(vNode *VariableNode,signal string,index string) string {
+	if (index == "") {
+		return vNode.sourceName + "_" + signal
+	}
+	return vNode.sourceName + "_" + signal + "[ " + index + " ]"
+}
+
+// the enable of the FIFO of a channel by a control bit. For an element of an array
+// of channels the enables are a vector with the bit of the element set 
+func elementBit(vNode *VariableNode,bit string,index string) string {
+	var size string
+
+	if (index == "") {
+		return bit
+	}
+	size = strconv.Itoa(arraySize(vNode))
+	return "( " + bit + " ? ( " + size + "'d1 << ( " + index + " ) ) : " + size + "'d0 )"
+}
+
+// get the channel of a channel operand, e.g. ch or cs[i], and the index of the
+// element of an array of channels, which is empty for a channel. The channel is
+// nil if the operand is not a channel or a whole array of channels 
+func (l *argoListener) getChannelOperand(pNode *ParseNode,funcName string) (*VariableNode, string) {
+	var vNode *VariableNode
+
+	if (pNode == nil) {
+		return nil, ""
+	}
+	name, indexes := getArrayElement(pNode.stripParens())
+	vNode = l.getVarNodeInScope(funcName,name,pNode)
+	if (vNode == nil) || (vNode.goLangType != "channel") || (len(indexes) != vNode.numDim) {
+		return nil, ""
+	}
+	if (len(indexes) == 0) {
+		return vNode, ""
+	}
+	index, err := l.arrayElementAddr(vNode,indexes,funcName)
+	if (err != nil) {
+		return nil, ""
+	}
+	return vNode, index
+}
+
+// the register which is set when the data at the head of a channel is valid 
+func chanReadValidName(vNode *VariableNode) string {
+	return vNode.sourceName + "_rd_valid"
+}
+
+// get the channel a control node sends on, the expression it sends and the index
+// of an element of an array of channels, or nil 
+func (l *argoListener) getChannelSend(cNode *CfgNode) (*VariableNode, *ParseNode, string) {
+	var sendNode *ParseNode
+
+	sendNode = cNode.statement.parseSubDef
+	if (cNode.cfgType != "send") || (sendNode == nil) || (sendNode.ruleType != "sendStmt") || (len(sendNode.children) < 3) {
+		return nil, nil, ""
+	}
+	vNode, index := l.getChannelOperand(sendNode.children[0],cNode.statement.funcName)
+	if (vNode == nil) {
+		return nil, nil, ""
+	}
+	return vNode, sendNode.children[2], index
+}
+
+// get the sends of the comm clauses of a select: for each, the channel, the value
+// sent and the control bit set when the clause is taken. The value is written
+// while the clause bit is set 
+func (l *argoListener) getSelectSends(cNode *CfgNode) ([]*VariableNode, []*ParseNode, []string) {
+	var chans []*VariableNode
+	var values []*ParseNode
+	var bits []string
+	var vNode *VariableNode
+
+	for k, comm := range cNode.caseComms {
+		if (comm.ruleType != "sendStmt") || (len(comm.children) < 3) {
+			continue
+		}
+		vNode = l.getVarNodeInScope(cNode.statement.funcName,comm.children[0].stripParens().getPlainOperandName(),comm.children[0])
+		if (vNode == nil) || (vNode.goLangType != "channel") {
+			continue
+		}
+		chans = append(chans,vNode)
+		values = append(values,comm.children[2])
+		bits = append(bits,selectCaseName(cNode,k))
+	}
+	return chans, values, bits
+}
+
+// get the channels a control node receives from, and the index of each element
+// of an array of channels 
+func (l *argoListener) getChannelRecvs(cNode *CfgNode) ([]*VariableNode, []string) {
+	var pNode *ParseNode
+	var chans []*VariableNode
+	var indexes []string
+
+	pNode = cNode.getReadExpr()
+	if (pNode == nil) {
+		return nil, nil
+	}
+	for _, unary := range append([]*ParseNode{pNode},pNode.walkDownToAllRules("unaryExpr")...) {
+		if (unary.ruleType != "unaryExpr") || (len(unary.children) != 2) || (unary.children[0].ruleType != "<-") {
+			continue
+		}
+		if vNode, index := l.getChannelOperand(unary.children[1],cNode.statement.funcName) ; vNode != nil {
+			chans = append(chans,vNode)
+			indexes = append(indexes,index)
+		}
+	}
+	return chans, indexes
+}
+
+// get the variable, field and bit offset of the enclosing fields of a struct
+// field selection such as p.hdr.src. The field is nil if the expression is not
+// a field of a struct variable 
+func (l *argoListener) structFieldBits(pNode *ParseNode,funcName string) (*VariableNode, *StructField, int) {
+	var vNode *VariableNode
+	var outer *StructField
+	var sType *StructType
+	var base int
+	var fieldName string
+
+	if (len(pNode.children) != 2) || (pNode.children[1].ruleType != "selector") || (len(pNode.children[1].children) < 2) {
+		return nil, nil, 0
+	}
+	fieldName = pNode.children[1].children[1].ruleType
+	inner := pNode.children[0]
+	if (inner.ruleType == "primaryExpr") && (len(inner.children) == 2) && (inner.children[1].ruleType == "selector") {
+		vNode, outer, base = l.structFieldBits(inner,funcName)
+		if (outer == nil) {
+			return nil, nil, 0
+		}
+		sType = outer.structType
+		base = base + outer.offset
+	} else {
+		vNode = l.getVarNodeInScope(funcName,inner.getPlainOperandName(),inner)
+		if (vNode == nil) || (vNode.goLangType != "numeric") {
+			return nil, nil, 0
+		}
+		sType = vNode.structType
+	}
+	if (sType == nil) || (sType.getField(fieldName) == nil) {
+		return nil, nil, 0
+	}
+	return vNode, sType.getField(fieldName), base
+}
+
+// output the access to the channels of a module, local or parameter. A send
+// writes the sent value, e.g. all the fields of a struct packed into one bit
+// vector, and a receive reads the data at the head of the FIFO. The FIFO memory
+// has a one cycle read, so a receive stalls until the read valid bit is set.
+// A close sets the closed flag of a local channel, or of the top module's FIFO 
+func OutputChannelAccess(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var sendBits, recvBits, closeBits, validBits []string
+	var wrTerms, rdTerms, closeTerms []string
+	var dataExpr, rdEnable, ce string
+	var closable bool
+	var chanArgs []*ChannelArgument
+
+	out = parsedProgram.outputFile
+	chanArgs = parsedProgram.getChannelArguments(funcName)
+	for _, vNode := range parsedProgram.varNodeList {
+		if (vNode.funcName != funcName) || (vNode.goLangType != "channel") {
+			continue
+		}
+		sendBits = make([]string,0)
+		recvBits = make([]string,0)
+		closeBits = make([]string,0)
+		validBits = make([]string,0)
+		wrTerms = make([]string,0)
+		rdTerms = make([]string,0)
+		closeTerms = make([]string,0)
+		dataExpr = "0"
+		// the enables of a bank of FIFOs are a bit for each element 
+		ce = "ce"
+		if (vNode.numDim > 0) {
+			ce = "{" + strconv.Itoa(arraySize(vNode)) + "{ce}}"
+		}
+		closable = parsedProgram.isClosedChannel(vNode)
+		for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+			if chanVar, sendExpr, index := parsedProgram.getChannelSend(cNode) ; chanVar == vNode {
+				dataExpr = "( " + cNode.cannName + " ) ? ( " + parsedProgram.sizedExpr(sendExpr,funcName,vNode) + " ) : " + dataExpr
+				sendBits = append(sendBits,elementBit(vNode,cNode.cannName,index))
+			}
+			chans, values, bits := parsedProgram.getSelectSends(cNode)
+			for k, chanVar := range chans {
+				if (chanVar == vNode) {
+					dataExpr = "( " + bits[k] + " ) ? ( " + parsedProgram.sizedExpr(values[k],funcName,vNode) + " ) : " + dataExpr
+					sendBits = append(sendBits,bits[k])
+				}
+			}
+			chans, indexes := parsedProgram.getChannelRecvs(cNode)
+			for k, chanVar := range chans {
+				if (chanVar == vNode) {
+					recvBits = append(recvBits,elementBit(vNode,cNode.cannName,indexes[k]))
+					validBits = append(validBits,"( " + cNode.cannName + " & ~" + chanSignal(vNode,"empty",indexes[k]) + " )")
+					break
+				}
+			}
+			if (parsedProgram.getChannelClose(cNode) == vNode) {
+				closeBits = append(closeBits,deferCallBit(cNode))
+			}
+		}
+
+		if (len(sendBits) > 0) {
+			wrTerms = append(wrTerms,"( ( " + strings.Join(sendBits," | ") + " ) & " + ce + " )")
+		}
+		if (len(closeBits) > 0) {
+			closeTerms = append(closeTerms,"( ( " + strings.Join(closeBits," | ") + " ) & ce )")
+		}
+		// a called function given the channel uses the FIFO while the caller waits
+		// for it to be done, so the caller's enables are not set at the same time 
+		for _, chanArg := range chanArgs {
+			if (chanArg.arg != vNode) {
+				continue
+			}
+			if (chanArg.param.chanDir != "recv") {
+				wrTerms = append(wrTerms,chanArgWireName(chanArg,"wr_en"))
+				dataExpr = "( " + chanArgWireName(chanArg,"wr_en") + " ) ? ( " + chanArgWireName(chanArg,"wr_data") + " ) : " + dataExpr
+				if (parsedProgram.isClosedChannel(chanArg.param)) {
+					closeTerms = append(closeTerms,chanArgWireName(chanArg,"close"))
+				}
+			}
+			if (chanArg.param.chanDir != "send") {
+				rdTerms = append(rdTerms,chanArgWireName(chanArg,"rd_en"))
+			}
+		}
+
+		// the unused side of a channel port is idle, so the top module can
+		// merge the ports of every module using the channel 
+		isPort := (vNode.isParameter) || (parsedProgram.isHoistedChannel(vNode))
+		if (len(wrTerms) == 0) && (len(recvBits) == 0) && (len(rdTerms) == 0) && (len(closeTerms) == 0) && (!isPort) {
+			continue
+		}
+		fmt.Fprintf(out,"// -------- Channel Access Section for %s ---------- \n",vNode.sourceName)
+		if (len(wrTerms) > 0) {
+			fmt.Fprintf(out," \t assign %s_wr_en = %s ; \n",vNode.sourceName,strings.Join(wrTerms," | "))
+			fmt.Fprintf(out," \t assign %s_wr_data = %s ; \n",vNode.sourceName,dataExpr)
+		} else if (isPort) && (vNode.chanDir != "recv") {
+			fmt.Fprintf(out," \t assign %s_wr_en = 1'b0 ; \n",vNode.sourceName)
+			fmt.Fprintf(out," \t assign %s_wr_data = 0 ; \n",vNode.sourceName)
+		}
+		if (len(recvBits) == 0) && (len(rdTerms) > 0) {
+			fmt.Fprintf(out," \t assign %s_rd_en = %s ; \n",vNode.sourceName,strings.Join(rdTerms," | "))
+		} else if (len(recvBits) == 0) && (isPort) && (vNode.chanDir != "send") {
+			fmt.Fprintf(out," \t assign %s_rd_en = 1'b0 ; \n",vNode.sourceName)
+		}
+		if (closable) && (len(closeTerms) > 0) {
+			fmt.Fprintf(out," \t assign %s = %s ; \n",chanCloseName(vNode),strings.Join(closeTerms," | "))
+		} else if (closable) && ( (!isPort) || (vNode.chanDir != "recv") ) {
+			fmt.Fprintf(out," \t assign %s = 1'b0 ; \n",chanCloseName(vNode))
+		}
+		if (closable) && (!isPort) {
+			fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+			fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",chanClosedName(vNode))
+			fmt.Fprintf(out," \t \t else if (%s) %s <= 1 ; \n",chanCloseName(vNode),chanClosedName(vNode))
+			fmt.Fprintf(out," \t end \n")
+		}
+		if (len(recvBits) > 0) {
+			// a receive from an empty closed channel does not read the FIFO 
+			rdEnable = "( " + strings.Join(recvBits," | ") + " ) & " + ce
+			if (closable) {
+				rdEnable = rdEnable + " & ~" + vNode.sourceName + "_empty"
+			}
+			if (len(rdTerms) > 0) {
+				rdEnable = "( " + rdEnable + " ) | " + strings.Join(rdTerms," | ")
+			}
+			fmt.Fprintf(out," \t assign %s_rd_en = %s ; \n",vNode.sourceName,rdEnable)
+			fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+			fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",chanReadValidName(vNode))
+			fmt.Fprintf(out," \t \t else if (ce) %s <= 0 ; \n",chanReadValidName(vNode))
+			fmt.Fprintf(out," \t \t else if ( %s ) %s <= 1 ; \n",strings.Join(validBits," | "),chanReadValidName(vNode))
+			fmt.Fprintf(out," \t end \n")
+		}
+	}
+}
+
+/* ***************************************************** */
+// ouput the initialization section for simulation 
+func OutputInitialization(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	out = parsedProgram.outputFile
+	
+	fmt.Fprintf(out,"// -------- Initialization Section  ---------- \n")
+	fmt.Fprintf(out,"initial begin \n")
+	fmt.Fprintf(out," \t clock = 0 ; \n ")
+	fmt.Fprintf(out," \t rst = 0 ; \n ")
+	fmt.Fprintf(out," \t cycle_count = 0 ; \n")
+	fmt.Fprintf(out," \t %s = 1 ; \n",parsedProgram.controlFlowGraph[0].cannName)
+	fmt.Fprintf(out,"end \n")
+}
+
+/* ***************************************************** */
+// ouput the I/O section for simulation
+// right now just change the printfs to $display statements 
+func OutputIO(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var stmt *StatementNode
+	var pNode *ParseNode
+	var numCnodes int
+	
+	out = parsedProgram.outputFile
+	
+	fmt.Fprintf(out,"// -------- I/O Section  ---------- \n")
+
+
+	// count the number of printf nodes, if zero, do not output anything
+	// FIXME: add count by function module name
+	numCnodes = 0
+	for _, cNode := range(parsedProgram.controlFlowGraph) {
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) == funcName) {
+			if (cNode.cfgType == "expression" ) {
+				stmt = cNode.statement
+				pNode = stmt.parseDef
+				if (getPrintFunc(pNode) != "") {
+					numCnodes ++ ;
+				}
+			}
+		}
+	}
+	if (numCnodes == 0) {
+		return; 
+	}
+	
+	fmt.Fprintf(out,"always @(posedge clock) begin \n")
+	
+	for _, cNode := range(parsedProgram.controlFlowGraph) {
+
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) == funcName) { 
+			if (cNode.cfgType == "expression" ) {
+				stmt = cNode.statement
+				pNode = stmt.parseDef
+				if (getPrintFunc(pNode) != "") {
+					displayStr := "$write(" + parsedProgram.printToVerilog(pNode,funcName) + "); "
+					fmt.Fprintf(out," \t if ((%s == 1) && ce) begin \n",cNode.cannName)
+					fmt.Fprintf(out," \t \t %s \n",displayStr)
+					fmt.Fprintf(out," \t end \n")
+				}
+			}
+		}
+	}
+	fmt.Fprintf(out,"end \n")
+
+	
+}
+
+// return the name of the fmt print function an expression calls, e.g. Printf,
+// or an empty string if the expression is not a call to a fmt print function 
+func getPrintFunc(pNode *ParseNode) string {
+	rePrint := regexp.MustCompile(`^fmt\.(Printf|Println|Print|Sprintf)\s*\(`)
+	match := rePrint.FindStringSubmatch(strings.TrimSpace(pNode.getSourceCode()))
+	if (match == nil) {
+		return ""
+	}
+	return match[1]
+}
+
+// translate a Go format string, without the quotes, to a Verilog format string.
+// Returns the Verilog conversion of each verb and the text around them, so
+// len(text) is len(specs)+1, and the Go verb of each argument, in order.
+// Go prints integers with no padding, so %d becomes %0d, %x becomes %h, and
+// %t becomes %s as booleans are printed as "true" or "false". An unsupported
+// verb is warned of at the row and column of the print 
+func (l *argoListener) goFormatToVerilog(format string,row int,col int) ([]string, []string, []byte) {
+	var text, specs []string
+	var verbs []byte
+	var segment strings.Builder
+	var spec string
+
+	reSpec := regexp.MustCompile(`^%[-+# 0]*([0-9]*)(\.[0-9]+)?([a-zA-Z%])`)
+	for i := 0; i < len(format); i++ {
+		if (format[i] != '%') {
+			segment.WriteByte(format[i])
+			continue
+		}
+		match := reSpec.FindStringSubmatch(format[i:])
+		if (match == nil) {
+			segment.WriteString("%%")
+			continue
+		}
+		i += len(match[0]) - 1
+		width, precision, verb := match[1], match[2], match[3][0]
+		switch verb {
+		case '%':
+			segment.WriteString("%%")
+			continue
+		case 'd', 'v':
+			if (width == "") {
+				width = "0"
+			}
+			spec = "%" + width + "d"
+		case 'x', 'X':
+			if (width == "") {
+				width = "0"
+			}
+			spec = "%" + width + "h"
+		case 'b', 'o', 'c', 's':
+			spec = "%" + width + string(verb)
+		case 'q':
+			spec = "\\\"%s\\\""
+		case 't':
+			spec = "%s"
+		case 'f', 'F', 'e', 'g':
+			spec = "%" + width + precision + strings.ToLower(string(verb))
+		default:
+			l.reportWarning(row,col,"format verb %%%c not supported, printing it as %%d",verb)
+			spec = "%0d"
+		}
+		text = append(text,segment.String())
+		segment.Reset()
+		specs = append(specs,spec)
+		verbs = append(verbs,verb)
+	}
+	text = append(text,segment.String())
+	return text, specs, verbs
+}
+
+// translate the arguments of a fmt print call to a Verilog format string, without
+// the quotes, and the list of Verilog arguments. Variables are renamed to their
+// Verilog signals. A fmt.Sprintf argument printed with %s or %v is spliced into
+// the format string of the enclosing Printf 
+func (l *argoListener) printToFormat(pNode *ParseNode,funcName string) (string, []string) {
+	var argNode *ParseNode
+	var exprList []*ParseNode
+	var printFunc, vFormat, argStr string
+	var text, specs []string
+	var verbs []byte
+	var args []string
+
+	printFunc = getPrintFunc(pNode)
+	argNode = pNode.walkDownToRule("arguments")
+	if (argNode != nil) {
+		exprList = argNode.walkDownToRule("expressionList").getExpressionList()
+	}
+
+	if (printFunc == "Printf") || (printFunc == "Sprintf") {
+		if (len(exprList) == 0) {
+			l.reportError(pNode.sourceLineStart,pNode.sourceColStart,"%s has no format string",strings.TrimSpace(pNode.getSourceCode()))
+			return "", nil
+		}
+		text, specs, verbs = l.goFormatToVerilog(strings.Trim(strings.TrimSpace(exprList[0].getSourceCode()),"\""),
+			pNode.sourceLineStart,pNode.sourceColStart)
+		exprList = exprList[1:]
+		if (len(verbs) != len(exprList)) {
+			l.reportWarning(pNode.sourceLineStart,pNode.sourceColStart,"%d format verbs for %d arguments in %s",len(verbs),len(exprList),
+				strings.TrimSpace(pNode.getSourceCode()))
+		}
+
+		vFormat = text[0]
+		for k, spec := range specs {
+			if (k < len(exprList)) {
+				exprNode := exprList[k]
+				// $write prints an expression at its own width, so a sum wraps first 
+				argStr = l.truncatedOperand(exprNode,funcName)
+				switch {
+				case (verbs[k] == 't'):
+					argStr = "( " + argStr + " ) ? \"true\" : \"false\""
+				case ((verbs[k] == 's') || (verbs[k] == 'v')) && (getPrintFunc(exprNode) == "Sprintf"):
+					// splice the inner format and arguments into this one 
+					innerFormat, innerArgs := l.printToFormat(exprNode,funcName)
+					vFormat = vFormat + innerFormat + text[k+1]
+					args = append(args,innerArgs...)
+					continue
+				}
+				args = append(args,argStr)
+			}
+			vFormat = vFormat + spec + text[k+1]
+		}
+		return vFormat, args
+	}
+
+	// Print and Println: string literals are copied, other values printed as integers.
+	// Println separates the operands with spaces and adds a newline 
+	for k, exprNode := range exprList {
+		if (k > 0) && (printFunc == "Println") {
+			vFormat = vFormat + " "
+		}
+		argStr = strings.TrimSpace(exprNode.getSourceCode())
+		if (strings.HasPrefix(argStr,"\"")) {
+			vFormat = vFormat + strings.Trim(argStr,"\"")
+		} else {
+			vFormat = vFormat + "%0d"
+			args = append(args,l.truncatedOperand(exprNode,funcName))
+		}
+	}
+	if (printFunc == "Println") {
+		vFormat = vFormat + "\\n"
+	}
+	return vFormat, args
+}
+
+// the arguments of the Verilog $write for a fmt print call 
+func (l *argoListener) printToVerilog(pNode *ParseNode,funcName string) string {
+	vFormat, args := l.printToFormat(pNode,funcName)
+	return strings.Join(append([]string{"\"" + vFormat + "\""},args...),", ")
+}
+
+/* ***************************************************** */
+// return "signed " for variables of signed Go types. Unsigned Go types are declared
+// unsigned so Verilog operators, e.g. >>>, follow the Go semantics. Packed structs are unsigned 
+func verilogSigned(vNode *VariableNode) string {
+	if (vNode.primType == "uint") || (vNode.primType == "byte") || (vNode.primType == "bool") {
+		return ""
+	}
+	if (vNode.structType != nil) {
+		return ""
+	}
+	return "signed "
+}
+
+// remove any enclosing parentheses from an expression, i.e. the
+// expression -> unaryExpr -> primaryExpr -> operand -> ( expression ) chain
+func (node *ParseNode) stripParens() *ParseNode {
+	var inner *ParseNode
+
+	inner = node
+	for (inner != nil) {
+		if (inner.ruleType == "expression") || (inner.ruleType == "unaryExpr") || (inner.ruleType == "primaryExpr") {
+			if (len(inner.children) != 1) {
+				return inner
+			}
+			inner = inner.children[0]
+		} else if (inner.ruleType == "operand") && (len(inner.children) == 3) && (inner.children[0].ruleType == "(") {
+			inner = inner.children[1]
+		} else {
+			return inner
+		}
+	}
+	return node
+}
+
+// if an expression is a pointer dereference, e.g. *p, return the pointer expression,
+// else the expression. A pointer names the value it points to 
+func (node *ParseNode) stripDeref() *ParseNode {
+	var inner *ParseNode
+
+	if (node == nil) {
+		return nil
+	}
+	inner = node.stripParens()
+	if (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && (inner.children[0].ruleType == "*") {
+		return inner.children[1]
+	}
+	return node
+}
+
+// if an expression is only an integer literal, return the value of the literal 
+func (node *ParseNode) getIntLiteral() (int64, bool) {
+	var basicLitNode *ParseNode
+	var litStr string
+
+	basicLitNode = node.stripParens().walkDownToRule("basicLit")
+	if (basicLitNode == nil) || (len(basicLitNode.children) == 0) {
+		return 0, false
+	}
+	litStr = basicLitNode.children[0].ruleType
+	if (strings.TrimSpace(node.stripParens().getSourceCode()) != litStr) {
+		return 0, false
+	}
+	value, _, err := parseIntLiteral(litStr,64)
+	if (err != nil) {
+		return 0, false
+	}
+	return value, true
+}
+
+// convert a Go integer literal to a Verilog constant. Hex, octal and binary literals
+// keep their base and are sized by their digits, e.g. 0x1f becomes 8'h1f. Decimal
+// literals wider than the 32 bits of an unsized Verilog constant are sized 
+func verilogIntLiteral(lit string,defaultBits int) (string, bool) {
+	var digits string
+
+	value, numBits, err := parseIntLiteral(lit,defaultBits)
+	if (err != nil) {
+		return "", false
+	}
+	digits = strings.Replace(lit,"_","",-1)
+	if (len(digits) >= 2) && (digits[0] == '0') {
+		switch digits[1] {
+		case 'x', 'X':
+			return strconv.Itoa(numBits) + "'h" + digits[2:], true
+		case 'o', 'O':
+			return strconv.Itoa(numBits) + "'o" + digits[2:], true
+		case 'b', 'B':
+			return strconv.Itoa(numBits) + "'b" + digits[2:], true
+		default:
+			return strconv.Itoa(numBits) + "'o" + digits[1:], true
+		}
+	}
+	if (numBits > 32) {
+		return strconv.Itoa(numBits) + "'d" + strconv.FormatUint(uint64(value),10), true
+	}
+	return digits, true
+}
+
+// lower the idiom (x >> n) & mask, where the mask is 2^k-1, to a Verilog bit-select x[n]
+// or part-select x[n+k-1:n]. Returns false if the expression does not match the idiom 
+func (l *argoListener) bitSelect(shiftExpr *ParseNode,maskExpr *ParseNode,funcName string) (string, bool) {
+	var mask, shift int64
+	var width int64
+	var varName string
+	var varNode *VariableNode
+	var ok bool
+
+	mask, ok = maskExpr.getIntLiteral()
+	if (!ok) || (mask <= 0) || ((mask & (mask+1)) != 0) {
+		return "", false
+	}
+	width = 0
+	for (mask >> uint(width)) != 0 {
+		width++
+	}
+
+	shiftExpr = shiftExpr.stripParens()
+	if (shiftExpr.ruleType != "expression") || (len(shiftExpr.children) != 3) || (shiftExpr.children[1].ruleType != ">>") {
+		return "", false
+	}
+
+	// part selects are only legal on variables 
+	varName = shiftExpr.children[0].getPlainOperandName()
+	varNode = l.getVarNodeInScope(funcName,varName,shiftExpr)
+	if (varNode == nil) || (varNode.goLangType != "numeric") {
+		return "", false
+	}
+	if _, subst := l.substitutes[varNode] ; subst {
+		return "", false
+	}
+
+	shift, ok = shiftExpr.children[2].getIntLiteral()
+	if (ok) {
+		if (shift < 0) || ((varNode.numBits > 0) && (shift+width > int64(varNode.numBits))) {
+			return "", false
+		}
+		if (width == 1) {
+			return fmt.Sprintf("%s[%d]",varName,shift), true
+		}
+		return fmt.Sprintf("%s[%d:%d]",varName,shift+width-1,shift), true
+	}
+
+	// a variable shift amount can only be lowered to a single bit-select 
+	if (width == 1) {
+		return varName + "[" + l.exprToVerilog(shiftExpr.children[2],funcName) + "]", true
+	}
+	return "", false
+}
+
+/* ***************************************************** */
+// the width and signedness of a Go integer type name, e.g. 16 bits unsigned for
+// uint16. Returns false if the name is not an integer type 
+func intTypeBits(typeName string,intBits int) (int, bool, bool) {
+	var match []string
+	var bits int
+
+	switch typeName {
+	case "byte":
+		return 8, false, true
+	case "rune":
+		return 32, true, true
+	}
+	match = regexp.MustCompile("^(u?)int(8|16|32|64)?$").FindStringSubmatch(typeName)
+	if (match == nil) {
+		return 0, false, false
+	}
+	bits = intBits
+	if (match[2] != "") {
+		bits, _ = strconv.Atoi(match[2])
+	}
+	return bits, match[1] == "", true
+}
+
+// a constant of all ones in the low bits, e.g. 16'hffff 
+func widthMask(bits int) string {
+	if (bits >= 64) {
+		return "64'hffffffffffffffff"
+	}
+	return fmt.Sprintf("%d'h%x",bits,(uint64(1) << uint(bits)) - 1)
+}
+
+// a Verilog identifier which can be bit-selected 
+var verilogIdentifier = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_$]*$")
+
+// if an expression is a conversion to an integer type, e.g. uint32(x), return the
+// type name and the converted expression. A conversion parses either as a
+// conversion or as a call of the type name 
+func (l *argoListener) getIntConversion(pNode *ParseNode,funcName string) (string, *ParseNode) {
+	var typeName string
+	var exprList []*ParseNode
+
+	if (pNode.ruleType == "conversion") && (len(pNode.children) >= 4) {
+		typeName = strings.TrimSpace(pNode.children[0].getSourceCode())
+		exprList = []*ParseNode{pNode.children[2]}
+	} else if (pNode.ruleType == "primaryExpr") && (len(pNode.children) == 2) && (pNode.children[1].ruleType == "arguments") {
+		typeName = strings.TrimSpace(pNode.children[0].getSourceCode())
+		exprList = pNode.children[1].walkDownToRule("expressionList").getExpressionList()
+	} else {
+		return "", nil
+	}
+	if _, _, ok := intTypeBits(typeName,l.intBits) ; (!ok) || (len(exprList) != 1) {
+		return "", nil
+	}
+	return typeName, exprList[0]
+}
+
+// the width and signedness of the Go type of an integer expression. Variables
+// have the width of their Go type, even if the register is narrowed. An untyped
+// constant, or an expression of unknown type, has a width of zero 
+func (l *argoListener) exprType(pNode *ParseNode,funcName string) (int, bool) {
+	var bits int
+	var signed bool
+
+	if (pNode == nil) {
+		return 0, false
+	}
+	pNode = pNode.stripParens()
+	if typeName, _ := l.getIntConversion(pNode,funcName) ; typeName != "" {
+		bits, signed, _ = intTypeBits(typeName,l.intBits)
+		return bits, signed
+	}
+	switch pNode.ruleType {
+	case "operand":
+		vNode := l.getVarNodeInScope(funcName,pNode.getPlainOperandName(),pNode)
+		if (vNode == nil) {
+			// a typed constant has the width of its type 
+			if cNode := l.getConstant(funcName,pNode.getPlainOperandName()) ; (cNode != nil) && (cNode.numBits > 0) {
+				_, signed, _ := intTypeBits(cNode.typeName,l.intBits)
+				return cNode.numBits, signed
+			}
+			return 0, false
+		}
+		if (vNode.goLangType != "numeric") || (vNode.structType != nil) {
+			return 0, false
+		}
+		if (vNode.goBits > 0) {
+			return vNode.goBits, verilogSigned(vNode) != ""
+		}
+		return vNode.numBits, verilogSigned(vNode) != ""
+	case "primaryExpr":
+		if _, field, _ := l.structFieldBits(pNode,funcName) ; (field != nil) && (field.structType == nil) {
+			return field.numBits, field.primType == "int"
+		}
+		// a call in a condition has the type of the result of the callee 
+		if hoisted := l.getHoistedCall(pNode) ; hoisted != nil {
+			retVar := hoisted.callee.retVars[0]
+			return retVar.numBits, verilogSigned(retVar) != ""
+		}
+		// an element of an array has the type of the array's elements 
+		if (len(pNode.children) == 2) && (pNode.children[1].ruleType == "index") {
+			name, _ := getArrayElement(pNode)
+			if vNode := l.getVarNodeByNames("",funcName,name) ; (vNode != nil) && (vNode.goLangType == "array") && (intTypeName(vNode) != "") {
+				return vNode.numBits, verilogSigned(vNode) != ""
+			}
+		}
+	case "expression":
+		if (len(pNode.children) != 3) {
+			return 0, false
+		}
+		switch pNode.children[1].ruleType {
+		case "==", "!=", "<", "<=", ">", ">=", "&&", "||":
+			return 1, false
+		case "<<", ">>":
+			return l.exprType(pNode.children[0],funcName)
+		}
+		if bits, signed = l.exprType(pNode.children[0],funcName) ; bits > 0 {
+			return bits, signed
+		}
+		return l.exprType(pNode.children[2],funcName)
+	case "unaryExpr":
+		// a receive has the element type of the channel 
+		if (len(pNode.children) == 2) && (pNode.children[0].ruleType == "<-") {
+			vNode, _ := l.getChannelOperand(pNode.children[1],funcName)
+			if (vNode == nil) || (intTypeName(vNode) == "") {
+				return 0, false
+			}
+			return vNode.numBits, verilogSigned(vNode) != ""
+		}
+		if (len(pNode.children) != 2) {
+			return 0, false
+		}
+		if (pNode.children[0].ruleType == "!") {
+			return 1, false
+		}
+		return l.exprType(pNode.children[1],funcName)
+	}
+	return 0, false
+}
+
+// the arithmetic operators whose result can carry out of the width of its Go type 
+var carryOps = map[string]bool{"+": true, "-": true, "*": true, "<<": true}
+
+// translate an operand of an operator which sees the upper bits of its operands,
+// e.g. a comparison, right shift or mask. Verilog evaluates an expression at the
+// width of its widest operand, so the carry out of a uint16 sum would be seen.
+// An unsigned arithmetic or complemented operand is truncated to the width of its Go type.
+// A signed one narrower than an integer literal wraps as in Go, e.g. an int8 sum of
+// 200 is -56: the low bits are sign extended by flipping the sign bit and subtracting it 
+func (l *argoListener) truncatedOperand(pNode *ParseNode,funcName string) string {
+	var expr string
+	var inner *ParseNode
+	var isArith bool
+
+	expr = l.exprToVerilog(pNode,funcName)
+	inner = pNode.stripParens()
+	isArith = ( (inner.ruleType == "expression") && (len(inner.children) == 3) && (carryOps[inner.children[1].ruleType]) ) ||
+		( (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && ( (inner.children[0].ruleType == "-") || (inner.children[0].ruleType == "^") ) )
+	if (!isArith) {
+		return expr
+	}
+	bits, signed := l.exprType(inner,funcName)
+	if (bits > 0) && (!signed) {
+		return "( " + expr + " & " + widthMask(bits) + " )"
+	}
+	if (bits > 0) && (bits < 32) && (bits < l.intBits) {
+		signBit := fmt.Sprintf("%d'h%x",bits,uint64(1) << uint(bits-1))
+		return fmt.Sprintf("( $signed( { 1'b0, ( ( %s ) & %s ) ^ %s } ) - $signed( %d'd%d ) )",
+			expr,widthMask(bits),signBit,bits+1,uint64(1) << uint(bits-1))
+	}
+	return expr
+}
+
+// the operators which compare integers, as signed if their Go type is signed 
+var compareOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// if the Go type of an expression is a signed integer type 
+func (l *argoListener) isSignedInt(pNode *ParseNode,funcName string) bool {
+	bits, signed := l.exprType(pNode,funcName)
+	return (bits > 0) && (signed)
+}
+
+// if the translation of an expression is signed in Verilog. A signed register,
+// a decimal constant or an arithmetic expression of them is, but a hex constant,
+// a part select of a struct, the data read from a memory or a FIFO, and a
+// concatenation are unsigned. Unknown expressions are taken as unsigned 
+func (l *argoListener) isVerilogSigned(pNode *ParseNode,funcName string) bool {
+	var inner *ParseNode
+
+	inner = pNode.stripParens()
+	if typeName, operand := l.getIntConversion(inner,funcName) ; operand != nil {
+		toBits, toSigned, _ := intTypeBits(typeName,l.intBits)
+		fromBits, fromSigned := l.exprType(operand,funcName)
+		if (!toSigned) {
+			return false
+		}
+		// only a conversion to the same type, or of a constant, is its operand unchanged 
+		if (fromBits == 0) || ( (fromSigned) && (toBits == fromBits) ) {
+			return l.isVerilogSigned(operand,funcName)
+		}
+		return true
+	}
+	if _, ok := l.evalConstExpr(inner,funcName) ; ok {
+		lit := l.exprToVerilog(inner,funcName)
+		return (!strings.Contains(lit,"'")) || (strings.Contains(lit,"'s"))
+	}
+	switch inner.ruleType {
+	case "operand":
+		vNode := l.getVarNodeInScope(funcName,inner.getPlainOperandName(),inner)
+		if (vNode == nil) || (vNode.goLangType != "numeric") || (vNode.structType != nil) {
+			return false
+		}
+		if _, ok := l.substitutes[vNode] ; ok {
+			return false
+		}
+		return verilogSigned(vNode) != ""
+	case "expression":
+		if (len(inner.children) != 3) {
+			return false
+		}
+		switch inner.children[1].ruleType {
+		case "+", "-", "*", "/", "%", "&", "|", "^":
+			return (l.isVerilogSigned(inner.children[0],funcName)) && (l.isVerilogSigned(inner.children[2],funcName))
+		case "<<", ">>":
+			return l.isVerilogSigned(inner.children[0],funcName)
+		}
+	case "unaryExpr":
+		if (len(inner.children) == 2) && ( (inner.children[0].ruleType == "-") || (inner.children[0].ruleType == "^") || (inner.children[0].ruleType == "+") ) {
+			return l.isVerilogSigned(inner.children[1],funcName)
+		}
+	}
+	return false
+}
+
+// an untyped constant as a signed decimal, e.g. 0xff is 255 and not the 8'hff
+// which $signed would make -1 
+func signedConstToVerilog(value int64) string {
+	var sign string
+	var magnitude uint64
+
+	if (value >= -(1 << 31)) && (value < (1 << 31)) {
+		return strconv.FormatInt(value,10)
+	}
+	magnitude = uint64(value)
+	if (value < 0) {
+		sign = "-"
+		magnitude = uint64(-value)
+	}
+	return fmt.Sprintf("%s%d'sd%d",sign,len(strconv.FormatUint(magnitude,2))+1,magnitude)
+}
+
+// translate an operand of a comparison of signed integers. Verilog compares as
+// unsigned if either operand is unsigned, so -1 < 0x10 would be false. An operand
+// which is not signed in Verilog is cast with $signed at the width of its Go type 
+func (l *argoListener) signedOperand(pNode *ParseNode,funcName string) string {
+	var expr string
+
+	if bits, _ := l.exprType(pNode,funcName) ; bits == 0 {
+		if value, ok := l.evalConstExpr(pNode,funcName) ; ok {
+			return signedConstToVerilog(value)
+		}
+	}
+	expr = l.truncatedOperand(pNode,funcName)
+	if (l.isVerilogSigned(pNode,funcName)) {
+		return expr
+	}
+	return "$signed( " + expr + " )"
+}
+
+// translate a comparison of two integers, as signed if either Go type is signed 
+func (l *argoListener) comparisonToVerilog(lhs *ParseNode,op string,rhs *ParseNode,funcName string) string {
+	if (l.isSignedInt(lhs,funcName)) || (l.isSignedInt(rhs,funcName)) {
+		return l.signedOperand(lhs,funcName) + " " + op + " " + l.signedOperand(rhs,funcName)
+	}
+	return l.truncatedOperand(lhs,funcName) + " " + op + " " + l.truncatedOperand(rhs,funcName)
+}
+
+// the integer type name of the register of a variable or the elements of a channel,
+// e.g. uint16, or "" if it is not an integer 
+func intTypeName(vNode *VariableNode) string {
+	if (vNode.structType != nil) || (vNode.numBits <= 0) {
+		return ""
+	}
+	switch vNode.primType {
+	case "int", "uint":
+		return vNode.primType + strconv.Itoa(vNode.numBits)
+	case "byte":
+		return "uint8"
+	case "rune":
+		return "int32"
+	}
+	return ""
+}
+
+// translate an expression sized to the register of a variable or the data of a
+// channel, as if converted to its type. Other expressions are not changed 
+func (l *argoListener) sizedExpr(pNode *ParseNode,funcName string,vNode *VariableNode) string {
+	var typeName string
+
+	typeName = intTypeName(vNode)
+	if (typeName == "") {
+		return l.exprToVerilog(pNode,funcName)
+	}
+	return l.intConversionToVerilog(typeName,pNode,funcName)
+}
+
+// translate a conversion between integer types. Widening is an explicit zero or
+// sign extension and narrowing a truncation to the width of the type. A signed
+// narrowing of an expression, rather than a variable, keeps the low bits but is
+// not sign extended in a wider expression 
+func (l *argoListener) intConversionToVerilog(typeName string,operand *ParseNode,funcName string) string {
+	var toBits, fromBits, regBits int
+	var toSigned, fromSigned, simple bool
+	var expr string
+
+	toBits, toSigned, _ = intTypeBits(typeName,l.intBits)
+	fromBits, fromSigned = l.exprType(operand,funcName)
+	expr = l.truncatedOperand(operand,funcName)
+
+	// a bit-select needs the width of the register, which may be narrowed 
+	simple = verilogIdentifier.MatchString(expr)
+	regBits = fromBits
+	if vNode := l.getVarNodeInScope(funcName,operand.getPlainOperandName(),operand) ; (simple) && (vNode != nil) {
+		regBits = vNode.numBits
+	}
+
+	switch {
+	case (fromBits == 0):
+		// an untyped constant takes the width of the expression 
+		return "( " + expr + " )"
+	case (toBits > fromBits) && (fromSigned) && (simple) && (regBits == fromBits):
+		expr = fmt.Sprintf("{ {%d{%s[%d]}}, %s }",toBits-fromBits,expr,fromBits-1,expr)
+	case (toBits > fromBits) && (fromSigned):
+		return "$signed( " + expr + " )"
+	case (toBits > fromBits) && (simple) && (regBits == fromBits):
+		expr = fmt.Sprintf("{ %d'd0, %s }",toBits-fromBits,expr)
+	case (toBits < fromBits) && (simple) && (regBits > toBits):
+		expr = fmt.Sprintf("%s[%d:0]",expr,toBits-1)
+	case (toBits < fromBits):
+		expr = "( " + expr + " & " + widthMask(toBits) + " )"
+	case (toBits > fromBits) && (toSigned):
+		// a zero bit above an unsigned operand keeps $signed from extending its top bit 
+		return "$signed( { 1'b0, " + expr + " } )"
+	case (toSigned == fromSigned):
+		return "( " + expr + " )"
+	}
+	// a concatenation, bit-select or mask is unsigned 
+	if (toSigned) {
+		return "$signed( " + expr + " )"
+	}
+	if (fromSigned) && (toBits == fromBits) {
+		return "$unsigned( " + expr + " )"
+	}
+	return expr
+}
+
+// the Verilog constant for a named constant. A typed constant is its localparam,
+// and an untyped constant is a decimal of the width of an int 
+func (l *argoListener) constToVerilog(cNode *ConstantNode) string {
+	if (cNode.verilogName != "") {
+		return cNode.verilogName
+	}
+	return l.constLiteral(cNode)
+}
+
+// the literal of the value of a named constant. A typed constant is sized to its type 
+func (l *argoListener) constLiteral(cNode *ConstantNode) string {
+	var value int64
+	var sign, lit string
+
+	value = cNode.value
+	sign = ""
+	if (value < 0) {
+		sign = "-"
+		value = -value
+	}
+	if (cNode.numBits == 0) {
+		lit, _ = verilogIntLiteral(strconv.FormatInt(value,10),l.intBits)
+		return sign + lit
+	}
+	if _, signed, _ := intTypeBits(cNode.typeName,l.intBits) ; signed {
+		return fmt.Sprintf("%s%d'sd%d",sign,cNode.numBits,value)
+	}
+	return fmt.Sprintf("%s%d'd%d",sign,cNode.numBits,value)
+}
+
+// get the channel of a call of len on a channel, e.g. control in len(control), and
+// the index of an element of an array of channels, or nil 
+func (l *argoListener) getChannelLen(pNode *ParseNode,funcName string) (*VariableNode, string) {
+	var exprList []*ParseNode
+
+	pNode = pNode.stripParens()
+	if (pNode.ruleType != "primaryExpr") || (len(pNode.children) != 2) || (pNode.children[1].ruleType != "arguments") {
+		return nil, ""
+	}
+	if (l.getBuiltinCall(pNode.children[1]) != "len") {
+		return nil, ""
+	}
+	exprList = pNode.children[1].walkDownToRule("expressionList").getExpressionList()
+	if (len(exprList) != 1) {
+		return nil, ""
+	}
+	return l.getChannelOperand(exprList[0],funcName)
+}
+
+// translate a comparison of the length of a channel with 0, the polling idiom
+// if (len(control) > 0) { msg = <- control }. The FIFO has no count, so the
+// length is only known to be zero or not, from its empty flag. Returns false
+// if neither operand is the length of a channel 
+func (l *argoListener) chanLenTest(lhs *ParseNode,op string,rhs *ParseNode,funcName string) (string, bool) {
+	var vNode *VariableNode
+	var index string
+	var value int64
+	var ok bool
+
+	// put the length on the left, e.g. 0 < len(ch) is len(ch) > 0 
+	if vNode, index = l.getChannelLen(lhs,funcName) ; vNode != nil {
+		value, ok = l.evalConstExpr(rhs,funcName)
+	} else if vNode, index = l.getChannelLen(rhs,funcName) ; vNode != nil {
+		value, ok = l.evalConstExpr(lhs,funcName)
+		op = map[string]string{"<": ">", ">": "<", "<=": ">=", ">=": "<=", "==": "==", "!=": "!="}[op]
+	} else {
+		return "", false
+	}
+	switch {
+	case (!ok):
+	case ( (op == ">") || (op == "!=") ) && (value == 0), (op == ">=") && (value == 1):
+		return "~" + chanSignal(vNode,"empty",index), true
+	case ( (op == "==") || (op == "<=") ) && (value == 0), (op == "<") && (value == 1):
+		return chanSignal(vNode,"empty",index), true
+	}
+	l.reportError(lhs.sourceLineStart,lhs.sourceColStart,"the length of channel %s can only be compared with 0",vNode.sourceName)
+	return "1'b0", true
+}
+
+// the operand of a binary expression which is its value, if the other operand is
+// an identity of the operator, e.g. x for x + 0, x * 1, x << 0 or x | 0. A constant
+// operand is folded first, so k * ONE is k for a constant ONE = 1. A mask of all
+// ones of the width of the other operand's type is the identity of & 
+func (l *argoListener) identityOperand(lhs *ParseNode,op string,rhs *ParseNode,funcName string) *ParseNode {
+	var value int64
+	var ok bool
+
+	// the identities on either side of a commutative operator 
+	if value, ok = l.evalConstExpr(lhs,funcName) ; ok {
+		switch {
+		case (value == 0) && ( (op == "+") || (op == "|") || (op == "^") ):
+			return rhs
+		case (value == 1) && (op == "*"):
+			return rhs
+		case (op == "&") && (l.isAllOnes(value,rhs,funcName)):
+			return rhs
+		}
+	}
+	if value, ok = l.evalConstExpr(rhs,funcName) ; !ok {
+		return nil
+	}
+	switch op {
+	case "+", "-", "|", "^", "<<", ">>", "&^":
+		if (value == 0) {
+			return lhs
+		}
+	case "*", "/":
+		if (value == 1) {
+			return lhs
+		}
+	case "&":
+		if (l.isAllOnes(value,lhs,funcName)) {
+			return lhs
+		}
+	}
+	return nil
+}
+
+// return true if a constant has all the bits of the type of an expression set,
+// e.g. 0xFF for a uint8, or is -1 
+func (l *argoListener) isAllOnes(value int64,pNode *ParseNode,funcName string) bool {
+	if (value == -1) {
+		return true
+	}
+	bits, _ := l.exprType(pNode,funcName)
+	return (bits > 0) && (bits < 63) && (value == (int64(1) << uint(bits)) - 1)
+}
+
+// translate an expression parse tree into a Verilog expression.
+// Most Go operators are the same in Verilog. The exceptions are:
+// unary ^ (bitwise not) is ~ in Verilog, as Verilog's unary ^ is a reduction xor,
+// unary + is dropped, unary - and logical ! are kept next to their operand,
+// &^ (and not) becomes & ~, and >> becomes >>> which is an arithmetic shift for signed
+// variables and a logical shift for unsigned ones, as in Go.
+// A comparison of len(ch) with 0 is the empty flag of the channel's FIFO.
+// The (x >> n) & mask idiom is lowered to a part-select, and an operator with an
+// identity operand, e.g. x + 0, to its other operand. Integer conversions and
+// the operands of operators which see the upper bits are sized to their Go type 
+func (l *argoListener) exprToVerilog(pNode *ParseNode,funcName string) string {
+	var parts []string
+	var lhs, rhs *ParseNode
+	var op string
+
+	if (pNode == nil) {
+		return ""
+	}
+	if (len(pNode.children) == 0) {
+		return pNode.ruleType
+	}
+
+	// integer literals become Verilog constants of the same base 
+	if (pNode.ruleType == "basicLit") && (len(pNode.children) == 1) {
+		if lit, ok := verilogIntLiteral(pNode.children[0].ruleType,l.intBits) ; ok {
+			return lit
+		}
+	}
+
+	// variables become the name of their Verilog signal, and named constants their value 
+	if (pNode.ruleType == "operandName") && (len(pNode.children) == 1) {
+		if vNode := l.getVarNodeInScope(funcName,pNode.children[0].ruleType,pNode) ; vNode != nil {
+			if subst, ok := l.substitutes[vNode] ; ok {
+				return subst
+			}
+			return verilogVarName(vNode)
+		}
+		if cNode := l.getConstant(funcName,pNode.children[0].ruleType) ; cNode != nil {
+			return l.constToVerilog(cNode)
+		}
+	}
+
+	// a field of a struct variable is a part select of its register 
+	if (pNode.ruleType == "primaryExpr") && (len(pNode.children) == 2) && (pNode.children[1].ruleType == "selector") {
+		if vNode, field, base := l.structFieldBits(pNode,funcName) ; field != nil {
+			return fieldSelect(verilogVarName(vNode),field,base)
+		}
+	}
+
+	// a receive is the data read from the channel's FIFO. A receive from an empty
+	// closed channel is the zero value 
+	if (pNode.ruleType == "unaryExpr") && (len(pNode.children) == 2) && (pNode.children[0].ruleType == "<-") {
+		if vNode, index := l.getChannelOperand(pNode.children[1],funcName) ; vNode != nil {
+			if (l.isClosedChannel(vNode)) {
+				return "( " + vNode.sourceName + "_empty ? 0 : " + vNode.sourceName + "_rd_data )"
+			}
+			return chanSignal(vNode,"rd_data",index)
+		}
+	}
+
+	// an element of an array is the data read from the array's memory 
+	if (pNode.ruleType == "primaryExpr") && (len(pNode.children) == 2) && (pNode.children[1].ruleType == "index") {
+		name, _ := getArrayElement(pNode)
+		if vNode := l.getVarNodeByNames("",funcName,name) ; (vNode != nil) && (vNode.goLangType == "array") {
+			return vNode.sourceName + "_output_data"
+		}
+	}
+
+	// the length of a channel is only supported in a test of if it is empty 
+	if vNode, _ := l.getChannelLen(pNode,funcName) ; vNode != nil {
+		l.reportError(pNode.sourceLineStart,pNode.sourceColStart,"the length of channel %s can only be compared with 0",
+			vNode.sourceName)
+		return "0"
+	}
+
+	// a conversion between integer types extends or truncates its operand 
+	if typeName, operand := l.getIntConversion(pNode,funcName) ; operand != nil {
+		return l.intConversionToVerilog(typeName,operand,funcName)
+	}
+
+	// new allocates a zero value, which the pointer names 
+	if newType := l.getNewType(pNode) ; newType != nil {
+		if _, numBits, _, err := l.getFieldType(newType,make(map[string]bool)) ; (err == nil) && (numBits > 0) {
+			return strconv.Itoa(numBits) + "'d0"
+		}
+		return "0"
+	}
+
+	// a pipelined multiply is the product of its multiplier 
+	if product, ok := l.mulProducts[pNode] ; ok {
+		return product
+	}
+
+	// a call in a condition is the result latched by the node which made it 
+	if hoisted := l.getHoistedCall(pNode) ; hoisted != nil {
+		return hoistedCallName(hoisted)
+	}
+
+	if (pNode.ruleType == "expression") && (len(pNode.children) == 3) {
+		lhs = pNode.children[0]
+		op = pNode.children[1].ruleType
+		rhs = pNode.children[2]
+
+		if test, ok := l.chanLenTest(lhs,op,rhs,funcName) ; ok {
+			return test
+		}
+		// the operand is translated as it would be next to the identity 
+		if operand := l.identityOperand(lhs,op,rhs,funcName) ; operand != nil {
+			if (carryOps[op]) {
+				return l.exprToVerilog(operand,funcName)
+			}
+			return l.truncatedOperand(operand,funcName)
+		}
+		switch op {
+		case "&":
+			if sel, ok := l.bitSelect(lhs,rhs,funcName) ; ok {
+				return sel
+			}
+			if sel, ok := l.bitSelect(rhs,lhs,funcName) ; ok {
+				return sel
+			}
+		case "&^":
+			return l.truncatedOperand(lhs,funcName) + " & ~( " + l.truncatedOperand(rhs,funcName) + " )"
+		case ">>":
+			op = ">>>"
+		case "&&", "||":
+			// the logical operators of Go are those of Verilog on 1 bit bools. Verilog
+			// evaluates both operands, so a call in either is always made, see checkShortCircuit 
+			return l.exprToVerilog(lhs,funcName) + " " + op + " " + l.exprToVerilog(rhs,funcName)
+		}
+		// the carry of an arithmetic operand only matters to the other operators,
+		// and to the amount of a shift 
+		if (op == "<<") {
+			return l.exprToVerilog(lhs,funcName) + " << " + l.indexToVerilog(rhs,funcName)
+		}
+		if (op == ">>>") {
+			return l.truncatedOperand(lhs,funcName) + " >>> " + l.indexToVerilog(rhs,funcName)
+		}
+		if (carryOps[op]) {
+			return l.exprToVerilog(lhs,funcName) + " " + op + " " + l.exprToVerilog(rhs,funcName)
+		}
+		if (compareOps[op]) {
+			return l.comparisonToVerilog(lhs,op,rhs,funcName)
+		}
+		return l.truncatedOperand(lhs,funcName) + " " + op + " " + l.truncatedOperand(rhs,funcName)
+	}
+
+	if (pNode.ruleType == "unaryExpr") && (len(pNode.children) == 2) {
+		operand := l.exprToVerilog(pNode.children[1],funcName)
+		// keep a nested unary operator apart, e.g. - -x is not the Verilog -- 
+		if (strings.HasPrefix(operand,"-")) || (strings.HasPrefix(operand,"~")) || (strings.HasPrefix(operand,"!")) {
+			operand = "( " + operand + " )"
+		}
+		switch pNode.children[0].ruleType {
+		case "^":
+			return "~" + operand
+		case "-":
+			return "-" + operand
+		case "!":
+			return "!" + operand
+		case "+":
+			return operand
+		case "*":
+			// a pointer names the value it points to 
+			return operand
+		}
+	}
+
+	if (pNode.ruleType == "compositeLit") && (len(pNode.children) == 2) {
+		if sType := l.getLiteralStructType(pNode) ; sType != nil {
+			return l.compositeLitConcat(sType,pNode.children[1],funcName)
+		}
+	}
+
+	for _, child := range pNode.children {
+		parts = append(parts,l.exprToVerilog(child,funcName))
+	}
+	return strings.Join(parts," ")
+}
+
+/* ***************************************************** */
+// the name of the Verilog register for a variable. A variable shadowing another
+// of the same name has its own register, and one sharing another's register with
+// -sharemem has the name of that register 
+func verilogVarName(vNode *VariableNode) string {
+	if (vNode.sharedWith != nil) {
+		return verilogVarName(vNode.sharedWith)
+	}
+	if (vNode.verilogName != "") {
+		return vNode.verilogName
+	}
+	return vNode.sourceName
+}
+
+// the name of the module of a function, which is the function name unless it is
+// not a legal Verilog identifier 
+func verilogModuleName(funcNode *FunctionNode) string {
+	if (funcNode.verilogName != "") {
+		return funcNode.verilogName
+	}
+	return funcNode.funcName
+}
+
+// the name of the wire in the caller module connected to a result port of a callee 
+func resultWireName(retVar *VariableNode) string {
+	return "w" + retVar.sourceName 
+}
+
+// return the verilog non-blocking assignment to a variable for a statement.
+// Return statements assign the i-th returned expression to the i-th result, and a call
+// to a function on the right hand side reads the i-th result port of the callee 
+func dataflowAssignment(parsedProgram *argoListener,vNode *VariableNode,sNode *StatementNode) string {
+	var sourceCode string
+	var funcNode, callee *FunctionNode
+	var exprList []*ParseNode
+
+	// the counter of a range over an integer starts at zero and counts up by one 
+	switch sNode.stmtType {
+	case "rangeInit":
+		return verilogVarName(vNode) + " <= 0"
+	case "rangePost":
+		return verilogVarName(vNode) + " <= " + verilogVarName(vNode) + " + 1"
+	}
+
+	if (sNode.stmtType == "returnStmt") && (vNode.isResult) {
+		funcNode = parsedProgram.getFuncNodeByNames("",vNode.funcName)
+		exprList = sNode.parseSubDef.walkDownToRule("expressionList").getExpressionList()
+		if (funcNode != nil) {
+			for k, retVar := range funcNode.retVars {
+				if (retVar == vNode) && (k < len(exprList)) {
+					return verilogVarName(vNode) + " <= " + parsedProgram.exprToVerilog(exprList[k],vNode.funcName)
+				}
+			}
+		}
+		parsedProgram.reportError(sNode.sourceRow,sNode.sourceCol,"no return expression for result %s",vNode.sourceName)
+	}
+
+	// the entry of an inlined function assigns each parameter its argument 
+	if (sNode.stmtType == "functionDecl") && (vNode.isParameter) {
+		funcNode = parsedProgram.getFuncNodeByNames("",vNode.funcName)
+		if (funcNode != nil) && (funcNode.inlineCall != nil) {
+			exprList = parsedProgram.inlineArguments(funcNode)
+			for k, param := range funcNode.parameters {
+				if (param == vNode) && (k < len(exprList)) {
+					return verilogVarName(vNode) + " <= " + parsedProgram.sizedExpr(exprList[k],funcNode.inlineCall.funcName,vNode)
+				}
+			}
+		}
+		parsedProgram.reportError(sNode.sourceRow,sNode.sourceCol,"no argument for parameter %s",vNode.sourceName)
+	}
+
+	// a comma-ok receive assigns the data at the head of the FIFO and if the channel is open 
+	if recvNode := sNode.parseSubDef.getCommaOkRecv() ; recvNode != nil {
+		chanVar := parsedProgram.getVarNodeInScope(vNode.funcName,recvNode.children[1].getPlainOperandName(),recvNode)
+		names := sNode.getLhsNames()
+		if (chanVar != nil) && (len(names) == 2) {
+			if (names[1] == vNode.sourceName) {
+				return verilogVarName(vNode) + " <= " + parsedProgram.chanRecvOk(chanVar)
+			}
+			return verilogVarName(vNode) + " <= " + parsedProgram.exprToVerilog(recvNode,vNode.funcName)
+		}
+	}
+
+	// a compound assignment, e.g. x += y, is x <= x + ( y ) 
+	if op := sNode.parseSubDef.getAssignOp() ; (sNode.stmtType == "assignment") && (op != "") {
+		lhs := parsedProgram.exprToVerilog(sNode.parseSubDef.children[0],vNode.funcName)
+		if product, ok := parsedProgram.mulProducts[sNode.parseSubDef] ; ok {
+			return lhs + " <= " + product
+		}
+		// the right operand wraps at its width before the operator sees it 
+		rhs := parsedProgram.truncatedOperand(sNode.parseSubDef.children[2],vNode.funcName)
+		switch op {
+		case "&^":
+			return lhs + " <= " + lhs + " & ~( " + rhs + " )"
+		case ">>":
+			op = ">>>"
+			rhs = parsedProgram.indexToVerilog(sNode.parseSubDef.children[2],vNode.funcName)
+		case "<<":
+			rhs = parsedProgram.indexToVerilog(sNode.parseSubDef.children[2],vNode.funcName)
+		}
+		return lhs + " <= " + lhs + " " + op + " ( " + rhs + " )"
+	}
+
+	// an inlined callee's results are registers of the same module 
+	callee = parsedProgram.getRhsCallee(sNode)
+	if (callee != nil) && (callee.funcName != vNode.funcName) {
+		for k, name := range sNode.getLhsNames() {
+			if (name == vNode.sourceName) && (k < len(callee.retVars)) {
+				if (callee.inlineCall != nil) {
+					return verilogVarName(vNode) + " <= " + verilogVarName(callee.retVars[k])
+				}
+				return verilogVarName(vNode) + " <= " + resultWireName(callee.retVars[k])
+			}
+		}
+	}
+
+	// a struct literal assigns each field to its bits of the register 
+	if compLit := sNode.getRhsExpr(vNode.sourceName).getCompositeLit() ; compLit != nil {
+		if sType := parsedProgram.getLiteralStructType(compLit) ; sType != nil {
+			return strings.Join(parsedProgram.compositeLitAssignments(verilogVarName(vNode),sType,compLit.children[1],0,vNode.funcName)," ; ")
+		}
+	}
+
+	// a receive is sized from the width of the channel to the width of the register 
+	if rhs := sNode.getRhsExpr(vNode.sourceName) ; (rhs != nil) && (vNode.goLangType == "numeric") {
+		if inner := rhs.stripParens() ; (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && (inner.children[0].ruleType == "<-") {
+			return verilogVarName(vNode) + " <= " + parsedProgram.sizedExpr(rhs,vNode.funcName,vNode)
+		}
+	}
+
+	// the declared names of a short var decl are not operands, so use the register name 
+	if (sNode.stmtType == "shortVarDecl") && (vNode.goLangType == "numeric") {
+		if rhs := sNode.getRhsExpr(vNode.sourceName) ; rhs != nil {
+			return verilogVarName(vNode) + " <= " + parsedProgram.exprToVerilog(rhs,vNode.funcName)
+		}
+	}
+
+	sourceCode = parsedProgram.exprToVerilog(sNode.parseDef,vNode.funcName)
+	if (sNode.stmtType == "shortVarDecl") {
+		sourceCode = strings.Replace(sourceCode,":=","<=",1)
+	} else { 
+		sourceCode = strings.Replace(sourceCode,"=","<=",1)
+	}
+	return sourceCode
+}
+
+// the assignment of a variable by a coalesced chain of assignments. Each right
+// hand side is sized to its variable, so it wraps as the register would, and is
+// substituted for the variable in the assignments after it. The variable is
+// assigned the last value the chain gives it 
+func (l *argoListener) coalescedAssignment(vNode *VariableNode,cNode *CfgNode) string {
+	var rhs string
+
+	l.substitutes = make(map[*VariableNode]string)
+	for k, stmt := range cNode.coalesced {
+		written := cNode.coalescedVars[k]
+		rhs = l.sizedExpr(stmt.getRhsExpr(written.sourceName),stmt.funcName,written)
+		l.substitutes[written] = "( " + rhs + " )"
+	}
+	rhs = l.substitutes[vNode]
+	l.substitutes = nil
+	return verilogVarName(vNode) + " <= " + rhs
+}
+
+/* ***************************************************** */
+// the expression a statement assigns to a variable, or sends on a channel.
+// Returns nil if the statement does not assign the variable a single expression 
+func (sNode *StatementNode) getRhsExpr(varName string) *ParseNode {
+	var rhsList []*ParseNode
+
+	if (sNode.parseSubDef == nil) || (len(sNode.parseSubDef.children) < 3) {
+		return nil
+	}
+	if (sNode.stmtType == "sendStmt") {
+		if (sNode.parseSubDef.children[0].getPlainOperandName() == varName) {
+			return sNode.parseSubDef.children[2]
+		}
+		return nil
+	}
+	if (sNode.stmtType != "shortVarDecl") && (sNode.stmtType != "assignment") {
+		return nil
+	}
+	rhsList = sNode.parseSubDef.children[2].getExpressionList()
+	for k, name := range sNode.getLhsNames() {
+		if (name == varName) && (len(rhsList) == len(sNode.getLhsNames())) {
+			return rhsList[k]
+		}
+	}
+	return nil
+}
+
+// get the field each element of a struct literal value assigns, in order. Keyed
+// elements name their field and positional elements take the fields in order 
+func (l *argoListener) literalFields(sType *StructType,litValue *ParseNode) ([]*StructField, []*ParseNode) {
+	var fields []*StructField
+	var elements []*ParseNode
+	var field *StructField
+	var elementList *ParseNode
+
+	elementList = litValue.walkDownToRule("elementList")
+	if (elementList == nil) {
+		return nil, nil
+	}
+	for pos, keyed := range elementList.children {
+		if (keyed.ruleType != "keyedElement") {
+			continue
+		}
+		field = nil
+		if (len(keyed.children) == 3) {
+			field = sType.getField(strings.TrimSpace(keyed.children[0].getSourceCode()))
+		} else if (pos/2 < len(sType.fields)) {
+			field = sType.fields[pos/2]
+		}
+		if (field == nil) {
+			l.reportError(keyed.sourceLineStart,keyed.sourceColStart,"no field for struct literal element %s",
+				strings.TrimSpace(keyed.getSourceCode()))
+			continue
+		}
+		fields = append(fields,field)
+		elements = append(elements,keyed.children[len(keyed.children)-1])
+	}
+	return fields, elements
+}
+
+// the literalValue of a struct literal element which is itself a struct, e.g. a
+// nested T{...} expression, or nil if the element is not a literal. Go only
+// allows a bare {...} for the elements of arrays, slices and maps 
+func nestedLiteralValue(element *ParseNode) *ParseNode {
+	if (len(element.children) == 0) {
+		return nil
+	}
+	if compLit := element.children[0].getCompositeLit() ; compLit != nil {
+		return compLit.children[1]
+	}
+	return nil
+}
+
+// the part-select of the bits of a field, offset by the base of an enclosing struct 
+func fieldSelect(dest string,field *StructField,base int) string {
+	if (field.numBits == 1) {
+		return fmt.Sprintf("%s[%d]",dest,base+field.offset)
+	}
+	return fmt.Sprintf("%s[%d:%d]",dest,base+field.offset+field.numBits-1,base+field.offset)
+}
+
+// translate a struct literal into an assignment of each field to its bits of the
+// destination. Nested struct literals assign the bits of the nested struct and
+// fields without an element are zero, as in Go 
+func (l *argoListener) compositeLitAssignments(dest string,sType *StructType,litValue *ParseNode,base int,funcName string) []string {
+	var assignments []string
+	var assigned map[*StructField]bool
+	var nested *ParseNode
+
+	assigned = make(map[*StructField]bool)
+	fields, elements := l.literalFields(sType,litValue)
+	for i, field := range fields {
+		assigned[field] = true
+		nested = nestedLiteralValue(elements[i])
+		if (field.structType != nil) && (nested != nil) {
+			assignments = append(assignments,l.compositeLitAssignments(dest,field.structType,nested,base+field.offset,funcName)...)
+			continue
+		}
+		assignments = append(assignments,fieldSelect(dest,field,base) + " <= " + l.exprToVerilog(elements[i],funcName))
+	}
+	for _, field := range sType.fields {
+		if (!assigned[field]) {
+			assignments = append(assignments,fieldSelect(dest,field,base) + " <= 0")
+		}
+	}
+	return assignments
+}
+
+// translate a struct literal in an expression into a concatenation of the fields.
+// Constant fields are sized to the field width 
+func (l *argoListener) compositeLitConcat(sType *StructType,litValue *ParseNode,funcName string) string {
+	var values map[*StructField]string
+	var parts []string
+	var nested *ParseNode
+
+	values = make(map[*StructField]string)
+	fields, elements := l.literalFields(sType,litValue)
+	for i, field := range fields {
+		nested = nestedLiteralValue(elements[i])
+		if (field.structType != nil) && (nested != nil) {
+			values[field] = l.compositeLitConcat(field.structType,nested,funcName)
+		} else if value, ok := elements[i].children[0].getIntLiteral() ; ok {
+			values[field] = fmt.Sprintf("%d'd%d",field.numBits,value)
+		} else {
+			values[field] = l.exprToVerilog(elements[i],funcName)
+		}
+	}
+	for _, field := range sType.fields {
+		if _, ok := values[field] ; ok {
+			parts = append(parts,values[field])
+		} else {
+			parts = append(parts,fmt.Sprintf("%d'd0",field.numBits))
+		}
+	}
+	return "{ " + strings.Join(parts,", ") + " }"
+}
+
+/* ***************************************************** */
+// the value of a variable on reset. A constant initializer in the declaration is
+// the reset value, else the Go zero value 
+func resetValue(vNode *VariableNode) string {
+	if (vNode.initValue != "") {
+		return vNode.initValue
+	}
+	return "0"
+}
+
+// the control nodes writing a variable, with the declaring statement first so a
+// non-constant initializer is the first dataflow action 
+func declFirst(vNode *VariableNode) []*CfgNode {
+	var ordered []*CfgNode
+
+	for _, cNode := range vNode.cfgNodes {
+		if (cNode.statement.parseSubDef != nil) && (cNode.statement.parseSubDef == vNode.parseDef) {
+			ordered = append([]*CfgNode{cNode},ordered...)
+		} else {
+			ordered = append(ordered,cNode)
+		}
+	}
+	return ordered
+}
+
+// the writes of the register of a variable: the control nodes writing the variable,
+// then those writing any variable sharing its register, and the variable written by each 
+func (l *argoListener) registerWrites(vNode *VariableNode) ([]*VariableNode, []*CfgNode) {
+	var writers []*VariableNode
+	var writeNodes []*CfgNode
+
+	for _, other := range l.varNodeList {
+		if (other != vNode) && (other.sharedWith != vNode) {
+			continue
+		}
+		for _, cNode := range declFirst(other) {
+			writers = append(writers,other)
+			writeNodes = append(writeNodes,cNode)
+		}
+	}
+	return writers, writeNodes
+}
+
+/* ***************************************************** */
+// ouput the data flow section 
+func OutputDataflow(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var sMainNode,sSubNode,sNode *StatementNode
+	var pNode *ParseNode
+	var sourceCode string
+	var debugFlags uint64 
+	var DBG_CONTROL_MASK uint64
+	
+	out = parsedProgram.outputFile
+
+	debugFlags = parsedProgram.debugFlags
+	DBG_CONTROL_MASK = 0x1
+	
+	fmt.Fprintf(out,"// -------- Data Flow Section  ---------- \n")
+	for _, vNode := range(parsedProgram.varNodeList) {
+
+		// an array is a memory and a channel a FIFO, not a register. A variable
+		// sharing another's register is written in the block of that register 
+		if (parsedProgram.moduleFuncName(vNode.funcName) == funcName) && (vNode.goLangType != "array") && (vNode.goLangType != "channel") &&
+			(vNode.goLangType != "function") && (vNode.sharedWith == nil) { 
+
+			fmt.Fprintf(out,"%s",sourcePosComment(parsedProgram,vNode.parseDef,vNode.sourceRow,vNode.sourceCol))
+			fmt.Fprintf(out,"always @(posedge clock) begin // dataflow for variable %s \n", vNode.sourceName)
+			fmt.Fprintf(out,"\t if `RESET begin \n ")
+			fmt.Fprintf(out,"\t \t %s <= %s ;  \n ",verilogVarName(vNode),resetValue(vNode))
+			fmt.Fprintf(out," \t end \n")
+			fmt.Fprintf(out," \t else if (ce) begin \n")			
+			writers, writeNodes := parsedProgram.registerWrites(vNode)
+			for i, cNode := range writeNodes {
+				writer := writers[i]
+				sMainNode = cNode.statement
+				sSubNode = cNode.subStmt 
+				// if a cfg node has a sub-node, it is an if or for conditional/post 
+				if (sSubNode != nil) {
+					sNode = sSubNode 				
+				} else {
+					sNode = sMainNode 
+				}
+				pNode = sNode.parseDef 
+				sourceCode = pNode.getSourceCode()
+
+				// Fixme: Need to parse the expression and get the readvars
+
+				if (len(cNode.coalesced) > 0) {
+					sourceCode = parsedProgram.coalescedAssignment(writer,cNode)
+				} else {
+					sourceCode = dataflowAssignment(parsedProgram,writer,sNode)
+				}
+
+				
+				if i == 0 {
+					fmt.Fprintf(out," \t \t if ( %s == 1 ) begin \n", cNode.cannName);
+				} else {
+					fmt.Fprintf(out," if ( %s == 1 ) begin \n", cNode.cannName);
+				}
+				fmt.Fprintf(out," \t \t \t %s ; \n", sourceCode)
+
+				if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+					fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, dataflow %%s \",cycle_count,`__FILE__,`__LINE__,\"" + sourceCode + "\" ) ; \n") ;
+				}
+				if (parsedProgram.traceWrites) {
+					fmt.Fprintf(out," \t \t \t %s ; \n",traceWrite(writer))
+				}
+			
+				fmt.Fprintf(out," \t \t end \n")
+				fmt.Fprintf(out," \t \t else ")
+				
+			}
+		
+			fmt.Fprintf(out," begin \n" )
+			fmt.Fprintf(out," \t \t \t %s <= %s ; \n", verilogVarName(vNode),verilogVarName(vNode));
+			fmt.Fprintf(out," \t \t end \n")
+			fmt.Fprintf(out," \t end \n")		
+			fmt.Fprintf(out,"end \n")
+		
+		}
+	}
+}
+
+// the display of a write of a variable for -trace. $strobe shows the register
+// after the non-blocking assignments of the clock edge, so it is the new value,
+// and the cycle count has also advanced, so the cycle of the write is one less.
+// Structs are shown in hex, as the packed fields do not make one number 
+func traceWrite(vNode *VariableNode) string {
+	var format string
+
+	format = "%0d"
+	if (vNode.structType != nil) {
+		format = "%h"
+	}
+	return fmt.Sprintf("$strobe(\"a2gWrite,%%5d,%s,%s\",cycle_count - 1,%s)",vNode.canName,format,verilogVarName(vNode))
+}
+
+/* ***************************************************** */
+// the name of the control bit a select or switch sets to take its k-th clause 
+func selectCaseName(cNode *CfgNode,k int) string {
+	return cNode.cannName + "_case" + strconv.Itoa(k)
+}
+
+// the assignments of the comm clause bits of a select which take only the
+// k-th clause. A negative k clears all the clause bits 
+func selectCaseAssigns(cNode *CfgNode,k int) string {
+	var assigns string
+
+	for j := range cNode.caseComms {
+		if (j == k) {
+			assigns = assigns + selectCaseName(cNode,j) + " <= 1 ; "
+		} else {
+			assigns = assigns + selectCaseName(cNode,j) + " <= 0 ; "
+		}
+	}
+	return assigns
+}
+
+// return true if a control node takes one of several clauses, each with its own
+// control bit: a select or a switch 
+func isCaseDispatch(cNode *CfgNode) bool {
+	return (cNode.cfgType == "select") || (cNode.cfgType == "switch")
+}
+
+// the control bit a predecessor sets to enter a node. A select or switch sets one
+// bit for each clause and its own bit when no channel is ready or no case matches 
+func edgeBitName(pred *CfgNode,succ *CfgNode) string {
+	var terms []string
+
+	if (!isCaseDispatch(pred)) {
+		return pred.cannName
+	}
+	for k, target := range pred.caseTargets {
+		if (target == succ) {
+			terms = append(terms,selectCaseName(pred,k))
+		}
+	}
+	if (pred.defaultTarget == succ) {
+		terms = append(terms,pred.cannName)
+	}
+	switch len(terms) {
+	case 0:
+		return pred.cannName
+	case 1:
+		return terms[0]
+	}
+	return "( " + strings.Join(terms," | ") + " )"
+}
+
+// the ready condition of a comm clause of a select. A receive is ready when its
+// channel is not empty or is closed, and a send when its channel is not full 
+func (l *argoListener) commReady(comm *ParseNode,funcName string) string {
+	var chanName string
+	var vNode *VariableNode
+
+	if (comm.ruleType == "sendStmt") && (len(comm.children) > 0) {
+		chanName = comm.children[0].getPlainOperandName()
+	} else {
+		for _, unary := range comm.walkDownToAllRules("unaryExpr") {
+			if (len(unary.children) == 2) && (unary.children[0].ruleType == "<-") {
+				chanName = unary.children[1].getPlainOperandName()
+				break
+			}
+		}
+	}
+
+	vNode = l.getVarNodeByNames("",funcName,chanName)
+	if (vNode == nil) || (vNode.goLangType != "channel") {
+		l.reportError(comm.sourceLineStart,comm.sourceColStart,"select case %s is not on a channel",
+			strings.TrimSpace(comm.getSourceCode()))
+		return "1'b0"
+	}
+	if (comm.ruleType == "sendStmt") {
+		return "~" + vNode.sourceName + "_full"
+	}
+	if (l.isClosedChannel(vNode)) {
+		return "( ~" + vNode.sourceName + "_empty | " + chanClosedName(vNode) + " )"
+	}
+	return "~" + vNode.sourceName + "_empty"
+}
+
+// the condition of the k-th case of a switch: the tag equals one of the expressions
+// of the case, or when there is no tag, one of the expressions is true 
+func (l *argoListener) switchCaseMatch(cNode *CfgNode,k int,funcName string) string {
+	var terms []string
+
+	for _, expr := range cNode.caseComms[k].getExpressionList() {
+		if (cNode.caseTag == nil) {
+			terms = append(terms,"( " + l.simplifyCondition(expr,funcName) + " )")
+		} else {
+			terms = append(terms,"( " + l.comparisonToVerilog(cNode.caseTag,"==",expr,funcName) + " )")
+		}
+	}
+	if (len(terms) == 0) {
+		return "1'b0"
+	}
+	return strings.Join(terms," | ")
+}
+
+// the condition which takes the k-th clause of a select or switch 
+func (l *argoListener) caseCondition(cNode *CfgNode,k int,funcName string) string {
+	if (cNode.cfgType == "switch") {
+		return l.switchCaseMatch(cNode,k,funcName)
+	}
+	return l.commReady(cNode.caseComms[k],funcName)
+}
+
+/* ***************************************************** */
+// the Verilog condition of an if test or for conditional node. A for loop
+// with no condition always takes the loop body 
+func (l *argoListener) branchCondition(cNode *CfgNode,funcName string) string {
+	if (cNode.cfgType == "ifTest") {
+		return "( " + l.simplifyCondition(cNode.statement.ifTest.parseDef,funcName) + " )"
+	}
+	// a range over an integer loops while the counter is less than the count 
+	if (cNode.subStmt != nil) && (cNode.subStmt.stmtType == "rangeCond") && (len(cNode.subStmt.readVars) > 0) {
+		rangeNode := cNode.subStmt.parseDef
+		return "( " + verilogVarName(cNode.subStmt.readVars[0]) + " < " + l.exprToVerilog(rangeNode.children[len(rangeNode.children)-1],funcName) + " )"
+	}
+	if (cNode.subStmt != nil) {
+		return "( " + l.simplifyCondition(cNode.subStmt.parseDef,funcName) + " )"
+	}
+	return "( 1 == 1 )"
+}
+
+// the comparison which is true when a comparison is false 
+var invertedComparison = map[string]string{"==": "!=", "!=": "==", "<": ">=", "<=": ">", ">": "<=", ">=": "<"}
+
+// if a boolean expression is constant, return its value. True and false, and
+// comparisons and logical operators of constant operands, are constant 
+func (l *argoListener) evalConstBool(pNode *ParseNode,funcName string) (bool, bool) {
+	var inner *ParseNode
+	var lhs, rhs int64
+	var lhsBool, rhsBool, ok bool
+
+	inner = pNode.stripParens()
+	if name := inner.getPlainOperandName() ; (name == "true") || (name == "false") {
+		if (l.getVarNodeInScope(funcName,name,inner) != nil) {
+			return false, false
+		}
+		return name == "true", true
+	}
+	if (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && (inner.children[0].ruleType == "!") {
+		if lhsBool, ok = l.evalConstBool(inner.children[1],funcName) ; ok {
+			return !lhsBool, true
+		}
+		return false, false
+	}
+	if (inner.ruleType != "expression") || (len(inner.children) != 3) {
+		return false, false
+	}
+	switch op := inner.children[1].ruleType ; op {
+	case "&&", "||":
+		if lhsBool, ok = l.evalConstBool(inner.children[0],funcName) ; !ok {
+			return false, false
+		}
+		if rhsBool, ok = l.evalConstBool(inner.children[2],funcName) ; !ok {
+			return false, false
+		}
+		if (op == "&&") {
+			return lhsBool && rhsBool, true
+		}
+		return lhsBool || rhsBool, true
+	case "==", "!=", "<", "<=", ">", ">=":
+		if lhs, ok = l.evalConstExpr(inner.children[0],funcName) ; !ok {
+			return false, false
+		}
+		if rhs, ok = l.evalConstExpr(inner.children[2],funcName) ; !ok {
+			return false, false
+		}
+		switch op {
+		case "==":
+			return lhs == rhs, true
+		case "!=":
+			return lhs != rhs, true
+		case "<":
+			return lhs < rhs, true
+		case "<=":
+			return lhs <= rhs, true
+		case ">":
+			return lhs > rhs, true
+		}
+		return lhs >= rhs, true
+	}
+	return false, false
+}
+
+// translate a branch condition into a simpler Verilog expression with the same
+// value. Redundant parentheses are dropped, constant comparisons are folded to
+// 1'b1 or 1'b0, a double negation is removed, a negated comparison becomes the
+// inverted comparison, and a logical operator with a constant operand becomes
+// the other operand or a constant 
+func (l *argoListener) simplifyCondition(pNode *ParseNode,funcName string) string {
+	var inner, operand *ParseNode
+	var op string
+
+	inner = pNode.stripParens()
+	if value, ok := l.evalConstBool(inner,funcName) ; ok {
+		if (value) {
+			return "1'b1"
+		}
+		return "1'b0"
+	}
+
+	if (inner.ruleType == "unaryExpr") && (len(inner.children) == 2) && (inner.children[0].ruleType == "!") {
+		operand = inner.children[1].stripParens()
+		if (operand.ruleType == "unaryExpr") && (len(operand.children) == 2) && (operand.children[0].ruleType == "!") {
+			return l.simplifyCondition(operand.children[1],funcName)
+		}
+		if (operand.ruleType == "expression") && (len(operand.children) == 3) {
+			if inverted, ok := invertedComparison[operand.children[1].ruleType] ; ok {
+				return l.truncatedOperand(operand.children[0],funcName) + " " + inverted + " " + l.truncatedOperand(operand.children[2],funcName)
+			}
+		}
+		return "!( " + l.simplifyCondition(operand,funcName) + " )"
+	}
+
+	if (inner.ruleType == "expression") && (len(inner.children) == 3) {
+		op = inner.children[1].ruleType
+		if (op == "&&") || (op == "||") {
+			// true && x is x, false && x is false, true || x is true and false || x is x 
+			for k, side := range []*ParseNode{inner.children[0],inner.children[2]} {
+				if value, ok := l.evalConstBool(side,funcName) ; ok {
+					other := inner.children[2-2*k]
+					if (value == (op == "&&")) {
+						return l.simplifyCondition(other,funcName)
+					}
+					if (value) {
+						return "1'b1"
+					}
+					return "1'b0"
+				}
+			}
+			return l.logicalOperand(inner.children[0],op,funcName) + " " + op + " " + l.logicalOperand(inner.children[2],op,funcName)
+		}
+	}
+	return l.exprToVerilog(inner,funcName)
+}
+
+// translate an operand of a logical operator for simplifyCondition. An operand
+// keeps its parentheses unless it binds more tightly than the operator 
+func (l *argoListener) logicalOperand(pNode *ParseNode,op string,funcName string) string {
+	var inner *ParseNode
+
+	inner = pNode.stripParens()
+	if (inner.ruleType == "expression") && (len(inner.children) == 3) {
+		innerOp := inner.children[1].ruleType
+		if (innerOp == op) || (invertedComparison[innerOp] != "") {
+			return l.simplifyCondition(inner,funcName)
+		}
+		return "( " + l.simplifyCondition(inner,funcName) + " )"
+	}
+	return l.simplifyCondition(inner,funcName)
+}
+
+// the condition of an if or for control node, or the wire it is assigned to with
+// -condwires 
+func (l *argoListener) controlCondition(cNode *CfgNode,funcName string) string {
+	var condition string
+
+	condition = l.branchCondition(cNode,funcName)
+	if name, ok := l.condWireNames[condition] ; (l.condWires) && (ok) {
+		return "( " + name + " )"
+	}
+	return condition
+}
+
+// with -condwires, hoist each distinct condition of the if and for control nodes of
+// a module into a named wire, cond_<id> for the first node with the condition. The
+// control logic uses the wire, so a condition shared by several nodes is computed
+// once and the waveforms show its value 
+func OutputConditionWires(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var condition, name string
+
+	out = parsedProgram.outputFile
+	parsedProgram.condWireNames = make(map[string]string)
+	if (!parsedProgram.condWires) {
+		return
+	}
+	for _, cNode := range parsedProgram.controlFlowGraph {
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) != funcName) {
+			continue
+		}
+		if (cNode.cfgType != "ifTest") && (cNode.cfgType != "forCond") {
+			continue
+		}
+		condition = parsedProgram.branchCondition(cNode,funcName)
+		if _, ok := parsedProgram.condWireNames[condition] ; ok {
+			continue
+		}
+		if (len(parsedProgram.condWireNames) == 0) {
+			fmt.Fprintf(out,"// -------- Condition Section  ---------- \n")
+		}
+		name = "cond_" + strconv.Itoa(cNode.id)
+		parsedProgram.condWireNames[condition] = name
+		fmt.Fprintf(out," \t wire %s ; \n",name)
+		fmt.Fprintf(out," \t assign %s = %s ; \n",name,condition)
+	}
+}
+
+/* ***************************************************** */
+// Ouput the control flow section 
+func OutputControlFlow(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var entryClauses []string
+	var allClauses string
+	var cName string
+	var condition string
+	var debugFlags uint64 
+	var DBG_CONTROL_MASK uint64 
+	
+	out = parsedProgram.outputFile
+	debugFlags = parsedProgram.debugFlags
+	DBG_CONTROL_MASK = 0x1
+	
+	fmt.Fprintf(out, "// -------- Control Flow Section  ---------- \n")
+
+	for i, cNode := range(parsedProgram.controlFlowGraph) {
+
+		// The start node gets its own clause 
+		if (i == 0 ) && (funcName == "main") {
+			fmt.Fprintf(out,"%s",sourcePosComment(parsedProgram,cNode.statement.parseDef,cNode.statement.sourceRow,cNode.statement.sourceCol))
+			fmt.Fprintf(out,"\t always @(posedge clock) begin // control for %s \n",cNode.cannName)
+			fmt.Fprintf(out,"\t \t if `RESET begin \n ")
+			fmt.Fprintf(out,"\t \t \t %s <= 0 ; \n ", cNode.cannName)
+			fmt.Fprintf(out,"\t \t end \n ")
+			fmt.Fprintf(out,"\t \t else if (start == 1) begin \n ")
+			fmt.Fprintf(out,"\t \t \t " + cNode.cannName + " <=  1 ; \n")
+			fmt.Fprintf(out,"\t \t end \n ")						
+			// the start bit is held while the arrays are cleared 
+			if (len(parsedProgram.clearedArrays(funcName)) > 0) {
+				fmt.Fprintf(out,"\t \t else if (ce) begin\n ")
+			} else {
+				fmt.Fprintf(out,"\t \t else begin\n ")
+			}
+			fmt.Fprintf(out,"\t \t \t "  + cNode.cannName + " <=  0 ; \n")
+			fmt.Fprintf(out,"\t \t end \n ")
+			fmt.Fprintf(out,"\t end \n ")				
+			continue
+		}
+		
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) == funcName) {
+			entryClauses = make([]string,0) 
+			allClauses = ""
+			cName = cNode.cannName 
+			// if there must be predecessors for the control node to be reachable 
+			if  ( len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) > 0) || (isCalledEntry(cNode)) {
+
+				// use the sub-statement for the position of if and for clauses 
+				if (cNode.subStmt != nil) {
+					fmt.Fprintf(out,"%s",sourcePosComment(parsedProgram,cNode.subStmt.parseDef,cNode.subStmt.sourceRow,cNode.subStmt.sourceCol))
+				} else {
+					fmt.Fprintf(out,"%s",sourcePosComment(parsedProgram,cNode.statement.parseDef,cNode.statement.sourceRow,cNode.statement.sourceCol))
+				}
+				fmt.Fprintf(out,"always @(posedge clock) begin // control for %s \n",cNode.cannName)	
+
+				fmt.Fprintf(out,"\t if `RESET begin \n ")
+			
+				fmt.Fprintf(out,"\t \t %s <= 0 ; \n ", cNode.cannName)
+
+				if (cNode.cfgType == "ifTest") || (cNode.cfgType == "forCond" ) {
+					fmt.Fprintf(out,"\t \t %s <= 0 ; \n ", cNode.cannName + "_taken" )
+				}
+				for k := range cNode.caseComms {
+					fmt.Fprintf(out,"\t \t %s <= 0 ; \n ", selectCaseName(cNode,k))
+				}
+				
+				fmt.Fprintf(out,"\t end else if (ce) begin \n ")
+
+				// a called function is entered when the caller asserts start 
+				if (isCalledEntry(cNode)) {
+					entryClauses = append(entryClauses,"( " + parsedProgram.startSignal(funcName) + " == 1 )")
+				}
+			
+				for _, pred := range cNode.predecessors {
+					entryClauses = append(entryClauses,"( " + edgeBitName(pred,cNode) + " == 1 )" )
+				}
+			
+				for _, p_taken := range cNode.predecessors_taken {
+					entryClauses = append(entryClauses,"( " + p_taken.cannName + "_taken == 1 )") 
+				}
+				
+				
+				last := len(entryClauses)-1
+				for j, clause := range entryClauses  {
+					allClauses = allClauses + clause
+					if  ( j < last )  {
+						allClauses = allClauses + " || "
+					}
+				}
+
+				fmt.Fprintf(out," \t \t if ( " + allClauses +  " ) begin \n")
+				
+				switch cNode.cfgType { 
+				case "ifTest":
+					condition = parsedProgram.controlCondition(cNode,funcName)
+				
+					fmt.Fprintf(out," \t \t \t if %s begin \n ",condition)
 					takenName := cName + "_taken"
 				
 					fmt.Fprintf(out," \t \t \t \t %s <= 1 ; %s <= 0 ; \n",takenName,cName)
@@ -393,84 +3128,1500 @@ func OutputControlFlow(parsedProgram *argoListener,funcName string) {
 					fmt.Fprintf(out," \t \t \t else begin \n")
 					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 1 ; \n",takenName,cName)
 
-					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
-						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s if_not_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
-					}				
-					fmt.Fprintf(out," \t \t \t end \n")
-					fmt.Fprintf(out," \t \t end \n")				
-					fmt.Fprintf(out," \t \t else begin \n")
-					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 0 ; \n",takenName,cName)
-					fmt.Fprintf(out," \t \t end \n")				
-				case "forCond":
-					if (cNode.subStmt != nil ) {
-						stmtNode = cNode.subStmt
-						pNode = stmtNode.parseDef
-						condition = "( " + pNode.sourceCode + " ) "
-					} else {
-						condition = "( 1 == 1 )"
-					}
-					
-					
-					fmt.Fprintf(out," \t \t \t if %s begin \n ",condition)
-					takenName := cName + "_taken"
-					
-					fmt.Fprintf(out," \t \t \t \t %s <= 1 ; %s <= 0 ; \n",takenName,cName)
-					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
-						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s for_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
-					}			
-					fmt.Fprintf(out," \t \t \t end \n")
-					fmt.Fprintf(out," \t \t \t else begin \n")
-					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 1 ; \n",takenName,cName)
-					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
-						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s for_not_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
+					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s if_not_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
+					}				
+					fmt.Fprintf(out," \t \t \t end \n")
+					fmt.Fprintf(out," \t \t end \n")				
+					fmt.Fprintf(out," \t \t else begin \n")
+					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 0 ; \n",takenName,cName)
+					fmt.Fprintf(out," \t \t end \n")				
+				case "forCond":
+					condition = parsedProgram.controlCondition(cNode,funcName)
+					
+					
+					fmt.Fprintf(out," \t \t \t if %s begin \n ",condition)
+					takenName := cName + "_taken"
+					
+					fmt.Fprintf(out," \t \t \t \t %s <= 1 ; %s <= 0 ; \n",takenName,cName)
+					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s for_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
+					}			
+					fmt.Fprintf(out," \t \t \t end \n")
+					fmt.Fprintf(out," \t \t \t else begin \n")
+					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 1 ; \n",takenName,cName)
+					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s for_not_taken \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
+				}
+					fmt.Fprintf(out," \t \t \t end \n")
+					fmt.Fprintf(out," \t \t end \n")				
+					fmt.Fprintf(out," \t \t else begin \n")
+					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 0 ; \n",takenName,cName)
+					fmt.Fprintf(out," \t \t end \n")
+
+				case "select", "switch":
+					// the first ready comm clause, or matching case, is taken. Else the
+					// node's own bit is set, which enters the default clause. With no
+					// default a select re-enters itself to wait, and a switch goes to its eos 
+					for k := range cNode.caseComms {
+						if (k == 0) {
+							fmt.Fprintf(out," \t \t \t if ( %s ) begin \n",parsedProgram.caseCondition(cNode,k,funcName))
+						} else {
+							fmt.Fprintf(out," \t \t \t else if ( %s ) begin \n",parsedProgram.caseCondition(cNode,k,funcName))
+						}
+						fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s \n",cName,selectCaseAssigns(cNode,k))
+						if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+							fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s %s_case_%d \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n",cNode.cfgType,k) ;
+						}
+						fmt.Fprintf(out," \t \t \t end \n")
+					}
+					if (len(cNode.caseComms) > 0) {
+						fmt.Fprintf(out," \t \t \t else begin \n")
+					} else {
+						fmt.Fprintf(out," \t \t \t begin \n")
+					}
+					fmt.Fprintf(out," \t \t \t \t %s <= 1 ; %s \n",cName,selectCaseAssigns(cNode,-1))
+					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s %s_default \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n",cNode.cfgType) ;
+					}
+					fmt.Fprintf(out," \t \t \t end \n")
+					fmt.Fprintf(out," \t \t end \n")
+					fmt.Fprintf(out," \t \t else begin \n")
+					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s \n",cName,selectCaseAssigns(cNode,-1))
+					fmt.Fprintf(out," \t \t end \n")
+					
+				default:
+					fmt.Fprintf(out," \t \t \t " + cName + " <=  1 ; \n")
+					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
+						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
+					}
+					if cNode.cfgType == "finishNode" {
+						fmt.Fprintf(out," \t \t \t $finish() ; \n" )
+					}
+					fmt.Fprintf(out," \t \t end \n ")
+					fmt.Fprintf(out," \t \t else begin \n ")
+					fmt.Fprintf(out," \t \t \t " + cName + " <=  0 ; \n" )
+					fmt.Fprintf(out," \t \t end \n ")				
+				
+				}
+				fmt.Fprintf(out,"\t end \n")
+				fmt.Fprintf(out,"end // end posedge clock \n ")
+				fmt.Fprintf(out,"\n")			
+			}
+		}
+	}
+}
+/* ***************************************************** */
+// the largest function, in control flow nodes, that is made combinational 
+const MAXCOMBONODES = 64
+
+// return true if a function can be a single combinational block: it is not main,
+// has only numeric variables, no loops, no channel operations, no calls, no I/O and
+// no defers, and its control flow graph is acyclic and small 
+func (l *argoListener) isCombinational(funcNode *FunctionNode) bool {
+	var entryCfg *CfgNode
+	var numNodes int
+	var onPath map[*CfgNode]bool
+	var done map[*CfgNode]bool
+	var hasCycle func(cNode *CfgNode) bool
+
+	if (funcNode.funcName == "main") || (len(funcNode.deferStmts) > 0) || (len(funcNode.retVars) == 0) || (funcNode.receiver != nil) {
+		return false
+	}
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcNode.funcName) && (vNode.goLangType != "numeric") {
+			return false
+		}
+	}
+
+	numNodes = 0
+	for _, cNode := range l.controlFlowGraph {
+		if (cNode.statement.funcName != funcNode.funcName) {
+			continue
+		}
+		numNodes++
+		switch cNode.cfgType {
+		case "forInit", "forCond", "forPost", "break", "continue", "goStmt", "send", "unaryExpr", "condCall", "switch":
+			return false
+		case "funcEntry":
+			entryCfg = cNode
+		case "expression":
+			if (getPrintFunc(cNode.statement.parseDef) != "") {
+				return false
+			}
+		}
+		if (len(cNode.statement.callTargets) > 0) || (len(cNode.statement.goTargets) > 0) {
+			return false
+		}
+		if (cNode.statement.parseDef != nil) && (strings.Contains(cNode.statement.parseDef.getSourceCode(),"<-")) {
+			return false
+		}
+	}
+	if (entryCfg == nil) || (numNodes > MAXCOMBONODES) {
+		return false
+	}
+	// the control flow of an inlined function is not in the statements of the caller 
+	for _, other := range l.funcNodeList {
+		if (other.inlineCall != nil) && (other.inlineCall.funcName == funcNode.funcName) {
+			return false
+		}
+	}
+
+	// a depth first search finds any cycle in the function's control flow graph 
+	onPath = make(map[*CfgNode]bool)
+	done = make(map[*CfgNode]bool)
+	hasCycle = func(cNode *CfgNode) bool {
+		if (cNode == nil) || (done[cNode]) || (cNode.statement.funcName != funcNode.funcName) {
+			return false
+		}
+		if (onPath[cNode]) {
+			return true
+		}
+		onPath[cNode] = true
+		for _, succ := range append(append([]*CfgNode{},cNode.successors...),cNode.successors_taken...) {
+			if (hasCycle(succ)) {
+				return true
+			}
+		}
+		onPath[cNode] = false
+		done[cNode] = true
+		return false
+	}
+	return !hasCycle(entryCfg)
+}
+
+// return true if a statement, or any statement nested in it, is a return 
+func containsReturn(stmt *StatementNode) bool {
+	if (stmt.parseDef == nil) {
+		return false
+	}
+	return (stmt.stmtType == "returnStmt") || (stmt.parseDef.walkDownToRule("returnStmt") != nil)
+}
+
+// output the statements of a list as blocking assignments, starting at the head
+// statement and ending at the stop statement or the function exit.
+// Statements after one that may return are guarded by the returned flag 
+func (l *argoListener) outputComboList(out *os.File,head *StatementNode,stop *StatementNode,funcNode *FunctionNode,indent string) {
+	var mayReturn bool
+	var guard string
+
+	mayReturn = false
+	for stmt := head; (stmt != nil) && (stmt != stop) && (stmt.stmtType != "FuncExit"); {
+		if (stmt.stmtType == "eos") || (stmt.stmtType == "emptyStmt") {
+			if (len(stmt.successors) == 0) {
+				break
+			}
+			stmt = stmt.successors[0]
+			continue
+		}
+
+		guard = indent
+		if (mayReturn) {
+			fmt.Fprintf(out,"%sif (%s == 0) begin \n",indent,comboReturnedName(funcNode))
+			guard = indent + "\t "
+		}
+		l.outputComboStmt(out,stmt,funcNode,guard)
+		if (mayReturn) {
+			fmt.Fprintf(out,"%send \n",indent)
+		}
+		if (containsReturn(stmt)) {
+			mayReturn = true
+		}
+
+		if (stmt.stmtType == "returnStmt") || (len(stmt.successors) == 0) {
+			break
+		}
+		stmt = stmt.successors[0]
+	}
+}
+
+// output one statement of a combinational function 
+func (l *argoListener) outputComboStmt(out *os.File,stmt *StatementNode,funcNode *FunctionNode,indent string) {
+	var sourceCode string
+	var exprList []*ParseNode
+	var elseStmt *StatementNode
+
+	switch stmt.stmtType {
+	case "assignment", "shortVarDecl":
+		sourceCode = l.exprToVerilog(stmt.parseSubDef,funcNode.funcName)
+		if (stmt.stmtType == "shortVarDecl") {
+			sourceCode = strings.Replace(sourceCode,":=","=",1)
+		}
+		fmt.Fprintf(out,"%s%s ; \n",indent,sourceCode)
+	case "incDecStmt":
+		sourceCode = l.exprToVerilog(stmt.parseSubDef.children[0],funcNode.funcName)
+		fmt.Fprintf(out,"%s%s = %s %c 1 ; \n",indent,sourceCode,sourceCode,stmt.parseSubDef.children[1].ruleType[0])
+	case "returnStmt":
+		exprList = stmt.parseSubDef.walkDownToRule("expressionList").getExpressionList()
+		for k, retVar := range funcNode.retVars {
+			if (k < len(exprList)) {
+				fmt.Fprintf(out,"%s%s = %s ; \n",indent,verilogVarName(retVar),l.exprToVerilog(exprList[k],funcNode.funcName))
+			}
+		}
+		fmt.Fprintf(out,"%s%s = 1 ; \n",indent,comboReturnedName(funcNode))
+	case "ifStmt":
+		if (stmt.ifSimple != nil) {
+			l.outputComboStmt(out,stmt.ifSimple,funcNode,indent)
+		}
+		fmt.Fprintf(out,"%sif ( %s ) begin \n",indent,l.simplifyCondition(stmt.ifTest.parseDef,funcNode.funcName))
+		l.outputComboList(out,stmt.ifTaken,stmt.ifExit,funcNode,indent + "\t ")
+		elseStmt = stmt.ifElse
+		if (elseStmt != nil) {
+			fmt.Fprintf(out,"%send else begin \n",indent)
+			if (elseStmt.parseDef.ruleType == "ifStmt") {
+				// the else is the next if of an else-if chain 
+				l.outputComboStmt(out,elseStmt,funcNode,indent + "\t ")
+			} else {
+				l.outputComboList(out,elseStmt,stmt.ifExit,funcNode,indent + "\t ")
+			}
+		}
+		fmt.Fprintf(out,"%send \n",indent)
+	case "expressionStmt", "expression":
+		// an expression with no call or I/O has no effect 
+	default:
+		l.reportError(stmt.sourceRow,stmt.sourceCol,"statement type %s not supported in a combinational function",stmt.stmtType)
+	}
+}
+
+// the name of the flag set when a combinational function has returned 
+func comboReturnedName(funcNode *FunctionNode) string {
+	return funcNode.funcName + "_returned"
+}
+
+// output the body of a combinational function as a single always @(*) block.
+// The results are valid in the same cycle the inputs are 
+func OutputCombinational(parsedProgram *argoListener,funcNode *FunctionNode) {
+	var out *os.File
+	var entryStmt *StatementNode
+	
+	out = parsedProgram.outputFile
+	OutputConstants(parsedProgram,funcNode.funcName)
+	fmt.Fprintf(out,"// -------- Variable Section  ----------\n")
+	for _, vNode := range(parsedProgram.varNodeList) {
+		if (vNode.funcName == funcNode.funcName) { 
+			fmt.Fprintf(out," \t reg %s[%d:0] %s ; \n", verilogSigned(vNode), vNode.numBits-1, verilogVarName(vNode))
+		}
+	}
+	fmt.Fprintf(out," \t reg %s ; \n",comboReturnedName(funcNode))
+
+	entryStmt = parsedProgram.getFunctionStmtEntry(funcNode.funcName)
+	if (entryStmt == nil) {
+		parsedProgram.reportError(funcNode.sourceRow,funcNode.sourceCol,"no entry statement for function %s",funcNode.funcName)
+		return
+	}
+	
+	fmt.Fprintf(out,"// -------- Combinational Section  ---------- \n")
+	fmt.Fprintf(out,"%s",sourcePosComment(parsedProgram,entryStmt.parseDef,entryStmt.sourceRow,entryStmt.sourceCol))
+	fmt.Fprintf(out,"always @(*) begin // combinational function %s \n",funcNode.funcName)
+	// default every local to zero so no latches are inferred 
+	for _, vNode := range(parsedProgram.varNodeList) {
+		if (vNode.funcName == funcNode.funcName) && (!vNode.isParameter) {
+			fmt.Fprintf(out,"\t %s = 0 ; \n",verilogVarName(vNode))
+		}
+	}
+	fmt.Fprintf(out,"\t %s = 0 ; \n",comboReturnedName(funcNode))
+	parsedProgram.outputComboList(out,entryStmt.child,nil,funcNode,"\t ")
+	fmt.Fprintf(out,"end \n")
+}
+
+/* ***************************************************** */
+// output the localparams of the typed constants of the module: those of the package,
+// of the function, and of the functions inlined into it. A localparam has the
+// width and signedness of the constant's type, so it sizes the expressions using it.
+// An untyped enum of iota is an int 
+func OutputConstants(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var signed, typeName string
+
+	out = parsedProgram.outputFile
+	for _, cNode := range parsedProgram.constNodeList {
+		if (cNode.verilogName == "") {
+			continue
+		}
+		if (cNode.funcName != "") && (parsedProgram.moduleFuncName(cNode.funcName) != funcName) {
+			continue
+		}
+		typeName = cNode.typeName
+		if (typeName == "") {
+			typeName = "int"
+		}
+		signed = ""
+		numBits, isSigned, _ := intTypeBits(typeName,parsedProgram.intBits)
+		if (isSigned) {
+			signed = "signed "
+		}
+		fmt.Fprintf(out," \t localparam %s[%d:0] %s = %s ;  // const %s %s \n",signed,numBits-1,cNode.verilogName,
+			parsedProgram.constLiteral(cNode),cNode.name,typeName)
+	}
+}
+
+/* ***************************************************** */
+// instantiate the modules of the functions called by this function and connect
+// the result ports of the callees to wires the caller's dataflow can read.
+// Array arguments connect the array ports of the callee to the caller's memory,
+// and channel arguments the channel ports of the callee to the caller's FIFO.
+// The callee starts when any of the control bits of the calling statements is set 
+func OutputCallInstances(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var calleeNames []string
+	var startBits map[string][]string
+	var callee *FunctionNode
+	var portStr string
+	var arrayArgs []*ArrayArgument
+	var chanArgs []*ChannelArgument
+	
+	out = parsedProgram.outputFile
+	calleeNames, startBits = callStartBits(parsedProgram,funcName)
+	arrayArgs = parsedProgram.getArrayArguments(funcName)
+	chanArgs = parsedProgram.getChannelArguments(funcName)
+	if (len(calleeNames) == 0) {
+		return
+	}
+
+	fmt.Fprintf(out,"// -------- Call Section  ---------- \n")
+	for _, calleeName := range calleeNames {
+		callee = parsedProgram.getFuncNodeByNames("",calleeName)
+		if (callee == nil) {
+			continue
+		}
+		portStr = ""
+		for _, retVar := range callee.retVars {
+			fmt.Fprintf(out," \t wire %s[%d:0] %s ; \n",verilogSigned(retVar),retVar.numBits-1,resultWireName(retVar))
+			portStr = portStr + ", ." + verilogVarName(retVar) + "(" + resultWireName(retVar) + ")"
+		}
+		// the callee reads the data output of the caller's memory directly 
+		for _, arrayArg := range arrayArgs {
+			if (arrayArg.callee != callee) {
+				continue
+			}
+			for _, port := range arrayPortNames(arrayArg.param) {
+				portName := arrayArg.param.sourceName + "_" + port
+				if (port == "output_data") {
+					portStr = portStr + ", ." + portName + "(" + arrayArg.arg.sourceName + "_output_data)"
+					continue
+				}
+				switch port {
+				case "read_addr", "write_addr":
+					fmt.Fprintf(out," \t wire [%d:0] %s ; \n",addrWidth(arraySize(arrayArg.param))-1,arrayArgWireName(arrayArg,port))
+				case "input_data":
+					fmt.Fprintf(out," \t wire [%d:0] %s ; \n",dataWidth(arrayArg.param)-1,arrayArgWireName(arrayArg,port))
+				default:
+					fmt.Fprintf(out," \t wire %s ; \n",arrayArgWireName(arrayArg,port))
+				}
+				portStr = portStr + ", ." + portName + "(" + arrayArgWireName(arrayArg,port) + ")"
+			}
+		}
+		// the callee reads and writes the FIFO of the caller's channel. Its enables
+		// and write data are merged with the caller's in the channel access section 
+		for _, chanArg := range chanArgs {
+			if (chanArg.callee != callee) {
+				continue
+			}
+			for _, port := range parsedProgram.channelPortNames(chanArg.param) {
+				signal := strings.TrimPrefix(port,chanArg.param.sourceName + "_")
+				switch signal {
+				case "rd_data", "empty", "full", "closed":
+					portStr = portStr + ", ." + port + "(" + chanArg.arg.sourceName + "_" + signal + ")"
+					continue
+				case "wr_data":
+					fmt.Fprintf(out," \t wire [%d:0] %s ; \n",dataWidth(chanArg.param)-1,chanArgWireName(chanArg,signal))
+				default:
+					fmt.Fprintf(out," \t wire %s ; \n",chanArgWireName(chanArg,signal))
+				}
+				portStr = portStr + ", ." + port + "(" + chanArgWireName(chanArg,signal) + ")"
+			}
+		}
+		// a method reads the receiver of the calling statement 
+		if (callee.receiver != nil) {
+			portStr = portStr + ", ." + verilogVarName(callee.receiver) + "(" + parsedProgram.receiverArgument(funcName,callee) + ")"
+		}
+		callBits := "( " + strings.Join(startBits[calleeName]," | ") + " )"
+
+		// the callee is busy from the cycle after start until it is done.
+		// Start is only asserted once, not while the caller is stalled 
+		fmt.Fprintf(out," \t reg %s ; \n",calleeBusyName(calleeName))
+		fmt.Fprintf(out," \t wire %s ; \n",calleeDoneName(calleeName))
+		fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+		fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",calleeBusyName(calleeName))
+		fmt.Fprintf(out," \t \t else if (%s == 1) %s <= 0 ; \n",calleeDoneName(calleeName),calleeBusyName(calleeName))
+		fmt.Fprintf(out," \t \t else if %s %s <= 1 ; \n",callBits,calleeBusyName(calleeName))
+		fmt.Fprintf(out," \t end \n")
+		fmt.Fprintf(out," \t %s %s_inst (.clock(clock), .rst(rst), .start(%s & ~%s), .done(%s)%s); \n",
+			verilogModuleName(callee),calleeName,callBits,calleeBusyName(calleeName),calleeDoneName(calleeName),portStr)
+
+		// a call in a condition latches the result when the callee is done. The
+		// module is stalled until then, so the test reads it in the next cycle 
+		for _, hoisted := range parsedProgram.hoistedCalls {
+			if (hoisted.callee != callee) || (hoisted.cfgNode.statement.funcName != funcName) {
+				continue
+			}
+			retVar := callee.retVars[0]
+			fmt.Fprintf(out," \t reg %s[%d:0] %s ; \n",verilogSigned(retVar),retVar.numBits-1,hoistedCallName(hoisted))
+			fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+			fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",hoistedCallName(hoisted))
+			fmt.Fprintf(out," \t \t else if ((%s == 1) && (%s == 1)) %s <= %s ; \n",hoisted.cfgNode.cannName,calleeDoneName(calleeName),
+				hoistedCallName(hoisted),resultWireName(retVar))
+			fmt.Fprintf(out," \t end \n")
+		}
+	}
+}
+
+/* ***************************************************** */
+// the number of cycles of the pipelined multiplier of -mulstyle=seq 
+const MULLATENCY = 3
+
+// the control nodes of a module which multiply with a pipelined multiplier 
+func (l *argoListener) multiplyNodes(funcName string) []*CfgNode {
+	var nodes []*CfgNode
+
+	if (l.mulStyle != "seq") {
+		return nil
+	}
+	for _, cNode := range moduleCfgNodes(l,funcName) {
+		if (cNode.mulDepth() > 0) {
+			nodes = append(nodes,cNode)
+		}
+	}
+	return nodes
+}
+
+// the wire set when the products of the multiplies of a control node are valid 
+func mulDoneName(cNode *CfgNode) string {
+	return cNode.cannName + "_mul_done"
+}
+
+// output a pipelined multiplier for each multiply of a module. The multiply node
+// stalls until a counter of its active cycles reaches the multiplier's latency
+// times the depth of the multiplies, as its operands are held by the stall. The product is the width of the Go type
+// of the multiply, which wraps as in Go 
+func OutputMultipliers(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var nodes []*CfgNode
+	var product, aExpr, bExpr string
+	var bits int
+
+	out = parsedProgram.outputFile
+	nodes = parsedProgram.multiplyNodes(funcName)
+	if (len(nodes) == 0) {
+		return
+	}
+	if (parsedProgram.mulProducts == nil) {
+		parsedProgram.mulProducts = make(map[*ParseNode]string)
+	}
+
+	fmt.Fprintf(out,"// -------- Multiply Section  ---------- \n")
+	for _, cNode := range nodes {
+		mults, depth := cNode.getMultiplies()
+		for k, mult := range mults {
+			aExpr = parsedProgram.exprToVerilog(mult.children[0],funcName)
+			bExpr = parsedProgram.exprToVerilog(mult.children[2],funcName)
+			bits, _ = parsedProgram.exprType(mult.children[0],funcName)
+			if (bits == 0) {
+				bits, _ = parsedProgram.exprType(mult.children[2],funcName)
+			}
+			if (bits == 0) {
+				bits = parsedProgram.intBits
+			}
+			product = cNode.cannName + "_mul" + strconv.Itoa(k) + "_product"
+			fmt.Fprintf(out," \t wire [%d:0] %s ; \n",bits-1,product)
+			fmt.Fprintf(out," \t argo_mult #(.WIDTH(%d),.LATENCY(%d)) %s_mul%d ( \n",bits,MULLATENCY,cNode.cannName,k)
+			fmt.Fprintf(out," \t \t .clock(clock), .a(%s), .b(%s), .product(%s) \n",aExpr,bExpr,product)
+			fmt.Fprintf(out," \t ); \n")
+			parsedProgram.mulProducts[mult] = product
+		}
+		fmt.Fprintf(out," \t reg [%d:0] %s_mul_count ; \n",addrWidth(depth*MULLATENCY+1)-1,cNode.cannName)
+		fmt.Fprintf(out," \t wire %s = ( %s_mul_count == %d ) ; \n",mulDoneName(cNode),cNode.cannName,depth*MULLATENCY)
+		fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+		fmt.Fprintf(out," \t \t if `RESET %s_mul_count <= 0 ; \n",cNode.cannName)
+		fmt.Fprintf(out," \t \t else if (~%s) %s_mul_count <= 0 ; \n",cNode.cannName,cNode.cannName)
+		fmt.Fprintf(out," \t \t else if (~%s) %s_mul_count <= %s_mul_count + 1 ; \n",mulDoneName(cNode),cNode.cannName,cNode.cannName)
+		fmt.Fprintf(out," \t end \n")
+	}
+}
+
+// the receiver a function passes to a called method. Each call selects its
+// receiver while the control bit of the calling statement is set 
+func (l *argoListener) receiverArgument(funcName string,callee *FunctionNode) string {
+	var value, expr string
+	var callCfg *CfgNode
+
+	value = ""
+	for _, stmt := range l.statementGraph {
+		if (stmt.funcName != funcName) || (stmt.parseDef == nil) || (len(stmt.cfgNodes) == 0) {
+			continue
+		}
+		for _, argNode := range stmt.parseDef.walkDownToAllRules("arguments") {
+			recvNode, _ := argNode.getMethodCall()
+			if (recvNode == nil) || (l.getCalleeName(argNode) != callee.funcName) {
+				continue
+			}
+			callCfg = stmt.cfgNodes[0]
+			if (stmt.stmtType == "deferStmt") {
+				callCfg = stmt.cfgNodes[len(stmt.cfgNodes)-1]
+			}
+			expr = l.exprToVerilog(recvNode,funcName)
+			if (value == "") {
+				value = expr
+			} else {
+				value = "( " + callCfg.cannName + " ) ? ( " + expr + " ) : " + value
+			}
+		}
+	}
+	return value
+}
+
+// the functions called by a function, in call order, and for each callee the
+// control bits of the calling statements which start it 
+func callStartBits(parsedProgram *argoListener,funcName string) ([]string, map[string][]string) {
+	var calleeNames []string
+	var startBits map[string][]string
+
+	startBits = make(map[string][]string)
+	for _, stmt := range parsedProgram.statementGraph {
+		if (stmt.funcName != funcName) || (len(stmt.callTargets) == 0) || (len(stmt.cfgNodes) == 0) {
+			continue
+		}
+		for _, target := range stmt.callTargets {
+			// recursive calls are not instantiated 
+			if (target.funcName == funcName) {
+				continue
+			}
+			if _, ok := startBits[target.funcName] ; !ok {
+				calleeNames = append(calleeNames,target.funcName)
+			}
+			// a deferred call starts from the deferred call node at the function exit 
+			callCfg := stmt.cfgNodes[0]
+			if (stmt.stmtType == "deferStmt") {
+				callCfg = stmt.cfgNodes[len(stmt.cfgNodes)-1]
+			}
+			startBits[target.funcName] = append(startBits[target.funcName],deferCallBit(callCfg))
+		}
+	}
+	for _, hoisted := range parsedProgram.hoistedCalls {
+		if (hoisted.cfgNode.statement.funcName != funcName) {
+			continue
+		}
+		if _, ok := startBits[hoisted.callee.funcName] ; !ok {
+			calleeNames = append(calleeNames,hoisted.callee.funcName)
+		}
+		startBits[hoisted.callee.funcName] = append(startBits[hoisted.callee.funcName],hoisted.cfgNode.cannName)
+	}
+	return calleeNames, startBits
+}
+
+// the register of the result latched by the node making a call in a condition 
+func hoistedCallName(hoisted *HoistedCall) string {
+	return hoisted.cfgNode.cannName + "_result"
+}
+
+// the names of the busy register and done wire of a called function 
+func calleeBusyName(calleeName string) string {
+	return calleeName + "_busy"
+}
+
+func calleeDoneName(calleeName string) string {
+	return calleeName + "_done"
+}
+
+// return true if a control node is the entry of a function other than main,
+// which is entered when the caller asserts start. The entry of an inlined
+// function is entered from the control flow of its caller instead 
+func isCalledEntry(cNode *CfgNode) bool {
+	return (cNode.cfgType == "funcEntry") && (cNode.statement.funcName != "main") && (len(cNode.predecessors) == 0)
+}
+
+/* ***************************************************** */
+// output the clock enable of a module. The clock enable is deasserted while the
+// module is stalled: an active send is waiting on a full channel, an active
+// receive on an empty channel which is not closed, a calling statement is waiting
+// for the callee to be done, an array read is waiting for the data from the memory,
+// or a pipelined multiply is waiting for its product.
+// The control flow, dataflow and cycle counter only advance when it is set 
+func OutputClockEnable(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var stallTerms []string
+	var calleeNames []string
+	var startBits map[string][]string
+
+	out = parsedProgram.outputFile
+
+	for _, cNode := range parsedProgram.controlFlowGraph {
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) != funcName) {
+			continue
+		}
+		if ( (len(cNode.predecessors) == 0) && (len(cNode.predecessors_taken) == 0) ) {
+			continue  // never active, so it has no control bit 
+		}
+		if vNode, _, index := parsedProgram.getChannelSend(cNode) ; vNode != nil {
+			stallTerms = append(stallTerms,"( " + cNode.cannName + " & " + chanSignal(vNode,"full",index) + " )")
+		}
+		// a send of a select was ready when the clause was taken, but a send in
+		// the cycle before may have filled the channel since 
+		chans, _, bits := parsedProgram.getSelectSends(cNode)
+		for k, vNode := range chans {
+			stallTerms = append(stallTerms,"( " + bits[k] + " & " + vNode.sourceName + "_full )")
+		}
+		// the data of a receive is valid the cycle after the channel is not empty 
+		chans, indexes := parsedProgram.getChannelRecvs(cNode)
+		for k, vNode := range chans {
+			if (parsedProgram.isClosedChannel(vNode)) {
+				stallTerms = append(stallTerms,"( " + cNode.cannName + " & ~( " + vNode.sourceName + "_empty & " + chanClosedName(vNode) + " ) & ( " +
+					vNode.sourceName + "_empty | ~" + chanReadValidName(vNode) + " ) )")
+				continue
+			}
+			stallTerms = append(stallTerms,"( " + cNode.cannName + " & ( " + chanSignal(vNode,"empty",indexes[k]) + " | ~" + chanReadValidName(vNode) + " ) )")
+		}
+	}
+	// the calling statement stays active until the callee is done, so a call with
+	// no result, e.g. pass(), waits for the callee like an assignment of its result 
+	calleeNames, startBits = callStartBits(parsedProgram,funcName)
+	for _, calleeName := range calleeNames {
+		stallTerms = append(stallTerms,"( ( " + strings.Join(startBits[calleeName]," | ") + " ) & ~" + calleeDoneName(calleeName) + " )")
+	}
+	for _, vNode := range parsedProgram.varNodeList {
+		if (vNode.funcName == funcName) && (vNode.goLangType == "array") {
+			if readBits := parsedProgram.arrayReadBits(vNode) ; len(readBits) > 0 {
+				stallTerms = append(stallTerms,"( ( " + strings.Join(readBits," | ") + " ) & ~" + arrayReadValidName(vNode) + " )")
+			}
+		}
+	}
+	for _, vNode := range parsedProgram.clearedArrays(funcName) {
+		stallTerms = append(stallTerms,arrayClearingName(vNode))
+	}
+	for _, cNode := range parsedProgram.multiplyNodes(funcName) {
+		stallTerms = append(stallTerms,"( " + cNode.cannName + " & ~" + mulDoneName(cNode) + " )")
+	}
+
+	fmt.Fprintf(out,"// -------- Clock Enable Section  ---------- \n")
+	if (len(stallTerms) == 0) {
+		fmt.Fprintf(out," \t wire ce = 1'b1 ; \n")
+		return
+	}
+	fmt.Fprintf(out," \t wire ce = ~( %s ) ; \n",strings.Join(stallTerms," | "))
+}
+
+// output the clearing of the memory of each array after reset. A counter writes a
+// zero to every address, and the module is stalled until all the arrays are clear,
+// so a read before any write returns zero as in Go 
+func OutputArrayClear(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var arrays []*VariableNode
+	var name string
+
+	out = parsedProgram.outputFile
+	arrays = parsedProgram.clearedArrays(funcName)
+	if (len(arrays) == 0) {
+		return
+	}
+	fmt.Fprintf(out,"// -------- Array Clear Section  ---------- \n")
+	for _, vNode := range arrays {
+		name = vNode.sourceName
+		fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+		fmt.Fprintf(out," \t \t if `RESET begin \n")
+		fmt.Fprintf(out," \t \t \t %s <= 1 ; %s_clear_addr <= 0 ; \n",arrayClearingName(vNode),name)
+		fmt.Fprintf(out," \t \t end else if (%s) begin \n",arrayClearingName(vNode))
+		fmt.Fprintf(out," \t \t \t if (%s_clear_addr == %s_SIZE-1) %s <= 0 ; \n",name,verilogParamPrefix(vNode),arrayClearingName(vNode))
+		fmt.Fprintf(out," \t \t \t %s_clear_addr <= %s_clear_addr + 1 ; \n",name,name)
+		fmt.Fprintf(out," \t \t end \n")
+		fmt.Fprintf(out," \t end \n")
+	}
+	fmt.Fprintf(out," \t reg start_held ; \n")
+	fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+	fmt.Fprintf(out," \t \t if `RESET start_held <= 0 ; \n")
+	fmt.Fprintf(out," \t \t else if (start & ~ce) start_held <= 1 ; \n")
+	fmt.Fprintf(out," \t \t else if (ce) start_held <= 0 ; \n")
+	fmt.Fprintf(out," \t end \n")
+}
+
+// output the done port of a module, which is the control bit of the function exit 
+func OutputDone(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+
+	out = parsedProgram.outputFile
+	for _, cNode := range parsedProgram.controlFlowGraph {
+		if (cNode.statement.funcName == funcName) && (cNode.cfgType == "funcExit") {
+			if (len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) > 0) {
+				fmt.Fprintf(out," \t assign done = %s ; \n",cNode.cannName)
+				return
+			}
+		}
+	}
+	fmt.Fprintf(out," \t assign done = 1'b0 ; \n")
+}
+
+/* ***************************************************** */
+// the port names of a channel parameter. These connect to the read and write sides of
+// the channel's FIFO. A receive-only channel has only the read side and a send-only
+// channel only the write side. A closed channel also has the closed flag on the read
+// side and the close on the write side 
+func (l *argoListener) channelPortNames(vNode *VariableNode) []string {
+	var ports []string
+
+	if (vNode.chanDir != "send") {
+		ports = append(ports,vNode.sourceName + "_rd_en",vNode.sourceName + "_rd_data",vNode.sourceName + "_empty")
+		if (l.isClosedChannel(vNode)) {
+			ports = append(ports,chanClosedName(vNode))
+		}
+	}
+	if (vNode.chanDir != "recv") {
+		ports = append(ports,vNode.sourceName + "_wr_en",vNode.sourceName + "_wr_data",vNode.sourceName + "_full")
+		if (l.isClosedChannel(vNode)) {
+			ports = append(ports,chanCloseName(vNode))
+		}
+	}
+	return ports
+}
+
+// output the port declarations for a channel parameter 
+func OutputChannelPorts(parsedProgram *argoListener,vNode *VariableNode) {
+	var out *os.File
+
+	out = parsedProgram.outputFile
+	if (vNode.chanDir != "send") {
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "%s_rd_en;  // read side of channel %s \n",vNode.sourceName,vNode.sourceName)
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "[%d:0] %s_rd_data; \n",dataWidth(vNode)-1,vNode.sourceName)
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "%s_empty; \n",vNode.sourceName)
+		if (parsedProgram.isClosedChannel(vNode)) {
+			fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "%s; \n",chanClosedName(vNode))
+		}
+	}
+	if (vNode.chanDir != "recv") {
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "%s_wr_en;  // write side of channel %s \n",vNode.sourceName,vNode.sourceName)
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "[%d:0] %s_wr_data; \n",dataWidth(vNode)-1,vNode.sourceName)
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "%s_full; \n",vNode.sourceName)
+		if (parsedProgram.isClosedChannel(vNode)) {
+			fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "%s; \n",chanCloseName(vNode))
+		}
+	}
+}
+
+/* ***************************************************** */
+// the direction of a channel as seen by a module 
+func channelDirName(vNode *VariableNode) string {
+	switch vNode.chanDir {
+	case "send":
+		return "send"
+	case "recv":
+		return "receive"
+	}
+	return "send and receive"
+}
+
+// output a comment block listing the interface of a module before the module: the
+// control ports, the results, and the parameters and channels with their direction,
+// width and depth. Scalar parameters are listed, but are not ports of the module 
+func OutputInterfaceSummary(parsedProgram *argoListener,funcNode *FunctionNode) {
+	var out *os.File
+	var access string
+
+	out = parsedProgram.outputFile
+	fmt.Fprintf(out,"// -------- Interface of module %s (%s:%d) ---------- \n",verilogModuleName(funcNode),funcNode.fileName,funcNode.sourceRow)
+	// identifiers which are Verilog keywords are renamed 
+	if (funcNode.verilogName != "") {
+		fmt.Fprintf(out,"//   renamed %s  Go function %s \n",funcNode.verilogName,parsedProgram.goName(funcNode.verilogName))
+	}
+	for _, vNode := range parsedProgram.varNodeList {
+		if _, ok := parsedProgram.goNames[vNode.verilogName] ; (vNode.funcName == funcNode.funcName) && (ok) {
+			fmt.Fprintf(out,"//   renamed %s  Go variable %s \n",vNode.verilogName,parsedProgram.goName(vNode.verilogName))
+		}
+	}
+	fmt.Fprintf(out,"//   input  clock \n")
+	fmt.Fprintf(out,"//   input  rst \n")
+	fmt.Fprintf(out,"//   input  start \n")
+	fmt.Fprintf(out,"//   output done \n")
+	for i, retVar := range funcNode.retVars {
+		fmt.Fprintf(out,"//   output %s  result %d, %d bits \n",verilogVarName(retVar),i,retVar.numBits)
+	}
+	if (funcNode.receiver != nil) {
+		fmt.Fprintf(out,"//   input  %s  receiver %s, %d bits \n",verilogVarName(funcNode.receiver),funcNode.receiver.primType,funcNode.receiver.numBits)
+	}
+	for _, param := range funcNode.parameters {
+		switch param.goLangType {
+		case "channel":
+			fmt.Fprintf(out,"//   channel parameter %s  %s, %d bits, the caller's FIFO \n",param.sourceName,channelDirName(param),dataWidth(param))
+		case "array":
+			access = "read"
+			if (isReadWriteArray(param)) {
+				access = "read-write"
+			}
+			fmt.Fprintf(out,"//   array parameter %s  %s, %d x %d bits, the caller's memory \n",param.sourceName,access,arraySize(param),dataWidth(param))
+		case "function":
+			fmt.Fprintf(out,"//   function parameter %s  calls %s, not a port \n",param.sourceName,param.funcTarget)
+		default:
+			fmt.Fprintf(out,"//   parameter %s  input, %d bits, not a port \n",param.sourceName,param.numBits)
+		}
+	}
+	for _, vNode := range parsedProgram.hoistedChannels(funcNode.funcName) {
+		fmt.Fprintf(out,"//   channel %s  %s, %d bits, depth %d, FIFO in the top module \n",vNode.sourceName,channelDirName(vNode),dataWidth(vNode),vNode.depth)
+	}
+	for _, goStmt := range parsedProgram.goStatements(funcNode.funcName) {
+		fmt.Fprintf(out,"//   output %s  starts goroutine %s \n",goStartName(goStmt.cfgNodes[0]),goStmt.goTargets[0].funcName)
+	}
+	fmt.Fprintf(out,"// ----------------------------------------------- \n")
+}
+
+/* ***************************************************** */
+// return true if the modules have a cycle counter. It is only used by the debug
+// control displays and the write trace, or requested for performance measurement 
+func (l *argoListener) useCycleCounter() bool {
+	var DBG_CONTROL_MASK uint64
+
+	DBG_CONTROL_MASK = 0x1
+	return (l.genPerf) || (l.traceWrites) || ((l.debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK)
+}
+
+func OutputCycleCounter(out *os.File,funcName string) { 
+	fmt.Fprintf(out,"\t // the cycle counter for performance and debugging \n")
+	fmt.Fprintf(out,"\t always @(posedge clock) begin \n")
+	fmt.Fprintf(out," \t \t if `RESET begin \n")
+	fmt.Fprintf(out," \t \t \t cycle_count <= 0; \n")
+	fmt.Fprintf(out," \t \t    end    \n")
+	fmt.Fprintf(out," \t \t    else if (ce) begin \n")
+	fmt.Fprintf(out," \t \t \t cycle_count <= cycle_count + 1 ; \n")
+	fmt.Fprintf(out," \t \t    end \n")
+	fmt.Fprintf(out," \t end \n")
+}
+
+/* ***************************************************** */
+// the stable key of a control node in a trace. The key is the function and the
+// source position and type of the node, so it does not depend on node IDs 
+func traceKey(cNode *CfgNode) string {
+	var row, col int
+
+	row, col = cNode.statement.sourceRow, cNode.statement.sourceCol
+	if (cNode.subStmt != nil) {
+		row, col = cNode.subStmt.sourceRow, cNode.subStmt.sourceCol
+	}
+	return fmt.Sprintf("%s:%d:%d:%s",cNode.statement.funcName,row,col,cNode.cfgType)
+}
+
+// output the trace section. Every cycle the module advances, a single always
+// block displays an a2gTrace line for each active control bit and each variable
+// written, in control flow graph order. As one block emits all the events of a
+// cycle, the order of the lines is canonical and the trace of each function can
+// be diffed against the path of a reference run. The cycle count is left out, so
+// stalls and bubbles do not change the trace 
+func OutputTrace(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+
+	out = parsedProgram.outputFile
+	fmt.Fprintf(out,"// -------- Trace Section  ---------- \n")
+	fmt.Fprintf(out,"always @(posedge clock) begin // canonical trace for %s \n",funcName)
+	fmt.Fprintf(out," \t if ((!`RESET) && (ce)) begin \n")
+	for _, cNode := range parsedProgram.controlFlowGraph {
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) != funcName) {
+			continue
+		}
+		if ( (len(cNode.predecessors) == 0) && (len(cNode.predecessors_taken) == 0) && (!isCalledEntry(cNode)) ) {
+			continue  // never active, so it has no control bit 
+		}
+		key := traceKey(cNode)
+		fmt.Fprintf(out," \t \t if ( %s == 1 ) begin \n",cNode.cannName)
+		fmt.Fprintf(out," \t \t \t $display(\"a2gTrace,%s\") ; \n",key)
+		for _, vNode := range cNode.writeVars {
+			fmt.Fprintf(out," \t \t \t $display(\"a2gTrace,%s,write,%s\") ; \n",key,vNode.sourceName)
+		}
+		fmt.Fprintf(out," \t \t end \n")
+		if (len(cNode.successors_taken) > 0) {
+			fmt.Fprintf(out," \t \t if ( %s_taken == 1 ) $display(\"a2gTrace,%s,taken\") ; \n",cNode.cannName,key)
+		}
+		for k := range cNode.caseComms {
+			fmt.Fprintf(out," \t \t if ( %s == 1 ) $display(\"a2gTrace,%s,case%d\") ; \n",selectCaseName(cNode,k),key,k)
+		}
+	}
+	fmt.Fprintf(out," \t end \n")
+	fmt.Fprintf(out,"end \n")
+}
+
+/* ***************************************************** */
+// the most control bits of a module which can be set in one cycle. The control is
+// one-hot, so this is 1, unless a bit enters more than one node, e.g. a parallel
+// section. Each extra node a bit enters is another thread of control 
+func controlBitBound(parsedProgram *argoListener,funcName string) int {
+	var entered map[string]int
+	var bound int
+
+	entered = make(map[string]int)
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		for _, bit := range nodeEntryBits(cNode) {
+			entered[bit]++
+		}
+	}
+	bound = 1
+	for _, count := range entered {
+		if (count > 1) {
+			bound += count - 1
+		}
+	}
+	return bound
+}
+
+// output the one-hot assertion section. The control bits set are counted every
+// cycle, and if there are more than the control can reach, the simulation stops.
+// Two branches of an if both active, say, means a wrong control edge 
+func OutputOneHotAssert(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var bits []string
+	var bound int
+
+	out = parsedProgram.outputFile
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		bits = append(bits,nodeExitBits(cNode)...)
+	}
+	if (len(bits) == 0) {
+		return
+	}
+	bound = controlBitBound(parsedProgram,funcName)
+	fmt.Fprintf(out,"// -------- One-hot Assertion Section  ---------- \n")
+	fmt.Fprintf(out," \t wire [%d:0] control_count = %s ; \n",bitsForValue(int64(len(bits)))-1,strings.Join(bits," + "))
+	fmt.Fprintf(out,"always @(posedge clock) begin // at most %d control bits of %s are set \n",bound,funcName)
+	fmt.Fprintf(out," \t if ((!`RESET) && (control_count > %d)) begin \n",bound)
+	fmt.Fprintf(out," \t \t $display(\"a2gAssert, %%0d control bits of %s are set at time %%0t, expected at most %d \",control_count,$time) ; \n",funcName,bound)
+	fmt.Fprintf(out," \t \t $finish() ; \n")
+	fmt.Fprintf(out," \t end \n")
+	fmt.Fprintf(out,"end \n")
+}
+
+/* ***************************************************** */
+// the control nodes of a module which have a control bit, in control flow
+// graph order. The start node of the program is a control node of main 
+func moduleCfgNodes(parsedProgram *argoListener,funcName string) []*CfgNode {
+	var nodes []*CfgNode
+
+	for i, cNode := range parsedProgram.controlFlowGraph {
+		if (i == 0) && (funcName == "main") {
+			nodes = append(nodes,cNode)
+			continue
+		}
+		if (parsedProgram.moduleFuncName(cNode.statement.funcName) != funcName) {
+			continue
+		}
+		if (len(cNode.predecessors) > 0) || (len(cNode.predecessors_taken) > 0) || (isCalledEntry(cNode)) {
+			nodes = append(nodes,cNode)
+		}
+	}
+	return nodes
+}
+
+// the control bits a node sets on exit: its own bit, then its taken bit or
+// the bit of each select or switch case 
+func nodeExitBits(cNode *CfgNode) []string {
+	var bits []string
+
+	bits = append(bits,cNode.cannName)
+	if (len(cNode.successors_taken) > 0) {
+		bits = append(bits,cNode.cannName + "_taken")
+	}
+	for k := range cNode.caseComms {
+		bits = append(bits,selectCaseName(cNode,k))
+	}
+	return bits
+}
+
+// the control bits of the predecessors of a node which enter the node 
+func nodeEntryBits(cNode *CfgNode) []string {
+	var bits []string
+
+	for _, pred := range cNode.predecessors {
+		if (pred == nil) {
+			continue
+		}
+		if (!isCaseDispatch(pred)) {
+			bits = append(bits,pred.cannName)
+			continue
+		}
+		for k, target := range pred.caseTargets {
+			if (target == cNode) {
+				bits = append(bits,selectCaseName(pred,k))
+			}
+		}
+		if (pred.defaultTarget == cNode) {
+			bits = append(bits,pred.cannName)
+		}
+	}
+	for _, pTaken := range cNode.predecessors_taken {
+		bits = append(bits,pTaken.cannName + "_taken")
+	}
+	return bits
+}
+
+// map each control bit of a module to the node it enters. Returns false if a
+// bit enters more than one node, as the module then has parallel control which
+// one state register can not encode 
+func fsmTransitions(parsedProgram *argoListener,funcName string) (map[string]*CfgNode, bool) {
+	var nextNode map[string]*CfgNode
+
+	nextNode = make(map[string]*CfgNode)
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		for _, bit := range nodeEntryBits(cNode) {
+			if prev, ok := nextNode[bit] ; (ok) && (prev != cNode) {
+				return nil, false
+			}
+			nextNode[bit] = cNode
+		}
+	}
+	return nextNode, true
+}
+
+// return true if the control of a module is generated as an encoded state machine 
+func (l *argoListener) useFSM(funcName string) bool {
+	if (!l.genFSM) {
+		return false
+	}
+	_, ok := fsmTransitions(l,funcName)
+	return ok
+}
+
+// the name of the state of a control bit 
+func fsmStateName(bit string) string {
+	return "S_" + bit
+}
+
+// output the state register of a module and decode each control bit from it.
+// State 0 is idle, and every control bit of the one-hot scheme is a state, so the
+// dataflow, IO and other sections use the decoded bits unchanged 
+func OutputStateRegister(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var bits []string
+	var width int
+
+	out = parsedProgram.outputFile
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		bits = append(bits,nodeExitBits(cNode)...)
+	}
+	width = bitsForValue(int64(len(bits)))
+
+	// only main has a state for the start node 
+	if (funcName != "main") {
+		fmt.Fprintf(out," \t reg %s ; \n",parsedProgram.controlFlowGraph[0].cannName)
+	}
+	fmt.Fprintf(out," \t localparam S_IDLE = %d'd0 ; \n",width)
+	for i, bit := range bits {
+		fmt.Fprintf(out," \t localparam %s = %d'd%d ; \n",fsmStateName(bit),width,i+1)
+	}
+	fmt.Fprintf(out," \t reg [%d:0] state ; \n",width-1)
+	for _, bit := range bits {
+		fmt.Fprintf(out," \t wire %s = ( state == %s ) ; \n",bit,fsmStateName(bit))
+	}
+}
+
+// the Verilog which enters a control node: the next state is the bit the node sets 
+func (l *argoListener) fsmEnter(cNode *CfgNode,funcName string) string {
+	var enter string
+
+	switch cNode.cfgType {
+	case "ifTest", "forCond":
+		return fmt.Sprintf("if %s state <= %s ; else state <= %s ;",l.controlCondition(cNode,funcName),
+			fsmStateName(cNode.cannName + "_taken"),fsmStateName(cNode.cannName))
+	case "select", "switch":
+		// the first ready comm clause or matching case is taken, else the default,
+		// the wait state of a select, or the eos of a switch 
+		for k := range cNode.caseComms {
+			enter = enter + fmt.Sprintf("if ( %s ) state <= %s ; else ",l.caseCondition(cNode,k,funcName),fsmStateName(selectCaseName(cNode,k)))
+		}
+		return enter + "state <= " + fsmStateName(cNode.cannName) + " ;"
+	case "finishNode":
+		return "begin state <= " + fsmStateName(cNode.cannName) + " ; $finish() ; end"
+	}
+	return "state <= " + fsmStateName(cNode.cannName) + " ;"
+}
+
+// output the control of a module as an encoded state machine. This is an
+// alternative to the one-hot control bits of OutputControlFlow. Each state
+// enters the node its bit leads to, and a bit which enters no node returns to idle.
+// The program starts, and a called function is entered, when start is asserted in idle 
+func OutputFSM(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var nextNode map[string]*CfgNode
+	var entryNode *CfgNode
+
+	out = parsedProgram.outputFile
+	nextNode, _ = fsmTransitions(parsedProgram,funcName)
+
+	entryNode = nil
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		if ((funcName == "main") && (cNode == parsedProgram.controlFlowGraph[0])) || (isCalledEntry(cNode)) {
+			entryNode = cNode
+			break
+		}
+	}
+
+	fmt.Fprintf(out,"// -------- State Machine Section  ---------- \n")
+	fmt.Fprintf(out,"always @(posedge clock) begin // state machine for %s \n",funcName)
+	fmt.Fprintf(out," \t if `RESET begin \n")
+	fmt.Fprintf(out," \t \t state <= S_IDLE ; \n")
+	fmt.Fprintf(out," \t end else if (ce) begin \n")
+	fmt.Fprintf(out," \t \t case (state) \n")
+	fmt.Fprintf(out," \t \t S_IDLE: ")
+	if (entryNode != nil) {
+		fmt.Fprintf(out,"if ( %s == 1 ) %s \n",parsedProgram.startSignal(funcName),parsedProgram.fsmEnter(entryNode,funcName))
+	} else {
+		fmt.Fprintf(out,"state <= S_IDLE ; \n")
+	}
+	for _, cNode := range moduleCfgNodes(parsedProgram,funcName) {
+		for _, bit := range nodeExitBits(cNode) {
+			if next, ok := nextNode[bit] ; ok {
+				fmt.Fprintf(out," \t \t %s: %s \n",fsmStateName(bit),parsedProgram.fsmEnter(next,funcName))
+			} else {
+				fmt.Fprintf(out," \t \t %s: state <= S_IDLE ; \n",fsmStateName(bit))
+			}
+		}
+	}
+	fmt.Fprintf(out," \t \t default: state <= S_IDLE ; \n")
+	fmt.Fprintf(out," \t \t endcase \n")
+	fmt.Fprintf(out," \t end \n")
+	fmt.Fprintf(out,"end \n")
+}
+
+/* ***************************************************** */
+// a goroutine module instance in the top module. Main is the root instance, and
+// every go statement of an instance starts a child instance 
+type GoInstance struct {
+	name     string                      // the instance name 
+	funcNode *FunctionNode               // the function of the module 
+	start    string                      // the signal starting the instance 
+	chans    map[*VariableNode]string    // the channels of the function bound to a FIFO of the top module 
+}
+
+// the go statements of a function which start a function of the program 
+func (l *argoListener) goStatements(funcName string) []*StatementNode {
+	var goStmts []*StatementNode
+
+	for _, stmt := range l.statementGraph {
+		if (stmt.funcName == funcName) && (stmt.stmtType == "goStmt") && (len(stmt.goTargets) > 0) && (len(stmt.cfgNodes) > 0) {
+			goStmts = append(goStmts,stmt)
+		}
+	}
+	return goStmts
+}
+
+// the output port of a module which starts the goroutine of a go statement 
+func goStartName(cNode *CfgNode) string {
+	return cNode.cannName + "_go"
+}
+
+// the variables of the arguments of a go statement, one per parameter of the
+// started function. Arguments which are not a variable are nil 
+func (l *argoListener) goArguments(stmt *StatementNode) []*VariableNode {
+	var args []*VariableNode
+	var argNode, exprListNode *ParseNode
+	var funcNode *FunctionNode
+	var argNum int
+
+	funcNode = l.getFuncNodeByNames("",stmt.goTargets[0].funcName)
+	if (funcNode == nil) {
+		return nil
+	}
+	args = make([]*VariableNode,len(funcNode.parameters))
+	argNode = stmt.parseDef.walkDownToRule("arguments")
+	if (argNode == nil) {
+		return args
+	}
+	exprListNode = argNode.walkDownToRule("expressionList")
+	if (exprListNode == nil) {
+		return args
+	}
+	argNum = 0
+	for _, exprNode := range exprListNode.children {
+		if (exprNode.ruleType != "expression") {
+			continue
+		}
+		if (argNum >= len(args)) {
+			break
+		}
+		args[argNum] = l.getVarNodeInScope(stmt.funcName,exprNode.getPlainOperandName(),exprNode)
+		argNum++
+	}
+	return args
+}
+
+// return true if a channel declared in a function is passed to a goroutine.
+// The FIFO of the channel is in the top module, so the goroutines share it 
+func (l *argoListener) isHoistedChannel(vNode *VariableNode) bool {
+	if (vNode.goLangType != "channel") || (vNode.isParameter) {
+		return false
+	}
+	for _, stmt := range l.goStatements(vNode.funcName) {
+		for _, arg := range l.goArguments(stmt) {
+			if (arg == vNode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the channels declared in a function which are passed to a goroutine 
+func (l *argoListener) hoistedChannels(funcName string) []*VariableNode {
+	var chans []*VariableNode
+
+	for _, vNode := range l.varNodeList {
+		if (vNode.funcName == funcName) && (l.isHoistedChannel(vNode)) {
+			chans = append(chans,vNode)
+		}
+	}
+	return chans
+}
+
+// return true if main starts goroutines, which are connected in a top module 
+func (l *argoListener) useHarness() bool {
+	return len(l.goStatements("main")) > 0
+}
+
+// the name of the top module, from the name of the source file 
+func harnessName(parsedProgram *argoListener) string {
+	return regexp.MustCompile("[^A-Za-z0-9_]").ReplaceAllString(parsedProgram.moduleName,"_") + "_top"
+}
+
+// make the goroutine instances of the top module, starting from main, and bind the
+// channels of each instance to the FIFOs of the top module. A channel declared in
+// an instance gets its own FIFO, and a channel parameter the FIFO of its argument.
+// Returns the instances and the channel declaring each FIFO 
+func (l *argoListener) getGoInstances() ([]*GoInstance, map[string]*VariableNode) {
+	var instances []*GoInstance
+	var fifos map[string]*VariableNode
+	var parents map[*GoInstance]*GoInstance
+	var mainNode, funcNode *FunctionNode
+	var onPath func(inst *GoInstance,funcName string) bool
+
+	fifos = make(map[string]*VariableNode)
+	parents = make(map[*GoInstance]*GoInstance)
+	mainNode = l.getFuncNodeByNames("","main")
+	if (mainNode == nil) {
+		return nil, fifos
+	}
+	instances = append(instances,&GoInstance{name: "main_inst", funcNode: mainNode, start: "start", chans: make(map[*VariableNode]string)})
+
+	// a goroutine starting its own function would need an unbounded number of instances 
+	onPath = func(inst *GoInstance,funcName string) bool {
+		for ; inst != nil ; inst = parents[inst] {
+			if (inst.funcNode.funcName == funcName) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0 ; i < len(instances) ; i++ {
+		inst := instances[i]
+		for _, vNode := range l.hoistedChannels(inst.funcNode.funcName) {
+			inst.chans[vNode] = inst.name + "_" + vNode.sourceName
+			fifos[inst.chans[vNode]] = vNode
+		}
+		for _, stmt := range l.goStatements(inst.funcNode.funcName) {
+			funcNode = l.getFuncNodeByNames("",stmt.goTargets[0].funcName)
+			if (funcNode == nil) {
+				continue
+			}
+			if (onPath(inst,funcNode.funcName)) {
+				l.reportError(stmt.parseDef.sourceLineStart,stmt.parseDef.sourceColStart,"goroutine %s starts itself, which is not supported",
+					funcNode.funcName)
+				continue
+			}
+			child := &GoInstance{name: funcNode.funcName + "_go" + strconv.Itoa(len(instances)), funcNode: funcNode,
+				start: inst.name + "_" + goStartName(stmt.cfgNodes[0]), chans: make(map[*VariableNode]string)}
+			parents[child] = inst
+			for k, arg := range l.goArguments(stmt) {
+				param := funcNode.parameters[k]
+				if (param.goLangType == "array") {
+					l.reportError(stmt.parseDef.sourceLineStart,stmt.parseDef.sourceColStart,"array parameter %s of goroutine %s is not supported",
+						param.sourceName,funcNode.funcName)
 				}
-					fmt.Fprintf(out," \t \t \t end \n")
-					fmt.Fprintf(out," \t \t end \n")				
-					fmt.Fprintf(out," \t \t else begin \n")
-					fmt.Fprintf(out," \t \t \t \t %s <= 0 ; %s <= 0 ; \n",takenName,cName)
-					fmt.Fprintf(out," \t \t end \n")
-					
-				default:
-					fmt.Fprintf(out," \t \t \t " + cName + " <=  1 ; \n")
-					if  ((debugFlags & DBG_CONTROL_MASK) == DBG_CONTROL_MASK) {
-						fmt.Fprintf(out, " \t \t $display(\"a2gDbg,%%5d,%%s,%%4d, at control node %%s \",cycle_count,`__FILE__,`__LINE__,\"" + cName + "\" ) ; \n") ;
-					}
-					if cNode.cfgType == "finishNode" {
-						fmt.Fprintf(out," \t \t \t $finish() ; \n" )
-					}
-					fmt.Fprintf(out," \t \t end \n ")
-					fmt.Fprintf(out," \t \t else begin \n ")
-					fmt.Fprintf(out," \t \t \t " + cName + " <=  0 ; \n" )
-					fmt.Fprintf(out," \t \t end \n ")				
-				
+				if (param.goLangType != "channel") {
+					continue
+				}
+				if fifoName, ok := inst.chans[arg] ; (arg != nil) && (ok) {
+					child.chans[param] = fifoName
+				} else {
+					l.reportError(stmt.parseDef.sourceLineStart,stmt.parseDef.sourceColStart,"channel parameter %s of goroutine %s is not bound to a channel",
+						param.sourceName,funcNode.funcName)
 				}
-				fmt.Fprintf(out,"\t end \n")
-				fmt.Fprintf(out,"end // end posedge clock \n ")
-				fmt.Fprintf(out,"\n")			
 			}
+			instances = append(instances,child)
 		}
 	}
+	return instances, fifos
 }
-/* ***************************************************** */
-func OutputCycleCounter(out *os.File,funcName string) { 
-	fmt.Fprintf(out,"\t // the cycle counter for performance and debugging \n")
-	fmt.Fprintf(out,"\t always @(posedge clock) begin \n")
-	fmt.Fprintf(out," \t \t if `RESET begin \n")
-	fmt.Fprintf(out," \t \t \t cycle_count <= 0; \n")
-	fmt.Fprintf(out," \t \t    end    \n")
-	fmt.Fprintf(out," \t \t    else begin \n")
-	fmt.Fprintf(out," \t \t \t cycle_count <= cycle_count + 1 ; \n")
-	fmt.Fprintf(out," \t \t    end \n")
-	fmt.Fprintf(out," \t end \n")
+
+// output the start ports of the goroutines started by a module. A goroutine
+// starts once, in the cycle the go statement advances 
+func OutputGoStarts(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+
+	out = parsedProgram.outputFile
+	for _, goStmt := range parsedProgram.goStatements(funcName) {
+		fmt.Fprintf(out," \t assign %s = %s & ce ; \n",goStartName(goStmt.cfgNodes[0]),goStmt.cfgNodes[0].cannName)
+	}
+}
+
+// the register set when a defer statement is reached, so its deferred call runs
+// at the function exit 
+func deferArmedName(callCfg *CfgNode) string {
+	return callCfg.cannName + "_armed"
+}
+
+// the bit which runs the call of a control node. A deferred call runs only if
+// its defer statement was reached 
+func deferCallBit(cNode *CfgNode) string {
+	if (cNode.cfgType != "deferCall") {
+		return cNode.cannName
+	}
+	return "( " + cNode.cannName + " & " + deferArmedName(cNode) + " )"
+}
+
+// output the armed flag of each deferred call. The flag is set by the defer
+// statement and cleared when the call runs at the exit, so a return before the
+// defer statement does not make the call 
+func OutputDeferFlags(parsedProgram *argoListener,funcName string) {
+	var out *os.File
+	var deferCfg, callCfg *CfgNode
+
+	out = parsedProgram.outputFile
+	funcNode := parsedProgram.getFuncNodeByNames("",funcName)
+	if (funcNode == nil) || (len(funcNode.deferStmts) == 0) {
+		return
+	}
+	fmt.Fprintf(out,"// -------- Defer Section  ---------- \n")
+	for _, deferStmt := range funcNode.deferStmts {
+		if (len(deferStmt.cfgNodes) < 2) {
+			continue
+		}
+		deferCfg = deferStmt.cfgNodes[0]
+		callCfg = deferStmt.cfgNodes[len(deferStmt.cfgNodes)-1]
+		fmt.Fprintf(out," \t reg %s ; \n",deferArmedName(callCfg))
+		fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+		fmt.Fprintf(out," \t \t if `RESET %s <= 0 ; \n",deferArmedName(callCfg))
+		fmt.Fprintf(out," \t \t else if (%s & ce) %s <= 1 ; \n",deferCfg.cannName,deferArmedName(callCfg))
+		fmt.Fprintf(out," \t \t else if (%s & ce) %s <= 0 ; \n",callCfg.cannName,deferArmedName(callCfg))
+		fmt.Fprintf(out," \t end \n")
+	}
+}
+
+// or a list of terms together, or return the default value if there are none 
+func orTerms(terms []string,defaultValue string) string {
+	if (len(terms) == 0) {
+		return defaultValue
+	}
+	return strings.Join(terms," | ")
+}
+
+// output the top module of a program with goroutines. It instantiates main, a
+// module for every go statement and a FIFO for every channel passed to a goroutine.
+// The write and read enables of the modules sharing a FIFO are or'ed together,
+// and the write data selected by the write enable of its module. A closed channel
+// has a closed flag, set when any of the modules closes it 
+func OutputHarness(parsedProgram *argoListener) {
+	var out *os.File
+	var instances []*GoInstance
+	var fifos map[string]*VariableNode
+	var fifoNames []string
+	var wrEn, wrData, rdEn, closeEn map[string][]string
+	var portStr, fifoName, wrName, dataName, rdName, closeName string
+
+	out = parsedProgram.outputFile
+	instances, fifos = parsedProgram.getGoInstances()
+	wrEn = make(map[string][]string)
+	wrData = make(map[string][]string)
+	rdEn = make(map[string][]string)
+	closeEn = make(map[string][]string)
+
+	fmt.Fprintf(out,"module %s(clock, rst, start, done);\n",harnessName(parsedProgram))
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "clock;  // clock x1 \n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "rst;    // reset. Can set to positve or negative\n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "start;  // start the main function \n")
+	fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "done;  // main has returned \n")
+	fmt.Fprintf(out,"\n")
+	fmt.Fprintf(out,"// -------- Channel Section  ----------\n")
+	for _, inst := range instances {
+		for _, vNode := range parsedProgram.hoistedChannels(inst.funcNode.funcName) {
+			fifoName = inst.chans[vNode]
+			fifoNames = append(fifoNames,fifoName)
+			OutputFifo(out,strings.ToUpper(fifoName),fifoName,fifos[fifoName])
+			if (parsedProgram.isClosedChannel(vNode)) {
+				fmt.Fprintf(out," \t reg %s_closed ; \n",fifoName)
+			}
+		}
+	}
+
+	fmt.Fprintf(out,"// -------- Goroutine Section  ----------\n")
+	for _, inst := range instances {
+		portStr = ""
+		for _, vNode := range append(parsedProgram.hoistedChannels(inst.funcNode.funcName),inst.funcNode.parameters...) {
+			fifoName, ok := inst.chans[vNode]
+			if (!ok) {
+				continue
+			}
+			if (vNode.chanDir != "send") {
+				rdName = inst.name + "_" + vNode.sourceName + "_rd_en"
+				fmt.Fprintf(out," \t wire %s ; \n",rdName)
+				rdEn[fifoName] = append(rdEn[fifoName],rdName)
+				portStr = portStr + ", ." + vNode.sourceName + "_rd_en(" + rdName + ")"
+				portStr = portStr + ", ." + vNode.sourceName + "_rd_data(" + fifoName + "_rd_data)"
+				portStr = portStr + ", ." + vNode.sourceName + "_empty(" + fifoName + "_empty)"
+				if (parsedProgram.isClosedChannel(vNode)) {
+					portStr = portStr + ", ." + chanClosedName(vNode) + "(" + fifoName + "_closed)"
+				}
+			}
+			if (vNode.chanDir != "recv") {
+				wrName = inst.name + "_" + vNode.sourceName + "_wr_en"
+				dataName = inst.name + "_" + vNode.sourceName + "_wr_data"
+				fmt.Fprintf(out," \t wire %s ; \n",wrName)
+				fmt.Fprintf(out," \t wire [%d:0] %s ; \n",dataWidth(vNode)-1,dataName)
+				wrEn[fifoName] = append(wrEn[fifoName],wrName)
+				wrData[fifoName] = append(wrData[fifoName],"( {" + strconv.Itoa(dataWidth(vNode)) + "{" + wrName + "}} & " + dataName + " )")
+				portStr = portStr + ", ." + vNode.sourceName + "_wr_en(" + wrName + ")"
+				portStr = portStr + ", ." + vNode.sourceName + "_wr_data(" + dataName + ")"
+				portStr = portStr + ", ." + vNode.sourceName + "_full(" + fifoName + "_full)"
+				if (parsedProgram.isClosedChannel(vNode)) {
+					closeName = inst.name + "_" + chanCloseName(vNode)
+					fmt.Fprintf(out," \t wire %s ; \n",closeName)
+					closeEn[fifoName] = append(closeEn[fifoName],closeName)
+					portStr = portStr + ", ." + chanCloseName(vNode) + "(" + closeName + ")"
+				}
+			}
+		}
+		for _, goStmt := range parsedProgram.goStatements(inst.funcNode.funcName) {
+			fmt.Fprintf(out," \t wire %s_%s ; \n",inst.name,goStartName(goStmt.cfgNodes[0]))
+			portStr = portStr + ", ." + goStartName(goStmt.cfgNodes[0]) + "(" + inst.name + "_" + goStartName(goStmt.cfgNodes[0]) + ")"
+		}
+		fmt.Fprintf(out," \t wire %s_done ; \n",inst.name)
+		fmt.Fprintf(out," \t %s %s (.clock(clock), .rst(rst), .start(%s), .done(%s_done)%s); \n",
+			verilogModuleName(inst.funcNode),inst.name,inst.start,inst.name,portStr)
+	}
+
+	fmt.Fprintf(out,"// -------- Channel Access Section  ----------\n")
+	for _, fifoName = range fifoNames {
+		fmt.Fprintf(out," \t assign %s_wr_en = %s ; \n",fifoName,orTerms(wrEn[fifoName],"1'b0"))
+		fmt.Fprintf(out," \t assign %s_wr_data = %s ; \n",fifoName,orTerms(wrData[fifoName],"0"))
+		fmt.Fprintf(out," \t assign %s_rd_en = %s ; \n",fifoName,orTerms(rdEn[fifoName],"1'b0"))
+		if (parsedProgram.isClosedChannel(fifos[fifoName])) {
+			fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+			fmt.Fprintf(out," \t \t if `RESET %s_closed <= 0 ; \n",fifoName)
+			fmt.Fprintf(out," \t \t else if ( %s ) %s_closed <= 1 ; \n",orTerms(closeEn[fifoName],"1'b0"),fifoName)
+			fmt.Fprintf(out," \t end \n")
+		}
+	}
+	fmt.Fprintf(out," \t assign done = main_inst_done ; \n")
+	fmt.Fprintf(out,"endmodule // %s \n",harnessName(parsedProgram))
+	fmt.Fprintf(out,"// ----------------------------------------------- \n")
 }
 
 func OutputVerilog(parsedProgram *argoListener,genTestBench bool,max_cycles int) {
 	var out *os.File
 	var funcNode *FunctionNode
 	var funcName string
+	var DBG_TRACE_MASK uint64 
 	
 	// out := parsedProgram.outputFile
 	out = parsedProgram.outputFile 
+	DBG_TRACE_MASK = 0x2
+
+	if (parsedProgram.timescale != "") {
+		fmt.Fprintf(out,"`timescale %s \n",parsedProgram.timescale)
+	}
+	if (parsedProgram.strictNets) {
+		fmt.Fprintf(out,"`default_nettype none \n")
+	}
 
 	if (genTestBench)  {
 		OutputTestBench(parsedProgram,max_cycles)
@@ -479,34 +4630,285 @@ func OutputVerilog(parsedProgram *argoListener,genTestBench bool,max_cycles int)
 	// each Go function maps to a verilog Module 
 	for _, funcNode = range parsedProgram.funcNodeList {
 
+		// an inlined function is part of the module of its caller 
+		if (funcNode.inlineCall != nil) {
+			continue
+		}
 		funcName = funcNode.funcName 
-		fmt.Fprintf(out,"module %s(clock, rst,start);\n",funcName)
-		fmt.Fprintf(out,"\t input clock;  // clock x1 \n") 
-		fmt.Fprintf(out,"\t input rst;    // reset. Can set to positve or negative\n")
-		fmt.Fprintf(out,"\t input start;  // start the function \n")
+		// every result of the function is an output port 
+		portList := "clock, rst,start,done"
+		for _, retVar := range funcNode.retVars {
+			portList = portList + ", " + verilogVarName(retVar)
+		}
+		if (funcNode.receiver != nil) {
+			portList = portList + ", " + verilogVarName(funcNode.receiver)
+		}
+		for _, param := range funcNode.parameters {
+			if (param.goLangType == "channel") {
+				for _, port := range parsedProgram.channelPortNames(param) {
+					portList = portList + ", " + port
+				}
+			}
+			if (param.goLangType == "array") {
+				for _, port := range arrayPortNames(param) {
+					portList = portList + ", " + param.sourceName + "_" + port
+				}
+			}
+		}
+		for _, vNode := range parsedProgram.hoistedChannels(funcName) {
+			for _, port := range parsedProgram.channelPortNames(vNode) {
+				portList = portList + ", " + port
+			}
+		}
+		for _, goStmt := range parsedProgram.goStatements(funcName) {
+			portList = portList + ", " + goStartName(goStmt.cfgNodes[0])
+		}
+		OutputInterfaceSummary(parsedProgram,funcNode)
+		fmt.Fprintf(out,"module %s(%s);\n",verilogModuleName(funcNode),portList)
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "clock;  // clock x1 \n") 
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "rst;    // reset. Can set to positve or negative\n")
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "start;  // start the function \n")
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "done;  // the function has returned \n")
+		for i, retVar := range funcNode.retVars {
+			fmt.Fprintf(out,"\t output [%d:0] %s;  // result %d \n",retVar.numBits-1,verilogVarName(retVar),i)
+		}
+		if (funcNode.receiver != nil) {
+			fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "%s[%d:0] %s;  // receiver \n",verilogSigned(funcNode.receiver),funcNode.receiver.numBits-1,verilogVarName(funcNode.receiver))
+		}
+		for _, param := range funcNode.parameters {
+			if (param.goLangType == "channel") {
+				OutputChannelPorts(parsedProgram,param)
+			}
+			if (param.goLangType == "array") {
+				OutputArrayPorts(parsedProgram,param)
+			}
+		}
+		for _, vNode := range parsedProgram.hoistedChannels(funcName) {
+			OutputChannelPorts(parsedProgram,vNode)
+		}
+		for _, goStmt := range parsedProgram.goStatements(funcName) {
+			fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "%s;  // start the goroutine %s \n",goStartName(goStmt.cfgNodes[0]),goStmt.goTargets[0].funcName)
+		}
 		fmt.Fprintf(out,"\n")
 	
 		fmt.Fprintf(out,"\n \t `define RESET (rst) \n")
 
 		fmt.Fprintf(out,"\n")
+
+		// small leaf functions need no control flow 
+		if (parsedProgram.genCombo) && (parsedProgram.isCombinational(funcNode)) {
+			OutputCombinational(parsedProgram,funcNode)
+			fmt.Fprintf(out," \t assign done = start ; \n")
+			fmt.Fprintf(out,"endmodule \n")
+			fmt.Fprintf(out,"// ----------------------------------------------- \n")
+			continue 
+		}
 		
+		if (parsedProgram.genFSM) && (!parsedProgram.useFSM(funcName)) {
+			parsedProgram.reportWarning(funcNode.sourceRow,funcNode.sourceCol,"function %s has parallel control, using one-hot control bits",funcName)
+		}
+
+		OutputConstants(parsedProgram,funcName)
+
 		OutputVariables(parsedProgram,funcName)
 
+		OutputChannels(parsedProgram,funcName)
+
+		OutputDone(parsedProgram,funcName)
+
 		//OutputInitialization(parsedProgram)
 
+		OutputCallInstances(parsedProgram,funcName)
+
+		OutputMultipliers(parsedProgram,funcName)
+
+		OutputClockEnable(parsedProgram,funcName)
+
+		OutputArrayClear(parsedProgram,funcName)
+
+		OutputGoStarts(parsedProgram,funcName)
+
+		OutputDeferFlags(parsedProgram,funcName)
+
+		OutputArrayAccess(parsedProgram,funcName)
+
+		OutputChannelAccess(parsedProgram,funcName)
+
 		OutputIO(parsedProgram,funcName)
 		
 		OutputDataflow(parsedProgram,funcName)
+
+		OutputConditionWires(parsedProgram,funcName)
 		
-		OutputControlFlow(parsedProgram,funcName)
+		if (parsedProgram.useFSM(funcName)) {
+			OutputFSM(parsedProgram,funcName)
+		} else {
+			OutputControlFlow(parsedProgram,funcName)
+		}
+
+		if ((parsedProgram.debugFlags & DBG_TRACE_MASK) == DBG_TRACE_MASK) {
+			OutputTrace(parsedProgram,funcName)
+		}
+
+		if (parsedProgram.assertOneHot) {
+			OutputOneHotAssert(parsedProgram,funcName)
+		}
 
-		OutputCycleCounter(out,funcName)
+		if (parsedProgram.useCycleCounter()) {
+			OutputCycleCounter(out,funcName)
+		}
 		
 		fmt.Fprintf(out,"endmodule \n")
 		fmt.Fprintf(out,"// ----------------------------------------------- \n")
 	}
-		
 
+	if (parsedProgram.useHarness()) {
+		OutputHarness(parsedProgram)
+	}
+	if (parsedProgram.ioInterface == "readyvalid") {
+		OutputReadyValid(parsedProgram)
+	}
+	if (parsedProgram.resetSync) {
+		OutputResetSync(parsedProgram)
+	}
+	// restore the default for the FIFO and memory files compiled after this one 
+	if (parsedProgram.strictNets) {
+		fmt.Fprintf(out,"`default_nettype wire \n")
+	}
+}
+
+// the net type of a port with no other declaration. With -strictnets there are no
+// implicit nets, so such a port is declared a wire 
+func portNet(parsedProgram *argoListener) string {
+	if (parsedProgram.strictNets) {
+		return "wire "
+	}
+	return ""
+}
+
+// the name of the module a test bench or another design instantiates: main, the
+// top module of a program with goroutines, or the ready/valid wrapper of either 
+func topModuleName(parsedProgram *argoListener) string {
+	if (parsedProgram.resetSync) {
+		return resetSyncName(parsedProgram)
+	}
+	if (parsedProgram.ioInterface == "readyvalid") {
+		return readyValidName(parsedProgram)
+	}
+	if (parsedProgram.useHarness()) {
+		return harnessName(parsedProgram)
+	}
+	return "main"
+}
+
+// the name of the ready/valid wrapper of the top module 
+func readyValidName(parsedProgram *argoListener) string {
+	return regexp.MustCompile("[^A-Za-z0-9_]").ReplaceAllString(parsedProgram.moduleName,"_") + "_rv"
+}
+
+// output a wrapper of the top module with ready/valid handshakes in place of the
+// start and done bits, as an AXI-Stream like source and sink. A run of main starts
+// on an input transfer, when in_valid and in_ready are both set. in_ready is
+// set while main is idle and no output is waiting. When main returns, out_valid
+// is set and held until the output transfer, when out_ready is set. Main has no
+// parameters or results, so the transfers carry no data 
+func OutputReadyValid(parsedProgram *argoListener) {
+	var out *os.File
+	var innerName string
+
+	out = parsedProgram.outputFile
+	innerName = "main"
+	if (parsedProgram.useHarness()) {
+		innerName = harnessName(parsedProgram)
+	}
+
+	fmt.Fprintf(out,"module %s(clock, rst, in_valid, in_ready, out_valid, out_ready);\n",readyValidName(parsedProgram))
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "clock;  // clock x1 \n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "rst;    // reset. Can set to positve or negative\n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "in_valid;  // the source asks to start a run of main \n")
+	fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "in_ready;  // main is idle and takes the input \n")
+	fmt.Fprintf(out,"\t output reg out_valid;  // main has returned \n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "out_ready;  // the sink takes the output \n")
+	fmt.Fprintf(out,"\n")
+	fmt.Fprintf(out,"\n \t `define RESET (rst) \n")
+	fmt.Fprintf(out," \t reg busy ; \n")
+	fmt.Fprintf(out," \t wire start ; \n")
+	fmt.Fprintf(out," \t wire done ; \n")
+	fmt.Fprintf(out," \t assign in_ready = ~busy & ~out_valid ; \n")
+	fmt.Fprintf(out," \t assign start = in_valid & in_ready ; \n")
+	fmt.Fprintf(out," \t always @(posedge clock) begin \n")
+	fmt.Fprintf(out," \t \t if `RESET begin \n")
+	fmt.Fprintf(out," \t \t \t busy <= 0 ; \n")
+	fmt.Fprintf(out," \t \t \t out_valid <= 0 ; \n")
+	fmt.Fprintf(out," \t \t end else begin \n")
+	fmt.Fprintf(out," \t \t \t if (start) busy <= 1 ; \n")
+	fmt.Fprintf(out," \t \t \t else if (done) busy <= 0 ; \n")
+	fmt.Fprintf(out," \t \t \t if (busy & done) out_valid <= 1 ; \n")
+	fmt.Fprintf(out," \t \t \t else if (out_ready) out_valid <= 0 ; \n")
+	fmt.Fprintf(out," \t \t end \n")
+	fmt.Fprintf(out," \t end \n")
+	fmt.Fprintf(out," \t %s top_inst (.clock(clock), .rst(rst), .start(start), .done(done)); \n",innerName)
+	fmt.Fprintf(out,"endmodule // %s \n",readyValidName(parsedProgram))
+	fmt.Fprintf(out,"// ----------------------------------------------- \n")
+}
+
+// the name of the reset synchronizer wrapper of the top module 
+func resetSyncName(parsedProgram *argoListener) string {
+	return regexp.MustCompile("[^A-Za-z0-9_]").ReplaceAllString(parsedProgram.moduleName,"_") + "_rsync"
+}
+
+// output a wrapper of the top module which passes the reset through two flops.
+// The reset is asserted asynchronously and released on the second clock after the
+// input is released, so the release cannot be metastable in the top module. The
+// reset is active high, as the `RESET of every module 
+func OutputResetSync(parsedProgram *argoListener) {
+	var out *os.File
+	var innerName string
+
+	out = parsedProgram.outputFile
+	innerName = "main"
+	if (parsedProgram.useHarness()) {
+		innerName = harnessName(parsedProgram)
+	}
+	if (parsedProgram.ioInterface == "readyvalid") {
+		innerName = readyValidName(parsedProgram)
+	}
+
+	if (parsedProgram.ioInterface == "readyvalid") {
+		fmt.Fprintf(out,"module %s(clock, rst, in_valid, in_ready, out_valid, out_ready);\n",resetSyncName(parsedProgram))
+	} else {
+		fmt.Fprintf(out,"module %s(clock, rst, start, done);\n",resetSyncName(parsedProgram))
+	}
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "clock;  // clock x1 \n")
+	fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "rst;    // asynchronous reset, active high \n")
+	if (parsedProgram.ioInterface == "readyvalid") {
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "in_valid;  // the source asks to start a run of main \n")
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "in_ready;  // main is idle and takes the input \n")
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "out_valid;  // main has returned \n")
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "out_ready;  // the sink takes the output \n")
+	} else {
+		fmt.Fprintf(out,"\t input " + portNet(parsedProgram) + "start;  // start the main program \n")
+		fmt.Fprintf(out,"\t output " + portNet(parsedProgram) + "done;  // main has returned \n")
+	}
+	fmt.Fprintf(out,"\n")
+	fmt.Fprintf(out," \t reg rst_meta ;  // first flop, may be metastable on the release \n")
+	fmt.Fprintf(out," \t reg rst_sync ;  // the synchronized reset of the top module \n")
+	fmt.Fprintf(out," \t always @(posedge clock or posedge rst) begin \n")
+	fmt.Fprintf(out," \t \t if (rst) begin \n")
+	fmt.Fprintf(out," \t \t \t rst_meta <= 1 ; \n")
+	fmt.Fprintf(out," \t \t \t rst_sync <= 1 ; \n")
+	fmt.Fprintf(out," \t \t end else begin \n")
+	fmt.Fprintf(out," \t \t \t rst_meta <= 0 ; \n")
+	fmt.Fprintf(out," \t \t \t rst_sync <= rst_meta ; \n")
+	fmt.Fprintf(out," \t \t end \n")
+	fmt.Fprintf(out," \t end \n")
+	if (parsedProgram.ioInterface == "readyvalid") {
+		fmt.Fprintf(out," \t %s top_inst (.clock(clock), .rst(rst_sync), .in_valid(in_valid), .in_ready(in_ready), .out_valid(out_valid), .out_ready(out_ready)); \n",innerName)
+	} else {
+		fmt.Fprintf(out," \t %s top_inst (.clock(clock), .rst(rst_sync), .start(start), .done(done)); \n",innerName)
+	}
+	fmt.Fprintf(out,"endmodule // %s \n",resetSyncName(parsedProgram))
+	fmt.Fprintf(out,"// ----------------------------------------------- \n")
 }
 
 
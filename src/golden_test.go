@@ -0,0 +1,120 @@
+// golden file test of the Verilog output. Each program in ../test is translated
+// with the default flags, as ./argo2verilog -i ../test/x.go -o x.v does, and the
+// Verilog compared with ../test/golden/x.v. Run it from this directory with
+// make golden, and rewrite the golden files with make golden-update after a
+// change to the output which is intended
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update",false,"rewrite the golden Verilog files with the current output")
+
+// the test programs which are not translated, and why
+var goldenSkip = map[string]string{
+	"diagnostics.go": "fails with two errors by design, see make diagnostics",
+}
+
+// translate a program with the default flags of main and return its Verilog
+func translateGolden(t *testing.T,fileName string) []byte {
+	var parsedProgram *argoListener
+
+	parsedProgram = parseArgo(&fileName,false,false)
+	parsedProgram.mulStyle = "comb"
+	parsedProgram.ioInterface = "start"
+	parsedProgram.keywordPrefix = "go_"
+	parsedProgram.intBits = 32
+	parsedProgram.maxMem = 1048576
+	parsedProgram.ignoredCalls = make(map[string]string)
+	parsedProgram.runPasses(false,false)
+
+	outFile, err := os.CreateTemp(t.TempDir(),"golden*.v")
+	if (err != nil) {
+		t.Fatalf("creating the output file: %s",err)
+	}
+	parsedProgram.outputFile = outFile
+	OutputVerilog(parsedProgram,true,2000)
+	outFile.Close()
+	if numErrors := parsedProgram.printDiagnostics() ; numErrors > 0 {
+		t.Fatalf("translation of %s failed with %d errors",fileName,numErrors)
+	}
+
+	verilog, err := os.ReadFile(outFile.Name())
+	if (err != nil) {
+		t.Fatalf("reading the output file: %s",err)
+	}
+	return normalizeVerilog(verilog,fileName)
+}
+
+// remove what depends on where the test is run from: the path of the program
+// in the source position comments becomes its base name, and line ends are \n
+func normalizeVerilog(verilog []byte,fileName string) []byte {
+	verilog = bytes.ReplaceAll(verilog,[]byte("\r\n"),[]byte("\n"))
+	return bytes.ReplaceAll(verilog,[]byte(fileName),[]byte(filepath.Base(fileName)))
+}
+
+// the first line where two outputs differ, for the failure message
+func firstDiff(got []byte,want []byte) (int, string, string) {
+	gotLines := strings.Split(string(got),"\n")
+	wantLines := strings.Split(string(want),"\n")
+	for i := 0; (i < len(gotLines)) || (i < len(wantLines)); i++ {
+		var gotLine, wantLine string
+		if (i < len(gotLines)) {
+			gotLine = gotLines[i]
+		}
+		if (i < len(wantLines)) {
+			wantLine = wantLines[i]
+		}
+		if (gotLine != wantLine) {
+			return i+1, gotLine, wantLine
+		}
+	}
+	return 0, "", ""
+}
+
+func TestGoldenVerilog(t *testing.T) {
+	programs, err := filepath.Glob("../test/*.go")
+	if (err != nil) {
+		t.Fatalf("listing the test programs: %s",err)
+	}
+	for _, program := range programs {
+		base := filepath.Base(program)
+		// the negative tests do not translate
+		if strings.HasSuffix(base,"_bad.go") {
+			continue
+		}
+		if reason, ok := goldenSkip[base] ; ok {
+			t.Logf("skipping %s: %s",base,reason)
+			continue
+		}
+		program := program
+		t.Run(strings.TrimSuffix(base,".go"), func(t *testing.T) {
+			goldenName := filepath.Join("../test/golden",strings.TrimSuffix(base,".go") + ".v")
+			got := translateGolden(t,program)
+			if (*update) {
+				if err := os.MkdirAll(filepath.Dir(goldenName),0755) ; err != nil {
+					t.Fatalf("creating the golden directory: %s",err)
+				}
+				if err := os.WriteFile(goldenName,got,0644) ; err != nil {
+					t.Fatalf("writing %s: %s",goldenName,err)
+				}
+				return
+			}
+			want, err := os.ReadFile(goldenName)
+			if (err != nil) {
+				t.Fatalf("no golden file for %s, run make golden-update: %s",base,err)
+			}
+			if (!bytes.Equal(got,want)) {
+				line, gotLine, wantLine := firstDiff(got,want)
+				t.Errorf("%s differs from %s at line %d:\n got: %s\nwant: %s",base,goldenName,line,gotLine,wantLine)
+			}
+		})
+	}
+}
@@ -18,8 +18,8 @@
 /* A FIFO template for Channels */
 /* this file is the template for the Verilog Templates for Channels */
 
-/* switch for positive vs negative resets */
-`define NEGRESET
+/* switch for positive vs negative resets. The generated modules reset on a high rst */
+// `define NEGRESET
 
 `ifdef NEGRESET
   `define RESET (~(rst))
@@ -50,12 +50,13 @@ module argo_fifo #(parameter ADDR_WIDTH=3, DATA_WIDTH=32, DEPTH = (1 << ADDR_WID
    wire [DATA_WIDTH-1:0]   data_ram ;
 
    /********* full empty status lines *******/
-   assign full = (item_cnt == (DEPTH-1)); 
+   /* the FIFO holds DEPTH items, the capacity of the channel */
+   assign full = (item_cnt == DEPTH); 
    assign empty = (item_cnt == 0);
 
    /******* Instantiate a dual read/write port RAM for this FIFO *****/
    d_p_ram #(.ADDR_WIDTH(ADDR_WIDTH),.DATA_WIDTH(DATA_WIDTH),.DEPTH(DEPTH)) FIFO_RAM (
-     .clk(clk),
+     .clock(clk),
      .write_en(wr_en),					  
      .write_addr(write_ptr),
      .read_addr(read_ptr),					  
@@ -78,7 +79,7 @@ module argo_fifo #(parameter ADDR_WIDTH=3, DATA_WIDTH=32, DEPTH = (1 << ADDR_WID
 	 write_ptr <= 0 ;
       end else if (wr_en) begin
 	 $display("%5d,%s,%4d, ID %2d incrementing write pointer at val %d ",cycle_count,`__FILE__,`__LINE__,fifo_id,write_ptr);
-	 write_ptr <= write_ptr + 1;
+	 write_ptr <= (write_ptr == DEPTH-1) ? 0 : write_ptr + 1;
       end else begin 
 	 write_ptr <= write_ptr;
       end 
@@ -89,7 +90,7 @@ module argo_fifo #(parameter ADDR_WIDTH=3, DATA_WIDTH=32, DEPTH = (1 << ADDR_WID
       if `RESET begin 
 	 read_ptr <= 0 ;
       end else if (rd_en) begin
-	 read_ptr <= read_ptr + 1;
+	 read_ptr <= (read_ptr == DEPTH-1) ? 0 : read_ptr + 1;
 	 $display("%5d,%s,%4d, ID %2d increment read pointer at val %d",cycle_count,`__FILE__,`__LINE__,fifo_id, read_ptr);
       end else begin 
 	 read_ptr <= read_ptr;
@@ -0,0 +1,99 @@
+/* Argo to Verilog Compiler: Verilog Templates 
+    (c) 2020, Richard P. Martin and contributers 
+    
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    This program is distributed WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License Version 3 for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>
+*/
+
+/* A bench for the channel FIFO. The FIFO is empty after a high rst and runs
+   while rst is low, as the generated modules and test bench drive it. It holds
+   DEPTH items and its pointers wrap at DEPTH */
+
+module argo_fifo_bench ;
+   reg clk ;
+   reg rst ;
+   reg rd_en ;
+   reg wr_en ;
+   reg [7:0] wr_data ;
+   wire [7:0] rd_data ;
+   wire full ;
+   wire empty ;
+   integer errors ;
+
+   argo_fifo #(.ADDR_WIDTH(2),.DATA_WIDTH(8),.DEPTH(3),.FIFO_ID(1)) fifo (
+     .clk(clk),
+     .rst(rst),
+     .rd_en(rd_en),
+     .rd_data(rd_data),
+     .wr_en(wr_en),
+     .wr_data(wr_data),
+     .full(full),
+     .empty(empty)
+   );
+
+   always #5 clk = ~clk ;
+
+   /* count a failed check */
+   task check_that ;
+      input ok ;
+      input [8*40-1:0] what ;
+      begin
+	 if (!ok) begin
+	    $display("argo_fifo check failed: %0s at %0t",what,$time);
+	    errors = errors + 1 ;
+	 end
+      end
+   endtask
+
+   /* the inputs change on the falling edge, away from the clock edge of the FIFO */
+   initial begin
+      errors = 0 ;
+      clk = 0 ; rst = 1 ; rd_en = 0 ; wr_en = 0 ; wr_data = 0 ;
+      @(negedge clk) ; @(negedge clk) ;
+      check_that(empty && !full,"empty after a high rst") ;
+
+      /* the FIFO is not held in reset while rst is low */
+      rst = 0 ;
+      wr_en = 1 ;
+      wr_data = 8'd11 ; @(negedge clk) ;
+      wr_data = 8'd22 ; @(negedge clk) ;
+      wr_data = 8'd33 ; @(negedge clk) ;
+      wr_en = 0 ;
+      check_that(full && !empty,"full with DEPTH items") ;
+      check_that(rd_data == 8'd11,"the first item") ;
+
+      /* the RAM gives the next item a cycle after a read */
+      rd_en = 1 ; @(negedge clk) ; rd_en = 0 ;
+      check_that(!full,"not full after a read") ;
+      @(negedge clk) ;
+      check_that(rd_data == 8'd22,"the second item") ;
+
+      /* the write pointer wraps at DEPTH, then the read pointer */
+      wr_en = 1 ; wr_data = 8'd44 ; @(negedge clk) ; wr_en = 0 ;
+      check_that(full,"full after the write pointer wraps") ;
+      rd_en = 1 ; @(negedge clk) ; rd_en = 0 ; @(negedge clk) ;
+      check_that(rd_data == 8'd33,"the third item") ;
+      rd_en = 1 ; @(negedge clk) ; rd_en = 0 ; @(negedge clk) ;
+      check_that(rd_data == 8'd44,"the item after the read pointer wraps") ;
+
+      /* a high rst empties the FIFO again */
+      rst = 1 ; @(negedge clk) ; rst = 0 ;
+      check_that(empty && !full,"empty after a second rst") ;
+
+      if (errors == 0) begin
+	 $display("argo_fifo PASS") ;
+      end else begin
+	 $display("argo_fifo FAIL %0d checks",errors) ;
+      end
+      $finish ;
+   end
+endmodule // argo_fifo_bench
@@ -0,0 +1,40 @@
+/* Argo to Verilog Compiler: Verilog Templates 
+    (c) 2020, Richard P. Martin and contributers 
+    
+    This program is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    This program is distributed WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+    GNU General Public License Version 3 for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with this program.  If not, see <https://www.gnu.org/licenses/>
+*/
+
+/* a pipelined multiplier for argo2verilog -mulstyle=seq */
+/* the product of a and b is valid LATENCY cycles after they are presented. */
+/* the product wraps to WIDTH bits, as a Go multiply does */
+module argo_mult #(parameter WIDTH = 32, LATENCY = 3)
+                 (clock, a, b, product);
+
+/* port definitions */    
+   input wire 			clock;
+   input wire [WIDTH-1:0] 	a;
+   input wire [WIDTH-1:0] 	b;
+   output wire [WIDTH-1:0] 	product;
+
+   reg [WIDTH-1:0] stage [0:LATENCY-1]; 
+   integer i;
+
+   always @ (posedge clock) begin
+      stage[0] <= a * b;
+      for (i = 1; i < LATENCY; i = i + 1) begin
+         stage[i] <= stage[i-1];
+      end
+   end
+
+   assign product = stage[LATENCY-1];
+endmodule
@@ -0,0 +1,22 @@
+// small program to test passing arrays by reference to functions
+
+package main ;
+
+import ( "fmt" ) ;
+
+// b is read-only, so sumEnds only gets the read ports of the caller's array 
+func sumEnds(a int, b [8]int) int {
+	var first, last int ;
+
+	first = b[0] ;
+	last = b[7] ;
+	return a + first + last ;
+} ;
+
+func main() {
+	var table [8]int ;
+	var s int ;
+
+	s = sumEnds(3,table) ;
+	fmt.Printf("sum of the ends is %d \n",s) ;
+} ;
@@ -0,0 +1,30 @@
+// small program to test the blank identifier in range loops and assignments 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func divmod(a int, b int) (int, int) {
+	var q,r int ;
+
+	q = a / b ;
+	r = a % b ;
+	return q, r ;
+} ;
+
+func main() {
+	var rem, total int ;
+	var values [4]int ;
+
+	// the index is used and the value discarded, as in router-csp.go 
+	total = 0 ;
+	for j, _ := range values {
+		total = total + j ;
+	} ;
+
+	// the blank identifier keeps the results aligned 
+	quot, _ := divmod(total,4) ;
+	_, rem = divmod(total,4) ;
+
+	fmt.Printf("total %d quotient %d remainder %d \n",total,quot,rem) ;
+} ;
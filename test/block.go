@@ -0,0 +1,26 @@
+// small program to test nested blocks. The statements of a bare block run
+// in order between the statements around them 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var a, b int ;
+
+	a = 1 ;
+	{
+		b = a + 1 ;
+		{
+			a := b * 2 ;
+			b = a + 1 ;
+		} ;
+	} ;
+	if (b > 4) {
+		{
+			a = a + b ;
+		} ;
+	} ;
+	{ } ;
+	fmt.Printf("a is %d b is %d \n",a,b) ;
+} ;
@@ -0,0 +1,23 @@
+// small program to test a call statement with no result. The caller waits
+// for the callee to return before the next statement 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func show(x int) {
+	var y int ;
+
+	y = x * 2 ;
+	fmt.Printf("show %d twice is %d \n",x,y) ;
+} ;
+
+func main() {
+	var i int ;
+
+	i = 3 ;
+	show(i) ;
+	i = i + 1 ;
+	show(i) ;
+	fmt.Printf("done at %d \n",i) ;
+} ;
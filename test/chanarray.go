@@ -0,0 +1,40 @@
+// Small program to test arrays of channels. Each element of the array is a
+// FIFO, and a send or receive selects the FIFO of the element by its index 
+
+package main ;
+
+import ( "fmt" ) ;
+
+const NCHAN = 4 ;
+
+func main() {
+	var cs [NCHAN]chan int ;
+	var grid [2][2]chan uint8 ;
+	var i, j, sum int ;
+	var b uint8 ;
+
+	for i = 0; i < NCHAN; i++ {
+		cs[i] = make(chan int,2) ;
+	} ;
+	grid[0][0] = make(chan uint8) ;
+	grid[0][1] = make(chan uint8,1) ;
+	grid[1][0] = make(chan uint8,1) ;
+	grid[1][1] = make(chan uint8,1) ;
+
+	for i = 0; i < NCHAN; i++ {
+		cs[i] <- i * 10 ;
+		cs[NCHAN-1-i] <- i ;
+	} ;
+	sum = 0 ;
+	for i = 0; i < NCHAN; i++ {
+		j = <- cs[i] ;
+		sum = sum + j ;
+		j = <- cs[i] ;
+		sum = sum + j ;
+		fmt.Printf("chanarray %d %d \n",i,sum) ;
+	} ;
+
+	grid[1][i-NCHAN] <- 7 ;
+	b = <- grid[1][0] ;
+	fmt.Printf("chanarray grid %d \n",b) ;
+} ;
@@ -0,0 +1,40 @@
+// small program to test that a channel holds exactly the capacity of its make.
+// main fills each channel before it receives, which blocks forever if the FIFO
+// holds fewer values. make chancap compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+const CAP = 3 ;
+
+func main() {
+	var sum, x int ;
+
+	one := make(chan int,1) ;
+	four := make(chan int, 4) ;
+	named := make(chan int,CAP) ;
+
+	one <- 7 ;
+	x = <- one ;
+	fmt.Printf("chancap one %d \n",x) ;
+
+	for i := 0; i < 4; i++ {
+		four <- i + 10 ;
+	} ;
+	for i := 0; i < 4; i++ {
+		x = <- four ;
+		sum = sum + x ;
+	} ;
+	fmt.Printf("chancap four %d \n",sum) ;
+
+	for i := 0; i < CAP; i++ {
+		named <- i * 2 ;
+	} ;
+	sum = 0 ;
+	for i := 0; i < CAP; i++ {
+		x = <- named ;
+		sum = sum + x ;
+	} ;
+	fmt.Printf("chancap named %d \n",sum) ;
+} ;
@@ -0,0 +1,35 @@
+// small program to test directional channels. producer only sends on its chan<-
+// parameter and consumer only receives from its <-chan parameter, so -check
+// accepts them. chandir_bad.go is the same program with a send on a receive-only
+// channel, which -check rejects
+
+package main ;
+
+import ( "fmt" ) ;
+
+func producer(out chan<- int) {
+	var i int ;
+
+	for i = 0; i < 4; i++ {
+		out <- i * 3 ;
+	} ;
+} ;
+
+func consumer(in <-chan int, done chan<- int) {
+	var i, sum int ;
+
+	sum = 0 ;
+	for i = 0; i < 4; i++ {
+		sum = sum + <- in ;
+	} ;
+	done <- sum ;
+} ;
+
+func main() {
+	data := make(chan int,4) ;
+	done := make(chan int,1) ;
+
+	go producer(data) ;
+	go consumer(data,done) ;
+	fmt.Printf("chandir %d \n",<- done) ;
+} ;
@@ -0,0 +1,34 @@
+// negative test of directional channels, it does not build with go build: consumer
+// sends on its receive-only parameter in. make chandir checks -check rejects it
+
+package main ;
+
+import ( "fmt" ) ;
+
+func producer(out chan<- int) {
+	var i int ;
+
+	for i = 0; i < 4; i++ {
+		out <- i * 3 ;
+	} ;
+} ;
+
+func consumer(in <-chan int, done chan<- int) {
+	var i, sum int ;
+
+	sum = 0 ;
+	for i = 0; i < 4; i++ {
+		sum = sum + <- in ;
+	} ;
+	in <- sum ;
+	done <- sum ;
+} ;
+
+func main() {
+	data := make(chan int,4) ;
+	done := make(chan int,1) ;
+
+	go producer(data) ;
+	go consumer(data,done) ;
+	fmt.Printf("chandir %d \n",<- done) ;
+} ;
@@ -0,0 +1,26 @@
+// small program to test sending computed expressions on channels 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, k, total int ;
+	var out chan int ;
+	var done chan int ;
+
+	out = make(chan int, 4) ;
+	done = make(chan int, 4) ;
+	k = 3 ;
+	for i = 0 ; i < 4 ; i++ {
+		select {
+		case out <- i * k + 1 :
+			k = k + 1 ;
+		case done <- (i + k) :
+			total = total + 1 ;
+		} ;
+	} ;
+	(done) <- k - i ;
+	total = <- out ;
+	fmt.Printf("total %d \n",total) ;
+} ;
@@ -0,0 +1,25 @@
+// small program to test sends and receives on channels whose element width
+// differs from the value sent or the variable received into. The sent value
+// and the received data are sized as if converted to the type 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var small uint8 ;
+	var wide int64 ;
+	var word uint32 ;
+
+	words := make(chan uint32, 2) ;
+	bytes := make(chan uint8, 2) ;
+
+	small = 200 ;
+	words <- uint32(small) + 100 ;
+	words <- 7 ;
+	wide = int64(<-words) ;
+	word = <- words ;
+	bytes <- uint8(word + 300) ;
+	small = <- bytes ;
+	fmt.Printf("wide %d word %d small %d \n",wide,word,small) ;
+} ;
@@ -0,0 +1,44 @@
+// small program to test closing a channel. The producer closes the channel
+// after its last value, which ends the consumer's loop 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func producer(data chan uint16, total uint16) {
+	var i uint16 ;
+
+	for i = 0; i < total; i = i + 1 {
+		data <- i * 3 ;
+	} ;
+	close(data) ;
+} ;
+
+func consumer(data chan uint16, done chan uint16) {
+	var sum uint16 ;
+	var val uint16 ;
+	var open bool ;
+
+	sum = 0 ;
+	for {
+		val, open = <- data ;
+		if (!open) {
+			break ;
+		} ;
+		sum = sum + val ;
+	} ;
+	done <- sum ;
+} ;
+
+func main() {
+	var sum uint16 ;
+
+	data := make(chan uint16, 2) ;
+	done := make(chan uint16, 1) ;
+
+	go consumer(data,done) ;
+	go producer(data,5) ;
+
+	sum = <- done ;
+	fmt.Printf("sum is %d \n",sum) ;
+} ;
@@ -0,0 +1,37 @@
+// small program to test the comma-ok form of a channel receive 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func producer(data chan uint16, total uint16) {
+	var i uint16 ;
+
+	for i = 0; i < total; i = i + 1 {
+		data <- i * 3 ;
+	} ;
+} ;
+
+func main() {
+	var sum uint16 ;
+	var val uint16 ;
+	var open bool ;
+
+	data := make(chan uint16, 2) ;
+
+	go producer(data,3) ;
+
+	sum = 0 ;
+	// the declared form 
+	v, ok := <- data ;
+	if (ok) {
+		sum = sum + v ;
+	} ;
+	// the assigned form 
+	val, open = <- data ;
+	if (open) {
+		sum = sum + val ;
+	} ;
+	val, open = <- data ;
+	fmt.Printf("sum is %d last is %d open is %t \n",sum,val,open) ;
+} ;
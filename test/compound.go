@@ -0,0 +1,44 @@
+// small program to test the compound assignment operators 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var a, b int ;
+	var u uint32 ;
+
+	a = 100 ;
+	b = 7 ;
+	u = 0xf0f0 ;
+
+	a += b ;
+	fmt.Printf("a += b is %d \n",a) ;
+	a -= 3 ;
+	fmt.Printf("a -= 3 is %d \n",a) ;
+	a *= 2 ;
+	fmt.Printf("a *= 2 is %d \n",a) ;
+	a /= b + 1 ;
+	fmt.Printf("a /= b + 1 is %d \n",a) ;
+	a %= 5 ;
+	fmt.Printf("a %%= 5 is %d \n",a) ;
+
+	u |= 0x000f ;
+	fmt.Printf("u |= 0x000f is 0x%x \n",u) ;
+	u &= 0xff0f ;
+	fmt.Printf("u &= 0xff0f is 0x%x \n",u) ;
+	u ^= 0x0101 ;
+	fmt.Printf("u ^= 0x0101 is 0x%x \n",u) ;
+	u &^= 0x0003 ;
+	fmt.Printf("u &^= 0x0003 is 0x%x \n",u) ;
+	u <<= 4 ;
+	fmt.Printf("u <<= 4 is 0x%x \n",u) ;
+	u >>= 2 ;
+	fmt.Printf("u >>= 2 is 0x%x \n",u) ;
+
+	// the left hand side is read in a loop 
+	for b = 0; b < 4; b += 1 {
+		a += b ;
+	} ;
+	fmt.Printf("final a is %d b is %d \n",a,b) ;
+} ;
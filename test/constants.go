@@ -0,0 +1,28 @@
+// small program to test named constants in array lengths, indexes and shifts 
+
+package main ;
+
+import ( "fmt" ) ;
+
+const LOG uint32 = 2 ;
+const SIZE uint32 = (1<<LOG) ;
+const DEPTH = (LOG + 1) ;
+const ( ROWS = DEPTH + 1 ; MASK uint8 = 0xF0 ; ) ;
+
+func main() {
+	const first = 1 ;
+	var table [ROWS][SIZE] int ;
+	var i, bits, total int ;
+	var r uint32 ;
+
+	bits = 0 ;
+	total = 0 ;
+	for i = 0 ; i < int(SIZE) ; i++ {
+		bits = bits | (1 << i) ;
+		r = uint32(i) ;
+		total = total + table[DEPTH][int(r)] ;
+		total = total + table[i][first] ;
+	} ;
+	bits = (bits >> LOG) & int(MASK) ;
+	fmt.Printf("bits %d total %d \n",bits,total) ;
+} ;
@@ -0,0 +1,25 @@
+// small program to test -datawidth, which gives every integer without an explicit
+// size the same width. make datawidth checks the int registers are 16 bits and the
+// uint8 register stays 8 bits, and compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, sum int ;
+	var small uint8 ;
+	var values [4]int ;
+
+	mask := 0xFF ;
+	sum = 0 ;
+	small = 250 ;
+	for i = 0; i < 4; i++ {
+		values[i] = i * 1000 ;
+	} ;
+	for i = 0; i < 4; i++ {
+		sum = sum + values[i] ;
+		small = small + 2 ;
+	} ;
+	fmt.Printf("datawidth %d %d %d \n",sum,small,sum & mask) ;
+} ;
@@ -0,0 +1,36 @@
+// small program to test the deadlock warnings under -check
+// main sends on an unbuffered channel nothing else receives from,
+// and ping and pong each wait on the other before sending
+
+package main ;
+
+import ( "fmt" ) ;
+
+func ping(in chan int, out chan int) {
+	var v int ;
+
+	v = <- in ;
+	out <- v + 1 ;
+} ;
+
+func pong(in chan int, out chan int) {
+	var v int ;
+
+	v = <- in ;
+	out <- v + 1 ;
+} ;
+
+func main() {
+	var v int ;
+
+	self := make(chan int) ;
+	a := make(chan int, 1) ;
+	b := make(chan int, 1) ;
+
+	go ping(a,b) ;
+	go pong(b,a) ;
+
+	self <- 1 ;
+	v = <- self ;
+	fmt.Printf("v is %d \n",v) ;
+} ;
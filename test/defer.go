@@ -0,0 +1,33 @@
+// small program to test defer in a function with multiple returns 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func done() {
+	fmt.Printf("done \n") ;
+} ;
+
+func cleanup() {
+	fmt.Printf("cleanup \n") ;
+} ;
+
+func clamp(a int) int {
+	defer done() ;
+	defer cleanup() ;
+
+	if (a > 10) {
+		return 10 ;
+	} ;
+	if (a < 0) {
+		return 0 ;
+	} ;
+	return a ;
+} ;
+
+func main() {
+	var i int ;
+
+	i = clamp(17) ;
+	fmt.Printf("clamped %d \n",i) ;
+} ;
@@ -0,0 +1,38 @@
+// small program to test defer statements which are not reached on every path.
+// The first return is before any defer, and the second defer is in an if 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func done() {
+	fmt.Printf("deferearly done \n") ;
+} ;
+
+func clipped() {
+	fmt.Printf("deferearly clipped \n") ;
+} ;
+
+func scale(a int) int {
+	// no deferred call runs for this return 
+	if (a < 0) {
+		return 0 ;
+	} ;
+	defer done() ;
+	if (a > 10) {
+		defer clipped() ;
+		return 20 ;
+	} ;
+	return a + a ;
+} ;
+
+func main() {
+	var i int ;
+
+	i = scale(-3) ;
+	fmt.Printf("deferearly scale -3 is %d \n",i) ;
+	i = scale(4) ;
+	fmt.Printf("deferearly scale 4 is %d \n",i) ;
+	i = scale(17) ;
+	fmt.Printf("deferearly scale 17 is %d \n",i) ;
+} ;
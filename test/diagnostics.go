@@ -0,0 +1,25 @@
+// small program with two errors the translation reports in one run: each length
+// of a channel is compared with a value other than 0. make diagnostics checks both
+// errors are printed and the translation fails
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var n int ;
+
+	data := make(chan int,4) ;
+	data <- 1 ;
+	data <- 2 ;
+	n = 0 ;
+	if (len(data) > 1) {
+		n = n + 1 ;
+	} ;
+	if (len(data) == 2) {
+		n = n + 2 ;
+	} ;
+	n = n + <- data ;
+	n = n + <- data ;
+	fmt.Printf("diagnostics %d \n",n) ;
+} ;
@@ -0,0 +1,31 @@
+// small program to test function variables and function parameters bound to one function 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func plusOne(a int) int {
+	return a + 1 ;
+} ;
+
+func twice(b int) int {
+	return b + b ;
+} ;
+
+func apply(f func(int) int, x int) int {
+	var y int ;
+
+	y = f(x) ;
+	return y ;
+} ;
+
+func main() {
+	var g func(int) int ;
+	var i, j int ;
+
+	g = twice ;
+	h := plusOne ;
+	i = g(3) ;
+	j = apply(h,i) ;
+	fmt.Printf("i %d j %d \n",i,j) ;
+} ;
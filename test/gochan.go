@@ -0,0 +1,36 @@
+// small program to test the channels connecting goroutines. worker receives from
+// jobs and sends on results, which main sends on and receives from. Its log
+// channel is never used, so -check warns its port is not connected
+
+package main ;
+
+import ( "fmt" ) ;
+
+func worker(jobs chan int, results chan int, log chan int) {
+	var i, job int ;
+
+	for i = 0; i < 3; i++ {
+		job = <- jobs ;
+		results <- job * 2 ;
+	} ;
+} ;
+
+func main() {
+	var i, sum, r int ;
+
+	jobs := make(chan int,3) ;
+	results := make(chan int,3) ;
+	log := make(chan int,1) ;
+
+	go worker(jobs,results,log) ;
+	for i = 0; i < 3; i++ {
+		jobs <- i + 1 ;
+	} ;
+	sum = 0 ;
+	for i = 0; i < 3; i++ {
+		r = <- results ;
+		sum = sum + r ;
+	} ;
+	log <- sum ;
+	fmt.Printf("gochan %d %d \n",sum,<- log) ;
+} ;
@@ -0,0 +1,29 @@
+// small program to test assignments to elements of arrays, e.g. a[i] = x, which
+// write the memory of the array at the index and only read the index variables.
+// make indexassign compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var a [8]int ;
+	var grid [4][4]int16 ;
+	var i, j, x, y int ;
+	var g, h int16 ;
+
+	i = 2 ;
+	j = 3 ;
+	a[3] = 12 ;
+	a[i] = 7 ;
+	a[i+1] += 5 ;
+	grid[1][j] = 11 ;
+	grid[i][1] = int16(a[3]) ;
+	grid[1][j] -= 1 ;
+	x = a[2] ;
+	y = a[3] ;
+	g = grid[1][3] ;
+	h = grid[2][1] ;
+	fmt.Printf("indexassign a %d %d %d \n",x,y,i) ;
+	fmt.Printf("indexassign grid %d %d %d \n",g,h,j) ;
+} ;
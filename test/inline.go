@@ -0,0 +1,35 @@
+// small program to test inlining the functions called from one statement 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func plusOne(a int) int {
+	return a + 1 ;
+} ;
+
+func decoid(input,output int) int {
+	output = input ;
+	if (input > 3) {
+		return output + input ;
+	} else {
+		return input + input + output ;
+	} ;
+} ;
+
+func report(x int) {
+	fmt.Printf("report %d \n",x) ;
+} ;
+
+func main() {
+	var i,j,k int ;
+
+	i = 2 ;
+	j = plusOne(i) ;
+	for k = 0 ; k < 3 ; k++ {
+		i = i + k ;
+	} ;
+	k = decoid(j,i) ;
+	report(k) ;
+	fmt.Printf("i j k are %d %d %d \n",i,j,k) ;
+} ;
@@ -0,0 +1,33 @@
+// small program to test enums of iota, whose values repeat the expression of the
+// constSpec before them. The enums are localparams in the hardware.
+// make iota compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+const ( IDLE = iota ; RUN ; STOP ; ) ;
+const ( QUIT uint8 = iota + 1 ; DEBUG_ON ; DEBUG_OFF ; ) ;
+const ( FLAG_A = 1 << iota ; FLAG_B ; FLAG_C ; ) ;
+
+func main() {
+	var state, flags, i int ;
+	var msg uint8 ;
+
+	state = IDLE ;
+	flags = 0 ;
+	msg = DEBUG_ON ;
+	for i = 0; i < 3; i++ {
+		if (state == IDLE) {
+			state = RUN ;
+			flags = flags | FLAG_A ;
+		} else if (state == RUN) {
+			state = STOP ;
+			flags = flags | FLAG_C ;
+		} ;
+	} ;
+	if (msg != QUIT) && (msg < DEBUG_OFF) {
+		fmt.Printf("iota message %d \n",msg) ;
+	} ;
+	fmt.Printf("iota state %d flags %d last %d \n",state,flags,FLAG_C) ;
+} ;
@@ -0,0 +1,23 @@
+// small program to test Go identifiers which are Verilog keywords. The
+// registers and modules are renamed with the -keywordprefix prefix 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func output(reg uint8, wire uint8) uint8 {
+	var begin uint8 ;
+
+	begin = reg + wire ;
+	return begin ;
+} ;
+
+func main() {
+	var module, end uint8 ;
+	var done bool ;
+
+	module = 3 ;
+	end = output(module,4) ;
+	done = end == 7 ;
+	fmt.Printf("end is %d done is %t \n",end,done) ;
+} ;
@@ -0,0 +1,40 @@
+// small program to test break and continue to the label of an enclosing for or
+// switch statement. Go does not break out of a labeled block, so a break to a
+// block label is an error, see labels_bad.go 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, j, sum, found int ;
+
+	sum = 0 ;
+outer:
+	for i = 0; i < 5; i++ {
+		for j = 0; j < 5; j++ {
+			if (j > i) {
+				continue outer ;
+			} ;
+			if (i + j == 6) {
+				break outer ;
+			} ;
+			sum = sum + j ;
+		} ;
+	} ;
+
+	found = 0 ;
+	for i = 0; i < 8; i++ {
+	cases:
+		switch i & 3 {
+		case 1:
+			if (i > 4) {
+				break cases ;
+			} ;
+			found = found + 1 ;
+		case 2:
+			found = found + 10 ;
+		} ;
+	} ;
+	fmt.Printf("labels i %d j %d sum %d found %d \n",i,j,sum,found) ;
+} ;
@@ -0,0 +1,24 @@
+// negative test of labels, it does not build with go build: Go does not break out
+// of a labeled block, so the break to done is rejected. make labels checks the
+// translator rejects it too 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, sum int ;
+
+	sum = 0 ;
+	for i = 0; i < 4; i++ {
+	done:
+		{
+			sum = sum + i ;
+			if (sum > 3) {
+				break done ;
+			} ;
+			sum = sum + 1 ;
+		} ;
+	} ;
+	fmt.Printf("labels_bad sum %d \n",sum) ;
+} ;
@@ -0,0 +1,27 @@
+// small program to test shifts, masks and bit extraction using the
+// lfsr3 shift sequence from router-csp.go 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var lfsr, value, bits uint16 ;
+	var i int ;
+
+	lfsr = 0xACE1 ;
+	for i = 0; i < 16 ; i++ {
+		// the lfsr3 shift sequence 
+		lfsr = lfsr ^ (lfsr >> 7) ;
+		lfsr = lfsr ^ (lfsr << 9) ;
+		lfsr = lfsr ^ (lfsr >> 13) ;
+
+		// single bit and part selects 
+		value = (lfsr >> 3) & 1 ;
+		bits = (lfsr >> 4) & 0xF ;
+		fmt.Printf("lfsr is %x bit3 %d bits7to4 %x inverted %x \n",lfsr,value,bits,^lfsr) ;
+	};
+	
+	value = lfsr &^ 0xFF ;
+	fmt.Printf("upper byte is %x \n",value) ;
+} ;
@@ -0,0 +1,21 @@
+// small program to test integer literals in every base 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var sum int ;
+
+	h := 0x1f ;
+	o := 0o17 ;
+	legacy := 017 ;
+	b := 0b1010 ;
+	d := 1_000 ;
+	big := 5_000_000_000 ;
+	var buf [0x10]int ;
+
+	buf[0] = h ;
+	sum = h + o + legacy + b + d + buf[0] ;
+	fmt.Printf("sum is %d big is %d \n",sum,big) ;
+} ;
@@ -0,0 +1,29 @@
+// small program to test the && and || operators in conditions. Both operands
+// are evaluated in hardware, so the call of odd on the right of && is always
+// made, and the check warns about it 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func odd(n int) bool {
+	return (n & 1) == 1 ;
+} ;
+
+func main() {
+	var i, count int ;
+	var done, found bool ;
+
+	count = 0 ;
+	done = false ;
+	for i = 0 ; (i < 10) && !done ; i = i + 1 {
+		if (i > 2 && i < 6) || i == 8 {
+			count = count + 1 ;
+		} ;
+		found = i > 4 && odd(i) ;
+		if found || count > 5 {
+			done = true ;
+		} ;
+	} ;
+	fmt.Printf("i %d count %d done %t \n",i,count,done) ;
+} ;
@@ -0,0 +1,35 @@
+// small program to test methods with value receivers. Methods of
+// different types may have the same name 
+
+package main ;
+
+import ( "fmt" ) ;
+
+type RouterPkt struct {
+	dst uint8 ;
+	seq uint16 ;
+} ;
+
+type Port struct {
+	id uint8 ;
+} ;
+
+func (p RouterPkt) route() uint8 {
+	return p.dst & 3 ;
+} ;
+
+func (p Port) route() uint8 {
+	return p.id + 1 ;
+} ;
+
+func main() {
+	var pkt RouterPkt ;
+	var port Port ;
+	var a, b uint8 ;
+
+	pkt = RouterPkt{dst: 6, seq: 1} ;
+	port = Port{id: 2} ;
+	a = pkt.route() ;
+	b = port.route() ;
+	fmt.Printf("route %d port %d \n",a,b) ;
+} ;
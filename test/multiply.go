@@ -0,0 +1,19 @@
+// small program to test multiplies, which -mulstyle=seq pipelines 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var a, b, c int ;
+	var x uint8 ;
+
+	a = 7 ;
+	b = 6 ;
+	c = a * b ;
+	c *= b ;
+	c = a * b * c + 1 ;
+	x = 200 ;
+	x = x * 3 ;
+	fmt.Printf("c is %d x is %d \n",c,x) ;
+} ;
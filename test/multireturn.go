@@ -0,0 +1,21 @@
+// small program to test functions returning multiple values 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func divmod(a int, b int) (int, int) {
+	var q,r int ;
+
+	q = a / b ;
+	r = a % b ;
+	return q, r ;
+} ;
+
+func main() {
+	var i int ;
+
+	i = 17 ;
+	quot, rem := divmod(i,5) ;
+	fmt.Printf("quotient %d remainder %d \n",quot,rem) ;
+} ;
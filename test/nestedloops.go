@@ -0,0 +1,64 @@
+// small program to test the control flow of nested if and for statements
+// built from the nested loops in forstatements.go 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func snafu(i,j int) int {
+	var a int ;
+	a = i;
+	if (i <= j) {
+		return i*j*a ;
+	} ;
+
+	return i*j - (i-j) ;
+} ;
+
+func main() {
+	var i,j,k int ; 
+	
+	i = 1 ;
+	j = 2 ; 
+	k = 4 ;
+
+	sum := 0x0000; 
+	// an if inside a for inside an if 
+	if (k > 2) {
+		for i = 1; i < 5 ; i = i + 1 {
+			for j = 1; j < 3; j++ {
+				for z:= 0; z < k; z++ {
+					sum = sum + j  ;
+					if (sum > 20) {
+						break;
+					} ;
+					j = snafu(j,k);
+				}; 
+				if (j > 10) {
+					continue;
+				} ;
+				sum = sum + 1 ;
+			};
+		}; 
+	} else {
+		sum = 1 ;
+	} ;
+	fmt.Printf("The sum is %d \n",sum) ;
+
+	// a for inside an if inside a for, with the for as the last statement 
+	for i = 0; i < 3 ; i = i + 1 {
+		if (i == 1) {
+			for j = 0; j < 2; j++ {
+				sum = sum + j ;
+			};
+		} else if (i == 2) {
+			for {
+				sum = sum - 1 ;
+				if (sum < 5) {
+					break;
+				} ;
+			};
+		} ;
+	};
+	fmt.Printf("Final sum is %d \n",sum) ;
+} ;
@@ -0,0 +1,33 @@
+// small program to test the identities removed from expressions, e.g. x + 0,
+// x * 1, x << 0, x & 0xFF for a uint8 and x | 0. make peephole compares the output
+// of the hardware with go run 
+
+package main ;
+
+import ( "fmt" ) ;
+
+const ZERO = 0 ;
+const ONE = 1 ;
+const MASK8 = 0xFF ;
+
+func main() {
+	var x, y uint8 ;
+	var n, k int ;
+	var s int8 ;
+
+	x = 200 ;
+	n = 12345 ;
+	s = -7 ;
+	y = (x + x) + 0 ;
+	k = n * ONE ;
+	fmt.Printf("peephole add %d mul %d \n",y,k) ;
+	y = ((x + x) & MASK8) >> 0 ;
+	k = (n << 0) | 0 ;
+	fmt.Printf("peephole mask %d shift %d \n",y,k) ;
+	k = 0 + (n ^ 0) - ZERO ;
+	n = ONE * (n / 1) ;
+	fmt.Printf("peephole xor %d div %d \n",k,n) ;
+	s = (s & -1) + (s &^ 0) ;
+	x = (x + 100) | ZERO ;
+	fmt.Printf("peephole signed %d or %d \n",s,x) ;
+} ;
@@ -0,0 +1,29 @@
+// small program to test new() and pointers to structs. There is no heap, so
+// new allocates the registers of the struct statically and the pointer names them 
+
+package main ;
+
+import ( "fmt" ) ;
+
+type Counter struct {
+	count uint16 ;
+	limit uint16 ;
+} ;
+
+func remaining(c *Counter) uint16 {
+	return c.limit - c.count ;
+} ;
+
+func main() {
+	var c *Counter ;
+	var n *int ;
+
+	c = new(Counter) ;
+	c.limit = 10 ;
+	for c.count < 4 {
+		c.count = c.count + 1 ;
+	} ;
+	n = new(int) ;
+	*n = 3 ;
+	fmt.Printf("count %d remaining %d n %d \n",c.count,remaining(c),*n) ;
+} ;
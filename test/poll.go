@@ -0,0 +1,24 @@
+// Small program to test polling a channel with len instead of a select. The
+// receive is only made when the channel is not empty, so the loop does not
+// stall on the empty channel 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var msg, got, polls, empty int ;
+
+	control := make(chan int,2) ;
+	control <- 5 ;
+	for polls = 0; polls < 3; polls++ {
+		if (len(control) > 0) {
+			msg = <- control ;
+			got = got + msg ;
+		} ;
+		if (0 == len(control)) {
+			empty = empty + 1 ;
+		} ;
+	} ;
+	fmt.Printf("poll got %d polls %d empty %d \n",got,polls,empty) ;
+} ;
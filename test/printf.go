@@ -0,0 +1,18 @@
+// small program to test translating fmt print calls to Verilog $write 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, j int ;
+	var done bool ;
+
+	i = 42 ;
+	j = 255 ;
+	done = true ;
+	fmt.Printf("i is %d j is 0x%x \n",i,j) ;
+	fmt.Printf("done %t \n",done) ;
+	fmt.Printf("%s and %d \n",fmt.Sprintf("i=%d",i),j) ;
+	fmt.Println("sum",i+j) ;
+} ;
@@ -0,0 +1,27 @@
+// Small program to test a called function which sends on a channel and returns
+// a value. The caller waits for the function to be done while the sends write the
+// caller's FIFO, then receives the values 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func produce(out chan int, base int) int {
+	var count int ;
+
+	out <- base ;
+	count = 1 ;
+	out <- base + 1 ;
+	count = count + 1 ;
+	return count ;
+} ;
+
+func main() {
+	var n, a, b int ;
+
+	data := make(chan int,2) ;
+	n = produce(data,40) ;
+	a = <- data ;
+	b = <- data ;
+	fmt.Printf("producer count %d values %d %d \n",n,a,b) ;
+} ;
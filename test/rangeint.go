@@ -0,0 +1,30 @@
+// small program to test for range over an integer 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var sum, n, j int ;
+
+	sum = 0 ;
+	n = 3 ;
+
+	// a constant count 
+	for i := range 5 {
+		sum = sum + i ;
+	} ;
+	fmt.Printf("sum of 0..4 is %d \n",sum) ;
+
+	// a variable count with an existing counter 
+	for j = range n {
+		sum = sum + j ;
+	} ;
+	fmt.Printf("sum is %d j is %d \n",sum,j) ;
+
+	// no counter at all 
+	for range 4 {
+		sum = sum * 2 ;
+	} ;
+	fmt.Printf("final sum is %d \n",sum) ;
+} ;
@@ -0,0 +1,33 @@
+// small program to test receives on the right hand side of assignments: a plain
+// assignment, a short var decl which takes the element type of the channel, a
+// parenthesized receive, a compound assignment and a receive in an expression.
+// make recvassign compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var total, last int ;
+	var small uint8 ;
+
+	data := make(chan int,4) ;
+	bytes := make(chan uint8,2) ;
+
+	data <- 3 ;
+	data <- 5 ;
+	data <- 7 ;
+	data <- 9 ;
+	bytes <- 200 ;
+	bytes <- 100 ;
+
+	total = <- data ;
+	first := <- data ;
+	last = (<- data) ;
+	total += <- data ;
+	small = <- bytes ;
+	wide := <- bytes ;
+	fmt.Printf("recvassign %d %d %d \n",total,first,last) ;
+	small = small + wide ;
+	fmt.Printf("recvassign bytes %d %d \n",small,wide) ;
+} ;
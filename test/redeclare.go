@@ -0,0 +1,27 @@
+// small program to test a short variable declaration which assigns a variable
+// declared before in the same scope, and declares the others 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func divmod(a int, b int) (int, int) {
+	return a / b, a % b ;
+} ;
+
+func scale(x int) int {
+	x, y := x * 2, x + 1 ;
+	return x + y ;
+} ;
+
+func main() {
+	var q int ;
+
+	q, r := divmod(17,5) ;
+	r, s := divmod(q + r,2) ;
+	if (s == 1) {
+		q, t := divmod(s,2) ;
+		fmt.Printf("inner q %d t %d \n",q,t) ;
+	} ;
+	fmt.Printf("q %d r %d s %d scale %d \n",q,r,s,scale(4)) ;
+} ;
@@ -0,0 +1,44 @@
+// small program to test select statements, with and without a default clause 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func producer(data chan uint32, total uint32) {
+	var i uint32 ;
+
+	for i = 0; i < total; i = i + 1 {
+		data <- i ;
+	} ;
+} ;
+
+func main() {
+	var count, polls, val uint32 ;
+	var finished bool ;
+
+	data := make(chan uint32, 2) ;
+	done := make(chan bool, 1) ;
+
+	go producer(data,4) ;
+
+	count = 0 ;
+	polls = 0 ;
+	// non-blocking select: poll the channel until all the values arrive 
+	for (count < 4) {
+		select {
+		case val = <- data:
+			count = count + 1 ;
+		default:
+			polls = polls + 1 ;
+		} ;
+	} ;
+
+	done <- true ;
+	// blocking select: waits until the channel is ready 
+	select {
+	case finished = <- done:
+		fmt.Printf("finished %t \n",finished) ;
+	} ;
+
+	fmt.Printf("count %d last %d polls %d \n",count,val,polls) ;
+} ;
@@ -0,0 +1,23 @@
+// small program to test variables shadowed in nested scopes.
+// Each shadowing i is its own register 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, sum int ;
+
+	i = 10 ;
+	sum = 0 ;
+	// the initializer reads the i of main, the loop uses its own i 
+	for i := i - 8; i < 5 ; i = i + 1 {
+		sum = sum + i ;
+		if (sum > 3) {
+			i := 100 ;
+			sum = sum + i ;
+		} ;
+	} ;
+
+	fmt.Printf("i of main is still %d, sum is %d \n",i,sum) ;
+} ;
@@ -0,0 +1,56 @@
+// small program to test comparisons of negative values, e.g. with a hex constant,
+// an element of an array or a conversion of an unsigned value, which Verilog
+// would compare as unsigned. make signcmp compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+const LIMIT = 0x10 ;
+const BIG int16 = 0x7F00 ;
+
+func main() {
+	var s int ;
+	var b int8 ;
+	var h int16 ;
+	var u uint8 ;
+	var w uint16 ;
+	var values [4]int8 ;
+	var bytes [4]uint8 ;
+
+	s = -3 ;
+	b = -1 ;
+	h = -200 ;
+	u = 200 ;
+	w = 0xFFFF ;
+	values[1] = -5 ;
+	bytes[2] = 250 ;
+
+	if (s < 0x10) {
+		fmt.Printf("signcmp hex less %d \n",s) ;
+	} ;
+	if (b < LIMIT) && (h < BIG) {
+		fmt.Printf("signcmp const less %d %d \n",b,h) ;
+	} ;
+	if (s < int(u)) {
+		fmt.Printf("signcmp conversion less %d %d \n",s,u) ;
+	} ;
+	if (int(bytes[2]) > 0) {
+		fmt.Printf("signcmp byte element %d \n",bytes[2]) ;
+	} ;
+	if (values[1] < 0) && (values[1] < b) {
+		fmt.Printf("signcmp element negative %d \n",values[1]) ;
+	} ;
+	if (int8(u) < 0) {
+		fmt.Printf("signcmp wrapped %d \n",int8(u)) ;
+	} ;
+	if (uint8(b) > u) {
+		fmt.Printf("signcmp unsigned greater %d \n",uint8(b)) ;
+	} ;
+	if (int(w) > s) && (int16(w) == -1) {
+		fmt.Printf("signcmp uint16 %d %d \n",int(w),int16(w)) ;
+	} ;
+	if (h + 100 >= -100) {
+		fmt.Printf("signcmp sum %d \n",h + 100) ;
+	} ;
+} ;
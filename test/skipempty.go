@@ -0,0 +1,25 @@
+// small program with empty statements. Each ;; is an empty statement and its eos,
+// and a ; follows every statement. make skipempty checks the hardware prints the
+// same with and without -skip-empty, and that -skip-empty has fewer control bits
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i, sum, last int ;
+
+	sum = 0 ;
+	;;
+	last = 0 ;
+	for i = 0; i < 5; i++ {
+		sum = sum + i ;
+		;;
+		if (sum > 5) {
+			last = i ;
+			;;
+		} ;
+	} ;
+	sum = sum * 2 ;
+	fmt.Printf("skipempty %d %d \n",sum,last) ;
+} ;
@@ -0,0 +1,32 @@
+// small program to test the library calls with no hardware meaning. time.Sleep is
+// removed from the control flow and runtime.Gosched takes one cycle 
+
+package main ;
+
+import ( "fmt" ) ;
+import ( "runtime" ) ;
+import ( "time" ) ;
+
+func producer(out chan int) {
+	var i int ;
+
+	for i = 0; i < 4; i++ {
+		out <- i ;
+		runtime.Gosched() ;
+	} ;
+} ;
+
+func main() {
+	var c chan int ;
+	var i, v, sum int ;
+
+	c = make(chan int,2) ;
+	go producer(c) ;
+	time.Sleep(3) ;
+	for i = 0; i < 4; i++ {
+		v = <- c ;
+		sum = sum + v ;
+		time.Sleep(1) ;
+	} ;
+	fmt.Printf("sum %d \n",sum) ;
+} ;
@@ -0,0 +1,33 @@
+// small program to test sending and receiving structs on channels.
+// A packet is sent on one channel, received, forwarded on a second
+// channel and received again with all its fields intact 
+
+package main ;
+
+import ( "fmt" ) ;
+
+type Header struct {
+	src uint8 ;
+	dst uint8 ;
+} ;
+
+type RouterPkt struct {
+	dest_port uint16 ;
+	path uint32 ;
+	header Header ;
+} ;
+
+func main() {
+	var inPkt, outPkt RouterPkt ;
+
+	first := make(chan RouterPkt, 2) ;
+	second := make(chan RouterPkt, 2) ;
+
+	first <- RouterPkt{dest_port: 3, path: 0x5, header: Header{src: 1, dst: 2}} ;
+	inPkt = <- first ;
+	inPkt.path = inPkt.path | 0x8 ;
+	second <- inPkt ;
+	outPkt = <- second ;
+
+	fmt.Printf("port %d path 0x%x src %d dst %d \n",outPkt.dest_port,outPkt.path,outPkt.header.src,outPkt.header.dst) ;
+} ;
@@ -0,0 +1,40 @@
+// small program to test struct types and composite literals 
+
+package main ;
+
+import ( "fmt" ) ;
+
+type Header struct {
+	src uint8 ;
+	dst uint8 ;
+} ;
+
+type Packet struct {
+	hdr Header ;
+	seq uint16 ;
+	valid bool ;
+} ;
+
+func sender(out chan Packet) {
+	var p Packet ;
+
+	// keyed fields, missing fields are zero 
+	p = Packet{seq: 7, hdr: Header{src: 1, dst: 2}} ;
+	out <- p ;
+
+	// positional fields with a nested literal 
+	out <- Packet{ Header{3, 4}, 8, true } ;
+} ;
+
+func main() {
+	var got Packet ;
+	var pair struct { a uint8 ; b uint8 ; } ;
+
+	pair = struct { a uint8 ; b uint8 ; }{ 5, 6 } ;
+	link := make(chan Packet, 2) ;
+
+	go sender(link) ;
+	got = <- link ;
+	got = <- link ;
+	fmt.Printf("got %d pair %d \n",got.seq,pair.b) ;
+} ;
@@ -0,0 +1,30 @@
+// small program to test break inside a switch nested in a for loop 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var i,sum int ;
+
+	sum = 0 ;
+	for i = 0; i < 10 ; i = i + 1 {
+		switch i {
+		case 3:
+			sum = sum + 3 ;
+			// breaks the switch, not the for loop 
+			break ;
+		case 7:
+			sum = sum + 7 ;
+		default:
+			sum = sum + 1 ;
+		};
+		if (sum > 15) {
+			// breaks the for loop 
+			break ;
+		};
+		fmt.Printf("switchbreak sum %d \n",sum) ;
+	};
+
+	fmt.Printf("switchbreak final sum %d at i %d \n",sum,i) ;
+} ;
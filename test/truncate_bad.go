@@ -0,0 +1,29 @@
+// negative test of the truncation warnings under -check, it does not build with go
+// build. A 32 bit sum is assigned to a 16 bit variable, a constant does not fit
+// in a byte, and a call returns an int to a uint8, which Go rejects without a
+// conversion. The conversions do not warn. make truncate checks the warnings 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func wide(a int) int {
+	return a * 1000 ;
+} ;
+
+func main() {
+	var small int16 ;
+	var b uint8 ;
+	var n int ;
+	var m0 [4]int ;
+	var m1 [2]int64 ;
+
+	n = 40000 ;
+	m0[3] = n ;
+	small = n + 1 ;
+	b = 300 ;
+	b = wide(n) ;
+	small = int16(n) ;
+	m1[1] = int64(m0[3]) ;
+	fmt.Printf("truncate %d %d %d \n",small,b,m1[1]) ;
+} ;
@@ -0,0 +1,35 @@
+// small program to test typed constants, which give their type to the variables
+// they initialize and to the constants derived from them. make typedconst
+// compares the output of the hardware with go run
+
+package main ;
+
+import ( "fmt" ) ;
+
+const STEP uint8 = 200 ;
+const WIDE uint16 = 0x1234 ;
+const NEXT = STEP + 10 ;
+const ( LOW int8 = -100 ; SHIFT = 1 << 4 ; ) ;
+
+func main() {
+	const SCALE int16 = 300 ;
+	var n int ;
+
+	x := STEP ;
+	y := NEXT ;
+	w := WIDE ;
+	s := LOW ;
+	m := SCALE * 2 ;
+
+	x = x + STEP ;
+	y = y + NEXT ;
+	w = (w << 4) + WIDE ;
+	s = s - 100 ;
+	m = m * 100 ;
+	n = int(w) + SHIFT ;
+	fmt.Printf("typedconst wrap %d %d %d \n",x,y,w) ;
+	fmt.Printf("typedconst signed %d %d %d \n",s,m,n) ;
+	if (s > LOW) && (x < NEXT) {
+		fmt.Printf("typedconst compare %d %d \n",s,x) ;
+	} ;
+} ;
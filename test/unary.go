@@ -0,0 +1,29 @@
+// small program to test the unary operators: minus, logical not and bitwise not 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var x, y int ;
+	var mask uint8 ;
+	var found bool ;
+
+	x = -1 ;
+	y = -x ;
+	mask = ^mask ;
+	found = false ;
+
+	// negative integer literals in conditions 
+	if (x == -1) {
+		found = !found ;
+	} ;
+	for (x > -4) {
+		x = x - 1 ;
+	} ;
+	if (!found) {
+		y = - -y ;
+	} ;
+
+	fmt.Printf("x %d y %d mask %x found %t \n",x,y,mask,found) ;
+} ;
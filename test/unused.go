@@ -0,0 +1,27 @@
+// small program with functions main never calls. -check warns of them and
+// -prune-unused outputs no module for them. make prune checks the modules
+
+package main ;
+
+import ( "fmt" ) ;
+
+func square(x int) int {
+	return x * x ;
+} ;
+
+// only called from helper, which is never called 
+func cube(x int) int {
+	return x * square(x) ;
+} ;
+
+// never called 
+func helper(x int) int {
+	return cube(x) + 1 ;
+} ;
+
+func main() {
+	var y int ;
+
+	y = square(5) ;
+	fmt.Printf("unused %d \n",y) ;
+} ;
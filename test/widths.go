@@ -0,0 +1,30 @@
+// small program to test arithmetic on integers of different widths. Sums wrap
+// at the width of their type, and conversions zero or sign extend and truncate 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var a, b, sum16, fold uint16 ;
+	var wide, sum32 uint32 ;
+	var small int8 ;
+	var big int32 ;
+	var carry bool ;
+
+	a = 0xffff ;
+	b = 2 ;
+	sum16 = a + b ;                    // wraps to 1 
+	sum32 = uint32(a) + uint32(b) ;    // 0x10001 
+	carry = (a + b) < a ;              // true, the uint16 sum wraps 
+	wide = uint32(a + b) << 8 ;        // 0x100, not 0x1000100 
+	small = -3 ;
+	big = int32(small) ;               // sign extended to -3 
+	b = uint16(sum32 >> 1) ;           // truncated to 0x8000 
+
+	// fold a ones complement checksum into 16 bits 
+	wide = uint32(a) + uint32(b) ;
+	fold = uint16(wide & 0xffff) + uint16(wide >> 16) ;
+
+	fmt.Printf("sum16 %d sum32 0x%x carry %t wide 0x%x big %d b 0x%x fold 0x%x \n",sum16,sum32,carry,wide,big,b,fold) ;
+} ;
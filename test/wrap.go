@@ -0,0 +1,26 @@
+// small program to test that integer arithmetic wraps at the width of its type, as
+// in Go. make wrap compares the output of the hardware with go run 
+
+package main ;
+
+import ( "fmt" ) ;
+
+func main() {
+	var count, lfsr uint8 ;
+	var small, half int8 ;
+	var i int ;
+
+	count = 250 ;
+	lfsr = 0xE1 ;
+	for i = 0; i < 10; i++ {
+		count = count + 1 ;
+		lfsr = (lfsr >> 1) ^ (-(lfsr & 1) & 0xB8) ;
+	} ;
+	small = 100 ;
+	half = (small + small) / 2 ;
+	fmt.Printf("wrap count %d lfsr %d \n",count,lfsr) ;
+	fmt.Printf("wrap half %d sum %d \n",half,count + 255) ;
+	if (count + 252 < count) {
+		fmt.Printf("wrap compare \n") ;
+	} ;
+} ;